@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+
+	"thoreinstein.com/rig/pkg/config"
+	"thoreinstein.com/rig/pkg/notes"
+)
+
+var (
+	captureWindow string
+	captureLines  int
+)
+
+// captureCmd saves pane output to the ticket note
+var captureCmd = &cobra.Command{
+	Use:   "capture [ticket]",
+	Short: "Capture pane scrollback into the ticket note",
+	Long: `Capture the output of the ticket session's current window, or the window
+named with --window, and append it to the Log section of the ticket note as
+a fenced, timestamped block.
+
+Terminal escape codes are stripped, and passwords, tokens, keys and other
+credentials are replaced with [REDACTED] before anything is written.
+
+The ticket may also be a hack name or hack/name, to capture a hack session
+into its note. If it is omitted, it is inferred from $RIG_TICKET, the
+current worktree path, or the current branch name.
+
+Examples:
+  rig capture
+  rig capture --window term --lines 500
+  rig capture proj-123 --window logs
+  rig capture hack/experiment-auth`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ticket, err := resolveTicketArg(args)
+		if err != nil {
+			return err
+		}
+		return runCaptureCommand(ticket)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(captureCmd)
+
+	captureCmd.Flags().StringVarP(&captureWindow, "window", "w", "", "Window to capture (default: the session's current window)")
+	captureCmd.Flags().IntVarP(&captureLines, "lines", "n", 200, "Number of lines to capture, including scrollback")
+}
+
+func runCaptureCommand(ticket string) error {
+	if captureLines <= 0 {
+		return errors.Newf("--lines must be positive, got %d", captureLines)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.Wrap(err, "failed to load configuration")
+	}
+
+	ref, err := noteRef(ticket)
+	if err != nil {
+		return err
+	}
+
+	sessionManager, err := tmuxSessionManager(cfg, "capture")
+	if err != nil {
+		return err
+	}
+
+	sessionName := sessionManager.GetSessionName(ref.Name)
+	if !sessionManager.SessionExists(sessionName) {
+		return errors.Newf("tmux session '%s' does not exist for ticket '%s'", sessionName, ref.Name)
+	}
+
+	windowName, err := sessionManager.WindowName(sessionName, captureWindow)
+	if err != nil {
+		return err
+	}
+
+	output, err := sessionManager.CapturePane(sessionName, captureWindow, captureLines)
+	if err != nil {
+		return err
+	}
+	output = notes.RedactSecrets(notes.StripANSI(output))
+	if strings.TrimSpace(output) == "" {
+		fmt.Printf("Nothing to capture in %s:%s\n", sessionName, windowName)
+		return nil
+	}
+
+	noteManager := newNoteManager(cfg, verbose)
+	notePath, err := noteManager.AppendTicketLog(ref.Type, ref.Name, formatCapture(time.Now(), windowName, output))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Captured %d lines from %s:%s into %s\n", strings.Count(output, "\n")+1, sessionName, windowName, notePath)
+	return nil
+}
+
+// formatCapture formats captured output as a Log entry
+func formatCapture(at time.Time, windowName, output string) string {
+	return fmt.Sprintf("- [%s] Captured `%s`\n\n%s\n", at.Format("2006-01-02 15:04"), windowName, notes.FencedBlock("text", output))
+}
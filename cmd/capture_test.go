@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestCaptureCommandStructure(t *testing.T) {
+	if captureCmd.Use != "capture [ticket]" {
+		t.Errorf("capture command Use = %q, want %q", captureCmd.Use, "capture [ticket]")
+	}
+	if captureCmd.Parent() != rootCmd {
+		t.Error("capture should be a root command")
+	}
+
+	for _, name := range []string{"window", "lines"} {
+		if captureCmd.Flags().Lookup(name) == nil {
+			t.Errorf("capture should have a --%s flag", name)
+		}
+	}
+	if lines := captureCmd.Flags().Lookup("lines"); lines != nil && lines.DefValue != "200" {
+		t.Errorf("--lines default = %s, want 200", lines.DefValue)
+	}
+}
+
+func TestFormatCapture(t *testing.T) {
+	at := time.Date(2025, 1, 15, 14, 30, 0, 0, time.Local)
+
+	got := formatCapture(at, "term", "$ make test\nok")
+	want := "- [2025-01-15 14:30] Captured `term`\n\n```text\n$ make test\nok\n```\n"
+	if got != want {
+		t.Errorf("formatCapture() =\n%q\nwant:\n%q", got, want)
+	}
+
+	if got := formatCapture(at, "term", "```go\nx\n```"); !strings.Contains(got, "````text\n") {
+		t.Errorf("output containing fences should get a longer fence, got:\n%s", got)
+	}
+}
+
+func TestRunCaptureCommand_InvalidLines(t *testing.T) {
+	captureLines = 0
+	defer func() { captureLines = 200 }()
+
+	if err := runCaptureCommand("proj-1"); err == nil || !strings.Contains(err.Error(), "--lines") {
+		t.Errorf("runCaptureCommand() error = %v, want --lines error", err)
+	}
+}
+
+func TestRunCaptureCommand_HackSession(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not available")
+	}
+
+	notesDir := t.TempDir()
+	setupSyncTestConfig(t, notesDir)
+	defer viper.Reset()
+	t.Setenv(ticketEnvVar, "")
+	t.Setenv("RIG_TICKET_TYPE", "")
+
+	notePath := filepath.Join(notesDir, "hack", "winter-cleanup.md")
+	if err := os.MkdirAll(filepath.Dir(notePath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(notePath, []byte("# winter-cleanup\n\n## Log\n\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	socket := os.Getenv("RIG_TEST_TMUX_SOCKET")
+	if out, err := exec.Command("tmux", "-L", socket, "new-session", "-d", "-s", "winter-cleanup", "-n", "term",
+		"echo hack-capture-marker; sleep 30").CombinedOutput(); err != nil {
+		t.Fatalf("failed to create session: %v: %s", err, out)
+	}
+	t.Cleanup(func() {
+		_ = exec.Command("tmux", "-L", socket, "kill-session", "-t", "winter-cleanup").Run()
+	})
+
+	// Wait for the marker to reach the pane
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		out, _ := exec.Command("tmux", "-L", socket, "capture-pane", "-p", "-t", "winter-cleanup").Output()
+		if strings.Contains(string(out), "hack-capture-marker") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for pane output")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if err := runCaptureCommand("hack/winter-cleanup"); err != nil {
+		t.Fatalf("runCaptureCommand() error = %v", err)
+	}
+
+	content, err := os.ReadFile(notePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "Captured `term`") || !strings.Contains(string(content), "hack-capture-marker") {
+		t.Errorf("hack note should contain the capture, got:\n%s", content)
+	}
+}
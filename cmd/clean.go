@@ -3,21 +3,29 @@ package cmd
 import (
 	"bufio"
 	"fmt"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cockroachdb/errors"
 	"github.com/spf13/cobra"
 
 	"thoreinstein.com/rig/pkg/config"
 	"thoreinstein.com/rig/pkg/git"
-	"thoreinstein.com/rig/pkg/tmux"
+	"thoreinstein.com/rig/pkg/jira"
 )
 
 var cleanDryRun bool
 var cleanForce bool
+var cleanMergedOnly bool
+var cleanOlderThan string
+var cleanNoSession bool
+var cleanTicketStatus string
 
 // cleanCmd represents the clean command
 var cleanCmd = &cobra.Command{
@@ -26,12 +34,20 @@ var cleanCmd = &cobra.Command{
 	Long: `Clean up git worktrees and their associated tmux sessions.
 
 This command identifies worktrees that can be safely removed and offers
-to clean them up. By default, it prompts for confirmation before removing.
+to clean them up. By default, it prompts you to select which candidates
+to remove.
+
+Policy filters narrow the candidate list. Defaults can be set in the
+[clean] section of the config file; flags take precedence.
 
 Examples:
-  rig clean              # Interactive cleanup with confirmation
-  rig clean --dry-run    # Show what would be removed without removing
-  rig clean --force      # Remove without confirmation`,
+  rig clean                        # Interactive selection of worktrees to remove
+  rig clean --dry-run              # Show what would be removed without removing
+  rig clean --force                # Remove all candidates without confirmation
+  rig clean --merged-only          # Only branches merged into the base branch
+  rig clean --older-than 30d       # Only worktrees inactive for 30 days
+  rig clean --no-session           # Skip worktrees with an active tmux session
+  rig clean --ticket-status Done   # Only tickets with the given JIRA status`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runCleanCommand()
 	},
@@ -42,16 +58,39 @@ func init() {
 
 	cleanCmd.Flags().BoolVar(&cleanDryRun, "dry-run", false, "Show what would be removed without removing")
 	cleanCmd.Flags().BoolVar(&cleanForce, "force", false, "Remove without confirmation prompts")
+	cleanCmd.Flags().BoolVar(&cleanMergedOnly, "merged-only", false, "Only remove worktrees whose branch is merged")
+	cleanCmd.Flags().StringVar(&cleanOlderThan, "older-than", "", "Only remove worktrees inactive for this long (e.g., 30d, 2w, 12h)")
+	cleanCmd.Flags().BoolVar(&cleanNoSession, "no-session", false, "Skip worktrees that have an active tmux session")
+	cleanCmd.Flags().StringVar(&cleanTicketStatus, "ticket-status", "", "Only remove worktrees whose JIRA ticket has this status (e.g., Done)")
 }
 
 // CleanupCandidate represents a worktree that can be cleaned up
 type CleanupCandidate struct {
 	Path       string
 	Branch     string
+	BaseBranch string
 	RepoName   string
 	RepoPath   string
 	IsMerged   bool
 	HasSession bool
+
+	// Populated only when the corresponding policy filter is active
+	LastActivity time.Time
+	TicketStatus string
+}
+
+// cleanPolicy describes which worktrees are eligible for removal.
+// Zero values disable the corresponding filter.
+type cleanPolicy struct {
+	MergedOnly   bool
+	OlderThan    time.Duration
+	NoSession    bool
+	TicketStatus string
+}
+
+// isActive reports whether any filter is enabled
+func (p cleanPolicy) isActive() bool {
+	return p.MergedOnly || p.OlderThan > 0 || p.NoSession || p.TicketStatus != ""
 }
 
 func runCleanCommand() error {
@@ -61,12 +100,21 @@ func runCleanCommand() error {
 		return errors.Wrap(err, "failed to load configuration")
 	}
 
+	policy, err := resolveCleanPolicy(cfg)
+	if err != nil {
+		return err
+	}
+
 	// Find cleanup candidates
 	candidates, err := findCleanupCandidates(cfg)
 	if err != nil {
 		return errors.Wrap(err, "failed to find cleanup candidates")
 	}
 
+	if policy.isActive() {
+		candidates = applyCleanPolicy(cfg, candidates, policy, time.Now())
+	}
+
 	if len(candidates) == 0 {
 		fmt.Println("No worktrees found to clean up.")
 		return nil
@@ -84,12 +132,21 @@ func runCleanCommand() error {
 		if candidate.HasSession {
 			status += " [has session]"
 		}
+		if candidate.TicketStatus != "" {
+			status += fmt.Sprintf(" [%s]", candidate.TicketStatus)
+		}
 
 		relPath := strings.TrimPrefix(candidate.Path, candidate.RepoPath+"/")
 		fmt.Printf("  %d. [%s] %s%s\n", i+1, candidate.RepoName, relPath, status)
 		if verbose {
 			fmt.Printf("      Branch: %s\n", candidate.Branch)
+			if candidate.BaseBranch != "" {
+				fmt.Printf("      Base: %s\n", candidate.BaseBranch)
+			}
 			fmt.Printf("      Path: %s\n", candidate.Path)
+			if !candidate.LastActivity.IsZero() {
+				fmt.Printf("      Last activity: %s\n", candidate.LastActivity.Format("2006-01-02 15:04"))
+			}
 		}
 	}
 	fmt.Println()
@@ -99,25 +156,36 @@ func runCleanCommand() error {
 		return nil
 	}
 
-	// Confirm unless --force
+	// Let the user pick candidates unless --force
+	selected := candidates
 	if !cleanForce {
-		fmt.Printf("Remove %d worktree(s)? [y/N]: ", len(candidates))
+		fmt.Print("Select worktrees to remove (e.g. 1,3-4 or 'all'; empty to abort): ")
 		reader := bufio.NewReader(os.Stdin)
 		response, err := reader.ReadString('\n')
 		if err != nil {
 			return errors.Wrap(err, "failed to read input")
 		}
 
-		response = strings.TrimSpace(strings.ToLower(response))
-		if response != "y" && response != "yes" {
+		indexes, err := parseSelection(response, len(candidates))
+		if err != nil {
+			fmt.Printf("Invalid selection: %v\n", err)
+			fmt.Println("Aborted.")
+			return nil
+		}
+		if len(indexes) == 0 {
 			fmt.Println("Aborted.")
 			return nil
 		}
+
+		selected = make([]CleanupCandidate, 0, len(indexes))
+		for _, idx := range indexes {
+			selected = append(selected, candidates[idx])
+		}
 	}
 
 	// Remove worktrees
 	removed := 0
-	for _, candidate := range candidates {
+	for _, candidate := range selected {
 		err := removeWorktree(cfg, candidate)
 		if err != nil {
 			fmt.Printf("  Failed to remove %s: %v\n", candidate.Path, err)
@@ -143,10 +211,13 @@ func findCleanupCandidates(cfg *config.Config) ([]CleanupCandidate, error) {
 		return nil, err
 	}
 
-	sessionManager := tmux.NewSessionManager(cfg.Tmux.SessionPrefix, nil, verbose)
+	sessionManager, err := newMultiplexer(cfg, nil)
+	if err != nil {
+		return nil, err
+	}
 	sessions, err := sessionManager.ListSessions()
 	if err != nil && verbose {
-		fmt.Printf("Warning: Could not list tmux sessions: %v\n", err)
+		fmt.Printf("Warning: Could not list %s sessions: %v\n", sessionManager.Name(), err)
 	}
 	sessionSet := make(map[string]bool)
 	for _, s := range sessions {
@@ -160,12 +231,6 @@ func findCleanupCandidates(cfg *config.Config) ([]CleanupCandidate, error) {
 
 	worktreeDetails := getWorktreeDetailsForClean(repoRoot)
 
-	// Determine base branch for merge checking
-	baseBranch, err := gitManager.GetDefaultBranch()
-	if err != nil {
-		baseBranch = "main" // fallback
-	}
-
 	candidates := make([]CleanupCandidate, 0, len(worktrees))
 	for _, wt := range worktrees {
 		// Skip the main repo path (handle symlink resolution for comparison)
@@ -187,12 +252,18 @@ func findCleanupCandidates(cfg *config.Config) ([]CleanupCandidate, error) {
 			sessionName = cfg.Tmux.SessionPrefix + sessionName
 		}
 
-		// Check if branch is merged
+		// Check if branch is merged into its recorded base (e.g. a stacked
+		// parent or release branch), falling back to the default branch
+		baseBranch, err := gitManager.BaseBranchFor(branch)
+		if err != nil {
+			baseBranch = "main" // fallback
+		}
 		isMerged := isBranchMerged(repoRoot, branch, baseBranch)
 
 		candidate := CleanupCandidate{
 			Path:       wt,
 			Branch:     branch,
+			BaseBranch: baseBranch,
 			RepoName:   repoName,
 			RepoPath:   repoRoot,
 			IsMerged:   isMerged,
@@ -205,6 +276,228 @@ func findCleanupCandidates(cfg *config.Config) ([]CleanupCandidate, error) {
 	return candidates, nil
 }
 
+// resolveCleanPolicy merges config defaults with command-line flags.
+// Flags only ever enable or override a filter; they cannot unset a config default.
+func resolveCleanPolicy(cfg *config.Config) (cleanPolicy, error) {
+	policy := cleanPolicy{
+		MergedOnly:   cfg.Clean.MergedOnly || cleanMergedOnly,
+		NoSession:    cfg.Clean.NoSession || cleanNoSession,
+		TicketStatus: cfg.Clean.TicketStatus,
+	}
+
+	if cleanTicketStatus != "" {
+		policy.TicketStatus = cleanTicketStatus
+	}
+	// The status comes from JIRA, so without it the filter can't match anything
+	if policy.TicketStatus != "" && !cfg.Jira.Enabled {
+		if cleanTicketStatus != "" {
+			return policy, errors.New("--ticket-status requires jira.enabled")
+		}
+		return policy, errors.New("clean.ticket_status requires jira.enabled")
+	}
+
+	olderThan := cfg.Clean.OlderThan
+	if cleanOlderThan != "" {
+		olderThan = cleanOlderThan
+	}
+	if olderThan != "" {
+		age, err := parseAge(olderThan)
+		if err != nil {
+			return policy, errors.Wrap(err, "invalid --older-than value")
+		}
+		policy.OlderThan = age
+	}
+
+	return policy, nil
+}
+
+// parseAge parses a duration that additionally supports day (d) and week (w) units
+func parseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, errors.New("age cannot be empty")
+	}
+
+	unit := value[len(value)-1]
+	if unit == 'd' || unit == 'w' {
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err != nil || n < 0 {
+			return 0, errors.Newf("unable to parse age: %s", value)
+		}
+		days := n
+		if unit == 'w' {
+			days = n * 7
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return 0, errors.Newf("unable to parse age: %s", value)
+	}
+	return age, nil
+}
+
+// applyCleanPolicy fills in the data each active filter needs and returns the
+// candidates that satisfy all of them
+func applyCleanPolicy(cfg *config.Config, candidates []CleanupCandidate, policy cleanPolicy, now time.Time) []CleanupCandidate {
+	var jiraClient *jira.Client
+	if policy.TicketStatus != "" && cfg.Jira.Enabled {
+		client, err := jira.NewClient(cfg.Jira.CliCommand, verbose)
+		if err != nil {
+			if verbose {
+				fmt.Printf("Warning: Invalid JIRA CLI command: %v\n", err)
+			}
+		} else {
+			jiraClient = client
+		}
+	}
+
+	result := make([]CleanupCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		if policy.OlderThan > 0 {
+			candidate.LastActivity = lastWorktreeActivity(candidate.RepoPath, candidate.Path, candidate.Branch)
+		}
+
+		if policy.TicketStatus != "" && jiraClient != nil {
+			if ticketInfo, err := parseTicket(filepath.Base(candidate.Path)); err == nil {
+				jiraInfo, err := jiraClient.FetchTicketDetails(ticketInfo.Full)
+				if err != nil {
+					if verbose {
+						fmt.Printf("Warning: Could not fetch JIRA details for %s: %v\n", ticketInfo.Full, err)
+					}
+				} else {
+					candidate.TicketStatus = jiraInfo.Status
+				}
+			}
+		}
+
+		if matchesCleanPolicy(candidate, policy, now) {
+			result = append(result, candidate)
+		}
+	}
+
+	return result
+}
+
+// matchesCleanPolicy reports whether a candidate passes every active filter.
+// Candidates with unknown activity or ticket status are kept out when the
+// corresponding filter is active, so missing data never leads to removal.
+func matchesCleanPolicy(candidate CleanupCandidate, policy cleanPolicy, now time.Time) bool {
+	if policy.MergedOnly && !candidate.IsMerged {
+		return false
+	}
+
+	if policy.NoSession && candidate.HasSession {
+		return false
+	}
+
+	if policy.OlderThan > 0 {
+		if candidate.LastActivity.IsZero() || now.Sub(candidate.LastActivity) < policy.OlderThan {
+			return false
+		}
+	}
+
+	if policy.TicketStatus != "" && !strings.EqualFold(candidate.TicketStatus, policy.TicketStatus) {
+		return false
+	}
+
+	return true
+}
+
+// lastWorktreeActivity returns the later of the branch's last commit time and
+// the newest file modification time in the worktree
+func lastWorktreeActivity(repoPath, worktreePath, branch string) time.Time {
+	var latest time.Time
+
+	if branch != "" {
+		cmd := exec.Command("git", "log", "-1", "--format=%ct", branch)
+		cmd.Dir = repoPath
+		if output, err := cmd.Output(); err == nil {
+			if ts, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64); err == nil {
+				latest = time.Unix(ts, 0)
+			}
+		}
+	}
+
+	_ = filepath.WalkDir(worktreePath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip unreadable entries
+		}
+		if d.Name() == ".git" {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		return nil
+	})
+
+	return latest
+}
+
+// parseSelection parses a selection such as "1,3-4" or "all" into sorted,
+// zero-based candidate indexes. An empty selection returns no indexes.
+func parseSelection(input string, count int) ([]int, error) {
+	input = strings.TrimSpace(strings.ToLower(input))
+	if input == "" || input == "n" || input == "no" {
+		return nil, nil
+	}
+
+	if input == "a" || input == "all" || input == "y" || input == "yes" {
+		indexes := make([]int, count)
+		for i := range indexes {
+			indexes[i] = i
+		}
+		return indexes, nil
+	}
+
+	seen := make(map[int]bool)
+	for _, field := range strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == ' ' }) {
+		start, end := field, field
+		if before, after, found := strings.Cut(field, "-"); found {
+			start, end = before, after
+		}
+
+		lo, err := strconv.Atoi(start)
+		if err != nil {
+			return nil, errors.Newf("not a number: %q", start)
+		}
+		hi, err := strconv.Atoi(end)
+		if err != nil {
+			return nil, errors.Newf("not a number: %q", end)
+		}
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		if lo < 1 || hi > count {
+			return nil, errors.Newf("selection %q out of range 1-%d", field, count)
+		}
+
+		for n := lo; n <= hi; n++ {
+			seen[n-1] = true
+		}
+	}
+
+	indexes := make([]int, 0, len(seen))
+	for idx := range seen {
+		indexes = append(indexes, idx)
+	}
+	sort.Ints(indexes)
+
+	return indexes, nil
+}
+
 func getWorktreeDetailsForClean(repoPath string) map[string]WorktreeInfo {
 	result := make(map[string]WorktreeInfo)
 
@@ -268,13 +561,16 @@ func removeWorktree(cfg *config.Config, candidate CleanupCandidate) error {
 			sessionName = cfg.Tmux.SessionPrefix + sessionName
 		}
 
-		sessionManager := tmux.NewSessionManager(cfg.Tmux.SessionPrefix, nil, verbose)
-		if err := sessionManager.KillSession(filepath.Base(candidate.Path)); err != nil {
+		sessionManager, err := newMultiplexer(cfg, nil)
+		if err == nil {
+			err = sessionManager.KillSession(filepath.Base(candidate.Path))
+		}
+		if err != nil {
 			if verbose {
 				fmt.Printf("    Warning: Could not kill session %s: %v\n", sessionName, err)
 			}
 		} else if verbose {
-			fmt.Printf("    Killed tmux session: %s\n", sessionName)
+			fmt.Printf("    Killed %s session: %s\n", sessionManager.Name(), sessionName)
 		}
 	}
 
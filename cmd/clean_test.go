@@ -4,7 +4,10 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 
@@ -991,3 +994,271 @@ func setupCleanTestConfig(t *testing.T, notesPath string) {
 func loadTestConfig() (*config.Config, error) {
 	return config.Load()
 }
+
+func TestCleanPolicyFlags(t *testing.T) {
+	cmd := cleanCmd
+
+	for _, name := range []string{"merged-only", "older-than", "no-session", "ticket-status"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Errorf("clean command should have --%s flag", name)
+		}
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{input: "30d", expected: 30 * 24 * time.Hour},
+		{input: "2w", expected: 14 * 24 * time.Hour},
+		{input: "12h", expected: 12 * time.Hour},
+		{input: "90m", expected: 90 * time.Minute},
+		{input: "0d", expected: 0},
+		{input: "", wantErr: true},
+		{input: "d", wantErr: true},
+		{input: "-3d", wantErr: true},
+		{input: "thirty", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseAge(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseAge(%q) expected error, got %v", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseAge(%q) error: %v", tt.input, err)
+			}
+			if got != tt.expected {
+				t.Errorf("parseAge(%q) = %v, want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseSelection(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		count    int
+		expected []int
+		wantErr  bool
+	}{
+		{name: "empty aborts", input: "\n", count: 3, expected: nil},
+		{name: "no aborts", input: "n", count: 3, expected: nil},
+		{name: "all", input: "all\n", count: 3, expected: []int{0, 1, 2}},
+		{name: "yes selects all", input: "y", count: 2, expected: []int{0, 1}},
+		{name: "single", input: "2", count: 3, expected: []int{1}},
+		{name: "list", input: "3,1", count: 3, expected: []int{0, 2}},
+		{name: "range", input: "2-4", count: 5, expected: []int{1, 2, 3}},
+		{name: "mixed with spaces", input: "1, 3-4", count: 4, expected: []int{0, 2, 3}},
+		{name: "duplicates collapse", input: "1,1-2", count: 2, expected: []int{0, 1}},
+		{name: "out of range", input: "4", count: 3, wantErr: true},
+		{name: "zero", input: "0", count: 3, wantErr: true},
+		{name: "garbage", input: "abc", count: 3, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSelection(tt.input, tt.count)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseSelection(%q) expected error, got %v", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSelection(%q) error: %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) && !(len(got) == 0 && len(tt.expected) == 0) {
+				t.Errorf("parseSelection(%q) = %v, want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMatchesCleanPolicy(t *testing.T) {
+	now := time.Date(2026, 1, 31, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-45 * 24 * time.Hour)
+	recent := now.Add(-2 * 24 * time.Hour)
+
+	tests := []struct {
+		name      string
+		candidate CleanupCandidate
+		policy    cleanPolicy
+		expected  bool
+	}{
+		{
+			name:      "no policy keeps everything",
+			candidate: CleanupCandidate{HasSession: true},
+			policy:    cleanPolicy{},
+			expected:  true,
+		},
+		{
+			name:      "merged-only rejects unmerged",
+			candidate: CleanupCandidate{IsMerged: false},
+			policy:    cleanPolicy{MergedOnly: true},
+			expected:  false,
+		},
+		{
+			name:      "merged-only keeps merged",
+			candidate: CleanupCandidate{IsMerged: true},
+			policy:    cleanPolicy{MergedOnly: true},
+			expected:  true,
+		},
+		{
+			name:      "no-session rejects active session",
+			candidate: CleanupCandidate{HasSession: true},
+			policy:    cleanPolicy{NoSession: true},
+			expected:  false,
+		},
+		{
+			name:      "older-than keeps stale worktree",
+			candidate: CleanupCandidate{LastActivity: old},
+			policy:    cleanPolicy{OlderThan: 30 * 24 * time.Hour},
+			expected:  true,
+		},
+		{
+			name:      "older-than rejects recent worktree",
+			candidate: CleanupCandidate{LastActivity: recent},
+			policy:    cleanPolicy{OlderThan: 30 * 24 * time.Hour},
+			expected:  false,
+		},
+		{
+			name:      "older-than rejects unknown activity",
+			candidate: CleanupCandidate{},
+			policy:    cleanPolicy{OlderThan: 30 * 24 * time.Hour},
+			expected:  false,
+		},
+		{
+			name:      "ticket status matches case-insensitively",
+			candidate: CleanupCandidate{TicketStatus: "done"},
+			policy:    cleanPolicy{TicketStatus: "Done"},
+			expected:  true,
+		},
+		{
+			name:      "ticket status mismatch",
+			candidate: CleanupCandidate{TicketStatus: "In Progress"},
+			policy:    cleanPolicy{TicketStatus: "Done"},
+			expected:  false,
+		},
+		{
+			name:      "ticket status unknown",
+			candidate: CleanupCandidate{},
+			policy:    cleanPolicy{TicketStatus: "Done"},
+			expected:  false,
+		},
+		{
+			name:      "all filters combined",
+			candidate: CleanupCandidate{IsMerged: true, LastActivity: old, TicketStatus: "Done"},
+			policy:    cleanPolicy{MergedOnly: true, NoSession: true, OlderThan: 30 * 24 * time.Hour, TicketStatus: "Done"},
+			expected:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesCleanPolicy(tt.candidate, tt.policy, now); got != tt.expected {
+				t.Errorf("matchesCleanPolicy() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResolveCleanPolicy(t *testing.T) {
+	defer func() {
+		cleanMergedOnly = false
+		cleanOlderThan = ""
+		cleanNoSession = false
+		cleanTicketStatus = ""
+	}()
+
+	cfg := &config.Config{
+		Jira: config.JiraConfig{Enabled: true},
+		Clean: config.CleanConfig{
+			MergedOnly:   true,
+			OlderThan:    "30d",
+			TicketStatus: "Done",
+		},
+	}
+
+	policy, err := resolveCleanPolicy(cfg)
+	if err != nil {
+		t.Fatalf("resolveCleanPolicy() error: %v", err)
+	}
+	if !policy.MergedOnly || policy.OlderThan != 30*24*time.Hour || policy.TicketStatus != "Done" || policy.NoSession {
+		t.Errorf("resolveCleanPolicy() from config = %+v", policy)
+	}
+
+	// Flags override config values
+	cleanOlderThan = "1w"
+	cleanNoSession = true
+	cleanTicketStatus = "Closed"
+
+	policy, err = resolveCleanPolicy(cfg)
+	if err != nil {
+		t.Fatalf("resolveCleanPolicy() error: %v", err)
+	}
+	if policy.OlderThan != 7*24*time.Hour {
+		t.Errorf("OlderThan = %v, want 1 week", policy.OlderThan)
+	}
+	if !policy.NoSession {
+		t.Error("NoSession should be enabled by flag")
+	}
+	if policy.TicketStatus != "Closed" {
+		t.Errorf("TicketStatus = %q, want %q", policy.TicketStatus, "Closed")
+	}
+
+	cleanOlderThan = "soon"
+	if _, err := resolveCleanPolicy(cfg); err == nil {
+		t.Error("resolveCleanPolicy() should reject an invalid --older-than value")
+	}
+	cleanOlderThan = ""
+
+	// Ticket statuses come from JIRA
+	cfg.Jira.Enabled = false
+	if _, err := resolveCleanPolicy(cfg); err == nil || !strings.Contains(err.Error(), "--ticket-status requires jira.enabled") {
+		t.Errorf("resolveCleanPolicy() with JIRA disabled error = %v", err)
+	}
+	cleanTicketStatus = ""
+	if _, err := resolveCleanPolicy(cfg); err == nil || !strings.Contains(err.Error(), "clean.ticket_status requires jira.enabled") {
+		t.Errorf("resolveCleanPolicy() with JIRA disabled error = %v", err)
+	}
+	cfg.Clean.TicketStatus = ""
+	if _, err := resolveCleanPolicy(cfg); err != nil {
+		t.Errorf("resolveCleanPolicy() without a ticket status error = %v", err)
+	}
+}
+
+func TestLastWorktreeActivity(t *testing.T) {
+	worktree := t.TempDir()
+
+	older := time.Now().Add(-72 * time.Hour).Truncate(time.Second)
+	newer := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+
+	oldFile := filepath.Join(worktree, "old.txt")
+	newFile := filepath.Join(worktree, "sub", "new.txt")
+	gitFile := filepath.Join(worktree, ".git")
+
+	if err := os.MkdirAll(filepath.Dir(newFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{oldFile, newFile, gitFile} {
+		if err := os.WriteFile(f, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = os.Chtimes(oldFile, older, older)
+	_ = os.Chtimes(newFile, newer, newer)
+	// .git must be ignored even though it is the newest entry
+
+	got := lastWorktreeActivity(worktree, worktree, "")
+	if !got.Equal(newer) {
+		t.Errorf("lastWorktreeActivity() = %v, want %v", got, newer)
+	}
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+
+	"thoreinstein.com/rig/pkg/config"
+	"thoreinstein.com/rig/pkg/git"
+	"thoreinstein.com/rig/pkg/jira"
+)
+
+var (
+	commitType     string
+	commitScope    string
+	commitBody     string
+	commitBreaking bool
+	commitAll      bool
+	commitEdit     bool
+	commitDryRun   bool
+)
+
+// conventionalCommitTypes lists the accepted conventional-commit types
+var conventionalCommitTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+// commitCmd represents the commit command
+var commitCmd = &cobra.Command{
+	Use:   "commit [description]",
+	Short: "Commit with a conventional-commit message built from the ticket",
+	Long: `Create a git commit with a conventional-commit message for the current ticket.
+
+The ticket is derived from the worktree path or branch name. By default the
+ticket key is used as the scope, e.g. "feat(PROJ-123): add login". When a
+--scope is given, the ticket is added as a "Refs:" footer instead.
+
+If no description is given, the JIRA summary is used (when JIRA is enabled).
+
+Examples:
+  rig commit "add login form"
+  rig commit -t fix "handle empty token"
+  rig commit -t feat -s auth "add login form"
+  rig commit -a --edit
+  rig commit --dry-run "add login form"`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCommitCommand(strings.Join(args, " "))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(commitCmd)
+
+	commitCmd.Flags().StringVarP(&commitType, "type", "t", "feat", "Conventional-commit type ("+strings.Join(conventionalCommitTypes, ", ")+")")
+	commitCmd.Flags().StringVarP(&commitScope, "scope", "s", "", "Scope (default: ticket key)")
+	commitCmd.Flags().StringVarP(&commitBody, "body", "b", "", "Commit message body")
+	commitCmd.Flags().BoolVar(&commitBreaking, "breaking", false, "Mark as a breaking change")
+	commitCmd.Flags().BoolVarP(&commitAll, "all", "a", false, "Stage all modified and deleted files (git commit -a)")
+	commitCmd.Flags().BoolVarP(&commitEdit, "edit", "e", false, "Open the generated message in the editor before committing")
+	commitCmd.Flags().BoolVar(&commitDryRun, "dry-run", false, "Print the message without committing")
+}
+
+func runCommitCommand(description string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.Wrap(err, "failed to load configuration")
+	}
+
+	if !isConventionalCommitType(commitType) {
+		return errors.Newf("invalid commit type %q: expected one of %s", commitType, strings.Join(conventionalCommitTypes, ", "))
+	}
+
+	gitManager := git.NewWorktreeManager(cfg.Git.BaseBranch, verbose)
+	ticket := gitManager.DetectTicket()
+	if ticket == "" {
+		return errors.New("could not determine ticket from worktree path or branch")
+	}
+
+	description = strings.TrimSpace(description)
+	if description == "" {
+		description = fetchCommitDescription(cfg, ticket)
+	}
+	if description == "" {
+		return errors.New("commit description required (no JIRA summary available)")
+	}
+
+	message := buildCommitMessage(strings.ToUpper(ticket), description)
+
+	if commitDryRun {
+		fmt.Println(message)
+		return nil
+	}
+
+	args := []string{"commit", "-m", message}
+	if commitAll {
+		args = append(args, "-a")
+	}
+	if commitEdit {
+		args = append(args, "-e")
+	}
+
+	gitCmd := exec.Command("git", args...)
+	gitCmd.Stdin = os.Stdin
+	gitCmd.Stdout = os.Stdout
+	gitCmd.Stderr = os.Stderr
+
+	if err := gitCmd.Run(); err != nil {
+		return errors.Wrap(err, "git commit failed")
+	}
+
+	return nil
+}
+
+// buildCommitMessage builds the conventional-commit message from the command flags
+func buildCommitMessage(ticket, description string) string {
+	scope := commitScope
+	if scope == "" {
+		scope = ticket
+	}
+
+	return git.ConventionalCommit{
+		Type:        commitType,
+		Scope:       scope,
+		Description: description,
+		Body:        commitBody,
+		Breaking:    commitBreaking,
+		Ticket:      ticket,
+	}.String()
+}
+
+// fetchCommitDescription returns the JIRA summary for the ticket, if available
+func fetchCommitDescription(cfg *config.Config, ticket string) string {
+	if !cfg.Jira.Enabled {
+		return ""
+	}
+
+	jiraClient, err := jira.NewClient(cfg.Jira.CliCommand, verbose)
+	if err != nil {
+		if verbose {
+			fmt.Printf("Warning: Invalid JIRA CLI command: %v\n", err)
+		}
+		return ""
+	}
+
+	jiraInfo, err := jiraClient.FetchTicketDetails(ticket)
+	if err != nil {
+		if verbose {
+			fmt.Printf("Warning: Could not fetch JIRA details: %v\n", err)
+		}
+		return ""
+	}
+
+	return jiraInfo.Summary
+}
+
+// isConventionalCommitType reports whether t is an accepted conventional-commit type
+func isConventionalCommitType(t string) bool {
+	for _, valid := range conventionalCommitTypes {
+		if t == valid {
+			return true
+		}
+	}
+	return false
+}
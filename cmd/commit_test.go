@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestCommitCommandFlags(t *testing.T) {
+	for _, name := range []string{"type", "scope", "body", "breaking", "all", "edit", "dry-run"} {
+		if commitCmd.Flags().Lookup(name) == nil {
+			t.Errorf("commit command should have --%s flag", name)
+		}
+	}
+
+	if flag := commitCmd.Flags().Lookup("type"); flag != nil && flag.DefValue != "feat" {
+		t.Errorf("--type default = %q, want %q", flag.DefValue, "feat")
+	}
+}
+
+func TestIsConventionalCommitType(t *testing.T) {
+	for _, valid := range []string{"feat", "fix", "chore", "refactor"} {
+		if !isConventionalCommitType(valid) {
+			t.Errorf("isConventionalCommitType(%q) = false, want true", valid)
+		}
+	}
+	for _, invalid := range []string{"", "feature", "FIX", "wip"} {
+		if isConventionalCommitType(invalid) {
+			t.Errorf("isConventionalCommitType(%q) = true, want false", invalid)
+		}
+	}
+}
+
+func TestBuildCommitMessage(t *testing.T) {
+	defer func() {
+		commitType = "feat"
+		commitScope = ""
+		commitBody = ""
+		commitBreaking = false
+	}()
+
+	commitType = "feat"
+	commitScope = ""
+	if got := buildCommitMessage("PROJ-123", "add login"); got != "feat(PROJ-123): add login" {
+		t.Errorf("buildCommitMessage() = %q", got)
+	}
+
+	commitType = "fix"
+	commitScope = "auth"
+	commitBreaking = true
+	expected := "fix(auth)!: handle empty token\n\nRefs: PROJ-123"
+	if got := buildCommitMessage("PROJ-123", "handle empty token"); got != expected {
+		t.Errorf("buildCommitMessage() = %q, want %q", got, expected)
+	}
+}
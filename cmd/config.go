@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 
 	"github.com/cockroachdb/errors"
 	"github.com/spf13/cobra"
@@ -87,11 +88,51 @@ func createDefaultConfig() error {
 path = "~/Documents/Notes"
 daily_dir = "daily"
 template_dir = "~/.config/rig/templates"
+# "markdown", or "obsidian" for [[wikilinks]] in daily notes, .md vault
+# templates and Templater commands
+flavor = "markdown"
+# Optional directory for ticket notes inside path, e.g. "Areas/Work"
+# subdir = ""
+
+# Full-text index used by 'rig notes search' (rebuilt on demand)
+# index_path = "~/.cache/rig/notes-index.db"
+
+# Regenerate the _index.md notes of 'rig notes index' after work, sync and
+# done
+# auto_index = false
+
+# Optional per-type note directories, relative to path
+# [notes.type_subdirs]
+# incident = "Areas/Incidents"
+
+# Daily note Log entries. Repeats of an event for a ticket within
+# dedupe_window are skipped. Formats are templates per event (started,
+# resumed, synced, status, pr_opened, done) with .Ticket, .Type, .Link,
+# .Detail and .Time
+# [notes.log]
+# dedupe_window = "30m"
+# [notes.log.formats]
+# started = "🚀 Started"
+# pr_opened = "Opened {{.Detail}}"
 
 [git]
 # Optional: override auto-detected default branch
 # base_branch = "main"
 
+[clean]
+# Optional default policies for 'rig clean' (flags take precedence)
+# merged_only = true
+# older_than = "30d"
+# no_session = true
+# ticket_status = "Done"
+
+[hooks]
+# Commit message handling for 'rig hooks install'
+# mode = "prefix" adds the ticket key; "validate" only checks it
+mode = "prefix"
+pattern = "[A-Z][A-Z0-9]*-[0-9]+"
+prefix_format = "{ticket}: "
+
 [history]
 database_path = "~/.histdb/zsh-history.db"
 ignore_patterns = ["ls", "cd", "pwd", "clear"]
@@ -99,9 +140,25 @@ ignore_patterns = ["ls", "cd", "pwd", "clear"]
 [jira]
 enabled = true
 cli_command = "acli"
+# Site URL for ticket links, exported to sessions as $RIG_URL
+# base_url = "https://example.atlassian.net"
+
+[session]
+# Multiplexer for ticket sessions: "auto" (detect from $TMUX, $ZELLIJ or
+# $WEZTERM_PANE, defaulting to tmux), "tmux", "zellij" or "wezterm".
+# Windows and profiles below are used by every backend.
+backend = "auto"
 
 [tmux]
 session_prefix = ""
+# Extra commands tmux windows may run, beyond the built-in allowlist
+# allowed_commands = ["just", "lazygit", "direnv exec"]
+
+# Extra session environment variables. Values may use {ticket},
+# {ticket_type}, {repo}, {worktree_path}, {branch}, {base_branch},
+# {note_path}, {summary} and {url}.
+# [tmux.env]
+# LOG_DIR = "{worktree_path}/logs"
 
 [[tmux.windows]]
 name = "note"
@@ -115,6 +172,21 @@ working_dir = "{worktree_path}"
 [[tmux.windows]]
 name = "term"
 working_dir = "{worktree_path}"
+
+# Optional session profiles, chosen by --profile, ticket type or repository.
+# "extends" starts from another profile's windows ("default" is tmux.windows).
+# A built-in "incident" profile (note, term and logs windows) is used for
+# incident tickets and 'rig incident start'; keys set here replace its own.
+# [tmux.profiles.incident]
+# extends = "default"
+# ticket_types = ["incident"]
+#
+# [tmux.profiles.incident.env]
+# KUBECONFIG = "~/.kube/prod"
+#
+# [[tmux.profiles.incident.windows]]
+# name = "logs"
+# command = "kubectl logs -f deploy/api"
 `
 
 	// Write the default configuration with restricted permissions (owner read/write only)
@@ -141,6 +213,10 @@ func showConfig() error {
 	fmt.Printf("Notes Path:          %s\n", cfg.Notes.Path)
 	fmt.Printf("Daily Notes Dir:     %s\n", cfg.Notes.DailyDir)
 	fmt.Printf("Template Dir:        %s\n", cfg.Notes.TemplateDir)
+	fmt.Printf("Notes Flavor:        %s\n", cfg.Notes.Flavor)
+	if cfg.Notes.Subdir != "" {
+		fmt.Printf("Notes Subdir:        %s\n", cfg.Notes.Subdir)
+	}
 
 	if cfg.Git.BaseBranch != "" {
 		fmt.Printf("Git Base Branch:     %s (override)\n", cfg.Git.BaseBranch)
@@ -153,8 +229,12 @@ func showConfig() error {
 
 	if cfg.Jira.Enabled {
 		fmt.Printf("JIRA CLI Command:    %s\n", cfg.Jira.CliCommand)
+		if cfg.Jira.BaseURL != "" {
+			fmt.Printf("JIRA Base URL:       %s\n", cfg.Jira.BaseURL)
+		}
 	}
 
+	fmt.Printf("Session Backend:     %s\n", cfg.Session.Backend)
 	fmt.Printf("Tmux Windows:        %d configured\n", len(cfg.Tmux.Windows))
 	for i, window := range cfg.Tmux.Windows {
 		fmt.Printf("  %d. %s", i+1, window.Name)
@@ -164,6 +244,14 @@ func showConfig() error {
 		fmt.Println()
 	}
 
+	if len(cfg.Tmux.AllowedCommands) > 0 {
+		fmt.Printf("Allowed Commands:    %s\n", strings.Join(cfg.Tmux.AllowedCommands, ", "))
+	}
+
+	if names := cfg.Tmux.ProfileNames(); len(names) > 0 {
+		fmt.Printf("Tmux Profiles:       %s\n", strings.Join(names, ", "))
+	}
+
 	return nil
 }
 
@@ -183,6 +271,12 @@ func editConfig() error {
 		}
 	}
 
+	return openInEditor(configFile)
+}
+
+// openInEditor opens path in $EDITOR, falling back to $VISUAL and then
+// common editors (vim, vi, nano)
+func openInEditor(path string) error {
 	// Get editor from environment, with fallbacks
 	editor := os.Getenv("EDITOR")
 	if editor == "" {
@@ -202,7 +296,7 @@ func editConfig() error {
 	}
 
 	// Execute editor
-	cmd := exec.Command(editor, configFile)
+	cmd := exec.Command(editor, path)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -0,0 +1,264 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+
+	"thoreinstein.com/rig/pkg/config"
+	"thoreinstein.com/rig/pkg/git"
+)
+
+var (
+	trustYes    bool
+	trustRevoke bool
+	trustList   bool
+)
+
+// configTrustCmd reviews and approves repository-local config files
+var configTrustCmd = &cobra.Command{
+	Use:   "trust [file...]",
+	Short: "Review and approve repository-local .rig.toml files",
+	Long: `Review and approve repository-local .rig.toml files.
+
+A .rig.toml can define tmux windows, profiles and allowed commands, so rig
+ignores it until you approve its exact content. The approval is pinned to a
+SHA-256 hash in ~/.config/rig/trust.json; any later change must be reviewed
+and approved again, and is shown as a diff against the approved version.
+
+Without arguments, the .rig.toml files in the repository root and the current
+worktree are reviewed.
+
+Examples:
+  rig config trust
+  rig config trust ~/src/org/repo/.rig.toml
+  rig config trust --list
+  rig config trust --revoke .rig.toml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConfigTrustCommand(args)
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configTrustCmd)
+
+	configTrustCmd.Flags().BoolVarP(&trustYes, "yes", "y", false, "Approve without prompting")
+	configTrustCmd.Flags().BoolVar(&trustRevoke, "revoke", false, "Remove the approval for the given files")
+	configTrustCmd.Flags().BoolVar(&trustList, "list", false, "List approved files and whether they have changed")
+}
+
+func runConfigTrustCommand(args []string) error {
+	storePath, err := config.DefaultTrustStorePath()
+	if err != nil {
+		return err
+	}
+	store, err := config.LoadTrustStore(storePath)
+	if err != nil {
+		return err
+	}
+
+	if trustList {
+		return listTrustedFiles(store)
+	}
+
+	files := args
+	if len(files) == 0 {
+		files = discoverRepoConfigFiles()
+		if len(files) == 0 {
+			return errors.Newf("no %s found in the repository root or current worktree", config.RepoConfigFile)
+		}
+	}
+
+	if trustRevoke {
+		for _, file := range files {
+			if store.Revoke(file) {
+				fmt.Printf("Revoked: %s\n", file)
+			} else {
+				fmt.Printf("Not trusted: %s\n", file)
+			}
+		}
+		return store.Save()
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	changed := false
+	for _, file := range files {
+		approved, err := reviewRepoConfig(store, file, reader)
+		if err != nil {
+			return err
+		}
+		changed = changed || approved
+	}
+
+	if changed {
+		return store.Save()
+	}
+	return nil
+}
+
+// reviewRepoConfig shows a file (or its changes since approval) and asks
+// whether to trust it. Returns true if the store was updated.
+func reviewRepoConfig(store *config.TrustStore, file string, reader *bufio.Reader) (bool, error) {
+	// Check, show and pin the same bytes, so a change made while the user
+	// reviews the file is never trusted unseen
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to read %s", file)
+	}
+
+	status, entry, err := store.ContentStatus(file, content)
+	if err != nil {
+		return false, err
+	}
+
+	if status == config.Trusted {
+		fmt.Printf("Already trusted: %s\n", file)
+		return false, nil
+	}
+
+	fmt.Printf("=== %s (%s) ===\n", file, status)
+	if status == config.Changed && entry != nil {
+		for _, line := range lineDiff(entry.Content, string(content)) {
+			fmt.Println(line)
+		}
+	} else {
+		for _, line := range splitLines(string(content)) {
+			fmt.Println("  " + line)
+		}
+	}
+	fmt.Println()
+
+	if !trustYes && !confirmTrust(file, reader) {
+		fmt.Println("Not trusted.")
+		return false, nil
+	}
+
+	if err := store.TrustContent(file, content); err != nil {
+		return false, err
+	}
+	fmt.Printf("Trusted: %s\n", file)
+	return true, nil
+}
+
+// confirmTrust asks whether to trust a file
+func confirmTrust(file string, reader *bufio.Reader) bool {
+	fmt.Printf("Trust %s? Its commands will run in new tmux sessions. [y/N]: ", file)
+
+	response, err := reader.ReadString('\n')
+	if err != nil {
+		return false
+	}
+
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "y" || response == "yes"
+}
+
+// listTrustedFiles prints the trust store entries with their current status
+func listTrustedFiles(store *config.TrustStore) error {
+	files := store.Files()
+	if len(files) == 0 {
+		fmt.Println("No trusted files.")
+		return nil
+	}
+
+	for _, file := range files {
+		status, _, err := store.Status(file)
+		label := status.String()
+		if err != nil {
+			label = "missing"
+		}
+		fmt.Printf("  %-10s %s\n", label, file)
+	}
+
+	return nil
+}
+
+// discoverRepoConfigFiles returns the existing .rig.toml files in the
+// repository root and the current worktree
+func discoverRepoConfigFiles() []string {
+	gitManager := git.NewWorktreeManager("", verbose)
+
+	var dirs []string
+	if repoRoot, err := gitManager.GetRepoRoot(); err == nil {
+		dirs = append(dirs, repoRoot)
+	}
+	if worktree, err := gitManager.CurrentWorktree(); err == nil {
+		dirs = append(dirs, worktree)
+	}
+
+	var files []string
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		path := filepath.Join(dir, config.RepoConfigFile)
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		if _, err := os.Stat(path); err == nil {
+			files = append(files, path)
+		}
+	}
+
+	return files
+}
+
+// lineDiff returns a minimal line diff from old to new. Lines are prefixed
+// with "- " (removed), "+ " (added) or "  " (unchanged).
+func lineDiff(old, new string) []string {
+	a := splitLines(old)
+	b := splitLines(new)
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	diff := make([]string, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = append(diff, "  "+a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, "- "+a[i])
+			i++
+		default:
+			diff = append(diff, "+ "+b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, "- "+a[i])
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, "+ "+b[j])
+	}
+
+	return diff
+}
+
+// splitLines splits s into lines, ignoring a trailing newline
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
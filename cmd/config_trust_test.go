@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"thoreinstein.com/rig/pkg/config"
+)
+
+func TestConfigTrustCommandFlags(t *testing.T) {
+	if configTrustCmd.Parent() != configCmd {
+		t.Error("trust should be a subcommand of config")
+	}
+	for _, name := range []string{"yes", "revoke", "list"} {
+		if configTrustCmd.Flags().Lookup(name) == nil {
+			t.Errorf("config trust command should have --%s flag", name)
+		}
+	}
+}
+
+func TestLineDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want []string
+	}{
+		{
+			name: "unchanged",
+			old:  "a\nb\n",
+			new:  "a\nb\n",
+			want: []string{"  a", "  b"},
+		},
+		{
+			name: "changed line",
+			old:  "[tmux]\nallowed_commands = [\"just\"]\n",
+			new:  "[tmux]\nallowed_commands = [\"just\", \"curl\"]\n",
+			want: []string{"  [tmux]", "- allowed_commands = [\"just\"]", "+ allowed_commands = [\"just\", \"curl\"]"},
+		},
+		{
+			name: "added and removed",
+			old:  "a\nb\nc",
+			new:  "a\nc\nd",
+			want: []string{"  a", "- b", "  c", "+ d"},
+		},
+		{
+			name: "from empty",
+			old:  "",
+			new:  "a\n",
+			want: []string{"+ a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lineDiff(tt.old, tt.new)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("lineDiff() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestReviewRepoConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), config.RepoConfigFile)
+	if err := os.WriteFile(file, []byte("[tmux]\nallowed_commands = [\"just\"]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := config.LoadTrustStore(filepath.Join(t.TempDir(), "trust.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Declining leaves the file untrusted
+	var approved bool
+	output := captureOutput(func() {
+		approved, err = reviewRepoConfig(store, file, bufio.NewReader(strings.NewReader("n\n")))
+	})
+	if err != nil || approved {
+		t.Fatalf("reviewRepoConfig() = %v, %v; want not approved", approved, err)
+	}
+	if !strings.Contains(output, `allowed_commands = ["just"]`) {
+		t.Errorf("review should show the file content, got:\n%s", output)
+	}
+
+	// Accepting trusts it
+	_ = captureOutput(func() {
+		approved, err = reviewRepoConfig(store, file, bufio.NewReader(strings.NewReader("y\n")))
+	})
+	if err != nil || !approved {
+		t.Fatalf("reviewRepoConfig() = %v, %v; want approved", approved, err)
+	}
+	if status, _, _ := store.Status(file); status != config.Trusted {
+		t.Errorf("status = %v, want trusted", status)
+	}
+
+	// A change is shown as a diff against the approved content
+	if err := os.WriteFile(file, []byte("[tmux]\nallowed_commands = [\"just\", \"curl\"]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	output = captureOutput(func() {
+		approved, err = reviewRepoConfig(store, file, bufio.NewReader(strings.NewReader("\n")))
+	})
+	if err != nil || approved {
+		t.Fatalf("reviewRepoConfig() = %v, %v; want not approved", approved, err)
+	}
+	if !strings.Contains(output, "(changed)") || !strings.Contains(output, `+ allowed_commands = ["just", "curl"]`) {
+		t.Errorf("review of a changed file should show a diff, got:\n%s", output)
+	}
+	if status, _, _ := store.Status(file); status != config.Changed {
+		t.Errorf("status = %v, want changed", status)
+	}
+
+	// A change made while the user reviews the file is not trusted with it
+	reviewed := "[tmux]\nallowed_commands = [\"just\", \"curl\"]\n"
+	_ = captureOutput(func() {
+		approved, err = reviewRepoConfig(store, file, bufio.NewReader(&editingReader{file: file, content: "[tmux]\nallowed_commands = [\"sh\"]\n", answer: "y\n"}))
+	})
+	if err != nil || !approved {
+		t.Fatalf("reviewRepoConfig() = %v, %v; want approved", approved, err)
+	}
+	if status, entry, _ := store.Status(file); status != config.Changed || entry == nil || entry.Content != reviewed {
+		t.Errorf("status = %v, entry = %+v; want the reviewed content pinned and the edit unapproved", status, entry)
+	}
+}
+
+// editingReader rewrites file before answering the trust prompt, as if it
+// changed while the user reviewed it
+type editingReader struct {
+	file, content, answer string
+	done                  bool
+}
+
+func (r *editingReader) Read(p []byte) (int, error) {
+	if r.done {
+		return 0, io.EOF
+	}
+	r.done = true
+	if err := os.WriteFile(r.file, []byte(r.content), 0644); err != nil {
+		return 0, err
+	}
+	return copy(p, r.answer), nil
+}
@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+
+	"thoreinstein.com/rig/pkg/config"
+	"thoreinstein.com/rig/pkg/git"
+	"thoreinstein.com/rig/pkg/jira"
+)
+
+var envProfile string
+
+// envCmd prints a ticket's session environment as shell exports
+var envCmd = &cobra.Command{
+	Use:   "env [ticket]",
+	Short: "Print a ticket's session environment as shell exports",
+	Long: `Print the environment variables rig sets in a ticket's session, as
+shell export lines, for use outside the session:
+
+  RIG_TICKET, RIG_TICKET_TYPE, RIG_REPO, RIG_WORKTREE, RIG_BRANCH,
+  RIG_BASE_BRANCH, RIG_NOTE, RIG_SUMMARY and RIG_URL
+
+followed by the env variables of the ticket's session profile. Variables
+with no value (e.g. RIG_URL without jira.base_url) are omitted.
+
+If the ticket is omitted, it is inferred from $RIG_TICKET, the current
+worktree path, or the current branch name.
+
+Examples:
+  eval "$(rig env)"
+  eval "$(rig env proj-123)"
+  rig env proj-123 --profile incident`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ticket, err := resolveTicketArg(args)
+		if err != nil {
+			return err
+		}
+		return runEnvCommand(ticket)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(envCmd)
+
+	envCmd.Flags().StringVar(&envProfile, "profile", "", "Session profile whose env to include (default: selected by ticket type and repo)")
+}
+
+// ticketContext describes a ticket's worktree, note and tracker details,
+// exported into its session as RIG_* variables
+type ticketContext struct {
+	Ticket     string
+	TicketType string
+	Repo       string
+	Worktree   string
+	Branch     string
+	BaseBranch string
+	Note       string
+	Summary    string
+	URL        string
+}
+
+// variables returns every RIG_* variable of the context, empty or not
+func (c ticketContext) variables() map[string]string {
+	return map[string]string{
+		"RIG_TICKET":      c.Ticket,
+		"RIG_TICKET_TYPE": c.TicketType,
+		"RIG_REPO":        c.Repo,
+		"RIG_WORKTREE":    c.Worktree,
+		"RIG_BRANCH":      c.Branch,
+		"RIG_BASE_BRANCH": c.BaseBranch,
+		"RIG_NOTE":        c.Note,
+		"RIG_SUMMARY":     c.Summary,
+		"RIG_URL":         c.URL,
+	}
+}
+
+// environment returns the context as RIG_* variables, omitting empty values
+func (c ticketContext) environment() map[string]string {
+	env := make(map[string]string)
+	for key, value := range c.variables() {
+		if value != "" {
+			env[key] = value
+		}
+	}
+	return env
+}
+
+// placeholders returns the values for {name} placeholders in profile env
+func (c ticketContext) placeholders() map[string]string {
+	return map[string]string{
+		"ticket":        c.Ticket,
+		"ticket_type":   c.TicketType,
+		"repo":          c.Repo,
+		"worktree_path": c.Worktree,
+		"branch":        c.Branch,
+		"base_branch":   c.BaseBranch,
+		"note_path":     c.Note,
+		"summary":       c.Summary,
+		"url":           c.URL,
+	}
+}
+
+// sessionEnvironment returns the full session environment for a ticket:
+// its RIG_* context plus profile variables with placeholders expanded.
+// Profile variables cannot override the RIG_* context.
+func (c ticketContext) sessionEnvironment(profileEnv map[string]string) map[string]string {
+	env := make(map[string]string, len(profileEnv))
+	placeholders := c.placeholders()
+	for key, value := range profileEnv {
+		env[key] = expandEnvTemplate(value, placeholders)
+	}
+	for key, value := range c.environment() {
+		env[key] = value
+	}
+	return env
+}
+
+// expandEnvTemplate replaces {name} placeholders with their values and a
+// leading ~/ with the home directory. Unknown placeholders are left as-is.
+func expandEnvTemplate(value string, placeholders map[string]string) string {
+	if strings.HasPrefix(value, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			value = filepath.Join(home, value[2:])
+		}
+	}
+
+	var b strings.Builder
+	for {
+		start := strings.Index(value, "{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(value[start:], "}")
+		if end < 0 {
+			break
+		}
+		end += start
+
+		b.WriteString(value[:start])
+		if replacement, ok := placeholders[value[start+1:end]]; ok {
+			b.WriteString(replacement)
+		} else {
+			b.WriteString(value[start : end+1])
+		}
+		value = value[end+1:]
+	}
+	b.WriteString(value)
+
+	return b.String()
+}
+
+// newTicketContext gathers the context of a ticket whose worktree exists.
+// baseBranch may be empty, in which case the branch's recorded base or the
+// default branch is used.
+func newTicketContext(cfg *config.Config, gitManager *git.WorktreeManager, ticket, ticketType, repoName, worktreePath, notePath, baseBranch string) ticketContext {
+	ctx := ticketContext{
+		Ticket:     ticket,
+		TicketType: ticketType,
+		Repo:       repoName,
+		Worktree:   worktreePath,
+		Note:       notePath,
+		BaseBranch: baseBranch,
+	}
+
+	ctx.Branch, _ = gitManager.BranchForWorktree(worktreePath)
+	if ctx.BaseBranch == "" && ctx.Branch != "" {
+		ctx.BaseBranch, _ = gitManager.BaseBranchFor(ctx.Branch)
+	}
+
+	noteManager := newNoteManager(cfg, false)
+	ctx.Summary = noteManager.TicketSummary(ticketType, ticket)
+
+	// Hacks aren't tracker tickets
+	if ticketType != "hack" {
+		ctx.URL = jira.TicketURL(cfg.Jira.BaseURL, ticket)
+	}
+
+	return ctx
+}
+
+func runEnvCommand(ticket string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.Wrap(err, "failed to load configuration")
+	}
+
+	gitManager := git.NewWorktreeManager(cfg.Git.BaseBranch, false)
+	repoRoot, err := gitManager.GetRepoRoot()
+	if err != nil {
+		return err
+	}
+	repoName, err := gitManager.GetRepoName()
+	if err != nil {
+		return err
+	}
+
+	worktreePath, err := findTicketWorktree(gitManager, ticket)
+	if err != nil {
+		return err
+	}
+	ticket = filepath.Base(worktreePath)
+	ticketType := filepath.Base(filepath.Dir(worktreePath))
+
+	noteManager := newNoteManager(cfg, false)
+	notePath := noteManager.GetNotePath(ticketType, ticket)
+	if _, err := os.Stat(notePath); err != nil {
+		notePath = ""
+	}
+
+	_, profileEnv, err := resolveSessionWindows(cfg, envProfile, ticketType, repoRoot, repoName, worktreePath)
+	if err != nil {
+		return err
+	}
+
+	ctx := newTicketContext(cfg, gitManager, ticket, ticketType, repoName, worktreePath, notePath, "")
+	fmt.Print(formatExports(ctx.sessionEnvironment(profileEnv)))
+	return nil
+}
+
+// findTicketWorktree returns the current repository's worktree for ticket
+func findTicketWorktree(gitManager *git.WorktreeManager, ticket string) (string, error) {
+	worktrees, err := gitManager.ListWorktrees()
+	if err != nil {
+		return "", err
+	}
+	for _, path := range worktrees {
+		if strings.EqualFold(filepath.Base(path), ticket) {
+			return path, nil
+		}
+	}
+	return "", errors.Newf("no worktree found for ticket '%s' in this repository", ticket)
+}
+
+// formatExports formats vars as sorted, single-quoted shell export lines
+func formatExports(vars map[string]string) string {
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&b, "export %s='%s'\n", key, strings.ReplaceAll(vars[key], "'", `'\''`))
+	}
+	return b.String()
+}
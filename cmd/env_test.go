@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestEnvCommandStructure(t *testing.T) {
+	if envCmd.Use != "env [ticket]" {
+		t.Errorf("env command Use = %q, want %q", envCmd.Use, "env [ticket]")
+	}
+	if envCmd.Parent() != rootCmd {
+		t.Error("env should be a root command")
+	}
+	if envCmd.Flags().Lookup("profile") == nil {
+		t.Error("env should have a --profile flag")
+	}
+	if err := envCmd.Args(envCmd, []string{"a", "b"}); err == nil {
+		t.Error("env should accept at most one ticket")
+	}
+}
+
+func TestExpandEnvTemplate(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	placeholders := map[string]string{"worktree_path": "/wt/proj-1", "ticket": "proj-1", "summary": ""}
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"{worktree_path}/logs", "/wt/proj-1/logs"},
+		{"rig-{ticket}-{ticket}", "rig-proj-1-proj-1"},
+		{"{summary}", ""},
+		{"{unknown} stays", "{unknown} stays"},
+		{"unclosed {ticket", "unclosed {ticket"},
+		{"~/.kube/{ticket}", filepath.Join(home, ".kube", "proj-1")},
+		{"plain", "plain"},
+	}
+
+	for _, tt := range tests {
+		if got := expandEnvTemplate(tt.value, placeholders); got != tt.want {
+			t.Errorf("expandEnvTemplate(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestTicketContextEnvironment(t *testing.T) {
+	ctx := ticketContext{
+		Ticket:     "proj-1",
+		TicketType: "proj",
+		Repo:       "api",
+		Worktree:   "/repo/proj/proj-1",
+		Branch:     "proj-1",
+		BaseBranch: "main",
+		Summary:    "Fix login",
+	}
+
+	env := ctx.environment()
+	want := map[string]string{
+		"RIG_TICKET":      "proj-1",
+		"RIG_TICKET_TYPE": "proj",
+		"RIG_REPO":        "api",
+		"RIG_WORKTREE":    "/repo/proj/proj-1",
+		"RIG_BRANCH":      "proj-1",
+		"RIG_BASE_BRANCH": "main",
+		"RIG_SUMMARY":     "Fix login",
+	}
+	if len(env) != len(want) {
+		t.Errorf("environment() = %v, want %v (empty RIG_NOTE and RIG_URL omitted)", env, want)
+	}
+	for key, value := range want {
+		if env[key] != value {
+			t.Errorf("environment()[%s] = %q, want %q", key, env[key], value)
+		}
+	}
+}
+
+func TestTicketContextSessionEnvironment(t *testing.T) {
+	ctx := ticketContext{Ticket: "proj-1", Worktree: "/wt", Branch: "feature/x"}
+
+	env := ctx.sessionEnvironment(map[string]string{
+		"LOG_DIR":    "{worktree_path}/logs",
+		"TITLE":      "{ticket} on {branch}",
+		"RIG_TICKET": "overridden",
+	})
+
+	if env["LOG_DIR"] != "/wt/logs" {
+		t.Errorf("LOG_DIR = %q, want %q", env["LOG_DIR"], "/wt/logs")
+	}
+	if env["TITLE"] != "proj-1 on feature/x" {
+		t.Errorf("TITLE = %q", env["TITLE"])
+	}
+	if env["RIG_TICKET"] != "proj-1" {
+		t.Errorf("profile env should not override RIG_TICKET, got %q", env["RIG_TICKET"])
+	}
+}
+
+func TestFormatExports(t *testing.T) {
+	got := formatExports(map[string]string{
+		"RIG_TICKET":  "proj-1",
+		"RIG_SUMMARY": "Don't break $HOME",
+	})
+	want := "export RIG_SUMMARY='Don'\\''t break $HOME'\nexport RIG_TICKET='proj-1'\n"
+	if got != want {
+		t.Errorf("formatExports() =\n%s\nwant:\n%s", got, want)
+	}
+
+	if got := formatExports(nil); got != "" {
+		t.Errorf("formatExports(nil) = %q, want empty", got)
+	}
+}
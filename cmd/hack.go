@@ -10,10 +10,12 @@ import (
 	"thoreinstein.com/rig/pkg/config"
 	"thoreinstein.com/rig/pkg/git"
 	"thoreinstein.com/rig/pkg/notes"
-	"thoreinstein.com/rig/pkg/tmux"
 )
 
-var hackNotes bool
+var (
+	hackNotes   bool
+	hackProfile string
+)
 
 // hackCmd represents the hack command
 var hackCmd = &cobra.Command{
@@ -25,11 +27,12 @@ This command creates a simplified workflow without JIRA integration:
 - Creates git worktree at {repo}/hack/{name}
 - Creates branch {name}
 - Optionally creates markdown note with --notes flag
-- Creates tmux session
+- Creates a tmux (or Zellij/WezTerm) session
 
 Examples:
   rig hack winter-2025
-  rig hack experiment-auth --notes`,
+  rig hack experiment-auth --notes
+  rig hack spike-ui --profile frontend`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runHackCommand(args[0])
@@ -40,6 +43,7 @@ func init() {
 	rootCmd.AddCommand(hackCmd)
 
 	hackCmd.Flags().BoolVar(&hackNotes, "notes", false, "Create a markdown note for this hack")
+	hackCmd.Flags().StringVar(&hackProfile, "profile", "", "Session profile (default: chosen by repository, or the \"hack\" ticket type)")
 }
 
 // hackNameRegex validates hack names: must start with letter, contain only alphanumeric/hyphen/underscore, max 64 chars
@@ -102,12 +106,7 @@ func runHackCommand(name string) error {
 		if verbose {
 			fmt.Println("Creating note...")
 		}
-		noteManager := notes.NewManager(
-			cfg.Notes.Path,
-			cfg.Notes.DailyDir,
-			cfg.Notes.TemplateDir,
-			verbose,
-		)
+		noteManager := newNoteManager(cfg, verbose)
 
 		noteData := notes.TicketData{
 			Ticket:       name,
@@ -116,6 +115,7 @@ func runHackCommand(name string) error {
 			RepoPath:     repoRoot,
 			WorktreePath: worktreePath,
 		}
+		noteData.Branch, _ = gitManager.BranchForWorktree(worktreePath)
 
 		notePath, err = noteManager.CreateTicketNote(noteData)
 		if err != nil {
@@ -128,31 +128,33 @@ func runHackCommand(name string) error {
 		}
 	}
 
-	// Step 3: Create tmux session
+	// Step 3: Create multiplexer session
 	if verbose {
-		fmt.Println("Creating tmux session...")
+		fmt.Println("Creating session...")
 	}
 
-	// Convert config windows to tmux windows
-	tmuxWindows := make([]tmux.WindowConfig, 0, len(cfg.Tmux.Windows))
-	for _, window := range cfg.Tmux.Windows {
-		tmuxWindows = append(tmuxWindows, tmux.WindowConfig{
-			Name:       window.Name,
-			Command:    window.Command,
-			WorkingDir: window.WorkingDir,
-		})
+	tmuxWindows, profileEnv, err := resolveSessionWindows(cfg, hackProfile, "hack", repoRoot, repoName, worktreePath)
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve tmux profile")
 	}
 
-	sessionManager := tmux.NewSessionManager(cfg.Tmux.SessionPrefix, tmuxWindows, verbose)
+	sessionManager, err := newMultiplexer(cfg, tmuxWindows)
+	if err != nil {
+		return err
+	}
+
+	ticketContext := newTicketContext(cfg, gitManager, name, "hack", repoName, worktreePath, notePath, "")
+	sessionManager.AddEnvironment(ticketContext.sessionEnvironment(profileEnv))
+
 	err = sessionManager.CreateSession(name, worktreePath, notePath)
 	if err != nil {
-		// Don't fail the entire process if tmux session creation fails
+		// Don't fail the entire process if session creation fails
 		if verbose {
-			fmt.Printf("Warning: Could not create tmux session: %v\n", err)
+			fmt.Printf("Warning: Could not create %s session: %v\n", sessionManager.Name(), err)
 		}
-		fmt.Println("Warning: Tmux session creation failed, but other steps completed successfully")
+		fmt.Printf("Warning: %s session creation failed, but other steps completed successfully\n", sessionManager.Name())
 	} else {
-		fmt.Println("Tmux session created successfully")
+		fmt.Printf("%s session created successfully\n", sessionManager.Name())
 	}
 
 	fmt.Printf("\nHack workflow for %s completed successfully!\n", name)
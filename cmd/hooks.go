@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+
+	"thoreinstein.com/rig/pkg/config"
+	"thoreinstein.com/rig/pkg/git"
+)
+
+var hooksForce bool
+
+// hooksCmd represents the hooks command
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Manage git hooks that add ticket keys to commits",
+	Long: `Manage git hooks that add ticket keys to commit messages.
+
+The hooks are installed in the bare repository, so every worktree shares them.
+They derive the ticket from the worktree path ({repo}/{type}/{ticket}) or the
+branch name and then, depending on hooks.mode:
+
+- prefix:   prepend hooks.prefix_format to messages that lack a ticket key
+- validate: only reject messages that don't match hooks.pattern
+
+Commits in worktrees without a detectable ticket are never rejected.`,
+}
+
+// hooksInstallCmd installs the managed hooks
+var hooksInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install prepare-commit-msg and commit-msg hooks",
+	Long: `Install prepare-commit-msg and commit-msg hooks in the current repository.
+
+Existing hooks that were not installed by rig are not overwritten unless
+--force is given.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runHooksInstallCommand()
+	},
+}
+
+// hooksUninstallCmd removes the managed hooks
+var hooksUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove hooks installed by rig",
+	Long:  `Remove the prepare-commit-msg and commit-msg hooks installed by rig. Other hooks are left untouched.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runHooksUninstallCommand()
+	},
+}
+
+// hooksRunCmd is invoked by the installed hook scripts
+var hooksRunCmd = &cobra.Command{
+	Use:    "run <hook> <args...>",
+	Short:  "Run a rig-managed git hook",
+	Hidden: true,
+	Args:   cobra.MinimumNArgs(2),
+	// Keep hook failures to a single line in git's output
+	SilenceUsage: true,
+	// Hook arguments are passed through verbatim from git
+	DisableFlagParsing: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runHookCommand(args[0], args[1:])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(hooksCmd)
+	hooksCmd.AddCommand(hooksInstallCmd)
+	hooksCmd.AddCommand(hooksUninstallCmd)
+	hooksCmd.AddCommand(hooksRunCmd)
+
+	hooksInstallCmd.Flags().BoolVar(&hooksForce, "force", false, "Overwrite existing hooks not installed by rig")
+}
+
+func runHooksInstallCommand() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.Wrap(err, "failed to load configuration")
+	}
+
+	rigPath, err := os.Executable()
+	if err != nil {
+		rigPath = "rig"
+	}
+
+	gitManager := git.NewWorktreeManager(cfg.Git.BaseBranch, verbose)
+	installed, err := gitManager.InstallHooks(rigPath, hooksForce)
+	if err != nil {
+		return errors.Wrap(err, "failed to install hooks")
+	}
+
+	for _, path := range installed {
+		fmt.Printf("Installed %s\n", path)
+	}
+	fmt.Printf("Hooks active in %s mode for all worktrees\n", cfg.Hooks.Mode)
+
+	return nil
+}
+
+func runHooksUninstallCommand() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.Wrap(err, "failed to load configuration")
+	}
+
+	gitManager := git.NewWorktreeManager(cfg.Git.BaseBranch, verbose)
+	removed, err := gitManager.UninstallHooks()
+	if err != nil {
+		return errors.Wrap(err, "failed to uninstall hooks")
+	}
+
+	if len(removed) == 0 {
+		fmt.Println("No rig hooks installed.")
+		return nil
+	}
+
+	for _, path := range removed {
+		fmt.Printf("Removed %s\n", path)
+	}
+
+	return nil
+}
+
+// runHookCommand dispatches a git hook invocation.
+// args are the arguments git passed to the hook script.
+func runHookCommand(hook string, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.Wrap(err, "failed to load configuration")
+	}
+
+	pattern, err := regexp.Compile(cfg.Hooks.Pattern)
+	if err != nil {
+		return errors.Wrapf(err, "invalid hooks.pattern %q", cfg.Hooks.Pattern)
+	}
+
+	gitManager := git.NewWorktreeManager(cfg.Git.BaseBranch, verbose)
+	ticket := strings.ToUpper(gitManager.DetectTicket())
+
+	switch hook {
+	case "prepare-commit-msg":
+		source := ""
+		if len(args) > 1 {
+			source = args[1]
+		}
+		return prepareCommitMsg(args[0], source, ticket, cfg.Hooks, pattern)
+	case "commit-msg":
+		return validateCommitMsg(args[0], ticket, pattern)
+	default:
+		return errors.Newf("unknown hook: %s", hook)
+	}
+}
+
+// prepareCommitMsg prefixes the commit message file with the ticket key
+func prepareCommitMsg(messageFile, source, ticket string, hooksCfg config.HooksConfig, pattern *regexp.Regexp) error {
+	if hooksCfg.Mode != "prefix" || ticket == "" {
+		return nil
+	}
+
+	// Leave merges, squashes and amended/reused messages alone
+	switch source {
+	case "merge", "squash", "commit":
+		return nil
+	}
+
+	content, err := os.ReadFile(messageFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to read commit message")
+	}
+
+	if git.CommitMessageMatches(string(content), pattern) {
+		return nil
+	}
+
+	prefix := strings.ReplaceAll(hooksCfg.PrefixFormat, "{ticket}", ticket)
+	updated := git.PrefixCommitMessage(string(content), prefix)
+
+	//nolint:gosec // G306: commit message file is owned by git
+	if err := os.WriteFile(messageFile, []byte(updated), 0644); err != nil {
+		return errors.Wrap(err, "failed to write commit message")
+	}
+
+	return nil
+}
+
+// validateCommitMsg rejects commit messages that don't match the configured pattern
+func validateCommitMsg(messageFile, ticket string, pattern *regexp.Regexp) error {
+	// Only enforce the pattern where a ticket applies (not main, hacks, etc.)
+	if ticket == "" {
+		return nil
+	}
+
+	content, err := os.ReadFile(messageFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to read commit message")
+	}
+
+	body := git.CommitMessageBody(string(content))
+	if body == "" {
+		// Let git abort on an empty message itself
+		return nil
+	}
+
+	if !pattern.MatchString(body) {
+		return errors.Newf("commit message must reference the ticket (pattern %q), e.g. %q", pattern.String(), ticket+": "+firstLine(body))
+	}
+
+	return nil
+}
+
+// firstLine returns the first line of s
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"thoreinstein.com/rig/pkg/config"
+)
+
+func TestHooksCommandStructure(t *testing.T) {
+	names := make(map[string]bool)
+	for _, sub := range hooksCmd.Commands() {
+		names[sub.Name()] = true
+	}
+
+	for _, expected := range []string{"install", "uninstall", "run"} {
+		if !names[expected] {
+			t.Errorf("hooks command should have %q subcommand", expected)
+		}
+	}
+
+	if !hooksRunCmd.Hidden {
+		t.Error("hooks run should be hidden")
+	}
+	if hooksInstallCmd.Flags().Lookup("force") == nil {
+		t.Error("hooks install should have --force flag")
+	}
+}
+
+func writeCommitMessage(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func readCommitMessage(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestPrepareCommitMsg(t *testing.T) {
+	pattern := regexp.MustCompile(`[A-Z][A-Z0-9]*-[0-9]+`)
+	prefixCfg := config.HooksConfig{Mode: "prefix", PrefixFormat: "{ticket}: "}
+
+	tests := []struct {
+		name     string
+		message  string
+		source   string
+		ticket   string
+		hooksCfg config.HooksConfig
+		expected string
+	}{
+		{
+			name:     "prefixes message",
+			message:  "fix login\n",
+			source:   "message",
+			ticket:   "PROJ-1",
+			hooksCfg: prefixCfg,
+			expected: "PROJ-1: fix login\n",
+		},
+		{
+			name:     "keeps message that already has a key",
+			message:  "OPS-7: fix login\n",
+			source:   "message",
+			ticket:   "PROJ-1",
+			hooksCfg: prefixCfg,
+			expected: "OPS-7: fix login\n",
+		},
+		{
+			name:     "skips merges",
+			message:  "Merge branch 'main'\n",
+			source:   "merge",
+			ticket:   "PROJ-1",
+			hooksCfg: prefixCfg,
+			expected: "Merge branch 'main'\n",
+		},
+		{
+			name:     "skips without ticket",
+			message:  "fix login\n",
+			ticket:   "",
+			hooksCfg: prefixCfg,
+			expected: "fix login\n",
+		},
+		{
+			name:     "validate mode does not modify",
+			message:  "fix login\n",
+			ticket:   "PROJ-1",
+			hooksCfg: config.HooksConfig{Mode: "validate", PrefixFormat: "{ticket}: "},
+			expected: "fix login\n",
+		},
+		{
+			name:     "custom prefix format",
+			message:  "fix login\n",
+			ticket:   "PROJ-1",
+			hooksCfg: config.HooksConfig{Mode: "prefix", PrefixFormat: "[{ticket}] "},
+			expected: "[PROJ-1] fix login\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeCommitMessage(t, tt.message)
+
+			if err := prepareCommitMsg(path, tt.source, tt.ticket, tt.hooksCfg, pattern); err != nil {
+				t.Fatalf("prepareCommitMsg() error: %v", err)
+			}
+
+			if got := readCommitMessage(t, path); got != tt.expected {
+				t.Errorf("message = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateCommitMsg(t *testing.T) {
+	pattern := regexp.MustCompile(`[A-Z][A-Z0-9]*-[0-9]+`)
+
+	tests := []struct {
+		name    string
+		message string
+		ticket  string
+		wantErr bool
+	}{
+		{name: "valid message", message: "PROJ-1: fix login\n", ticket: "PROJ-1"},
+		{name: "missing key", message: "fix login\n", ticket: "PROJ-1", wantErr: true},
+		{name: "key only in comment", message: "fix login\n# PROJ-1\n", ticket: "PROJ-1", wantErr: true},
+		{name: "no ticket context", message: "fix login\n", ticket: ""},
+		{name: "empty message left to git", message: "# only comments\n", ticket: "PROJ-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeCommitMessage(t, tt.message)
+
+			err := validateCommitMsg(path, tt.ticket, pattern)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateCommitMsg() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,391 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+
+	"thoreinstein.com/rig/pkg/config"
+	"thoreinstein.com/rig/pkg/history"
+	"thoreinstein.com/rig/pkg/notes"
+)
+
+// Incident severities, SEV1 being the worst
+const (
+	minSeverity = 1
+	maxSeverity = 5
+)
+
+// incidentResolvedStatus is the status a closed incident's note records
+const incidentResolvedStatus = "Resolved"
+
+var (
+	incidentSeverity int
+	incidentProfile  string
+	incidentTicket   string
+	incidentForce    bool
+)
+
+// incidentNumberRegex matches a bare incident number, e.g. "789"
+var incidentNumberRegex = regexp.MustCompile(`^[0-9]+$`)
+
+// incidentCmd groups the incident commands
+var incidentCmd = &cobra.Command{
+	Use:   "incident",
+	Short: "Run an incident: timeline, session and postmortem draft",
+	Long: `Work an incident from start to postmortem.
+
+'rig incident start' sets up the incident like 'rig work' does for a ticket,
+with the incident note template and the incident session profile. During
+the incident 'rig incident event' records what happens in the note's
+Timeline, and 'rig incident close' drafts the postmortem from the timeline
+and the commands run in the incident worktree.
+
+An incident is given as its number (789 for incident-789) or as a full
+ticket such as incident-789. Other tickets are not incidents.`,
+}
+
+// incidentStartCmd starts an incident
+var incidentStartCmd = &cobra.Command{
+	Use:   "start <id>",
+	Short: "Start an incident with a worktree, note and session",
+	Long: `Start an incident: create its worktree, note and session like 'rig work',
+and open its Timeline with the declaration.
+
+New notes use incident.md.tmpl, with Impact, Timeline, Comms and Action
+Items sections, and the severity in the front matter. The session uses the
+"incident" tmux profile, built in with note, term and logs windows, unless
+tmux.profiles.incident or --profile says otherwise.
+
+Starting an incident again with another --sev records the change of
+severity.
+
+Examples:
+  rig incident start 789 --sev 2
+  rig incident start incident-789 --sev 1 --profile oncall`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runIncidentStartCommand(time.Now(), args[0], incidentSeverity)
+	},
+}
+
+// incidentEventCmd adds an entry to the incident timeline
+var incidentEventCmd = &cobra.Command{
+	Use:   "event <message>",
+	Short: "Add a timestamped entry to the incident timeline",
+	Long: `Add a timestamped entry to the Timeline section of the incident note.
+
+The incident is inferred from $RIG_TICKET, the current worktree path, or
+the current branch name, unless --ticket is given.
+
+Examples:
+  rig incident event "Rolled back deploy 2025.01.15-3"
+  rig incident event --ticket 789 Error rate back to baseline`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runIncidentEventCommand(time.Now(), strings.Join(args, " "))
+	},
+}
+
+// incidentCloseCmd resolves an incident and drafts its postmortem
+var incidentCloseCmd = &cobra.Command{
+	Use:   "close [id]",
+	Short: "Resolve an incident and draft its postmortem",
+	Long: `Resolve an incident and write a postmortem draft next to its note, as
+<incident>-postmortem.md.
+
+The draft is rendered with postmortem.md.tmpl from the incident note's
+Timeline, Impact and Action Items, and the command history of the incident
+worktree between the first timeline entry and the resolution. The note's
+status is set to Resolved and the incident is logged as done in the daily
+note. Closing a resolved incident again with --force redrafts the
+postmortem, keeping the resolution time.
+
+If the incident is omitted, it is inferred like for 'rig incident event'.
+
+Examples:
+  rig incident close
+  rig incident close 789 --force`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runIncidentCloseCommand(time.Now(), strings.Join(args, ""))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(incidentCmd)
+	incidentCmd.AddCommand(incidentStartCmd)
+	incidentCmd.AddCommand(incidentEventCmd)
+	incidentCmd.AddCommand(incidentCloseCmd)
+
+	incidentStartCmd.Flags().IntVar(&incidentSeverity, "sev", 0, "Severity, 1 (worst) to 5")
+	incidentStartCmd.Flags().StringVar(&incidentProfile, "profile", config.IncidentProfile, "Session profile")
+	incidentEventCmd.Flags().StringVarP(&incidentTicket, "ticket", "t", "", "Incident to add to (default: inferred)")
+	incidentCloseCmd.Flags().BoolVar(&incidentForce, "force", false, "Replace an existing postmortem draft")
+}
+
+// incidentTicketName returns the ticket of an incident given as a number
+// or an incident ticket
+func incidentTicketName(id string) (string, error) {
+	id = strings.TrimSpace(id)
+	if incidentNumberRegex.MatchString(id) {
+		return notes.IncidentType + "-" + id, nil
+	}
+
+	ticketInfo, err := parseTicket(id)
+	if err != nil || ticketInfo.Type != notes.IncidentType {
+		return "", errors.Newf("invalid incident %q: expected a number or a ticket such as incident-789", id)
+	}
+	return ticketInfo.Full, nil
+}
+
+// validateSeverity checks an incident severity, 0 standing for none
+func validateSeverity(severity int) error {
+	if severity != 0 && (severity < minSeverity || severity > maxSeverity) {
+		return errors.Newf("invalid severity %d: use %d (worst) to %d", severity, minSeverity, maxSeverity)
+	}
+	return nil
+}
+
+// severityText formats a severity for the timeline, e.g. "SEV2"
+func severityText(severity string) string {
+	if severity == "" {
+		return "no severity"
+	}
+	return "SEV" + severity
+}
+
+func runIncidentStartCommand(now time.Time, id string, severity int) error {
+	ticket, err := incidentTicketName(id)
+	if err != nil {
+		return err
+	}
+	if err := validateSeverity(severity); err != nil {
+		return err
+	}
+
+	sev := ""
+	if severity != 0 {
+		sev = strconv.Itoa(severity)
+	}
+
+	if err := startWork(ticket, workOptions{Profile: incidentProfile, Severity: sev}); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.Wrap(err, "failed to load configuration")
+	}
+	ref, err := parseWorktreeRef(ticket)
+	if err != nil {
+		return err
+	}
+
+	entry, err := recordIncidentStart(newNoteManager(cfg, verbose), ref, sev, now)
+	if err != nil {
+		return err
+	}
+	if entry != "" {
+		fmt.Printf("Timeline: %s\n", entry)
+	}
+	return nil
+}
+
+// recordIncidentStart opens the timeline of a new incident with its
+// declaration, or records a change of severity when an incident is started
+// again. Returns the entry added, "" if there was nothing to record.
+func recordIncidentStart(noteManager *notes.Manager, ref worktreeRef, severity string, now time.Time) (string, error) {
+	content, err := os.ReadFile(noteManager.GetNotePath(ref.Type, ref.Name))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read incident note")
+	}
+	fm, err := notes.ParseFrontMatter(string(content))
+	if err != nil {
+		return "", err
+	}
+
+	var text string
+	switch {
+	case len(notes.ParseTimeline(string(content))) == 0:
+		text = "Declared"
+		if severity != "" {
+			text += " " + severityText(severity)
+		}
+	case severity != "" && severity != fm.Severity:
+		text = "Severity " + severityText(fm.Severity) + " → " + severityText(severity)
+	default:
+		return "", nil
+	}
+
+	if severity != "" {
+		if _, err := noteManager.UpdateTicketFrontMatter(ref.Type, ref.Name, notes.FrontMatter{Severity: severity}); err != nil {
+			return "", err
+		}
+	}
+	if _, err := noteManager.AddTicketEntry(ref.Type, ref.Name, notes.TimelineSection, formatNoteEntry(now, text)); err != nil {
+		return "", err
+	}
+	return text, nil
+}
+
+// resolveIncident resolves the incident a command works on: id given as a
+// number or ticket, else inferred like the note commands do
+func resolveIncident(id string) (*config.Config, *notes.Manager, worktreeRef, error) {
+	if id != "" {
+		ticket, err := incidentTicketName(id)
+		if err != nil {
+			return nil, nil, worktreeRef{}, err
+		}
+		id = ticket
+	}
+
+	noteManager, ref, err := resolveNoteTarget(id)
+	if err != nil {
+		return nil, nil, worktreeRef{}, err
+	}
+	// An inferred ticket may be a regular one, whose note must not get a
+	// timeline or be resolved
+	if ref.Type != notes.IncidentType {
+		return nil, nil, worktreeRef{}, errors.Newf("%s is not an incident (give one with its number or --ticket)", ref.Name)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, worktreeRef{}, errors.Wrap(err, "failed to load configuration")
+	}
+	return cfg, noteManager, ref, nil
+}
+
+func runIncidentEventCommand(now time.Time, text string) error {
+	text, err := noteText(text)
+	if err != nil {
+		return err
+	}
+
+	_, noteManager, ref, err := resolveIncident(incidentTicket)
+	if err != nil {
+		return err
+	}
+
+	notePath, err := noteManager.AddTicketEntry(ref.Type, ref.Name, notes.TimelineSection, formatNoteEntry(now, text))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Added to the timeline in %s\n", shortenHome(notePath))
+	return nil
+}
+
+func runIncidentCloseCommand(now time.Time, id string) error {
+	cfg, noteManager, ref, err := resolveIncident(id)
+	if err != nil {
+		return err
+	}
+
+	notePath := noteManager.GetNotePath(ref.Type, ref.Name)
+	if _, err := os.Stat(notePath); err != nil {
+		return errors.Newf("incident note not found: %s", notePath)
+	}
+	postmortemPath := noteManager.PostmortemPath(ref.Type, ref.Name)
+	if _, err := os.Stat(postmortemPath); err == nil && !incidentForce {
+		return errors.Newf("postmortem already exists: %s (use --force to replace it)", postmortemPath)
+	}
+
+	// Redrafting the postmortem of a closed incident keeps its resolution
+	resolved, err := incidentResolution(notePath)
+	if err != nil {
+		return err
+	}
+	if resolved.IsZero() {
+		resolved = now
+		if _, err := noteManager.AddTicketEntry(ref.Type, ref.Name, notes.TimelineSection, formatNoteEntry(now, incidentResolvedStatus)); err != nil {
+			return err
+		}
+	}
+
+	pm, err := noteManager.NewPostmortem(ref.Type, ref.Name, resolved)
+	if err != nil {
+		return err
+	}
+	pm.Commands, pm.History, err = incidentCommands(cfg, ref.Name, pm.StartedAt(), resolved)
+	if err != nil {
+		return err
+	}
+
+	postmortemPath, err = noteManager.WritePostmortem(pm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := noteManager.UpdateTicketFrontMatter(ref.Type, ref.Name, notes.FrontMatter{Status: incidentResolvedStatus, Finished: resolved.Format("2006-01-02")}); err != nil {
+		return err
+	}
+
+	if _, err := noteManager.AddDailyEvent(notes.LogEvent{Event: notes.EventDone, Ticket: ref.Name, Type: ref.Type, Time: resolved}); err != nil {
+		// Don't fail if daily note update fails
+		if verbose {
+			fmt.Printf("Warning: Could not update daily note: %v\n", err)
+		}
+	}
+
+	refreshIndexNotes(cfg, noteManager)
+
+	fmt.Printf("Incident %s resolved after %s\n", ref.Name, pm.Duration)
+	if !pm.History {
+		fmt.Println("Command history not available; the draft has no command timeline")
+	}
+	fmt.Printf("Postmortem draft: %s\n", shortenHome(postmortemPath))
+	return nil
+}
+
+// incidentResolution returns the time of the incident's Resolved timeline
+// entry if it is the last one, else the zero time
+func incidentResolution(notePath string) (time.Time, error) {
+	content, err := os.ReadFile(notePath)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "failed to read incident note")
+	}
+
+	timeline := notes.ParseTimeline(string(content))
+	if len(timeline) == 0 || timeline[len(timeline)-1].Text != incidentResolvedStatus {
+		return time.Time{}, nil
+	}
+	return timeline[len(timeline)-1].Time, nil
+}
+
+// incidentCommands returns the commands run in the incident's worktree
+// between since and until, and whether the history database was available
+func incidentCommands(cfg *config.Config, ticket string, since, until time.Time) ([]notes.PostmortemCommand, bool, error) {
+	dbManager := history.NewDatabaseManager(cfg.History.DatabasePath, verbose)
+	if !dbManager.IsAvailable() {
+		return nil, false, nil
+	}
+
+	cmds, err := dbManager.QueryCommands(history.QueryOptions{Since: &since, Until: &until, Ticket: ticket})
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to query command history")
+	}
+
+	var commands []notes.PostmortemCommand
+	for _, c := range cmds {
+		// The ticket filter also matches commands mentioning the ticket and
+		// longer tickets sharing its prefix
+		if ticketFromDir(c.Directory) != ticket {
+			continue
+		}
+		commands = append(commands, notes.PostmortemCommand{
+			Time:      c.Timestamp.Local().Format("15:04:05"),
+			Command:   c.Command,
+			Directory: c.Directory,
+			ExitCode:  c.ExitCode,
+		})
+	}
+	return commands, true, nil
+}
@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"thoreinstein.com/rig/pkg/notes"
+)
+
+func TestIncidentCommandStructure(t *testing.T) {
+	if incidentCmd.Parent() != rootCmd {
+		t.Error("incident should be a root command")
+	}
+
+	for _, sub := range []string{"start", "event", "close"} {
+		if _, _, err := incidentCmd.Find([]string{sub}); err != nil {
+			t.Errorf("incident should have a %s subcommand", sub)
+		}
+	}
+	if incidentStartCmd.Flags().Lookup("sev") == nil || incidentStartCmd.Flags().Lookup("profile") == nil {
+		t.Error("incident start should have --sev and --profile flags")
+	}
+}
+
+func TestIncidentTicketName(t *testing.T) {
+	tests := []struct {
+		id      string
+		want    string
+		wantErr bool
+	}{
+		{id: "789", want: "incident-789"},
+		{id: "incident-789", want: "incident-789"},
+		{id: "INCIDENT-12", want: "INCIDENT-12"},
+		{id: "SEC-12", wantErr: true},
+		{id: "proj-123", wantErr: true},
+		{id: "incident", wantErr: true},
+		{id: "../789", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := incidentTicketName(tt.id)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("incidentTicketName(%q) = %q, %v", tt.id, got, err)
+		}
+	}
+}
+
+func TestValidateSeverity(t *testing.T) {
+	for _, severity := range []int{0, 1, 5} {
+		if err := validateSeverity(severity); err != nil {
+			t.Errorf("validateSeverity(%d) error: %v", severity, err)
+		}
+	}
+	for _, severity := range []int{-1, 6} {
+		if err := validateSeverity(severity); err == nil {
+			t.Errorf("validateSeverity(%d) should fail", severity)
+		}
+	}
+}
+
+// writeIncidentNote creates the note of incident-7 from the built-in template
+func writeIncidentNote(t *testing.T, notesDir, severity string) *notes.Manager {
+	t.Helper()
+
+	setupSyncTestConfig(t, notesDir)
+	noteManager := notes.NewManager(notesDir, "daily", "", false)
+	if _, err := noteManager.CreateTicketNote(notes.TicketData{Ticket: "incident-7", TicketType: "incident", Date: "2025-01-15", Severity: severity}); err != nil {
+		t.Fatal(err)
+	}
+	return noteManager
+}
+
+func TestRecordIncidentStart(t *testing.T) {
+	notesDir := t.TempDir()
+	noteManager := writeIncidentNote(t, notesDir, "3")
+	defer viper.Reset()
+
+	ref := worktreeRef{Type: "incident", Name: "incident-7"}
+	at := time.Date(2025, 1, 15, 9, 0, 0, 0, time.Local)
+
+	steps := []struct {
+		severity string
+		want     string
+	}{
+		{"3", "Declared SEV3"},
+		{"3", ""},
+		{"", ""},
+		{"1", "Severity SEV3 → SEV1"},
+	}
+	for i, step := range steps {
+		got, err := recordIncidentStart(noteManager, ref, step.severity, at.Add(time.Duration(i)*time.Minute))
+		if err != nil || got != step.want {
+			t.Errorf("step %d: recordIncidentStart() = %q, %v; want %q", i, got, err, step.want)
+		}
+	}
+
+	fm, err := noteManager.TicketFrontMatter("incident", "incident-7")
+	if err != nil || fm.Severity != "1" {
+		t.Errorf("severity = %q, %v; want 1", fm.Severity, err)
+	}
+	content, _ := os.ReadFile(noteManager.GetNotePath("incident", "incident-7"))
+	if !strings.Contains(string(content), "## Timeline\n- [2025-01-15 09:00] Declared SEV3\n- [2025-01-15 09:03] Severity SEV3 → SEV1\n\n## Comms") {
+		t.Errorf("unexpected timeline:\n%s", content)
+	}
+}
+
+func TestRunIncidentEventCommand(t *testing.T) {
+	notesDir := t.TempDir()
+	noteManager := writeIncidentNote(t, notesDir, "2")
+	defer viper.Reset()
+	t.Setenv(ticketEnvVar, "incident-7")
+	t.Setenv("RIG_TICKET_TYPE", "incident")
+
+	at := time.Date(2025, 1, 15, 9, 5, 0, 0, time.Local)
+	output := captureOutput(func() {
+		if err := runIncidentEventCommand(at, "Paged the database\n team"); err != nil {
+			t.Errorf("runIncidentEventCommand() error: %v", err)
+		}
+	})
+	if !strings.Contains(output, "Added to the timeline") {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+
+	entries, _ := os.ReadFile(noteManager.GetNotePath("incident", "incident-7"))
+	timeline := notes.ParseTimeline(string(entries))
+	if len(timeline) != 1 || timeline[0].Text != "Paged the database team" || !timeline[0].Time.Equal(at) {
+		t.Errorf("timeline = %+v", timeline)
+	}
+
+	incidentTicket = "404"
+	defer func() { incidentTicket = "" }()
+	if err := runIncidentEventCommand(at, "lost"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("adding to a missing incident should fail, got %v", err)
+	}
+}
+
+func TestRunIncidentCloseCommand(t *testing.T) {
+	notesDir := t.TempDir()
+	noteManager := writeIncidentNote(t, notesDir, "2")
+	defer viper.Reset()
+	t.Setenv(ticketEnvVar, "")
+
+	dbPath := filepath.Join(t.TempDir(), "history.db")
+	createTestHistoryDatabase(t, dbPath)
+	viper.Set("history.database_path", dbPath)
+
+	start := time.Date(2025, 1, 15, 9, 0, 0, 0, time.Local)
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`
+		INSERT INTO places (id, dir) VALUES (1, '/src/api/incident/incident-7'), (2, '/src/api/incident/incident-70');
+		INSERT INTO sessions (id, session) VALUES (1, 'incident-7');
+		INSERT INTO commands (argv, start_time, duration, exit_status, place_id, session_id, hostname) VALUES
+			('kubectl get pods', ?, 10, 0, 1, 1, 'localhost'),
+			('kubectl rollout undo deploy/api', ?, 10, 1, 1, 1, 'localhost'),
+			('git status', ?, 10, 0, 2, 1, 'localhost'),
+			('make', ?, 10, 0, 1, 1, 'localhost');
+	`, start.Add(-time.Hour).Unix(), start.Add(10*time.Minute).Unix(), start.Add(20*time.Minute).Unix(), start.Add(3*time.Hour).Unix())
+	_ = db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ref := worktreeRef{Type: "incident", Name: "incident-7"}
+	if _, err := recordIncidentStart(noteManager, ref, "2", start); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := noteManager.AddTicketEntry("incident", "incident-7", "Action Items", "- [ ] Alert on rollout failures"); err != nil {
+		t.Fatal(err)
+	}
+
+	closed := start.Add(90 * time.Minute)
+	output := captureOutput(func() {
+		if err := runIncidentCloseCommand(closed, "7"); err != nil {
+			t.Errorf("runIncidentCloseCommand() error: %v", err)
+		}
+	})
+	if !strings.Contains(output, "Incident incident-7 resolved after 1h30m") || !strings.Contains(output, "incident-7-postmortem.md") {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+
+	postmortem, err := os.ReadFile(noteManager.PostmortemPath("incident", "incident-7"))
+	if err != nil {
+		t.Fatalf("postmortem should be written: %v", err)
+	}
+	for _, want := range []string{
+		"- Severity: SEV2",
+		"- **09:00** Declared SEV2\n- **10:30** Resolved",
+		"- **09:10:00** [Exit: 1] `kubectl rollout undo deploy/api`",
+		"- [ ] Alert on rollout failures",
+	} {
+		if !strings.Contains(string(postmortem), want) {
+			t.Errorf("postmortem missing %q:\n%s", want, postmortem)
+		}
+	}
+	for _, unwanted := range []string{"kubectl get pods", "git status", "`make`"} {
+		if strings.Contains(string(postmortem), unwanted) {
+			t.Errorf("postmortem should not contain %q:\n%s", unwanted, postmortem)
+		}
+	}
+
+	if fm, err := noteManager.TicketFrontMatter("incident", "incident-7"); err != nil || fm.Status != "Resolved" || fm.Finished != "2025-01-15" {
+		t.Errorf("status, finished = %q, %q, %v; want Resolved, 2025-01-15", fm.Status, fm.Finished, err)
+	}
+	daily, _ := os.ReadFile(noteManager.DailyNotePath(closed))
+	if !strings.Contains(string(daily), "- [10:30] Done") {
+		t.Errorf("daily note should log the incident as done:\n%s", daily)
+	}
+
+	// An existing draft is kept unless forced
+	if err := runIncidentCloseCommand(closed, "7"); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("closing again should fail without --force, got %v", err)
+	}
+	// Redrafting keeps the resolution
+	incidentForce = true
+	defer func() { incidentForce = false }()
+	captureOutput(func() {
+		if err := runIncidentCloseCommand(closed.Add(time.Hour), "7"); err != nil {
+			t.Errorf("runIncidentCloseCommand() with --force error: %v", err)
+		}
+	})
+	content, _ := os.ReadFile(noteManager.GetNotePath("incident", "incident-7"))
+	if timeline := notes.ParseTimeline(string(content)); len(timeline) != 2 {
+		t.Errorf("closing again should not add to the timeline: %+v", timeline)
+	}
+	postmortem, _ = os.ReadFile(noteManager.PostmortemPath("incident", "incident-7"))
+	if !strings.Contains(string(postmortem), "- Resolved: 2025-01-15 10:30") {
+		t.Errorf("redrafted postmortem should keep the resolution:\n%s", postmortem)
+	}
+}
+
+func TestIncidentCommands_RejectTickets(t *testing.T) {
+	notesDir := t.TempDir()
+	setupSyncTestConfig(t, notesDir)
+	defer viper.Reset()
+	t.Setenv(ticketEnvVar, "proj-123")
+	t.Setenv("RIG_TICKET_TYPE", "proj")
+
+	noteManager := notes.NewManager(notesDir, "daily", "", false)
+	notePath, err := noteManager.CreateTicketNote(notes.TicketData{Ticket: "proj-123", TicketType: "proj", Date: "2025-01-15"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.ReadFile(notePath)
+
+	at := time.Date(2025, 1, 15, 9, 0, 0, 0, time.Local)
+	if err := runIncidentEventCommand(at, "Rolled back"); err == nil || !strings.Contains(err.Error(), "not an incident") {
+		t.Errorf("runIncidentEventCommand() in a ticket worktree error = %v, want not an incident", err)
+	}
+	if err := runIncidentCloseCommand(at, ""); err == nil || !strings.Contains(err.Error(), "not an incident") {
+		t.Errorf("runIncidentCloseCommand() in a ticket worktree error = %v, want not an incident", err)
+	}
+	if err := runIncidentCloseCommand(at, "proj-123"); err == nil || !strings.Contains(err.Error(), "invalid incident") {
+		t.Errorf("runIncidentCloseCommand(proj-123) error = %v, want invalid incident", err)
+	}
+
+	after, _ := os.ReadFile(notePath)
+	if string(after) != string(before) {
+		t.Errorf("the ticket note should be left alone:\n%s", after)
+	}
+	if _, err := os.Stat(noteManager.PostmortemPath("proj", "proj-123")); !os.IsNotExist(err) {
+		t.Errorf("no postmortem should be written, got %v", err)
+	}
+}
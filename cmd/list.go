@@ -132,7 +132,7 @@ func listCurrentRepoWorktrees(cfg *config.Config) error {
 		}
 
 		if branch != "" {
-			fmt.Printf("  %-40s [%s]\n", relPath, branch)
+			fmt.Printf("  %-40s [%s]%s\n", relPath, branch, branchStatus(gitManager, branch))
 		} else {
 			fmt.Printf("  %s\n", relPath)
 		}
@@ -149,6 +149,27 @@ func listCurrentRepoWorktrees(cfg *config.Config) error {
 	return nil
 }
 
+// branchStatus describes how branch compares to its base, e.g. " +3/-1 vs proj-123".
+// Returns "" for the base branch itself or when the comparison fails.
+func branchStatus(gitManager *git.WorktreeManager, branch string) string {
+	// Branches are compared against their recorded base, else the default branch
+	baseBranch, err := gitManager.BaseBranchFor(branch)
+	if err != nil || baseBranch == branch {
+		return ""
+	}
+
+	ahead, behind, err := gitManager.AheadBehind(branch, baseBranch)
+	if err != nil {
+		return ""
+	}
+	return formatAheadBehind(ahead, behind, baseBranch)
+}
+
+// formatAheadBehind renders ahead/behind counts relative to a base branch
+func formatAheadBehind(ahead, behind int, baseBranch string) string {
+	return fmt.Sprintf(" +%d/-%d vs %s", ahead, behind, baseBranch)
+}
+
 func getWorktreeDetails(repoPath string) map[string]WorktreeInfo {
 	result := make(map[string]WorktreeInfo)
 
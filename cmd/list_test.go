@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"thoreinstein.com/rig/pkg/git"
 )
 
 func TestListCommandFlags(t *testing.T) {
@@ -372,3 +374,66 @@ hack-test|1|0`,
 		})
 	}
 }
+
+func TestFormatAheadBehind(t *testing.T) {
+	tests := []struct {
+		ahead, behind int
+		base          string
+		want          string
+	}{
+		{ahead: 3, behind: 1, base: "proj-123", want: " +3/-1 vs proj-123"},
+		{ahead: 0, behind: 0, base: "main", want: " +0/-0 vs main"},
+		{ahead: 12, behind: 0, base: "release/2.4", want: " +12/-0 vs release/2.4"},
+	}
+
+	for _, tt := range tests {
+		if got := formatAheadBehind(tt.ahead, tt.behind, tt.base); got != tt.want {
+			t.Errorf("formatAheadBehind(%d, %d, %q) = %q, want %q", tt.ahead, tt.behind, tt.base, got, tt.want)
+		}
+	}
+}
+
+func TestBranchStatus(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found in PATH, skipping test")
+	}
+
+	repoDir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-b", "main"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test User"},
+		{"config", "commit.gpgsign", "false"},
+		{"commit", "--allow-empty", "-m", "Initial commit"},
+		{"branch", "parent"},
+		{"branch", "child", "parent"},
+		{"branch", "orphan"},
+		{"config", "branch.child.rig-base", "parent"},
+		{"config", "branch.orphan.rig-base", "deleted"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	t.Chdir(repoDir)
+	gitManager := git.NewWorktreeManager("", false)
+
+	tests := []struct {
+		branch string
+		want   string
+	}{
+		{branch: "child", want: " +0/-0 vs parent"},
+		{branch: "orphan", want: " +0/-0 vs main"},
+		{branch: "parent", want: " +0/-0 vs main"},
+		{branch: "main", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := branchStatus(gitManager, tt.branch); got != tt.want {
+			t.Errorf("branchStatus(%q) = %q, want %q", tt.branch, got, tt.want)
+		}
+	}
+}
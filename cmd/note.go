@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+
+	"thoreinstein.com/rig/pkg/config"
+	"thoreinstein.com/rig/pkg/notes"
+)
+
+var noteTicket string
+
+// noteCmd groups the quick-capture commands for the current ticket's note
+var noteCmd = &cobra.Command{
+	Use:   "note",
+	Short: "Add to or open the current ticket's note",
+	Long: `Quickly add to the current ticket's note without opening it.
+
+The ticket is inferred from $RIG_TICKET, the current worktree path, or the
+current branch name. Use --ticket (or the argument of open and path) to pick
+another ticket or hack.`,
+}
+
+// noteAddCmd appends a timestamped bullet to the Log section
+var noteAddCmd = &cobra.Command{
+	Use:   "add <text>",
+	Short: "Add a timestamped entry to the note's Log",
+	Long: `Append a timestamped bullet to the Log section of the ticket note.
+
+Examples:
+  rig note add "Reproduced with the staging config"
+  rig note add --ticket proj-123 Deployed the fix`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runNoteAddCommand(time.Now(), strings.Join(args, " "))
+	},
+}
+
+// noteTodoCmd adds a checklist item to the Tasks section
+var noteTodoCmd = &cobra.Command{
+	Use:   "todo <text>",
+	Short: "Add a checklist item to the note's Tasks",
+	Long: `Add an unchecked "- [ ]" item to the Tasks section of the ticket note,
+creating the section if needed.
+
+Examples:
+  rig note todo "Update the runbook"`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runNoteTodoCommand(strings.Join(args, " "))
+	},
+}
+
+// noteEventCmd records a typed event in the daily note
+var noteEventCmd = &cobra.Command{
+	Use:   "event <event> [detail]",
+	Short: "Record a ticket event in today's daily note",
+	Long: `Record an event for the ticket in its subsection of the Log section of
+today's daily note. rig work and rig sync record started, resumed, synced
+and status events themselves; use this for the others, for example from a
+git alias or hook.
+
+Events: ` + strings.Join(notes.LogEvents, ", ") + `
+
+Repeats of the same entry within notes.log.dedupe_window are skipped, and
+entries are rendered with the templates in [notes.log.formats].
+
+Examples:
+  rig note event pr_opened https://github.com/org/repo/pull/42
+  rig note event done --ticket proj-123`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runNoteEventCommand(args[0], strings.Join(args[1:], " "))
+	},
+}
+
+// noteOpenCmd opens the note in $EDITOR
+var noteOpenCmd = &cobra.Command{
+	Use:   "open [ticket]",
+	Short: "Open the ticket note in $EDITOR",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runNoteOpenCommand(strings.Join(args, ""))
+	},
+}
+
+// notePathCmd prints the note path
+var notePathCmd = &cobra.Command{
+	Use:   "path [ticket]",
+	Short: "Print the path of the ticket note",
+	Long: `Print the path of the ticket note, whether or not it exists yet.
+
+Examples:
+  rig note path
+  code "$(rig note path proj-123)"`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runNotePathCommand(strings.Join(args, ""))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(noteCmd)
+	noteCmd.AddCommand(noteAddCmd)
+	noteCmd.AddCommand(noteTodoCmd)
+	noteCmd.AddCommand(noteEventCmd)
+	noteCmd.AddCommand(noteOpenCmd)
+	noteCmd.AddCommand(notePathCmd)
+
+	noteAddCmd.Flags().StringVarP(&noteTicket, "ticket", "t", "", "Ticket or hack to add to (default: inferred)")
+	noteTodoCmd.Flags().StringVarP(&noteTicket, "ticket", "t", "", "Ticket or hack to add to (default: inferred)")
+	noteEventCmd.Flags().StringVarP(&noteTicket, "ticket", "t", "", "Ticket or hack the event is about (default: inferred)")
+}
+
+// resolveNoteTarget loads the configuration and resolves the ticket or hack
+// whose note the command works on, inferring it when ticket is ""
+func resolveNoteTarget(ticket string) (*notes.Manager, worktreeRef, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, worktreeRef{}, errors.Wrap(err, "failed to load configuration")
+	}
+
+	ticket, err = resolveTicketArg([]string{ticket})
+	if err != nil {
+		return nil, worktreeRef{}, err
+	}
+
+	ref, err := noteRef(ticket)
+	if err != nil {
+		return nil, worktreeRef{}, err
+	}
+	return newNoteManager(cfg, verbose), ref, nil
+}
+
+// noteRef identifies the note of a ticket or hack. Hack sessions export
+// RIG_TICKET_TYPE=hack, which tells hacks named like tickets apart.
+func noteRef(ticket string) (worktreeRef, error) {
+	if os.Getenv("RIG_TICKET_TYPE") == "hack" && os.Getenv(ticketEnvVar) == ticket {
+		return worktreeRef{Type: "hack", Name: ticket}, nil
+	}
+	return parseWorktreeRef(ticket)
+}
+
+// formatNoteEntry formats text as a timestamped Log bullet
+func formatNoteEntry(at time.Time, text string) string {
+	return fmt.Sprintf("- [%s] %s", at.Format("2006-01-02 15:04"), text)
+}
+
+// noteText collapses text to one line so it stays a single list item
+func noteText(text string) (string, error) {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return "", errors.New("note text cannot be empty")
+	}
+	return text, nil
+}
+
+func runNoteAddCommand(now time.Time, text string) error {
+	text, err := noteText(text)
+	if err != nil {
+		return err
+	}
+
+	noteManager, ref, err := resolveNoteTarget(noteTicket)
+	if err != nil {
+		return err
+	}
+
+	notePath, err := noteManager.AddTicketEntry(ref.Type, ref.Name, "Log", formatNoteEntry(now, text))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Added to %s\n", shortenHome(notePath))
+	return nil
+}
+
+func runNoteTodoCommand(text string) error {
+	text, err := noteText(text)
+	if err != nil {
+		return err
+	}
+
+	noteManager, ref, err := resolveNoteTarget(noteTicket)
+	if err != nil {
+		return err
+	}
+
+	notePath, err := noteManager.AddTicketEntry(ref.Type, ref.Name, "Tasks", "- [ ] "+text)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Added task to %s\n", shortenHome(notePath))
+	return nil
+}
+
+func runNoteEventCommand(event, detail string) error {
+	if !notes.IsLogEvent(event) {
+		return errors.Newf("unknown event %q (use %s)", event, strings.Join(notes.LogEvents, ", "))
+	}
+
+	noteManager, ref, err := resolveNoteTarget(noteTicket)
+	if err != nil {
+		return err
+	}
+
+	added, err := noteManager.AddDailyEvent(notes.LogEvent{
+		Event:  event,
+		Ticket: ref.Name,
+		Type:   ref.Type,
+		Detail: strings.Join(strings.Fields(detail), " "),
+	})
+	if err != nil {
+		return err
+	}
+	if event == notes.EventDone {
+		finishTicket(noteManager, ref, time.Now())
+	}
+
+	dailyNotePath := shortenHome(noteManager.GetDailyNotePath())
+	if !added {
+		fmt.Printf("Already logged %s for %s recently in %s\n", event, ref.Name, dailyNotePath)
+		return nil
+	}
+	fmt.Printf("Logged %s for %s in %s\n", event, ref.Name, dailyNotePath)
+	return nil
+}
+
+// finishTicket records the day a ticket was done in its note's front
+// matter, for the index notes, and refreshes them. A ticket without a note
+// is only logged.
+func finishTicket(noteManager *notes.Manager, ref worktreeRef, at time.Time) {
+	if _, err := noteManager.UpdateTicketFrontMatter(ref.Type, ref.Name, notes.FrontMatter{Finished: at.Format("2006-01-02")}); err != nil {
+		if verbose {
+			fmt.Printf("Warning: Could not update front matter: %v\n", err)
+		}
+		return
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return
+	}
+	refreshIndexNotes(cfg, noteManager)
+}
+
+func runNoteOpenCommand(ticket string) error {
+	noteManager, ref, err := resolveNoteTarget(ticket)
+	if err != nil {
+		return err
+	}
+
+	notePath := noteManager.GetNotePath(ref.Type, ref.Name)
+	if _, err := os.Stat(notePath); err != nil {
+		return errors.Newf("ticket note not found: %s", notePath)
+	}
+
+	return openInEditor(notePath)
+}
+
+func runNotePathCommand(ticket string) error {
+	noteManager, ref, err := resolveNoteTarget(ticket)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(noteManager.GetNotePath(ref.Type, ref.Name))
+	return nil
+}
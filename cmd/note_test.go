@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"thoreinstein.com/rig/pkg/notes"
+)
+
+func TestNoteCommandStructure(t *testing.T) {
+	if noteCmd.Parent() != rootCmd {
+		t.Error("note should be a root command")
+	}
+
+	want := map[string]bool{"add": false, "todo": false, "event": false, "open": false, "path": false}
+	for _, sub := range noteCmd.Commands() {
+		if _, ok := want[sub.Name()]; ok {
+			want[sub.Name()] = true
+		}
+	}
+	for name, found := range want {
+		if !found {
+			t.Errorf("note should have a %s subcommand", name)
+		}
+	}
+
+	for _, sub := range []string{"add", "todo", "event"} {
+		cmd, _, err := noteCmd.Find([]string{sub})
+		if err != nil || cmd.Flags().Lookup("ticket") == nil {
+			t.Errorf("note %s should have a --ticket flag", sub)
+		}
+	}
+}
+
+func TestFormatNoteEntry(t *testing.T) {
+	at := time.Date(2025, 1, 15, 14, 30, 0, 0, time.Local)
+	if got := formatNoteEntry(at, "Deployed"); got != "- [2025-01-15 14:30] Deployed" {
+		t.Errorf("formatNoteEntry() = %q", got)
+	}
+}
+
+func TestNoteText(t *testing.T) {
+	got, err := noteText("  two\nlines  ")
+	if err != nil || got != "two lines" {
+		t.Errorf("noteText() = %q, %v; want %q", got, err, "two lines")
+	}
+	if _, err := noteText(" \n "); err == nil {
+		t.Error("noteText() should reject blank text")
+	}
+}
+
+func TestNoteRef(t *testing.T) {
+	t.Setenv(ticketEnvVar, "")
+	t.Setenv("RIG_TICKET_TYPE", "")
+
+	ref, err := noteRef("proj-123")
+	if err != nil || ref != (worktreeRef{Type: "proj", Name: "proj-123"}) {
+		t.Errorf("noteRef(proj-123) = %+v, %v", ref, err)
+	}
+
+	ref, err = noteRef("winter-cleanup")
+	if err != nil || ref != (worktreeRef{Type: "hack", Name: "winter-cleanup"}) {
+		t.Errorf("noteRef(winter-cleanup) = %+v, %v", ref, err)
+	}
+
+	// A hack session's ticket is a hack even if it looks like a ticket
+	t.Setenv(ticketEnvVar, "spike-2")
+	t.Setenv("RIG_TICKET_TYPE", "hack")
+	ref, err = noteRef("spike-2")
+	if err != nil || ref != (worktreeRef{Type: "hack", Name: "spike-2"}) {
+		t.Errorf("noteRef(spike-2) in a hack session = %+v, %v", ref, err)
+	}
+}
+
+func TestRunNoteCommands(t *testing.T) {
+	notesDir := t.TempDir()
+	setupSyncTestConfig(t, notesDir)
+	defer viper.Reset()
+	t.Setenv(ticketEnvVar, "proj-5")
+	t.Setenv("RIG_TICKET_TYPE", "proj")
+
+	notePath := filepath.Join(notesDir, "proj", "proj-5.md")
+	if err := os.MkdirAll(filepath.Dir(notePath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(notePath, []byte("# proj-5\n\n## Log\n\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	at := time.Date(2025, 1, 15, 14, 30, 0, 0, time.Local)
+	var errs []error
+	output := captureOutput(func() {
+		errs = append(errs,
+			runNoteAddCommand(at, "first"),
+			runNoteTodoCommand("check it"),
+			runNoteAddCommand(at, "second"),
+			runNotePathCommand(""),
+		)
+	})
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("command %d error: %v", i, err)
+		}
+	}
+
+	if !strings.HasSuffix(output, notePath+"\n") {
+		t.Errorf("path should be printed last, got:\n%s", output)
+	}
+
+	content, _ := os.ReadFile(notePath)
+	want := "# proj-5\n\n## Log\n- [2025-01-15 14:30] first\n- [2025-01-15 14:30] second\n\n## Tasks\n- [ ] check it\n"
+	if string(content) != want {
+		t.Errorf("note =\n%q\nwant:\n%q", content, want)
+	}
+
+	noteTicket = "proj-404"
+	defer func() { noteTicket = "" }()
+	if err := runNoteAddCommand(at, "lost"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("adding to a missing note should fail, got %v", err)
+	}
+}
+
+func TestRunNoteEventCommand(t *testing.T) {
+	notesDir := t.TempDir()
+	setupSyncTestConfig(t, notesDir)
+	defer viper.Reset()
+	t.Setenv(ticketEnvVar, "proj-5")
+	t.Setenv("RIG_TICKET_TYPE", "proj")
+
+	var errs []error
+	output := captureOutput(func() {
+		errs = append(errs,
+			runNoteEventCommand(notes.EventPROpened, "https://example.com/pull/5"),
+			runNoteEventCommand(notes.EventPROpened, "https://example.com/pull/5"),
+		)
+	})
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("command %d error: %v", i, err)
+		}
+	}
+	if !strings.Contains(output, "Logged pr_opened for proj-5") || !strings.Contains(output, "Already logged pr_opened") {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+
+	content, err := os.ReadFile(filepath.Join(notesDir, "daily", time.Now().Format("2006-01-02")+".md"))
+	if err != nil {
+		t.Fatalf("daily note should be created: %v", err)
+	}
+	if strings.Count(string(content), "Opened PR https://example.com/pull/5") != 1 || !strings.Contains(string(content), "### [proj-5](../proj/proj-5.md)\n") {
+		t.Errorf("daily note should have one PR entry under the ticket:\n%s", content)
+	}
+
+	if err := runNoteEventCommand("deployed", ""); err == nil || !strings.Contains(err.Error(), "unknown event") {
+		t.Errorf("an unknown event should fail, got %v", err)
+	}
+}
+
+func TestRunNoteEventCommand_Done(t *testing.T) {
+	notesDir := t.TempDir()
+	setupSyncTestConfig(t, notesDir)
+	defer viper.Reset()
+	viper.Set("notes.auto_index", true)
+	t.Setenv(ticketEnvVar, "proj-5")
+	t.Setenv("RIG_TICKET_TYPE", "proj")
+
+	noteManager := notes.NewManager(notesDir, "daily", "", false)
+	if _, err := noteManager.CreateTicketNote(notes.TicketData{Ticket: "proj-5", TicketType: "proj", Date: "2025-01-15"}); err != nil {
+		t.Fatal(err)
+	}
+
+	output := captureOutput(func() {
+		if err := runNoteEventCommand(notes.EventDone, ""); err != nil {
+			t.Errorf("runNoteEventCommand() error: %v", err)
+		}
+	})
+	if !strings.Contains(output, "Index notes updated") {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+
+	today := time.Now().Format("2006-01-02")
+	fm, err := noteManager.TicketFrontMatter("proj", "proj-5")
+	if err != nil || fm.Finished != today {
+		t.Errorf("finished = %q, %v; want %s", fm.Finished, err, today)
+	}
+	index, _ := os.ReadFile(noteManager.IndexNotePath("proj"))
+	if !strings.Contains(string(index), "## Done (1)\n\n- [proj-5](proj-5.md) · created 2025-01-15 · finished "+today) {
+		t.Errorf("index should list proj-5 as done:\n%s", index)
+	}
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+
+	"thoreinstein.com/rig/pkg/config"
+	"thoreinstein.com/rig/pkg/notes"
+)
+
+var (
+	notesSearchType    string
+	notesSearchStatus  string
+	notesSearchSince   string
+	notesSearchUntil   string
+	notesSearchLimit   int
+	notesSearchReindex bool
+)
+
+// notesCmd groups commands that work across all notes
+var notesCmd = &cobra.Command{
+	Use:   "notes",
+	Short: "Work with all ticket and daily notes",
+	Long:  `Commands that work across every ticket and daily note under notes.path.`,
+}
+
+// notesSearchCmd searches notes through the full-text index
+var notesSearchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Full-text search of ticket and daily notes",
+	Long: `Search ticket and daily notes and print the best matches with a snippet.
+
+Notes are indexed in an SQLite full-text index at notes.index_path, which is
+brought up to date before every search: only new, changed and deleted notes
+are re-read. --reindex rebuilds it from scratch.
+
+All words must match, in any form ("fixing" finds "fixed"). Use "quoted
+phrases" for exact phrases, a trailing * for prefixes, and OR or NOT
+between words.
+
+Examples:
+  rig notes search etcd quorum
+  rig notes search '"quorum lost"' --type incident
+  rig notes search deploy* --since 30d --status Done
+  rig notes search standup --type daily --since 2025-01-01 --until 2025-01-31`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runNotesSearchCommand(strings.Join(args, " "), time.Now())
+	},
+}
+
+// notesIndexCmd regenerates the index notes
+var notesIndexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "Regenerate the index notes of active and finished tickets",
+	Long: `Regenerate an _index.md note in each ticket type's note directory and an
+overall one in the notes directory.
+
+Tickets and hacks are grouped by the status in their note's front matter,
+which 'rig sync' adds to older notes and keeps up to date from JIRA, with the finished groups (Done,
+Closed, Resolved) last. Each ticket is listed with its summary, link, and
+the day its note was created and, once 'rig note event done' or 'rig
+incident close' recorded it, finished.
+
+The Notes section of an index note is yours: it is kept when the index is
+regenerated. Set notes.auto_index to regenerate the index notes after
+'rig work', 'rig sync' and done.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runNotesIndexCommand(time.Now())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(notesCmd)
+	notesCmd.AddCommand(notesSearchCmd)
+	notesCmd.AddCommand(notesIndexCmd)
+
+	notesSearchCmd.Flags().StringVar(&notesSearchType, "type", "", "Only notes of this ticket type (\"daily\" for daily notes)")
+	notesSearchCmd.Flags().StringVar(&notesSearchStatus, "status", "", "Only notes with this status (e.g. \"Done\")")
+	notesSearchCmd.Flags().StringVar(&notesSearchSince, "since", "", "Only notes dated on or after this day (YYYY-MM-DD or an age like 30d)")
+	notesSearchCmd.Flags().StringVar(&notesSearchUntil, "until", "", "Only notes dated on or before this day (YYYY-MM-DD or an age like 30d)")
+	notesSearchCmd.Flags().IntVarP(&notesSearchLimit, "limit", "n", 20, "Maximum number of results")
+	notesSearchCmd.Flags().BoolVar(&notesSearchReindex, "reindex", false, "Rebuild the search index before searching")
+}
+
+// parseSearchDate parses a --since/--until value: a date, or an age such
+// as 30d meaning that long before now
+func parseSearchDate(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	age, err := parseAge(value)
+	if err != nil {
+		return time.Time{}, errors.Newf("invalid date %q: use YYYY-MM-DD or an age like 30d", value)
+	}
+	return now.Add(-age), nil
+}
+
+func runNotesSearchCommand(query string, now time.Time) error {
+	if notesSearchLimit <= 0 {
+		return errors.Newf("--limit must be positive, got %d", notesSearchLimit)
+	}
+
+	since, err := parseSearchDate(notesSearchSince, now)
+	if err != nil {
+		return errors.Wrap(err, "invalid --since")
+	}
+	until, err := parseSearchDate(notesSearchUntil, now)
+	if err != nil {
+		return errors.Wrap(err, "invalid --until")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.Wrap(err, "failed to load configuration")
+	}
+
+	index, err := newNoteManager(cfg, verbose).OpenSearchIndex(cfg.Notes.IndexPath)
+	if err != nil {
+		return err
+	}
+	defer index.Close()
+
+	update := index.Update
+	if notesSearchReindex {
+		update = index.Rebuild
+	}
+	stats, err := update()
+	if err != nil {
+		return errors.Wrap(err, "failed to update search index")
+	}
+	if verbose || notesSearchReindex {
+		fmt.Printf("Indexed %d note(s): %d added, %d updated, %d removed\n\n", stats.Total, stats.Added, stats.Updated, stats.Removed)
+	}
+
+	results, err := index.Search(query, notes.SearchOptions{
+		Type:   notesSearchType,
+		Status: notesSearchStatus,
+		Since:  since,
+		Until:  until,
+		Limit:  notesSearchLimit,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		fmt.Printf("No notes match %q\n", query)
+		return nil
+	}
+	writeSearchResults(os.Stdout, results)
+	return nil
+}
+
+// writeSearchResults prints each result's path, title, status and date,
+// and snippet
+func writeSearchResults(w io.Writer, results []notes.SearchResult) {
+	for i, r := range results {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+
+		details := []string{r.Date}
+		if r.Status != "" {
+			details = append([]string{r.Status}, details...)
+		}
+		fmt.Fprintf(w, "%s\n", shortenHome(r.Path))
+		fmt.Fprintf(w, "  %s (%s)\n", r.Title, strings.Join(details, ", "))
+		if r.Snippet != "" {
+			fmt.Fprintf(w, "  %s\n", r.Snippet)
+		}
+	}
+}
+
+func runNotesIndexCommand(now time.Time) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.Wrap(err, "failed to load configuration")
+	}
+
+	results, err := newNoteManager(cfg, verbose).WriteIndexNotes(now)
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		state := "unchanged"
+		if result.Changed {
+			state = "updated"
+		}
+		fmt.Printf("%s: %d ticket(s), %s\n", shortenHome(result.Path), result.Tickets, state)
+	}
+	return nil
+}
+
+// refreshIndexNotes regenerates the index notes when notes.auto_index is
+// set. Failures are only warnings, the index notes can be regenerated with
+// 'rig notes index'.
+func refreshIndexNotes(cfg *config.Config, noteManager *notes.Manager) {
+	if !cfg.Notes.AutoIndex {
+		return
+	}
+
+	results, err := noteManager.WriteIndexNotes(time.Now())
+	if err != nil {
+		fmt.Printf("Warning: Could not update index notes: %v\n", err)
+		return
+	}
+	for _, result := range results {
+		if result.Changed {
+			fmt.Println("Index notes updated")
+			return
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"thoreinstein.com/rig/pkg/config"
+)
+
+func TestNotesCommandStructure(t *testing.T) {
+	if notesCmd.Parent() != rootCmd {
+		t.Error("notes should be a root command")
+	}
+	if notesSearchCmd.Parent() != notesCmd {
+		t.Error("search should be a notes subcommand")
+	}
+	if notesIndexCmd.Parent() != notesCmd {
+		t.Error("index should be a notes subcommand")
+	}
+
+	for _, name := range []string{"type", "status", "since", "until", "limit", "reindex"} {
+		if notesSearchCmd.Flags().Lookup(name) == nil {
+			t.Errorf("notes search should have a --%s flag", name)
+		}
+	}
+}
+
+func TestParseSearchDate(t *testing.T) {
+	now := time.Date(2025, 3, 31, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"", time.Time{}, false},
+		{"2025-01-15", time.Date(2025, 1, 15, 0, 0, 0, 0, time.Local), false},
+		{"30d", now.AddDate(0, 0, -30), false},
+		{"2w", now.AddDate(0, 0, -14), false},
+		{"last week", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseSearchDate(tt.value, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSearchDate(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseSearchDate(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunNotesSearchCommand(t *testing.T) {
+	notesDir := t.TempDir()
+	setupSyncTestConfig(t, notesDir)
+	defer viper.Reset()
+	viper.Set("notes.index_path", filepath.Join(t.TempDir(), "notes-index.db"))
+
+	notePath := filepath.Join(notesDir, "ops", "ops-7.md")
+	if err := os.MkdirAll(filepath.Dir(notePath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(notePath, []byte("---\nstatus: Done\ncreated: 2025-01-10\n---\n# Restore etcd quorum\n\nReplaced the failed member.\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() { notesSearchType, notesSearchLimit = "", 20 }()
+	notesSearchLimit = 20
+
+	var err error
+	output := captureOutput(func() { err = runNotesSearchCommand("failed member", time.Now()) })
+	if err != nil {
+		t.Fatalf("runNotesSearchCommand() error: %v", err)
+	}
+	for _, want := range []string{notePath, "ops-7: Restore etcd quorum (Done, 2025-01-10)", "**failed** **member**"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output should contain %q, got:\n%s", want, output)
+		}
+	}
+
+	notesSearchType = "proj"
+	output = captureOutput(func() { err = runNotesSearchCommand("failed member", time.Now()) })
+	if err != nil || !strings.Contains(output, "No notes match") {
+		t.Errorf("filtered search = %q, %v; want no matches", output, err)
+	}
+
+	notesSearchLimit = 0
+	if err := runNotesSearchCommand("etcd", time.Now()); err == nil || !strings.Contains(err.Error(), "--limit") {
+		t.Errorf("runNotesSearchCommand() error = %v, want --limit error", err)
+	}
+}
+
+func TestRunNotesIndexCommand(t *testing.T) {
+	notesDir := t.TempDir()
+	setupSyncTestConfig(t, notesDir)
+	defer viper.Reset()
+
+	notePath := filepath.Join(notesDir, "ops", "ops-7.md")
+	if err := os.MkdirAll(filepath.Dir(notePath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(notePath, []byte("---\nticket: ops-7\nstatus: In Progress\ncreated: 2025-01-10\n---\n# Restore etcd quorum\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var err error
+	output := captureOutput(func() { err = runNotesIndexCommand(time.Now()) })
+	if err != nil {
+		t.Fatalf("runNotesIndexCommand() error: %v", err)
+	}
+	for _, want := range []string{filepath.Join(notesDir, "_index.md") + ": 1 ticket(s), updated", filepath.Join(notesDir, "ops", "_index.md") + ": 1 ticket(s), updated"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output should contain %q, got:\n%s", want, output)
+		}
+	}
+
+	content, err := os.ReadFile(filepath.Join(notesDir, "ops", "_index.md"))
+	if err != nil || !strings.Contains(string(content), "## In Progress (1)\n\n- [ops-7](ops-7.md) Restore etcd quorum · created 2025-01-10\n") {
+		t.Errorf("unexpected ops index (%v):\n%s", err, content)
+	}
+
+	output = captureOutput(func() { err = runNotesIndexCommand(time.Now()) })
+	if err != nil || strings.Contains(output, "updated") {
+		t.Errorf("second run = %q, %v; want no changes", output, err)
+	}
+}
+
+func TestRefreshIndexNotes_Disabled(t *testing.T) {
+	notesDir := t.TempDir()
+	setupSyncTestConfig(t, notesDir)
+	defer viper.Reset()
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	refreshIndexNotes(cfg, newNoteManager(cfg, false))
+	if _, err := os.Stat(filepath.Join(notesDir, "_index.md")); !os.IsNotExist(err) {
+		t.Errorf("index notes should only be written with notes.auto_index, got %v", err)
+	}
+}
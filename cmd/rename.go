@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+
+	"thoreinstein.com/rig/pkg/config"
+	"thoreinstein.com/rig/pkg/git"
+	"thoreinstein.com/rig/pkg/multiplexer"
+	"thoreinstein.com/rig/pkg/notes"
+)
+
+// renameCmd represents the rename command
+var renameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a worktree and re-key its ticket",
+	Long: `Rename a worktree and everything rig associates with it.
+
+Use this when a ticket is cloned into a new key or a hack becomes a real
+ticket. The command:
+- Moves the worktree with 'git worktree move'
+- Renames the branch
+- Renames the session and, in tmux, rebuilds its RIG_* and profile
+  environment for the new ticket
+- Moves the markdown note and rewrites links to it in daily notes
+
+Arguments may be given as a ticket (proj-123), a hack name (experiment-auth),
+or an explicit type/name path relative to the repository.
+
+Examples:
+  rig rename hack/experiment-auth proj/proj-456
+  rig rename experiment-auth proj-456
+  rig rename proj-123 proj-789`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRenameCommand(args[0], args[1])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(renameCmd)
+}
+
+// worktreeRef identifies a worktree by its type directory and name
+type worktreeRef struct {
+	Type string
+	Name string
+}
+
+// parseWorktreeRef parses "type/name", a ticket ("proj-123") or a hack name
+func parseWorktreeRef(ref string) (worktreeRef, error) {
+	if typ, name, found := strings.Cut(ref, "/"); found {
+		if typ == "hack" {
+			if err := validateHackName(name); err != nil {
+				return worktreeRef{}, err
+			}
+			return worktreeRef{Type: typ, Name: name}, nil
+		}
+
+		ticketInfo, err := parseTicket(name)
+		if err != nil {
+			return worktreeRef{}, err
+		}
+		if !strings.EqualFold(typ, ticketInfo.Type) {
+			return worktreeRef{}, errors.Newf("type %q does not match ticket %q", typ, name)
+		}
+		return worktreeRef{Type: ticketInfo.Type, Name: ticketInfo.Full}, nil
+	}
+
+	if ticketInfo, err := parseTicket(ref); err == nil {
+		return worktreeRef{Type: ticketInfo.Type, Name: ticketInfo.Full}, nil
+	}
+
+	if err := validateHackName(ref); err != nil {
+		return worktreeRef{}, errors.Newf("invalid worktree %q: expected a ticket (proj-123), a hack name, or type/name", ref)
+	}
+	return worktreeRef{Type: "hack", Name: ref}, nil
+}
+
+func runRenameCommand(oldRef, newRef string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.Wrap(err, "failed to load configuration")
+	}
+
+	from, err := parseWorktreeRef(oldRef)
+	if err != nil {
+		return err
+	}
+	to, err := parseWorktreeRef(newRef)
+	if err != nil {
+		return err
+	}
+	if from == to {
+		return errors.New("old and new names are the same")
+	}
+
+	if verbose {
+		fmt.Printf("Renaming %s/%s to %s/%s\n", from.Type, from.Name, to.Type, to.Name)
+	}
+
+	gitManager := git.NewWorktreeManager(cfg.Git.BaseBranch, verbose)
+	repoRoot, err := gitManager.GetRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	// Determine the branch before moving so we can fail early on conflicts
+	oldBranch, err := gitManager.BranchForWorktree(worktreePathFor(repoRoot, from))
+	if err != nil {
+		return errors.Wrap(err, "failed to determine worktree branch")
+	}
+	if gitManager.BranchExists(to.Name) {
+		return errors.Newf("branch already exists: %s", to.Name)
+	}
+
+	// Step 1: Move the worktree
+	newWorktreePath, err := gitManager.MoveWorktree(from.Type, from.Name, to.Type, to.Name)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Worktree moved to: %s\n", newWorktreePath)
+
+	// Step 2: Rename the branch
+	if err := gitManager.RenameBranch(oldBranch, to.Name); err != nil {
+		return errors.Wrap(err, "worktree moved but branch rename failed")
+	}
+	fmt.Printf("Branch renamed: %s -> %s\n", oldBranch, to.Name)
+
+	// Step 3: Rename the session
+	sessionRenamed := false
+	sessionManager, err := newMultiplexer(cfg, nil)
+	if err != nil {
+		fmt.Printf("Warning: Could not rename session: %v\n", err)
+	} else if sessionManager.SessionExists(sessionManager.GetSessionName(from.Name)) {
+		if err := sessionManager.RenameSession(from.Name, to.Name); err != nil {
+			fmt.Printf("Warning: Could not rename %s session: %v\n", sessionManager.Name(), err)
+		} else {
+			sessionRenamed = true
+			fmt.Printf("%s session renamed to: %s\n", sessionManager.Name(), sessionManager.GetSessionName(to.Name))
+			fmt.Println("Note: shells in the session still point at the old directory; cd into the new worktree or restart them.")
+		}
+	}
+
+	// Step 4: Move the note and rewrite daily note links
+	noteManager := newNoteManager(cfg, verbose)
+	if _, statErr := os.Stat(noteManager.GetNotePath(from.Type, from.Name)); statErr == nil {
+		notePath, updated, err := noteManager.RenameTicketNote(from.Type, from.Name, to.Type, to.Name)
+		if err != nil {
+			fmt.Printf("Warning: Could not move note: %v\n", err)
+		} else {
+			fmt.Printf("Note moved to: %s (%d daily note(s) updated)\n", notePath, updated)
+
+			// Point front matter at the moved worktree and renamed branch
+			if fm, err := noteManager.TicketFrontMatter(to.Type, to.Name); err == nil && fm.Ticket != "" {
+				update := notes.FrontMatter{Branch: to.Name, Worktree: newWorktreePath}
+				if _, err := noteManager.UpdateTicketFrontMatter(to.Type, to.Name, update); err != nil {
+					fmt.Printf("Warning: Could not update note front matter: %v\n", err)
+				}
+			}
+		}
+	} else if verbose {
+		fmt.Println("No note found for the old name, skipping")
+	}
+
+	// Step 5: Point the session's environment at the new ticket, now that
+	// its note has moved
+	if sessionRenamed {
+		notePath := noteManager.GetNotePath(to.Type, to.Name)
+		if _, err := os.Stat(notePath); err != nil {
+			notePath = ""
+		}
+		env, stale, err := renamedSessionEnvironment(cfg, gitManager, from, to, repoRoot, newWorktreePath, notePath)
+		if err == nil {
+			err = updateSessionEnvironment(sessionManager, sessionManager.GetSessionName(to.Name), env, stale)
+		}
+		switch {
+		case errors.Is(err, multiplexer.ErrUnsupported):
+			fmt.Printf("Note: %s cannot change a running session's environment; its RIG_* variables describe %s until it is recreated.\n", sessionManager.Name(), from.Name)
+		case err != nil:
+			fmt.Printf("Warning: Could not update session environment: %v\n", err)
+		}
+	}
+
+	fmt.Printf("\nRenamed %s to %s\n", from.Name, to.Name)
+	return nil
+}
+
+// renamedSessionEnvironment returns the session environment of a renamed
+// worktree, built like the one 'rig work' starts sessions with, and the
+// variables of the old one that no longer apply: RIG_* variables the new
+// ticket has no value for, such as RIG_URL for a hack, and variables of the
+// old type's profile
+func renamedSessionEnvironment(cfg *config.Config, gitManager *git.WorktreeManager, from, to worktreeRef, repoRoot, worktreePath, notePath string) (map[string]string, []string, error) {
+	repoName := filepath.Base(repoRoot)
+	_, profileEnv, err := resolveSessionWindows(cfg, "", to.Type, repoRoot, repoName, worktreePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx := newTicketContext(cfg, gitManager, to.Name, to.Type, repoName, worktreePath, notePath, "")
+	env := ctx.sessionEnvironment(profileEnv)
+
+	candidates := slices.Collect(maps.Keys(ctx.variables()))
+	// Repository configs were merged by resolveSessionWindows
+	if oldProfile, _, err := cfg.Tmux.ResolveProfile("", from.Type, repoName); err == nil {
+		if oldEnv, err := cfg.Tmux.ProfileEnv(oldProfile); err == nil {
+			candidates = append(candidates, slices.Collect(maps.Keys(oldEnv))...)
+		}
+	}
+
+	var stale []string
+	for _, key := range candidates {
+		if _, ok := env[key]; !ok && !slices.Contains(stale, key) {
+			stale = append(stale, key)
+		}
+	}
+	slices.Sort(stale)
+	return env, stale, nil
+}
+
+// updateSessionEnvironment sets env in a session and removes the stale
+// variables
+func updateSessionEnvironment(sessionManager multiplexer.Multiplexer, sessionName string, env map[string]string, stale []string) error {
+	if err := sessionManager.SetEnvironment(sessionName, env); err != nil {
+		return err
+	}
+	return sessionManager.UnsetEnvironment(sessionName, stale)
+}
+
+// worktreePathFor returns the worktree directory for a ref under the repo root
+func worktreePathFor(repoRoot string, ref worktreeRef) string {
+	return filepath.Join(repoRoot, ref.Type, ref.Name)
+}
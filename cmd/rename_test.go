@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+
+	"thoreinstein.com/rig/pkg/config"
+	"thoreinstein.com/rig/pkg/git"
+	"thoreinstein.com/rig/pkg/multiplexer"
+)
+
+func TestRenameCommandStructure(t *testing.T) {
+	if renameCmd.Use != "rename <old> <new>" {
+		t.Errorf("rename command Use = %q, want %q", renameCmd.Use, "rename <old> <new>")
+	}
+	if err := renameCmd.ValidateArgs([]string{"a"}); err == nil {
+		t.Error("rename command should require two arguments")
+	}
+	if err := renameCmd.ValidateArgs([]string{"a", "b"}); err != nil {
+		t.Errorf("rename command should accept two arguments, got: %v", err)
+	}
+}
+
+func TestParseWorktreeRef(t *testing.T) {
+	tests := []struct {
+		ref      string
+		expected worktreeRef
+		wantErr  bool
+	}{
+		{ref: "proj-123", expected: worktreeRef{Type: "proj", Name: "proj-123"}},
+		{ref: "PROJ-123", expected: worktreeRef{Type: "proj", Name: "PROJ-123"}},
+		{ref: "experiment-auth", expected: worktreeRef{Type: "hack", Name: "experiment-auth"}},
+		{ref: "hack/experiment-auth", expected: worktreeRef{Type: "hack", Name: "experiment-auth"}},
+		{ref: "proj/proj-456", expected: worktreeRef{Type: "proj", Name: "proj-456"}},
+		{ref: "ops/proj-456", wantErr: true},
+		{ref: "proj/not-a-ticket", wantErr: true},
+		{ref: "hack/../etc", wantErr: true},
+		{ref: "../escape", wantErr: true},
+		{ref: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := parseWorktreeRef(tt.ref)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseWorktreeRef(%q) expected error, got %+v", tt.ref, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseWorktreeRef(%q) error: %v", tt.ref, err)
+			}
+			if got != tt.expected {
+				t.Errorf("parseWorktreeRef(%q) = %+v, want %+v", tt.ref, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenamedSessionEnvironment(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	repoRoot := t.TempDir()
+	worktreePath := filepath.Join(repoRoot, "proj", "proj-456")
+	if err := os.MkdirAll(worktreePath, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Jira: config.JiraConfig{BaseURL: "https://example.atlassian.net"},
+		Tmux: config.TmuxConfig{
+			Env: map[string]string{"LOG_DIR": "{worktree_path}/logs"},
+			Profiles: map[string]config.TmuxProfile{
+				"spike": {TicketTypes: []string{"hack"}, Env: map[string]string{"SPIKE": "1"}},
+			},
+		},
+	}
+	gitManager := git.NewWorktreeManager("", false)
+	from := worktreeRef{Type: "hack", Name: "experiment-auth"}
+	to := worktreeRef{Type: "proj", Name: "proj-456"}
+
+	// A hack becoming a ticket gets the ticket's context and loses the hack
+	// profile's variables
+	env, stale, err := renamedSessionEnvironment(cfg, gitManager, from, to, repoRoot, worktreePath, "/notes/proj/proj-456.md")
+	if err != nil {
+		t.Fatalf("renamedSessionEnvironment() error: %v", err)
+	}
+	for key, want := range map[string]string{
+		"RIG_TICKET":      "proj-456",
+		"RIG_TICKET_TYPE": "proj",
+		"RIG_WORKTREE":    worktreePath,
+		"RIG_NOTE":        "/notes/proj/proj-456.md",
+		"RIG_URL":         "https://example.atlassian.net/browse/PROJ-456",
+		"LOG_DIR":         worktreePath + "/logs",
+	} {
+		if env[key] != want {
+			t.Errorf("env[%s] = %q, want %q", key, env[key], want)
+		}
+	}
+	if !slices.Contains(stale, "SPIKE") || slices.Contains(stale, "RIG_NOTE") || slices.Contains(stale, "LOG_DIR") {
+		t.Errorf("stale = %v, want SPIKE but not the new variables", stale)
+	}
+
+	// A ticket becoming a hack loses its tracker link and note
+	env, stale, err = renamedSessionEnvironment(cfg, gitManager, to, from, repoRoot, worktreePath, "")
+	if err != nil {
+		t.Fatalf("renamedSessionEnvironment() error: %v", err)
+	}
+	if env["RIG_TICKET_TYPE"] != "hack" || env["SPIKE"] != "1" {
+		t.Errorf("env = %v, want the hack's context and profile", env)
+	}
+	if !slices.Contains(stale, "RIG_URL") || !slices.Contains(stale, "RIG_NOTE") || !slices.Contains(stale, "LOG_DIR") {
+		t.Errorf("stale = %v, want RIG_URL, RIG_NOTE and LOG_DIR", stale)
+	}
+}
+
+// fakeEnvSetter records the environment changes made to a session
+type fakeEnvSetter struct {
+	fakeMultiplexer
+	set   map[string]string
+	unset []string
+	err   error
+}
+
+func (f *fakeEnvSetter) SetEnvironment(sessionName string, vars map[string]string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.set = vars
+	return nil
+}
+
+func (f *fakeEnvSetter) UnsetEnvironment(sessionName string, keys []string) error {
+	f.unset = keys
+	return nil
+}
+
+func TestUpdateSessionEnvironment(t *testing.T) {
+	sessionManager := &fakeEnvSetter{}
+	env := map[string]string{"RIG_NOTE": "/notes/proj/proj-456.md", "RIG_TICKET_TYPE": "proj"}
+	if err := updateSessionEnvironment(sessionManager, "proj-456", env, []string{"SPIKE"}); err != nil {
+		t.Fatalf("updateSessionEnvironment() error: %v", err)
+	}
+	if sessionManager.set["RIG_NOTE"] != "/notes/proj/proj-456.md" || sessionManager.set["RIG_TICKET_TYPE"] != "proj" {
+		t.Errorf("set = %v", sessionManager.set)
+	}
+	if strings.Join(sessionManager.unset, ",") != "SPIKE" {
+		t.Errorf("unset = %v, want [SPIKE]", sessionManager.unset)
+	}
+
+	// Backends that can't change the environment report it
+	unsupported := &fakeEnvSetter{err: errors.Mark(errors.New("zellij"), multiplexer.ErrUnsupported)}
+	if err := updateSessionEnvironment(unsupported, "proj-456", env, nil); !errors.Is(err, multiplexer.ErrUnsupported) {
+		t.Errorf("updateSessionEnvironment() error = %v, want ErrUnsupported", err)
+	}
+}
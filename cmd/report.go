@@ -0,0 +1,292 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+
+	"thoreinstein.com/rig/pkg/config"
+	"thoreinstein.com/rig/pkg/history"
+	"thoreinstein.com/rig/pkg/notes"
+)
+
+// standupLookback is how far back rig report standup looks for the
+// previous working day's daily note
+const standupLookback = 7
+
+var (
+	reportDate   string
+	reportOutput string
+)
+
+// reportCmd groups the status reports built from daily notes
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Build stand-up and weekly reports from daily notes",
+	Long: `Build status reports from the Log sections of daily notes.
+
+Entries are grouped per ticket with the ticket's summary and status from its
+note, the status changes recorded by rig sync, and, when the history database
+is available, the time spent running commands in the ticket's worktree.
+
+Reports render through report-<kind>.md.tmpl, which can be overridden in
+notes.template_dir like any other template (see rig template show
+report-standup).`,
+}
+
+// reportStandupCmd reports the previous working day and today
+var reportStandupCmd = &cobra.Command{
+	Use:   "standup",
+	Short: "Report yesterday and today",
+	Long: `Report the previous working day and today.
+
+The previous working day is the most recent earlier day with a daily note,
+so a Monday stand-up covers Friday.
+
+Examples:
+  rig report standup
+  rig report standup --date 2025-01-13
+  rig report standup --output standup.md`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runReportCommand(notes.ReportStandup, time.Now())
+	},
+}
+
+// reportWeekCmd reports the current week
+var reportWeekCmd = &cobra.Command{
+	Use:   "week",
+	Short: "Report the week so far",
+	Long: `Report every day from Monday of the week through the given day.
+
+Examples:
+  rig report week
+  rig report week --date 2025-01-17 --output week-03.md`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runReportCommand(notes.ReportWeek, time.Now())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.AddCommand(reportStandupCmd)
+	reportCmd.AddCommand(reportWeekCmd)
+
+	reportCmd.PersistentFlags().StringVar(&reportDate, "date", "", "Report as of this day, YYYY-MM-DD (default: today)")
+	reportCmd.PersistentFlags().StringVarP(&reportOutput, "output", "o", "", "Write the report to this file (default: stdout)")
+}
+
+// reportDays returns the days a report covers, oldest first, as of day
+func reportDays(kind string, day time.Time, hasNote func(time.Time) bool) []time.Time {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+
+	if kind == notes.ReportWeek {
+		// Weeks start on Monday
+		offset := (int(day.Weekday()) + 6) % 7
+		var days []time.Time
+		for d := day.AddDate(0, 0, -offset); !d.After(day); d = d.AddDate(0, 0, 1) {
+			days = append(days, d)
+		}
+		return days
+	}
+
+	previous := day.AddDate(0, 0, -1)
+	for i := 1; i <= standupLookback; i++ {
+		if d := day.AddDate(0, 0, -i); hasNote(d) {
+			previous = d
+			break
+		}
+	}
+	return []time.Time{previous, day}
+}
+
+// reportDayLabel names a report day relative to the day of the report
+func reportDayLabel(kind string, d, day time.Time) string {
+	if kind == notes.ReportStandup {
+		switch {
+		case d.Equal(day):
+			return "Today"
+		case d.Equal(day.AddDate(0, 0, -1)):
+			return "Yesterday"
+		}
+	}
+	return d.Weekday().String()
+}
+
+// ticketFromDir returns the ticket or hack a command ran in, from a
+// worktree path ending in {type}/{ticket} or hack/{name}, or "" if the
+// directory isn't inside a worktree
+func ticketFromDir(dir string) string {
+	parts := strings.Split(filepath.ToSlash(filepath.Clean(dir)), "/")
+	for i := len(parts) - 1; i > 0; i-- {
+		name, parent := parts[i], parts[i-1]
+		if parent == "hack" && validateHackName(name) == nil {
+			return name
+		}
+		if ticketInfo, err := parseTicket(name); err == nil && strings.EqualFold(ticketInfo.Type, parent) {
+			return ticketInfo.Full
+		}
+	}
+	return ""
+}
+
+// addActiveTime fills in the commands run and active time of each ticket
+// from the commands attributed to it. Tickets with commands but no log
+// entries, such as one worked on all day in a resumed session, are added
+// after the logged ones, by name.
+func addActiveTime(tickets []notes.ReportTicket, byTicket map[string][]history.Command) []notes.ReportTicket {
+	logged := make(map[string]bool, len(tickets))
+	for _, ticket := range tickets {
+		logged[ticket.Ticket] = true
+	}
+	var unlogged []string
+	for ticket := range byTicket {
+		if !logged[ticket] {
+			unlogged = append(unlogged, ticket)
+		}
+	}
+	sort.Strings(unlogged)
+	for _, ticket := range unlogged {
+		tickets = append(tickets, notes.ReportTicket{Ticket: ticket})
+	}
+
+	for i := range tickets {
+		commands := byTicket[tickets[i].Ticket]
+		if len(commands) == 0 {
+			continue
+		}
+		tickets[i].Commands = len(commands)
+		tickets[i].Active = history.ActiveTime(commands, history.DefaultIdleGap)
+		tickets[i].ActiveTime = notes.FormatDuration(tickets[i].Active)
+	}
+	return tickets
+}
+
+// describeTickets fills in each ticket's type, summary and status from its
+// note
+func describeTickets(noteManager *notes.Manager, tickets []notes.ReportTicket) {
+	for i := range tickets {
+		ref, err := parseWorktreeRef(tickets[i].Ticket)
+		if err != nil {
+			continue
+		}
+		tickets[i].Type = ref.Type
+		tickets[i].Summary = noteManager.TicketSummary(ref.Type, ref.Name)
+		tickets[i].Status = noteManager.TicketStatus(ref.Type, ref.Name)
+	}
+}
+
+// buildReport aggregates the daily notes, and command history when
+// available, for a report as of day
+func buildReport(cfg *config.Config, noteManager *notes.Manager, kind string, day, now time.Time) (notes.Report, error) {
+	days := reportDays(kind, day, noteManager.HasDailyNote)
+	from, to := days[0], days[len(days)-1]
+
+	report := notes.Report{
+		Kind:      kind,
+		From:      from.Format("2006-01-02"),
+		To:        to.Format("2006-01-02"),
+		Generated: now.Format("2006-01-02 15:04"),
+	}
+
+	// Commands per day and ticket, when there is a history database
+	commands := make(map[string]map[string][]history.Command)
+	dbManager := history.NewDatabaseManager(cfg.History.DatabasePath, verbose)
+	if dbManager.IsAvailable() {
+		until := to.AddDate(0, 0, 1)
+		cmds, err := dbManager.QueryCommands(history.QueryOptions{Since: &from, Until: &until})
+		if err != nil {
+			return report, errors.Wrap(err, "failed to query command history")
+		}
+		for _, c := range cmds {
+			ticket := ticketFromDir(c.Directory)
+			if ticket == "" {
+				continue
+			}
+			date := c.Timestamp.In(day.Location()).Format("2006-01-02")
+			if commands[date] == nil {
+				commands[date] = make(map[string][]history.Command)
+			}
+			commands[date][ticket] = append(commands[date][ticket], c)
+		}
+		report.History = true
+	} else if verbose {
+		fmt.Printf("History database not available at %s, skipping active times\n", cfg.History.DatabasePath)
+	}
+
+	var all []notes.DailyEntry
+	total := make(map[string][]history.Command)
+	for _, d := range days {
+		entries, err := noteManager.DailyEntries(d, d)
+		if err != nil {
+			return report, err
+		}
+		all = append(all, entries...)
+
+		date := d.Format("2006-01-02")
+		tickets, dayNotes := notes.SummarizeEntries(entries)
+		tickets = addActiveTime(tickets, commands[date])
+		describeTickets(noteManager, tickets)
+		for ticket, cmds := range commands[date] {
+			total[ticket] = append(total[ticket], cmds...)
+		}
+
+		report.Days = append(report.Days, notes.ReportDay{
+			Date:    date,
+			Label:   reportDayLabel(kind, d, to),
+			Entries: entries,
+			Tickets: tickets,
+			Notes:   dayNotes,
+		})
+	}
+
+	report.Tickets, _ = notes.SummarizeEntries(all)
+	report.Tickets = addActiveTime(report.Tickets, total)
+	describeTickets(noteManager, report.Tickets)
+
+	return report, nil
+}
+
+func runReportCommand(kind string, now time.Time) error {
+	day := now
+	if reportDate != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", reportDate, time.Local)
+		if err != nil {
+			return errors.Newf("invalid --date %q: use YYYY-MM-DD", reportDate)
+		}
+		day = parsed
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.Wrap(err, "failed to load configuration")
+	}
+	noteManager := newNoteManager(cfg, verbose)
+
+	report, err := buildReport(cfg, noteManager, kind, day, now)
+	if err != nil {
+		return err
+	}
+
+	content, err := noteManager.RenderReport(report)
+	if err != nil {
+		return err
+	}
+
+	if reportOutput == "" {
+		fmt.Print(content)
+		return nil
+	}
+	if err := os.WriteFile(reportOutput, []byte(content), 0644); err != nil {
+		return errors.Wrapf(err, "failed to write report to %s", reportOutput)
+	}
+	fmt.Printf("Wrote %s report to %s\n", kind, reportOutput)
+	return nil
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"thoreinstein.com/rig/pkg/config"
+	"thoreinstein.com/rig/pkg/notes"
+)
+
+func TestReportCommandStructure(t *testing.T) {
+	names := make(map[string]bool)
+	for _, sub := range reportCmd.Commands() {
+		names[sub.Name()] = true
+	}
+	for _, want := range []string{"standup", "week"} {
+		if !names[want] {
+			t.Errorf("report command missing subcommand %q", want)
+		}
+	}
+	for _, flag := range []string{"date", "output"} {
+		if reportCmd.PersistentFlags().Lookup(flag) == nil {
+			t.Errorf("report command missing --%s flag", flag)
+		}
+	}
+}
+
+func TestReportDays(t *testing.T) {
+	monday := time.Date(2025, 1, 13, 9, 30, 0, 0, time.Local)
+	friday := time.Date(2025, 1, 10, 0, 0, 0, 0, time.Local)
+	hasNote := func(d time.Time) bool { return d.Equal(friday) }
+
+	days := reportDays(notes.ReportStandup, monday, hasNote)
+	if len(days) != 2 || !days[0].Equal(friday) || days[1].Format("2006-01-02") != "2025-01-13" {
+		t.Errorf("a Monday stand-up should cover Friday and Monday, got %v", days)
+	}
+	if got := reportDayLabel(notes.ReportStandup, days[0], days[1]); got != "Friday" {
+		t.Errorf("label = %q, want Friday", got)
+	}
+
+	days = reportDays(notes.ReportStandup, monday, func(time.Time) bool { return false })
+	if days[0].Format("2006-01-02") != "2025-01-12" {
+		t.Errorf("without earlier notes the stand-up should cover yesterday, got %v", days[0])
+	}
+	if got := reportDayLabel(notes.ReportStandup, days[0], days[1]); got != "Yesterday" {
+		t.Errorf("label = %q, want Yesterday", got)
+	}
+
+	thursday := time.Date(2025, 1, 16, 0, 0, 0, 0, time.Local)
+	days = reportDays(notes.ReportWeek, thursday, hasNote)
+	if len(days) != 4 || days[0].Weekday() != time.Monday {
+		t.Errorf("a week report should run from Monday, got %v", days)
+	}
+	sunday := time.Date(2025, 1, 19, 0, 0, 0, 0, time.Local)
+	if days = reportDays(notes.ReportWeek, sunday, hasNote); len(days) != 7 {
+		t.Errorf("a Sunday week report should cover 7 days, got %d", len(days))
+	}
+}
+
+func TestTicketFromDir(t *testing.T) {
+	tests := map[string]string{
+		"/home/u/src/repo/proj/proj-123":          "proj-123",
+		"/home/u/src/repo/proj/proj-123/pkg/api":  "proj-123",
+		"/home/u/src/repo/hack/spike":             "spike",
+		"/home/u/src/repo/ops/proj-123":           "",
+		"/home/u/src/repo/main":                   "",
+		"/home/u/src/repo/incident/incident-7/db": "incident-7",
+	}
+	for dir, want := range tests {
+		if got := ticketFromDir(dir); got != want {
+			t.Errorf("ticketFromDir(%q) = %q, want %q", dir, got, want)
+		}
+	}
+}
+
+func TestRunReportCommand(t *testing.T) {
+	notesDir := t.TempDir()
+	setupSyncTestConfig(t, notesDir)
+	defer viper.Reset()
+	viper.Set("history.database_path", filepath.Join(t.TempDir(), "missing.db"))
+
+	writeFile := func(path, content string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(filepath.Join(notesDir, "proj", "proj-123.md"), "---\nstatus: In Review\n---\n# proj-123\n\n## Summary\n\nFix the login flow\n")
+	writeFile(filepath.Join(notesDir, "daily", "2025-01-10.md"), "## Log\n- [09:00] [proj-123](../proj/proj-123.md)\n- [16:00] [proj-123](../proj/proj-123.md) status: In Progress → In Review\n")
+	writeFile(filepath.Join(notesDir, "daily", "2025-01-13.md"), "## Log\n- [10:00] Team planning\n")
+
+	reportDate = "2025-01-13"
+	reportOutput = filepath.Join(t.TempDir(), "standup.md")
+	defer func() { reportDate, reportOutput = "", "" }()
+
+	var err error
+	captureOutput(func() {
+		err = runReportCommand(notes.ReportStandup, time.Now())
+	})
+	if err != nil {
+		t.Fatalf("runReportCommand() error: %v", err)
+	}
+
+	content, err := os.ReadFile(reportOutput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# Stand-up 2025-01-13", "## Friday", "proj-123", "In Progress → In Review", "## Today", "Team planning"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("report missing %q:\n%s", want, content)
+		}
+	}
+
+	reportDate = "13/01/2025"
+	if err := runReportCommand(notes.ReportWeek, time.Now()); err == nil {
+		t.Error("an invalid --date should fail")
+	}
+}
+
+func TestBuildReport_HistoryOnlyTicket(t *testing.T) {
+	notesDir := t.TempDir()
+	setupSyncTestConfig(t, notesDir)
+	defer viper.Reset()
+
+	dbPath := filepath.Join(t.TempDir(), "history.db")
+	createTestHistoryDatabase(t, dbPath)
+	viper.Set("history.database_path", dbPath)
+
+	// proj-9 was worked on in a resumed session: commands but no log entry
+	day := time.Date(2025, 1, 13, 0, 0, 0, 0, time.Local)
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`
+		INSERT INTO places (id, dir) VALUES (1, '/src/api/proj/proj-9');
+		INSERT INTO sessions (id, session) VALUES (1, 'proj-9');
+		INSERT INTO commands (argv, start_time, duration, exit_status, place_id, session_id, hostname) VALUES
+			('make test', ?, 10, 0, 1, 1, 'localhost'),
+			('git commit', ?, 10, 0, 1, 1, 'localhost');
+	`, day.Add(10*time.Hour).Unix(), day.Add(10*time.Hour+5*time.Minute).Unix())
+	_ = db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notePath := filepath.Join(notesDir, "proj", "proj-9.md")
+	for _, dir := range []string{filepath.Dir(notePath), filepath.Join(notesDir, "daily")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(notePath, []byte("---\nstatus: In Progress\n---\n# Rate limits\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(notesDir, "daily", "2025-01-13.md"), []byte("## Log\n- [09:00] Team planning\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := buildReport(cfg, newNoteManager(cfg, false), notes.ReportStandup, day, day)
+	if err != nil {
+		t.Fatalf("buildReport() error: %v", err)
+	}
+
+	today := report.Days[len(report.Days)-1].Tickets
+	if len(today) != 1 || today[0].Ticket != "proj-9" || today[0].Commands != 2 || today[0].ActiveTime != "5m" || today[0].Status != "In Progress" {
+		t.Errorf("today's tickets = %+v, want proj-9 with its commands", today)
+	}
+	if len(report.Tickets) != 1 || report.Tickets[0].Ticket != "proj-9" || report.Tickets[0].Summary != "Rate limits" {
+		t.Errorf("report tickets = %+v, want proj-9", report.Tickets)
+	}
+}
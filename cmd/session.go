@@ -2,54 +2,80 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/cockroachdb/errors"
 	"github.com/spf13/cobra"
 
 	"thoreinstein.com/rig/pkg/config"
+	"thoreinstein.com/rig/pkg/multiplexer"
 	"thoreinstein.com/rig/pkg/tmux"
 )
 
 // sessionCmd represents the session command
 var sessionCmd = &cobra.Command{
 	Use:   "session",
-	Short: "Manage tmux sessions",
-	Long: `Manage tmux sessions for workflow tickets.
+	Short: "Manage ticket sessions",
+	Long: `Manage multiplexer sessions for workflow tickets.
 
-This command provides subcommands to list, attach, and manage tmux sessions
-created by the rig workflow.`,
-}
-
-// sessionListCmd lists all tmux sessions
-var sessionListCmd = &cobra.Command{
-	Use:   "list",
-	Short: "List all tmux sessions",
-	Long:  `List all active tmux sessions.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return runSessionListCommand()
-	},
+This command provides subcommands to list, attach, and manage the sessions
+created by the rig workflow. Sessions run in tmux, Zellij or WezTerm,
+depending on session.backend.`,
 }
 
 // sessionAttachCmd attaches to a tmux session
 var sessionAttachCmd = &cobra.Command{
-	Use:   "attach <ticket>",
-	Short: "Attach to a tmux session for a ticket",
-	Long:  `Attach to an existing tmux session for the specified ticket.`,
-	Args:  cobra.ExactArgs(1),
+	Use:   "attach [ticket]",
+	Short: "Attach to the session for a ticket",
+	Long: `Attach to the existing session for the specified ticket.
+
+If the ticket is omitted, it is inferred from $RIG_TICKET, the current
+worktree path, or the current branch name.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runSessionAttachCommand(args[0])
+		ticket, err := resolveTicketArg(args)
+		if err != nil {
+			return err
+		}
+		return runSessionAttachCommand(ticket)
 	},
 }
 
 // sessionKillCmd kills a tmux session
 var sessionKillCmd = &cobra.Command{
-	Use:   "kill <ticket>",
-	Short: "Kill a tmux session for a ticket",
-	Long:  `Kill the tmux session associated with the specified ticket.`,
-	Args:  cobra.ExactArgs(1),
+	Use:   "kill [ticket]",
+	Short: "Kill the session for a ticket",
+	Long: `Kill the session associated with the specified ticket.
+
+If the ticket is omitted, it is inferred from $RIG_TICKET, the current
+worktree path, or the current branch name.
+
+With --all-merged, the sessions of every worktree whose branch is merged
+(detected the same way as 'rig clean') are listed and killed after you
+select them.
+
+Examples:
+  rig session kill proj-123
+  rig session kill --all-merged
+  rig session kill --all-merged --force`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runSessionKillCommand(args[0])
+		if sessionKillAllMerged {
+			if len(args) > 0 {
+				return errors.New("--all-merged cannot be combined with a ticket")
+			}
+			return runSessionKillMergedCommand()
+		}
+		if sessionKillForce {
+			return errors.New("--force only applies with --all-merged")
+		}
+
+		ticket, err := resolveTicketArg(args)
+		if err != nil {
+			return err
+		}
+		return runSessionKillCommand(ticket)
 	},
 }
 
@@ -60,47 +86,21 @@ func init() {
 	sessionCmd.AddCommand(sessionKillCmd)
 }
 
-func runSessionListCommand() error {
+func runSessionAttachCommand(ticket string) error {
 	cfg, err := config.Load()
 	if err != nil {
 		return errors.Wrap(err, "failed to load configuration")
 	}
 
-	sessionManager := tmux.NewSessionManager(cfg.Tmux.SessionPrefix, nil, verbose)
-	sessions, err := sessionManager.ListSessions()
+	sessionManager, err := newMultiplexer(cfg, nil)
 	if err != nil {
-		return errors.Wrap(err, "failed to list sessions")
+		return err
 	}
-
-	if len(sessions) == 0 {
-		fmt.Println("No tmux sessions found.")
-		return nil
-	}
-
-	fmt.Println("Active tmux sessions:")
-	for i, session := range sessions {
-		prefix := "  "
-		if i == 0 {
-			prefix = "→ "
-		}
-		fmt.Printf("%s%s\n", prefix, session)
-	}
-
-	return nil
-}
-
-func runSessionAttachCommand(ticket string) error {
-	cfg, err := config.Load()
-	if err != nil {
-		return errors.Wrap(err, "failed to load configuration")
-	}
-
-	sessionManager := tmux.NewSessionManager(cfg.Tmux.SessionPrefix, nil, verbose)
 	sessionName := sessionManager.GetSessionName(ticket)
 
 	// Check if session exists
 	if !sessionManager.SessionExists(sessionName) {
-		return errors.Newf("tmux session '%s' does not exist for ticket '%s'", sessionName, ticket)
+		return errors.Newf("%s session '%s' does not exist for ticket '%s'", sessionManager.Name(), sessionName, ticket)
 	}
 
 	if verbose {
@@ -116,7 +116,10 @@ func runSessionKillCommand(ticket string) error {
 		return errors.Wrap(err, "failed to load configuration")
 	}
 
-	sessionManager := tmux.NewSessionManager(cfg.Tmux.SessionPrefix, nil, verbose)
+	sessionManager, err := newMultiplexer(cfg, nil)
+	if err != nil {
+		return err
+	}
 
 	if verbose {
 		fmt.Printf("Killing session for ticket: %s\n", ticket)
@@ -134,3 +137,87 @@ func runSessionKillCommand(ticket string) error {
 	fmt.Printf("✓ Session for ticket '%s' killed successfully.\n", ticket)
 	return nil
 }
+
+// newMultiplexer returns the session backend selected by session.backend,
+// with the configured command allowlist applied
+func newMultiplexer(cfg *config.Config, windows []tmux.WindowConfig) (multiplexer.Multiplexer, error) {
+	sessionManager, err := multiplexer.New(cfg.Session.Backend, cfg.Tmux.SessionPrefix, windows, verbose)
+	if err != nil {
+		return nil, err
+	}
+	sessionManager.AllowCommands(cfg.Tmux.AllowedCommands)
+	return sessionManager, nil
+}
+
+// resolveSessionWindows picks the session profile for a new session and returns
+// its windows and env. Repository-local .rig.toml files in the repo root and then the
+// worktree are merged into cfg first, so they can add or override profiles.
+// Files not approved with 'rig config trust' are skipped with a warning.
+func resolveSessionWindows(cfg *config.Config, profile, ticketType, repoRoot, repoName, worktreePath string) ([]tmux.WindowConfig, map[string]string, error) {
+	trustPath, err := config.DefaultTrustStorePath()
+	if err != nil {
+		return nil, nil, err
+	}
+	trust, err := config.LoadTrustStore(trustPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, dir := range []string{repoRoot, worktreePath} {
+		path, err := config.LoadRepoConfig(cfg, dir, trust)
+		// Report on stderr so that 'rig env' output stays safe to eval
+		if errors.Is(err, config.ErrUntrusted) {
+			fmt.Fprintf(os.Stderr, "Warning: Ignoring repository config: %v\n", err)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if path != "" && verbose {
+			fmt.Fprintf(os.Stderr, "Loaded repository config: %s\n", path)
+		}
+		if repoRoot == worktreePath {
+			break
+		}
+	}
+
+	name, windows, err := cfg.Tmux.ResolveProfile(profile, ticketType, repoName)
+	if err != nil {
+		return nil, nil, err
+	}
+	env, err := cfg.Tmux.ProfileEnv(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if verbose {
+		fmt.Fprintf(os.Stderr, "Using tmux profile: %s\n", name)
+	}
+
+	return tmuxWindowsFromConfig(windows), env, nil
+}
+
+// tmuxWindowsFromConfig converts configured windows and panes to tmux window configs
+func tmuxWindowsFromConfig(windows []config.TmuxWindow) []tmux.WindowConfig {
+	tmuxWindows := make([]tmux.WindowConfig, 0, len(windows))
+	for _, window := range windows {
+		panes := make([]tmux.PaneConfig, 0, len(window.Panes))
+		for _, pane := range window.Panes {
+			panes = append(panes, tmux.PaneConfig{
+				Split:      pane.Split,
+				Size:       pane.Size,
+				Command:    pane.Command,
+				WorkingDir: pane.WorkingDir,
+			})
+		}
+
+		tmuxWindows = append(tmuxWindows, tmux.WindowConfig{
+			Name:       window.Name,
+			Command:    window.Command,
+			WorkingDir: window.WorkingDir,
+			Layout:     window.Layout,
+			Panes:      panes,
+		})
+	}
+	return tmuxWindows
+}
@@ -0,0 +1,349 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+
+	"thoreinstein.com/rig/pkg/config"
+	"thoreinstein.com/rig/pkg/git"
+	"thoreinstein.com/rig/pkg/multiplexer"
+	"thoreinstein.com/rig/pkg/tmux"
+)
+
+var (
+	sessionListJSON    bool
+	sessionListSort    string
+	sessionListReverse bool
+)
+
+// sessionListSorts lists the accepted --sort keys
+var sessionListSorts = []string{"name", "ticket", "status", "created", "activity"}
+
+// sessionListCmd lists sessions with their ticket details
+var sessionListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all sessions",
+	Long: `List all active multiplexer sessions with the ticket each one belongs to:
+its tracker status and summary (from the ticket note), worktree, branch and
+whether it has uncommitted changes, plus attached clients, window count and
+creation and last-activity times (tmux only).
+
+Sessions whose worktree no longer exists, or that match no worktree of the
+current repository, are marked as orphans.
+
+Examples:
+  rig session list
+  rig session list --sort activity
+  rig session list --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSessionListCommand()
+	},
+}
+
+func init() {
+	sessionListCmd.Flags().BoolVar(&sessionListJSON, "json", false, "Print sessions as JSON")
+	sessionListCmd.Flags().StringVar(&sessionListSort, "sort", "name", "Sort by "+strings.Join(sessionListSorts, ", ")+" (times sort newest first)")
+	sessionListCmd.Flags().BoolVar(&sessionListReverse, "reverse", false, "Reverse the sort order")
+}
+
+// sessionRow describes a session and the ticket it belongs to
+type sessionRow struct {
+	Name     string    `json:"name"`
+	Ticket   string    `json:"ticket,omitempty"`
+	Status   string    `json:"status,omitempty"`
+	Summary  string    `json:"summary,omitempty"`
+	Worktree string    `json:"worktree,omitempty"`
+	Branch   string    `json:"branch,omitempty"`
+	Dirty    bool      `json:"dirty"`
+	Attached int       `json:"attached"`
+	Windows  int       `json:"windows,omitempty"`
+	Created  time.Time `json:"created,omitzero"`
+	Activity time.Time `json:"activity,omitzero"`
+	Orphan   bool      `json:"orphan"`
+}
+
+// sessionInfoLister is implemented by backends that report session details (tmux)
+type sessionInfoLister interface {
+	ListSessionInfo() ([]tmux.SessionInfo, error)
+}
+
+func runSessionListCommand() error {
+	if !isValidSessionSort(sessionListSort) {
+		return errors.Newf("invalid --sort %q (use %s)", sessionListSort, strings.Join(sessionListSorts, ", "))
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.Wrap(err, "failed to load configuration")
+	}
+
+	sessionManager, err := newMultiplexer(cfg, nil)
+	if err != nil {
+		return err
+	}
+
+	infos, err := listSessionInfo(sessionManager)
+	if err != nil {
+		return errors.Wrap(err, "failed to list sessions")
+	}
+
+	rows := collectSessionRows(cfg, sessionManager, infos)
+	sortSessionRows(rows, sessionListSort, sessionListReverse)
+
+	if sessionListJSON {
+		return writeSessionJSON(os.Stdout, rows)
+	}
+
+	if len(rows) == 0 {
+		fmt.Printf("No %s sessions found.\n", sessionManager.Name())
+		return nil
+	}
+
+	writeSessionTable(os.Stdout, rows, time.Now())
+	return nil
+}
+
+// listSessionInfo returns session details where the backend provides them,
+// and bare names otherwise
+func listSessionInfo(sessionManager multiplexer.Multiplexer) ([]tmux.SessionInfo, error) {
+	if lister, ok := sessionManager.(sessionInfoLister); ok {
+		infos, err := lister.ListSessionInfo()
+		if err != nil {
+			// No server running means no sessions
+			return nil, nil
+		}
+		return infos, nil
+	}
+
+	names, err := sessionManager.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]tmux.SessionInfo, len(names))
+	for i, name := range names {
+		infos[i] = tmux.SessionInfo{Name: name}
+	}
+	return infos, nil
+}
+
+// collectSessionRows links sessions to tickets, using their RIG_* variables
+// where the backend exposes them and the current repository's worktrees
+// otherwise
+func collectSessionRows(cfg *config.Config, sessionManager multiplexer.Multiplexer, infos []tmux.SessionInfo) []sessionRow {
+	gitManager := git.NewWorktreeManager(cfg.Git.BaseBranch, false)
+	noteManager := newNoteManager(cfg, false)
+
+	worktrees := make(map[string]string)
+	if paths, err := gitManager.ListWorktrees(); err == nil {
+		for _, path := range paths {
+			worktrees[filepath.Base(path)] = path
+		}
+	}
+
+	envReader, hasEnv := sessionManager.(environmentReader)
+
+	rows := make([]sessionRow, 0, len(infos))
+	for _, info := range infos {
+		if cfg.Tmux.SessionPrefix != "" && !strings.HasPrefix(info.Name, cfg.Tmux.SessionPrefix) {
+			continue
+		}
+
+		row := sessionRow{
+			Name:     info.Name,
+			Attached: info.Attached,
+			Windows:  info.Windows,
+			Created:  info.Created,
+			Activity: info.Activity,
+		}
+
+		if hasEnv {
+			row.Ticket, _ = envReader.GetEnvironment(info.Name, ticketEnvVar)
+			row.Worktree, _ = envReader.GetEnvironment(info.Name, "RIG_WORKTREE")
+		}
+		if row.Worktree == "" {
+			ticket := strings.TrimPrefix(info.Name, cfg.Tmux.SessionPrefix)
+			if path, ok := worktrees[ticket]; ok {
+				row.Worktree = path
+				if row.Ticket == "" {
+					row.Ticket = ticket
+				}
+			}
+		}
+
+		if row.Worktree != "" && row.Ticket != "" {
+			ticketType := filepath.Base(filepath.Dir(row.Worktree))
+			row.Summary = noteManager.TicketSummary(ticketType, row.Ticket)
+			row.Status = noteManager.TicketStatus(ticketType, row.Ticket)
+		}
+
+		if info, err := os.Stat(row.Worktree); row.Worktree == "" || err != nil || !info.IsDir() {
+			row.Orphan = true
+		} else {
+			row.Branch, _ = gitManager.BranchForWorktree(row.Worktree)
+			row.Dirty, _ = gitManager.IsDirty(row.Worktree)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// isValidSessionSort reports whether key is an accepted --sort value
+func isValidSessionSort(key string) bool {
+	for _, valid := range sessionListSorts {
+		if key == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// sortSessionRows orders rows by key. Times sort newest first; ties and
+// other keys fall back to the session name.
+func sortSessionRows(rows []sessionRow, key string, reverse bool) {
+	less := func(a, b sessionRow) bool {
+		switch key {
+		case "ticket":
+			if a.Ticket != b.Ticket {
+				return strings.ToLower(a.Ticket) < strings.ToLower(b.Ticket)
+			}
+		case "status":
+			if a.Status != b.Status {
+				return strings.ToLower(a.Status) < strings.ToLower(b.Status)
+			}
+		case "created":
+			if !a.Created.Equal(b.Created) {
+				return a.Created.After(b.Created)
+			}
+		case "activity":
+			if !a.Activity.Equal(b.Activity) {
+				return a.Activity.After(b.Activity)
+			}
+		}
+		return a.Name < b.Name
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if reverse {
+			return less(rows[j], rows[i])
+		}
+		return less(rows[i], rows[j])
+	})
+}
+
+// writeSessionJSON prints rows as a JSON array
+func writeSessionJSON(w io.Writer, rows []sessionRow) error {
+	if rows == nil {
+		rows = []sessionRow{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(rows)
+}
+
+// writeSessionTable prints rows as an aligned table. Dirty branches are
+// marked with *, and times are shown relative to now.
+func writeSessionTable(w io.Writer, rows []sessionRow, now time.Time) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SESSION\tTICKET\tSTATUS\tWINDOWS\tATTACHED\tCREATED\tACTIVITY\tBRANCH\tWORKTREE\tSUMMARY")
+
+	orphans := 0
+	for _, row := range rows {
+		branch := row.Branch
+		if row.Dirty {
+			branch += "*"
+		}
+		if row.Orphan {
+			branch = "(orphan)"
+			orphans++
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			row.Name,
+			orDash(row.Ticket),
+			orDash(row.Status),
+			orDash(countString(row.Windows)),
+			row.Attached,
+			orDash(formatAge(now, row.Created)),
+			orDash(formatAge(now, row.Activity)),
+			orDash(branch),
+			orDash(shortenHome(row.Worktree)),
+			truncate(row.Summary, 50),
+		)
+	}
+	_ = tw.Flush()
+
+	fmt.Fprintf(w, "\nTotal: %d session(s)", len(rows))
+	if orphans > 0 {
+		fmt.Fprintf(w, ", %d orphaned", orphans)
+	}
+	fmt.Fprintln(w)
+}
+
+// formatAge renders the time since t as e.g. "5m ago", or "" for a zero time
+func formatAge(now, t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	age := now.Sub(t)
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+	}
+}
+
+// countString formats a count, or "" for zero (unknown)
+func countString(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return fmt.Sprint(n)
+}
+
+// orDash returns value, or "-" for an empty table cell
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// shortenHome replaces the home directory prefix of path with ~
+func shortenHome(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" || path == "" {
+		return path
+	}
+	if path == home || strings.HasPrefix(path, home+string(filepath.Separator)) {
+		return "~" + strings.TrimPrefix(path, home)
+	}
+	return path
+}
+
+// truncate shortens s to at most n runes, marking the cut with "…"
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"thoreinstein.com/rig/pkg/config"
+	"thoreinstein.com/rig/pkg/tmux"
+)
+
+func TestSessionListFlags(t *testing.T) {
+	for _, name := range []string{"json", "sort", "reverse"} {
+		if sessionListCmd.Flags().Lookup(name) == nil {
+			t.Errorf("session list should have a --%s flag", name)
+		}
+	}
+	if err := sessionListCmd.Args(sessionListCmd, []string{"extra"}); err == nil {
+		t.Error("session list should reject arguments")
+	}
+}
+
+func TestRunSessionListCommand_InvalidSort(t *testing.T) {
+	sessionListSort = "size"
+	defer func() { sessionListSort = "name" }()
+
+	if err := runSessionListCommand(); err == nil || !strings.Contains(err.Error(), "--sort") {
+		t.Errorf("runSessionListCommand() error = %v, want invalid --sort", err)
+	}
+}
+
+func TestCollectSessionRows(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	notesDir := t.TempDir()
+	notePath := filepath.Join(notesDir, "proj", "proj-1.md")
+	if err := os.MkdirAll(filepath.Dir(notePath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(notePath, []byte("# Fix login\n\n**Status:** In Progress\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Not a git worktree, so branch and dirty state stay empty
+	worktree := filepath.Join(t.TempDir(), "proj", "proj-1")
+	if err := os.MkdirAll(worktree, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Notes: config.NotesConfig{Path: notesDir, DailyDir: "daily"},
+		Tmux:  config.TmuxConfig{SessionPrefix: "rig-"},
+	}
+	sessionManager := &fakeEnvMultiplexer{
+		env: map[string]map[string]string{
+			"rig-proj-1": {"RIG_TICKET": "proj-1", "RIG_WORKTREE": worktree},
+			"rig-proj-2": {"RIG_TICKET": "proj-2", "RIG_WORKTREE": "/gone/proj/proj-2"},
+		},
+	}
+	created := time.Unix(1700000000, 0)
+	infos := []tmux.SessionInfo{
+		{Name: "rig-proj-1", Attached: 1, Windows: 3, Created: created},
+		{Name: "rig-proj-2", Windows: 1},
+		{Name: "rig-scratch"},
+		{Name: "personal"},
+	}
+
+	rows := collectSessionRows(cfg, sessionManager, infos)
+
+	if len(rows) != 3 {
+		t.Fatalf("collectSessionRows() = %+v, want 3 rows (personal lacks the prefix)", rows)
+	}
+
+	got := rows[0]
+	if got.Ticket != "proj-1" || got.Worktree != worktree || got.Orphan {
+		t.Errorf("proj-1 row = %+v", got)
+	}
+	if got.Summary != "Fix login" || got.Status != "In Progress" {
+		t.Errorf("proj-1 summary/status = %q/%q", got.Summary, got.Status)
+	}
+	if got.Attached != 1 || got.Windows != 3 || !got.Created.Equal(created) {
+		t.Errorf("proj-1 session details = %+v", got)
+	}
+
+	if !rows[1].Orphan || rows[1].Ticket != "proj-2" {
+		t.Errorf("session with a missing worktree should be an orphan, got %+v", rows[1])
+	}
+	if !rows[2].Orphan || rows[2].Ticket != "" {
+		t.Errorf("session without a ticket should be an orphan, got %+v", rows[2])
+	}
+}
+
+func TestSortSessionRows(t *testing.T) {
+	base := time.Unix(1700000000, 0)
+	rows := func() []sessionRow {
+		return []sessionRow{
+			{Name: "b", Ticket: "proj-2", Status: "Done", Activity: base},
+			{Name: "a", Ticket: "proj-3", Status: "Open", Activity: base.Add(time.Hour)},
+			{Name: "c", Ticket: "proj-1", Status: "Done", Activity: base.Add(-time.Hour)},
+		}
+	}
+	names := func(rows []sessionRow) string {
+		var out []string
+		for _, row := range rows {
+			out = append(out, row.Name)
+		}
+		return strings.Join(out, ",")
+	}
+
+	tests := []struct {
+		key     string
+		reverse bool
+		want    string
+	}{
+		{"name", false, "a,b,c"},
+		{"name", true, "c,b,a"},
+		{"ticket", false, "c,b,a"},
+		{"status", false, "b,c,a"},
+		{"activity", false, "a,b,c"},
+		{"activity", true, "c,b,a"},
+	}
+
+	for _, tt := range tests {
+		got := rows()
+		sortSessionRows(got, tt.key, tt.reverse)
+		if names(got) != tt.want {
+			t.Errorf("sort %s (reverse %v) = %s, want %s", tt.key, tt.reverse, names(got), tt.want)
+		}
+	}
+}
+
+func TestWriteSessionTable(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	rows := []sessionRow{
+		{Name: "proj-1", Ticket: "proj-1", Status: "In Progress", Summary: "Fix login", Worktree: "/repo/proj/proj-1",
+			Branch: "proj-1", Dirty: true, Attached: 1, Windows: 3, Created: now.Add(-2 * time.Hour), Activity: now.Add(-5 * time.Minute)},
+		{Name: "old", Orphan: true},
+	}
+
+	var buf bytes.Buffer
+	writeSessionTable(&buf, rows, now)
+	output := buf.String()
+
+	for _, want := range []string{"SESSION", "proj-1*", "In Progress", "2h ago", "5m ago", "/repo/proj/proj-1", "Fix login", "(orphan)", "Total: 2 session(s), 1 orphaned"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("table missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestWriteSessionJSON(t *testing.T) {
+	rows := []sessionRow{{Name: "proj-1", Ticket: "proj-1", Dirty: true, Created: time.Unix(1700000000, 0).UTC()}, {Name: "x", Orphan: true}}
+
+	var buf bytes.Buffer
+	if err := writeSessionJSON(&buf, rows); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if len(decoded) != 2 || decoded[0]["ticket"] != "proj-1" || decoded[0]["dirty"] != true || decoded[1]["orphan"] != true {
+		t.Errorf("decoded = %v", decoded)
+	}
+	if decoded[0]["created"] != "2023-11-14T22:13:20Z" {
+		t.Errorf("created = %v", decoded[0]["created"])
+	}
+	if _, ok := decoded[1]["created"]; ok {
+		t.Error("unknown times should be omitted")
+	}
+
+	buf.Reset()
+	if err := writeSessionJSON(&buf, nil); err != nil || strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("no sessions should print [], got %q", buf.String())
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{time.Time{}, ""},
+		{now.Add(-10 * time.Second), "just now"},
+		{now.Add(-42 * time.Minute), "42m ago"},
+		{now.Add(-5 * time.Hour), "5h ago"},
+		{now.Add(-49 * time.Hour), "2d ago"},
+	}
+
+	for _, tt := range tests {
+		if got := formatAge(now, tt.t); got != tt.want {
+			t.Errorf("formatAge(%v) = %q, want %q", tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("short", 10); got != "short" {
+		t.Errorf("truncate() = %q", got)
+	}
+	if got := truncate("a longer summary", 8); got != "a longe…" {
+		t.Errorf("truncate() = %q", got)
+	}
+}
@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+
+	"thoreinstein.com/rig/pkg/config"
+	"thoreinstein.com/rig/pkg/multiplexer"
+)
+
+var (
+	sessionPruneIdle   string
+	sessionPruneDryRun bool
+	sessionPruneForce  bool
+
+	sessionKillAllMerged bool
+	sessionKillForce     bool
+)
+
+// sessionPruneCmd kills orphaned and idle sessions
+var sessionPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Kill sessions whose worktree is gone or that have been idle",
+	Long: `Find sessions that carry the configured session prefix and either belong
+to a worktree that no longer exists or, with --idle, have seen no activity
+for longer than the given age. The sessions are listed and killed after you
+select them.
+
+When no session prefix is configured, only sessions linked to a ticket are
+considered, so unrelated sessions are never touched. Idle detection needs
+tmux activity times, and attached sessions are never pruned for being idle.
+
+Examples:
+  rig session prune
+  rig session prune --idle 7d
+  rig session prune --idle 2w --dry-run
+  rig session prune --force`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSessionPruneCommand()
+	},
+}
+
+func init() {
+	sessionCmd.AddCommand(sessionPruneCmd)
+
+	sessionPruneCmd.Flags().StringVar(&sessionPruneIdle, "idle", "", "Also prune sessions idle longer than this age (e.g. 12h, 7d, 2w)")
+	sessionPruneCmd.Flags().BoolVar(&sessionPruneDryRun, "dry-run", false, "Show what would be killed without killing anything")
+	sessionPruneCmd.Flags().BoolVar(&sessionPruneForce, "force", false, "Kill without asking for a selection")
+
+	sessionKillCmd.Flags().BoolVar(&sessionKillAllMerged, "all-merged", false, "Kill the sessions of all worktrees whose branch is merged")
+	sessionKillCmd.Flags().BoolVar(&sessionKillForce, "force", false, "With --all-merged, kill without asking for a selection")
+}
+
+// sessionKillCandidate is a session selected for bulk killing
+type sessionKillCandidate struct {
+	Name     string
+	Ticket   string
+	Reason   string
+	Attached bool
+}
+
+func runSessionPruneCommand() error {
+	var idle time.Duration
+	if sessionPruneIdle != "" {
+		age, err := parseAge(sessionPruneIdle)
+		if err != nil {
+			return errors.Wrap(err, "invalid --idle value")
+		}
+		idle = age
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.Wrap(err, "failed to load configuration")
+	}
+
+	sessionManager, err := newMultiplexer(cfg, nil)
+	if err != nil {
+		return err
+	}
+
+	infos, err := listSessionInfo(sessionManager)
+	if err != nil {
+		return errors.Wrap(err, "failed to list sessions")
+	}
+
+	rows := collectSessionRows(cfg, sessionManager, infos)
+	sortSessionRows(rows, "name", false)
+	candidates := pruneCandidates(rows, cfg.Tmux.SessionPrefix, idle, time.Now())
+
+	if len(candidates) == 0 {
+		fmt.Println("No sessions to prune.")
+		return nil
+	}
+
+	return killSessionCandidates(sessionManager, cfg.Tmux.SessionPrefix, candidates, sessionPruneDryRun, sessionPruneForce, os.Stdin)
+}
+
+// pruneCandidates returns the sessions in rows whose worktree is known but
+// missing, or that have been detached and inactive for at least idle (when
+// idle is positive). Without a session prefix only ticket sessions qualify.
+func pruneCandidates(rows []sessionRow, prefix string, idle time.Duration, now time.Time) []sessionKillCandidate {
+	var candidates []sessionKillCandidate
+	for _, row := range rows {
+		if prefix == "" && row.Ticket == "" {
+			continue
+		}
+
+		reason := ""
+		switch {
+		case row.Orphan && row.Worktree != "":
+			reason = "worktree missing: " + shortenHome(row.Worktree)
+		case idle > 0 && row.Attached == 0 && !row.Activity.IsZero() && now.Sub(row.Activity) >= idle:
+			reason = "idle since " + formatAge(now, row.Activity)
+		default:
+			continue
+		}
+
+		candidates = append(candidates, sessionKillCandidate{
+			Name:     row.Name,
+			Ticket:   row.Ticket,
+			Reason:   reason,
+			Attached: row.Attached > 0,
+		})
+	}
+	return candidates
+}
+
+func runSessionKillMergedCommand() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.Wrap(err, "failed to load configuration")
+	}
+
+	worktrees, err := findCleanupCandidates(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to find merged worktrees")
+	}
+
+	candidates := mergedSessionCandidates(worktrees, cfg.Tmux.SessionPrefix)
+	if len(candidates) == 0 {
+		fmt.Println("No sessions found for merged worktrees.")
+		return nil
+	}
+
+	sessionManager, err := newMultiplexer(cfg, nil)
+	if err != nil {
+		return err
+	}
+
+	return killSessionCandidates(sessionManager, cfg.Tmux.SessionPrefix, candidates, false, sessionKillForce, os.Stdin)
+}
+
+// mergedSessionCandidates returns the sessions of worktrees whose branch is
+// merged, using the same detection as 'rig clean'
+func mergedSessionCandidates(worktrees []CleanupCandidate, prefix string) []sessionKillCandidate {
+	var candidates []sessionKillCandidate
+	for _, wt := range worktrees {
+		if !wt.IsMerged || !wt.HasSession {
+			continue
+		}
+
+		ticket := filepath.Base(wt.Path)
+		reason := "merged"
+		if wt.BaseBranch != "" {
+			reason = "merged into " + wt.BaseBranch
+		}
+		candidates = append(candidates, sessionKillCandidate{
+			Name:   prefix + ticket,
+			Ticket: ticket,
+			Reason: fmt.Sprintf("%s (%s)", reason, wt.Branch),
+		})
+	}
+	return candidates
+}
+
+// killSessionCandidates lists candidates, lets the user pick which to kill
+// unless force is set, and kills them. Sessions are killed by name with the
+// prefix removed, since the multiplexer adds it back.
+func killSessionCandidates(sessionManager multiplexer.Multiplexer, prefix string, candidates []sessionKillCandidate, dryRun, force bool, in io.Reader) error {
+	fmt.Println("=== Sessions ===")
+	fmt.Println()
+	for i, candidate := range candidates {
+		attached := ""
+		if candidate.Attached {
+			attached = " [attached]"
+		}
+		fmt.Printf("  %d. %s%s - %s\n", i+1, candidate.Name, attached, candidate.Reason)
+	}
+	fmt.Println()
+
+	if dryRun {
+		fmt.Printf("Would kill %d session(s) (dry-run mode)\n", len(candidates))
+		return nil
+	}
+
+	selected := candidates
+	if !force {
+		fmt.Print("Select sessions to kill (e.g. 1,3-4 or 'all'; empty to abort): ")
+		response, err := bufio.NewReader(in).ReadString('\n')
+		if err != nil && response == "" {
+			return errors.Wrap(err, "failed to read input")
+		}
+
+		indexes, err := parseSelection(response, len(candidates))
+		if err != nil {
+			fmt.Printf("Invalid selection: %v\n", err)
+			fmt.Println("Aborted.")
+			return nil
+		}
+		if len(indexes) == 0 {
+			fmt.Println("Aborted.")
+			return nil
+		}
+
+		selected = make([]sessionKillCandidate, 0, len(indexes))
+		for _, idx := range indexes {
+			selected = append(selected, candidates[idx])
+		}
+	}
+
+	killed := 0
+	for _, candidate := range selected {
+		if err := sessionManager.KillSession(strings.TrimPrefix(candidate.Name, prefix)); err != nil {
+			fmt.Printf("  Failed to kill %s: %v\n", candidate.Name, err)
+			continue
+		}
+		fmt.Printf("  Killed %s\n", candidate.Name)
+		killed++
+	}
+
+	fmt.Printf("\nKilled %d session(s)\n", killed)
+	return nil
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// killRecorder records the tickets passed to KillSession
+type killRecorder struct {
+	fakeMultiplexer
+	killed []string
+}
+
+func (k *killRecorder) KillSession(ticket string) error {
+	k.killed = append(k.killed, ticket)
+	return nil
+}
+
+func TestSessionPruneFlags(t *testing.T) {
+	for _, name := range []string{"idle", "dry-run", "force"} {
+		if sessionPruneCmd.Flags().Lookup(name) == nil {
+			t.Errorf("session prune should have a --%s flag", name)
+		}
+	}
+	for _, name := range []string{"all-merged", "force"} {
+		if sessionKillCmd.Flags().Lookup(name) == nil {
+			t.Errorf("session kill should have a --%s flag", name)
+		}
+	}
+}
+
+func TestRunSessionPruneCommand_InvalidIdle(t *testing.T) {
+	sessionPruneIdle = "soon"
+	defer func() { sessionPruneIdle = "" }()
+
+	if err := runSessionPruneCommand(); err == nil || !strings.Contains(err.Error(), "--idle") {
+		t.Errorf("runSessionPruneCommand() error = %v, want invalid --idle", err)
+	}
+}
+
+func TestSessionKill_AllMergedRejectsTicket(t *testing.T) {
+	sessionKillAllMerged = true
+	defer func() { sessionKillAllMerged = false }()
+
+	if err := sessionKillCmd.RunE(sessionKillCmd, []string{"proj-1"}); err == nil {
+		t.Error("--all-merged with a ticket should fail")
+	}
+}
+
+func TestPruneCandidates(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	rows := []sessionRow{
+		{Name: "rig-live", Ticket: "live", Worktree: "/repo/proj/live", Activity: now.Add(-time.Hour)},
+		{Name: "rig-gone", Ticket: "gone", Worktree: "/gone/proj/gone", Orphan: true, Attached: 1},
+		{Name: "rig-stale", Ticket: "stale", Worktree: "/repo/proj/stale", Activity: now.Add(-10 * 24 * time.Hour)},
+		{Name: "rig-busy", Ticket: "busy", Worktree: "/repo/proj/busy", Attached: 1, Activity: now.Add(-10 * 24 * time.Hour)},
+		{Name: "rig-unknown", Orphan: true},
+	}
+
+	names := func(candidates []sessionKillCandidate) string {
+		var out []string
+		for _, c := range candidates {
+			out = append(out, c.Name)
+		}
+		return strings.Join(out, ",")
+	}
+
+	got := pruneCandidates(rows, "rig-", 0, now)
+	if names(got) != "rig-gone" {
+		t.Fatalf("without --idle = %s, want only the missing worktree", names(got))
+	}
+	if !got[0].Attached || !strings.Contains(got[0].Reason, "worktree missing") {
+		t.Errorf("orphan candidate = %+v", got[0])
+	}
+
+	got = pruneCandidates(rows, "rig-", 7*24*time.Hour, now)
+	if names(got) != "rig-gone,rig-stale" {
+		t.Errorf("with --idle 7d = %s, want rig-gone,rig-stale", names(got))
+	}
+	if !strings.Contains(got[1].Reason, "10d ago") {
+		t.Errorf("idle reason = %q", got[1].Reason)
+	}
+
+	unprefixed := []sessionRow{
+		{Name: "personal", Orphan: true, Worktree: "/gone"},
+		{Name: "proj-1", Ticket: "proj-1", Orphan: true, Worktree: "/gone/proj/proj-1"},
+	}
+	if got := pruneCandidates(unprefixed, "", 0, now); names(got) != "proj-1" {
+		t.Errorf("without a prefix = %s, want only ticket sessions", names(got))
+	}
+}
+
+func TestMergedSessionCandidates(t *testing.T) {
+	worktrees := []CleanupCandidate{
+		{Path: "/repo/proj/proj-1", Branch: "proj-1", BaseBranch: "main", IsMerged: true, HasSession: true},
+		{Path: "/repo/proj/proj-2", Branch: "proj-2", IsMerged: true},
+		{Path: "/repo/proj/proj-3", Branch: "proj-3", HasSession: true},
+	}
+
+	got := mergedSessionCandidates(worktrees, "rig-")
+	if len(got) != 1 || got[0].Name != "rig-proj-1" || got[0].Ticket != "proj-1" {
+		t.Fatalf("mergedSessionCandidates() = %+v", got)
+	}
+	if got[0].Reason != "merged into main (proj-1)" {
+		t.Errorf("reason = %q", got[0].Reason)
+	}
+}
+
+func TestKillSessionCandidates(t *testing.T) {
+	candidates := []sessionKillCandidate{
+		{Name: "rig-proj-1", Reason: "worktree missing"},
+		{Name: "rig-proj-2", Reason: "idle since 9d ago"},
+		{Name: "rig-proj-3", Reason: "idle since 8d ago"},
+	}
+
+	tests := []struct {
+		name   string
+		input  string
+		dryRun bool
+		force  bool
+		want   string
+	}{
+		{"selection", "1,3\n", false, false, "proj-1,proj-3"},
+		{"empty aborts", "\n", false, false, ""},
+		{"invalid aborts", "9\n", false, false, ""},
+		{"force", "", false, true, "proj-1,proj-2,proj-3"},
+		{"dry run", "all\n", true, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &killRecorder{}
+			var err error
+			output := captureOutput(func() {
+				err = killSessionCandidates(recorder, "rig-", candidates, tt.dryRun, tt.force, strings.NewReader(tt.input))
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(recorder.killed, ","); got != tt.want {
+				t.Errorf("killed %q, want %q\n%s", got, tt.want, output)
+			}
+			if !strings.Contains(output, "2. rig-proj-2 - idle since 9d ago") {
+				t.Errorf("candidates not listed:\n%s", output)
+			}
+		})
+	}
+}
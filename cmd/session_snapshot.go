@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+
+	"thoreinstein.com/rig/pkg/config"
+	"thoreinstein.com/rig/pkg/tmux"
+)
+
+var sessionSaveAll bool
+
+// sessionSaveCmd records sessions so they can be rebuilt after a reboot
+var sessionSaveCmd = &cobra.Command{
+	Use:   "save [ticket]",
+	Short: "Save a session's windows and panes for restoring later",
+	Long: `Save the windows, pane layouts, working directories, running
+commands and environment of a tmux session to
+~/.local/state/rig/sessions.json, so that 'rig session restore' can rebuild
+it after the tmux server is gone.
+
+If the ticket is omitted, it is inferred from $RIG_TICKET, the current
+worktree path, or the current branch name. Use --all to save every rig
+session.
+
+Examples:
+  rig session save
+  rig session save proj-123
+  rig session save --all`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if sessionSaveAll {
+			if len(args) > 0 {
+				return errors.New("cannot combine a ticket with --all")
+			}
+			return runSessionSaveCommand("")
+		}
+		ticket, err := resolveTicketArg(args)
+		if err != nil {
+			return err
+		}
+		return runSessionSaveCommand(ticket)
+	},
+}
+
+// sessionRestoreCmd rebuilds saved sessions
+var sessionRestoreCmd = &cobra.Command{
+	Use:   "restore [ticket...]",
+	Short: "Rebuild saved sessions",
+	Long: `Rebuild sessions saved with 'rig session save'.
+
+Without arguments every saved session is restored, with the RIG_* and
+profile variables it was saved with. Sessions that are already running are
+skipped. Saved commands are restarted only if they pass the
+command allowlist.
+
+Examples:
+  rig session restore
+  rig session restore proj-123 proj-456`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSessionRestoreCommand(args)
+	},
+}
+
+func init() {
+	sessionCmd.AddCommand(sessionSaveCmd)
+	sessionCmd.AddCommand(sessionRestoreCmd)
+
+	sessionSaveCmd.Flags().BoolVar(&sessionSaveAll, "all", false, "Save every rig session")
+}
+
+// tmuxSessionManager returns the tmux backend, or an error when another
+// backend is configured, for features that only tmux supports
+func tmuxSessionManager(cfg *config.Config, feature string) (*tmux.SessionManager, error) {
+	sessionManager, err := newMultiplexer(cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+	tmuxManager, ok := sessionManager.(*tmux.SessionManager)
+	if !ok {
+		return nil, errors.Newf("%s is only supported with tmux (session backend is %s)", feature, sessionManager.Name())
+	}
+	return tmuxManager, nil
+}
+
+// loadSnapshotStore opens the default session snapshot file
+func loadSnapshotStore() (*tmux.SnapshotStore, error) {
+	path, err := tmux.DefaultSnapshotPath()
+	if err != nil {
+		return nil, err
+	}
+	return tmux.LoadSnapshotStore(path)
+}
+
+func runSessionSaveCommand(ticket string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.Wrap(err, "failed to load configuration")
+	}
+
+	sessionManager, err := tmuxSessionManager(cfg, "session save")
+	if err != nil {
+		return err
+	}
+
+	sessionNames, err := sessionsToSave(sessionManager, ticket)
+	if err != nil {
+		return err
+	}
+	if len(sessionNames) == 0 {
+		fmt.Println("No rig sessions to save.")
+		return nil
+	}
+
+	store, err := loadSnapshotStore()
+	if err != nil {
+		return err
+	}
+
+	for _, sessionName := range sessionNames {
+		snapshot, err := sessionManager.Snapshot(sessionName)
+		if err != nil {
+			return errors.Wrapf(err, "failed to save session %s", sessionName)
+		}
+		store.Sessions[sessionName] = *snapshot
+		fmt.Printf("Saved %s (%d windows)\n", sessionName, len(snapshot.Windows))
+	}
+
+	if err := store.Save(); err != nil {
+		return err
+	}
+
+	if verbose {
+		fmt.Printf("Snapshots written to %s\n", store.Path)
+	}
+	return nil
+}
+
+// sessionsToSave returns the session for ticket, or every session carrying
+// $RIG_TICKET when ticket is empty
+func sessionsToSave(sessionManager *tmux.SessionManager, ticket string) ([]string, error) {
+	if ticket != "" {
+		sessionName := sessionManager.GetSessionName(ticket)
+		if !sessionManager.SessionExists(sessionName) {
+			return nil, errors.Newf("tmux session '%s' does not exist for ticket '%s'", sessionName, ticket)
+		}
+		return []string{sessionName}, nil
+	}
+
+	sessions, err := sessionManager.ListSessions()
+	if err != nil {
+		// No server running means nothing to save
+		return nil, nil
+	}
+
+	var rigSessions []string
+	for _, session := range sessions {
+		if value, _ := sessionManager.GetEnvironment(session, ticketEnvVar); value != "" {
+			rigSessions = append(rigSessions, session)
+		}
+	}
+	return rigSessions, nil
+}
+
+func runSessionRestoreCommand(tickets []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.Wrap(err, "failed to load configuration")
+	}
+
+	sessionManager, err := tmuxSessionManager(cfg, "session restore")
+	if err != nil {
+		return err
+	}
+	sessionManager.AllowCommands(cfg.Tmux.AllowedCommands)
+
+	store, err := loadSnapshotStore()
+	if err != nil {
+		return err
+	}
+
+	sessionNames := store.Names()
+	if len(tickets) > 0 {
+		sessionNames = nil
+		for _, ticket := range tickets {
+			sessionName := sessionManager.GetSessionName(ticket)
+			if _, ok := store.Sessions[sessionName]; !ok {
+				return errors.Newf("no saved session for ticket '%s'", ticket)
+			}
+			sessionNames = append(sessionNames, sessionName)
+		}
+	}
+
+	if len(sessionNames) == 0 {
+		fmt.Println("No saved sessions.")
+		return nil
+	}
+
+	failed := 0
+	for _, sessionName := range sessionNames {
+		snapshot := store.Sessions[sessionName]
+		err := sessionManager.RestoreSession(&snapshot)
+		switch {
+		case errors.Is(err, tmux.ErrSessionExists):
+			fmt.Printf("Skipped %s (already running)\n", sessionName)
+		case err != nil:
+			fmt.Printf("Failed to restore %s: %v\n", sessionName, err)
+			failed++
+		default:
+			fmt.Printf("Restored %s (%d windows)\n", sessionName, len(snapshot.Windows))
+		}
+	}
+
+	if failed > 0 {
+		return errors.Newf("failed to restore %d session(s)", failed)
+	}
+	return nil
+}
@@ -4,8 +4,14 @@ import (
 	"bytes"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/spf13/cobra"
+
+	"thoreinstein.com/rig/pkg/config"
+	"thoreinstein.com/rig/pkg/tmux"
 )
 
 func TestSessionCommandStructure(t *testing.T) {
@@ -23,7 +29,7 @@ func TestSessionCommandStructure(t *testing.T) {
 		subcommandNames[sub.Use] = true
 	}
 
-	expectedSubcommands := []string{"list", "attach <ticket>", "kill <ticket>"}
+	expectedSubcommands := []string{"list", "attach [ticket]", "kill [ticket]"}
 	for _, expected := range expectedSubcommands {
 		if !subcommandNames[expected] {
 			t.Errorf("session command missing subcommand: %q", expected)
@@ -48,15 +54,15 @@ func TestSessionAttachCommand(t *testing.T) {
 	// Not parallel - accesses global sessionAttachCmd
 	cmd := sessionAttachCmd
 
-	if cmd.Use != "attach <ticket>" {
-		t.Errorf("session attach Use = %q, want %q", cmd.Use, "attach <ticket>")
+	if cmd.Use != "attach [ticket]" {
+		t.Errorf("session attach Use = %q, want %q", cmd.Use, "attach [ticket]")
 	}
 
 	if cmd.Short == "" {
 		t.Error("session attach should have Short description")
 	}
 
-	// Command should require exactly 1 argument
+	// Command accepts at most 1 argument
 	if cmd.Args == nil {
 		t.Error("session attach should have Args validation")
 	}
@@ -66,15 +72,15 @@ func TestSessionKillCommand(t *testing.T) {
 	// Not parallel - accesses global sessionKillCmd
 	cmd := sessionKillCmd
 
-	if cmd.Use != "kill <ticket>" {
-		t.Errorf("session kill Use = %q, want %q", cmd.Use, "kill <ticket>")
+	if cmd.Use != "kill [ticket]" {
+		t.Errorf("session kill Use = %q, want %q", cmd.Use, "kill [ticket]")
 	}
 
 	if cmd.Short == "" {
 		t.Error("session kill should have Short description")
 	}
 
-	// Command should require exactly 1 argument
+	// Command accepts at most 1 argument
 	if cmd.Args == nil {
 		t.Error("session kill should have Args validation")
 	}
@@ -171,87 +177,6 @@ func TestSessionCommandDescriptions(t *testing.T) {
 	}
 }
 
-func TestSessionListOutputFormatting(t *testing.T) {
-	t.Parallel()
-
-	tests := []struct {
-		name             string
-		sessions         []string
-		expectedContains []string
-		expectedPrefix   string
-	}{
-		{
-			name:     "empty session list",
-			sessions: []string{},
-			expectedContains: []string{
-				"No tmux sessions found.",
-			},
-		},
-		{
-			name:     "single session",
-			sessions: []string{"sre-FRAAS-123"},
-			expectedContains: []string{
-				"Active tmux sessions:",
-				"sre-FRAAS-123",
-			},
-			expectedPrefix: "\u2192 ", // Arrow prefix for first session
-		},
-		{
-			name:     "multiple sessions",
-			sessions: []string{"sre-FRAAS-123", "sre-CRE-456", "hack-test"},
-			expectedContains: []string{
-				"Active tmux sessions:",
-				"sre-FRAAS-123",
-				"sre-CRE-456",
-				"hack-test",
-			},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
-
-			// Simulate the output formatting logic from runSessionListCommand
-			var output strings.Builder
-
-			if len(tt.sessions) == 0 {
-				output.WriteString("No tmux sessions found.\n")
-			} else {
-				output.WriteString("Active tmux sessions:\n")
-				for i, session := range tt.sessions {
-					prefix := "  "
-					if i == 0 {
-						prefix = "\u2192 "
-					}
-					output.WriteString(prefix + session + "\n")
-				}
-			}
-
-			result := output.String()
-			for _, expected := range tt.expectedContains {
-				if !strings.Contains(result, expected) {
-					t.Errorf("Output missing expected string %q\nGot: %s", expected, result)
-				}
-			}
-
-			// Verify first session has arrow prefix
-			if len(tt.sessions) > 0 {
-				if !strings.Contains(result, "\u2192 "+tt.sessions[0]) {
-					t.Errorf("First session should have arrow prefix, got: %s", result)
-				}
-			}
-
-			// Verify subsequent sessions have space prefix
-			if len(tt.sessions) > 1 {
-				if !strings.Contains(result, "  "+tt.sessions[1]) {
-					t.Errorf("Second session should have space prefix, got: %s", result)
-				}
-			}
-		})
-	}
-}
-
 func TestSessionAttachArgValidation(t *testing.T) {
 	// Not parallel - accesses global sessionAttachCmd
 	tests := []struct {
@@ -260,9 +185,9 @@ func TestSessionAttachArgValidation(t *testing.T) {
 		expectError bool
 	}{
 		{
-			name:        "no arguments",
+			name:        "no arguments (ticket inferred)",
 			args:        []string{},
-			expectError: true,
+			expectError: false,
 		},
 		{
 			name:        "one argument",
@@ -307,9 +232,9 @@ func TestSessionKillArgValidation(t *testing.T) {
 		expectError bool
 	}{
 		{
-			name:        "no arguments",
+			name:        "no arguments (ticket inferred)",
 			args:        []string{},
-			expectError: true,
+			expectError: false,
 		},
 		{
 			name:        "one argument",
@@ -452,7 +377,7 @@ func TestSessionAttachVerboseOutput(t *testing.T) {
 func TestSessionSubcommandCount(t *testing.T) {
 	// Not parallel - accesses global sessionCmd
 	subcommands := sessionCmd.Commands()
-	expectedCount := 3 // list, attach, kill
+	expectedCount := 6 // list, attach, kill, save, restore, prune
 
 	if len(subcommands) != expectedCount {
 		t.Errorf("session command has %d subcommands, want %d", len(subcommands), expectedCount)
@@ -503,52 +428,20 @@ func captureOutput(f func()) string {
 	return buf.String()
 }
 
-func TestSessionListOutputWithNoSessions(t *testing.T) {
-	// This tests the actual output formatting function
-	// by simulating empty session list behavior
-
-	output := captureOutput(func() {
-		sessions := []string{}
-		if len(sessions) == 0 {
-			// This matches the logic in runSessionListCommand
-			_, _ = os.Stdout.WriteString("No tmux sessions found.\n")
-		}
-	})
-
-	expected := "No tmux sessions found.\n"
-	if output != expected {
-		t.Errorf("Output = %q, want %q", output, expected)
-	}
-}
-
-func TestSessionListOutputWithSessions(t *testing.T) {
-	sessions := []string{"sre-FRAAS-123", "sre-CRE-456"}
-
-	output := captureOutput(func() {
-		_, _ = os.Stdout.WriteString("Active tmux sessions:\n")
-		for i, session := range sessions {
-			prefix := "  "
-			if i == 0 {
-				prefix = "\u2192 "
-			}
-			_, _ = os.Stdout.WriteString(prefix + session + "\n")
-		}
-	})
+// captureStderr is a helper to capture stderr during test execution
+func captureStderr(f func()) string {
+	old := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
 
-	// Verify structure
-	if !strings.HasPrefix(output, "Active tmux sessions:\n") {
-		t.Error("Output should start with 'Active tmux sessions:'")
-	}
+	f()
 
-	// Verify first session has arrow
-	if !strings.Contains(output, "\u2192 sre-FRAAS-123") {
-		t.Error("First session should have arrow prefix")
-	}
+	w.Close()
+	os.Stderr = old
 
-	// Verify second session has space indent
-	if !strings.Contains(output, "  sre-CRE-456") {
-		t.Error("Second session should have space prefix")
-	}
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, r)
+	return buf.String()
 }
 
 func TestSessionAttachErrorFormat(t *testing.T) {
@@ -614,14 +507,14 @@ func TestSessionCommandUsageStrings(t *testing.T) {
 		{
 			name:         "session attach command",
 			cmd:          "attach",
-			expectedUse:  "attach <ticket>",
+			expectedUse:  "attach [ticket]",
 			hasLongDesc:  true,
 			hasShortDesc: true,
 		},
 		{
 			name:         "session kill command",
 			cmd:          "kill",
-			expectedUse:  "kill <ticket>",
+			expectedUse:  "kill [ticket]",
 			hasLongDesc:  true,
 			hasShortDesc: true,
 		},
@@ -688,3 +581,194 @@ func TestSessionVerboseKillOutput(t *testing.T) {
 		})
 	}
 }
+
+func TestTmuxWindowsFromConfig(t *testing.T) {
+	windows := []config.TmuxWindow{
+		{Name: "note", Command: "nvim {note_path}"},
+		{
+			Name:       "code",
+			WorkingDir: "{worktree_path}",
+			Layout:     "main-vertical",
+			Panes: []config.TmuxPane{
+				{Split: "horizontal", Size: 30, Command: "go test ./...", WorkingDir: "{worktree_path}/pkg"},
+			},
+		},
+	}
+
+	got := tmuxWindowsFromConfig(windows)
+	if len(got) != 2 {
+		t.Fatalf("tmuxWindowsFromConfig() returned %d windows, want 2", len(got))
+	}
+
+	if got[0].Name != "note" || got[0].Command != "nvim {note_path}" || len(got[0].Panes) != 0 {
+		t.Errorf("window 0 = %+v", got[0])
+	}
+
+	if got[1].Layout != "main-vertical" || got[1].WorkingDir != "{worktree_path}" {
+		t.Errorf("window 1 = %+v", got[1])
+	}
+	expected := tmux.PaneConfig{Split: "horizontal", Size: 30, Command: "go test ./...", WorkingDir: "{worktree_path}/pkg"}
+	if len(got[1].Panes) != 1 || got[1].Panes[0] != expected {
+		t.Errorf("window 1 panes = %+v, want [%+v]", got[1].Panes, expected)
+	}
+}
+
+func TestResolveSessionWindows(t *testing.T) {
+	repoRoot := t.TempDir()
+	worktreePath := filepath.Join(repoRoot, "incident", "incident-1")
+	if err := os.MkdirAll(worktreePath, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	repoConfig := `
+[tmux.profiles.incident]
+ticket_types = ["incident"]
+
+[[tmux.profiles.incident.windows]]
+name = "logs"
+`
+	if err := os.WriteFile(filepath.Join(repoRoot, config.RepoConfigFile), []byte(repoConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The worktree's file is merged last and wins
+	worktreeConfig := `
+[tmux.profiles.incident]
+ticket_types = ["incident"]
+
+[[tmux.profiles.incident.windows]]
+name = "kubectl"
+`
+	if err := os.WriteFile(filepath.Join(worktreePath, config.RepoConfigFile), []byte(worktreeConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Approve both files in an isolated trust store
+	t.Setenv("HOME", t.TempDir())
+	storePath, err := config.DefaultTrustStorePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := config.LoadTrustStore(storePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{repoRoot, worktreePath} {
+		if err := store.Trust(filepath.Join(dir, config.RepoConfigFile)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{Tmux: config.TmuxConfig{
+		Windows: []config.TmuxWindow{{Name: "note"}},
+		Env:     map[string]string{"editor": "nvim"},
+	}}
+
+	windows, _, err := resolveSessionWindows(cfg, "", "incident", repoRoot, "api", worktreePath)
+	if err != nil {
+		t.Fatalf("resolveSessionWindows() error: %v", err)
+	}
+	if len(windows) != 1 || windows[0].Name != "kubectl" {
+		t.Errorf("windows = %+v, want [kubectl]", windows)
+	}
+
+	windows, env, err := resolveSessionWindows(cfg, "", "proj", repoRoot, "api", worktreePath)
+	if err != nil {
+		t.Fatalf("resolveSessionWindows() error: %v", err)
+	}
+	if len(windows) != 1 || windows[0].Name != "note" {
+		t.Errorf("windows = %+v, want [note]", windows)
+	}
+	if env["EDITOR"] != "nvim" {
+		t.Errorf("env = %v, want the default profile's EDITOR", env)
+	}
+
+	if _, _, err := resolveSessionWindows(cfg, "missing", "proj", repoRoot, "api", worktreePath); err == nil {
+		t.Error("resolveSessionWindows() should fail for an unknown profile")
+	}
+}
+
+func TestProfileFlags(t *testing.T) {
+	for _, cmd := range []*cobra.Command{workCmd, hackCmd} {
+		if cmd.Flags().Lookup("profile") == nil {
+			t.Errorf("%s command should have --profile flag", cmd.Name())
+		}
+	}
+}
+
+func TestResolveSessionWindows_Untrusted(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	repoRoot := t.TempDir()
+	repoConfig := `
+[tmux]
+allowed_commands = ["curl"]
+
+[[tmux.windows]]
+name = "pwned"
+command = "curl example.com"
+`
+	if err := os.WriteFile(filepath.Join(repoRoot, config.RepoConfigFile), []byte(repoConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{Tmux: config.TmuxConfig{Windows: []config.TmuxWindow{{Name: "note"}}}}
+
+	var windows []tmux.WindowConfig
+	var err error
+	output := captureStderr(func() {
+		windows, _, err = resolveSessionWindows(cfg, "", "proj", repoRoot, "api", repoRoot)
+	})
+	if err != nil {
+		t.Fatalf("resolveSessionWindows() error: %v", err)
+	}
+
+	if len(windows) != 1 || windows[0].Name != "note" {
+		t.Errorf("untrusted repo config should be ignored, got windows %+v", windows)
+	}
+	if len(cfg.Tmux.AllowedCommands) != 0 {
+		t.Errorf("untrusted repo config should not extend the allowlist, got %v", cfg.Tmux.AllowedCommands)
+	}
+	if !strings.Contains(output, "rig config trust") {
+		t.Errorf("warning should explain how to trust the file, got: %q", output)
+	}
+}
+
+func TestNewMultiplexer(t *testing.T) {
+	tests := []struct {
+		backend string
+		want    string
+		wantErr bool
+	}{
+		{"tmux", "tmux", false},
+		{"zellij", "zellij", false},
+		{"wezterm", "wezterm", false},
+		{"screen", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.backend, func(t *testing.T) {
+			cfg := &config.Config{
+				Session: config.SessionConfig{Backend: tt.backend},
+				Tmux:    config.TmuxConfig{SessionPrefix: "rig-"},
+			}
+
+			sessionManager, err := newMultiplexer(cfg, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newMultiplexer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if sessionManager.Name() != tt.want {
+				t.Errorf("Name() = %q, want %q", sessionManager.Name(), tt.want)
+			}
+			if got := sessionManager.GetSessionName("PROJ-1"); got != "rig-PROJ-1" {
+				t.Errorf("GetSessionName() = %q, want %q", got, "rig-PROJ-1")
+			}
+		})
+	}
+}
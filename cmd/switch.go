@@ -0,0 +1,312 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+
+	"thoreinstein.com/rig/pkg/config"
+	"thoreinstein.com/rig/pkg/git"
+	"thoreinstein.com/rig/pkg/multiplexer"
+)
+
+// switchCmd switches to another rig session with a fuzzy picker
+var switchCmd = &cobra.Command{
+	Use:   "switch [query]",
+	Short: "Switch to a rig session with a fuzzy picker",
+	Long: `Switch to another rig-managed session.
+
+Sessions are listed with their ticket summary (from the ticket note) and the
+branch checked out in their worktree. The query is matched fuzzily against
+all three; if exactly one session matches, rig switches to it directly.
+Otherwise the matches are offered in fzf when it is installed, or in a
+numbered prompt.
+
+Inside tmux the current client is switched to the session; outside, rig
+attaches to it.
+
+Examples:
+  rig switch
+  rig switch 123
+  rig switch login timeout`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSwitchCommand(strings.Join(args, " "))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(switchCmd)
+}
+
+// switchCandidate is a rig-managed session offered by the picker
+type switchCandidate struct {
+	Session  string
+	Ticket   string
+	Worktree string
+	Branch   string
+	Summary  string
+}
+
+// label returns the line shown for a candidate in the picker
+func (c switchCandidate) label() string {
+	label := c.Session
+	if c.Branch != "" && c.Branch != c.Ticket {
+		label += "  [" + c.Branch + "]"
+	}
+	if c.Summary != "" {
+		label += "  " + c.Summary
+	}
+	return label
+}
+
+// environmentReader is implemented by backends that can read a session's
+// environment (tmux), which identifies rig sessions by their RIG_* variables
+type environmentReader interface {
+	GetEnvironment(sessionName, key string) (string, error)
+}
+
+func runSwitchCommand(query string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.Wrap(err, "failed to load configuration")
+	}
+
+	sessionManager, err := newMultiplexer(cfg, nil)
+	if err != nil {
+		return err
+	}
+
+	sessions, err := sessionManager.ListSessions()
+	if err != nil {
+		return errors.Wrap(err, "failed to list sessions")
+	}
+
+	candidates := collectSwitchCandidates(cfg, sessionManager, sessions)
+	if len(candidates) == 0 {
+		fmt.Println("No rig sessions found.")
+		return nil
+	}
+
+	candidate, err := pickSwitchCandidate(candidates, query, bufio.NewReader(os.Stdin))
+	if err != nil {
+		return err
+	}
+
+	if verbose {
+		fmt.Printf("Switching to session: %s\n", candidate.Session)
+	}
+	return sessionManager.AttachToSession(candidate.Session)
+}
+
+// collectSwitchCandidates returns the rig-managed sessions among sessions.
+// With tmux these are the sessions carrying $RIG_TICKET; other backends
+// can't expose session environments, so their sessions are matched to the
+// current repository's worktrees by ticket name.
+func collectSwitchCandidates(cfg *config.Config, sessionManager multiplexer.Multiplexer, sessions []string) []switchCandidate {
+	gitManager := git.NewWorktreeManager(cfg.Git.BaseBranch, false)
+	noteManager := newNoteManager(cfg, false)
+
+	worktrees := make(map[string]string)
+	if paths, err := gitManager.ListWorktrees(); err == nil {
+		for _, path := range paths {
+			worktrees[filepath.Base(path)] = path
+		}
+	}
+
+	envReader, hasEnv := sessionManager.(environmentReader)
+
+	var candidates []switchCandidate
+	for _, session := range sessions {
+		if cfg.Tmux.SessionPrefix != "" && !strings.HasPrefix(session, cfg.Tmux.SessionPrefix) {
+			continue
+		}
+		candidate := switchCandidate{
+			Session: session,
+			Ticket:  strings.TrimPrefix(session, cfg.Tmux.SessionPrefix),
+		}
+
+		if hasEnv {
+			ticket, _ := envReader.GetEnvironment(session, ticketEnvVar)
+			if ticket == "" {
+				continue
+			}
+			candidate.Ticket = ticket
+			candidate.Worktree, _ = envReader.GetEnvironment(session, "RIG_WORKTREE")
+		} else {
+			candidate.Worktree = worktrees[candidate.Ticket]
+			if candidate.Worktree == "" {
+				continue
+			}
+		}
+
+		if candidate.Worktree != "" {
+			candidate.Branch, _ = gitManager.BranchForWorktree(candidate.Worktree)
+			ticketType := filepath.Base(filepath.Dir(candidate.Worktree))
+			candidate.Summary = noteManager.TicketSummary(ticketType, candidate.Ticket)
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates
+}
+
+// pickSwitchCandidate narrows candidates by query and lets the user choose
+// among the remaining matches
+func pickSwitchCandidate(candidates []switchCandidate, query string, reader *bufio.Reader) (switchCandidate, error) {
+	matches := filterSwitchCandidates(candidates, query)
+	if len(matches) == 0 {
+		return switchCandidate{}, errors.Newf("no rig session matches %q", query)
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+
+	if fzf, err := exec.LookPath("fzf"); err == nil && stdinIsTerminal() {
+		return pickWithFzf(fzf, matches)
+	}
+
+	for {
+		for i, match := range matches {
+			fmt.Printf("  %d. %s\n", i+1, match.label())
+		}
+		fmt.Print("Select a session (number, or text to narrow): ")
+
+		response, err := reader.ReadString('\n')
+		response = strings.TrimSpace(response)
+		if response == "" {
+			return switchCandidate{}, errors.New("no session selected")
+		}
+
+		if n, convErr := strconv.Atoi(response); convErr == nil {
+			if n < 1 || n > len(matches) {
+				return switchCandidate{}, errors.Newf("selection out of range: %d", n)
+			}
+			return matches[n-1], nil
+		}
+
+		narrowed := filterSwitchCandidates(matches, response)
+		switch len(narrowed) {
+		case 0:
+			fmt.Printf("No session matches %q.\n", response)
+		case 1:
+			return narrowed[0], nil
+		default:
+			matches = narrowed
+		}
+
+		if err == io.EOF {
+			return switchCandidate{}, errors.New("no session selected")
+		}
+	}
+}
+
+// pickWithFzf offers candidates in fzf and returns the selected one
+func pickWithFzf(fzf string, candidates []switchCandidate) (switchCandidate, error) {
+	var input strings.Builder
+	for i, candidate := range candidates {
+		fmt.Fprintf(&input, "%d\t%s\n", i, candidate.label())
+	}
+
+	cmd := exec.Command(fzf, "--delimiter", "\t", "--with-nth", "2..", "--prompt", "switch> ", "--height", "40%", "--reverse")
+	cmd.Stdin = strings.NewReader(input.String())
+	cmd.Stderr = os.Stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		// fzf exits 130 when the picker is dismissed
+		return switchCandidate{}, errors.New("no session selected")
+	}
+
+	index, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\t")
+	i, err := strconv.Atoi(index)
+	if err != nil || i < 0 || i >= len(candidates) {
+		return switchCandidate{}, errors.Newf("unexpected fzf selection: %q", strings.TrimSpace(string(output)))
+	}
+	return candidates[i], nil
+}
+
+// stdinIsTerminal reports whether stdin is an interactive terminal
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// filterSwitchCandidates returns the candidates whose label fuzzily matches
+// query, best match first. An empty query matches everything in order.
+func filterSwitchCandidates(candidates []switchCandidate, query string) []switchCandidate {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return candidates
+	}
+
+	type scored struct {
+		candidate switchCandidate
+		score     int
+	}
+
+	var matches []scored
+	for _, candidate := range candidates {
+		if score, ok := fuzzyScore(query, candidate.label()); ok {
+			matches = append(matches, scored{candidate, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	result := make([]switchCandidate, len(matches))
+	for i, match := range matches {
+		result[i] = match.candidate
+	}
+	return result
+}
+
+// fuzzyScore reports whether every word of query appears in text as a
+// case-insensitive subsequence, and scores the match. Consecutive characters
+// and characters at the start of a word score higher.
+func fuzzyScore(query, text string) (int, bool) {
+	haystack := []rune(strings.ToLower(text))
+	total := 0
+
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		score := 0
+		pos := 0
+		prev := -2
+		for _, r := range word {
+			found := -1
+			for i := pos; i < len(haystack); i++ {
+				if haystack[i] == r {
+					found = i
+					break
+				}
+			}
+			if found < 0 {
+				return 0, false
+			}
+
+			score++
+			if found == prev+1 {
+				score += 5
+			}
+			if found == 0 || !unicode.IsLetter(haystack[found-1]) && !unicode.IsDigit(haystack[found-1]) {
+				score += 3
+			}
+			prev = found
+			pos = found + 1
+		}
+		total += score
+	}
+
+	return total, true
+}
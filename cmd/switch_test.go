@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"thoreinstein.com/rig/pkg/config"
+)
+
+// fakeEnvMultiplexer is a tmux-like backend whose session environments are
+// read from a map
+type fakeEnvMultiplexer struct {
+	fakeMultiplexer
+	env map[string]map[string]string
+}
+
+func (f *fakeEnvMultiplexer) GetEnvironment(sessionName, key string) (string, error) {
+	return f.env[sessionName][key], nil
+}
+
+// fakeMultiplexer satisfies multiplexer.Multiplexer without a terminal
+type fakeMultiplexer struct{}
+
+func (fakeMultiplexer) Name() string                                    { return "fake" }
+func (fakeMultiplexer) GetSessionName(ticket string) string             { return ticket }
+func (fakeMultiplexer) CreateSession(ticket, wt, note string) error     { return nil }
+func (fakeMultiplexer) SessionExists(sessionName string) bool           { return true }
+func (fakeMultiplexer) AttachToSession(sessionName string) error        { return nil }
+func (fakeMultiplexer) ListSessions() ([]string, error)                 { return nil, nil }
+func (fakeMultiplexer) KillSession(ticket string) error                 { return nil }
+func (fakeMultiplexer) RenameSession(oldTicket, newTicket string) error { return nil }
+func (fakeMultiplexer) SetEnvironment(string, map[string]string) error  { return nil }
+func (fakeMultiplexer) UnsetEnvironment(string, []string) error         { return nil }
+func (fakeMultiplexer) AddEnvironment(map[string]string)                {}
+func (fakeMultiplexer) AllowCommands(prefixes []string)                 {}
+
+func TestSwitchCommandStructure(t *testing.T) {
+	if switchCmd.Use != "switch [query]" {
+		t.Errorf("switch command Use = %q, want %q", switchCmd.Use, "switch [query]")
+	}
+	if switchCmd.Parent() != rootCmd {
+		t.Error("switch should be a root command")
+	}
+}
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		query string
+		text  string
+		match bool
+	}{
+		{"123", "proj-123  Fix login", true},
+		{"p123", "proj-123", true},
+		{"login fix", "proj-123  Fix login", true},
+		{"LOGIN", "proj-123  Fix login", true},
+		{"xyz", "proj-123  Fix login", false},
+		{"321", "proj-123", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if _, ok := fuzzyScore(tt.query, tt.text); ok != tt.match {
+				t.Errorf("fuzzyScore(%q, %q) match = %v, want %v", tt.query, tt.text, ok, tt.match)
+			}
+		})
+	}
+
+	// Consecutive matches rank above scattered ones
+	tight, _ := fuzzyScore("log", "proj-1  login")
+	loose, _ := fuzzyScore("log", "proj-1  lazy oauth grant")
+	if tight <= loose {
+		t.Errorf("consecutive score %d should beat scattered score %d", tight, loose)
+	}
+}
+
+func TestFilterSwitchCandidates(t *testing.T) {
+	candidates := []switchCandidate{
+		{Session: "proj-1", Summary: "Lazy oauth grant"},
+		{Session: "proj-2", Summary: "Fix login"},
+		{Session: "hack-x"},
+	}
+
+	if got := filterSwitchCandidates(candidates, ""); len(got) != 3 {
+		t.Errorf("empty query should match all, got %d", len(got))
+	}
+
+	got := filterSwitchCandidates(candidates, "log")
+	if len(got) != 2 || got[0].Session != "proj-2" {
+		t.Errorf("filter(log) = %+v, want proj-2 first of 2", got)
+	}
+
+	if got := filterSwitchCandidates(candidates, "hack"); len(got) != 1 || got[0].Session != "hack-x" {
+		t.Errorf("filter(hack) = %+v", got)
+	}
+}
+
+func TestPickSwitchCandidate(t *testing.T) {
+	candidates := []switchCandidate{
+		{Session: "proj-1", Summary: "Fix login"},
+		{Session: "proj-2", Summary: "Add metrics"},
+		{Session: "proj-3", Summary: "Login page styling"},
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "unique query switches directly", query: "metrics", want: "proj-2"},
+		{name: "number selection", input: "3\n", want: "proj-3"},
+		{name: "narrow then select", query: "login", input: "styl\n", want: "proj-3"},
+		{name: "narrow to several then number", input: "login\n2\n", want: "proj-3"},
+		{name: "no match", query: "zzz", wantErr: true},
+		{name: "out of range", input: "9\n", wantErr: true},
+		{name: "empty input", input: "\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got switchCandidate
+			var err error
+			captureOutput(func() {
+				got, err = pickSwitchCandidate(candidates, tt.query, bufio.NewReader(strings.NewReader(tt.input)))
+			})
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("pickSwitchCandidate() error = %v", err)
+			}
+			if got.Session != tt.want {
+				t.Errorf("picked %q, want %q", got.Session, tt.want)
+			}
+		})
+	}
+}
+
+func TestSwitchCandidateLabel(t *testing.T) {
+	tests := []struct {
+		candidate switchCandidate
+		want      string
+	}{
+		{switchCandidate{Session: "proj-1", Ticket: "proj-1", Branch: "proj-1"}, "proj-1"},
+		{switchCandidate{Session: "rig-proj-1", Ticket: "proj-1", Branch: "feature/x", Summary: "Fix it"}, "rig-proj-1  [feature/x]  Fix it"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.candidate.label(); got != tt.want {
+			t.Errorf("label() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestCollectSwitchCandidates(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	notesDir := t.TempDir()
+	notePath := filepath.Join(notesDir, "proj", "proj-1.md")
+	if err := os.MkdirAll(filepath.Dir(notePath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(notePath, []byte("# Fix login timeout\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Notes: config.NotesConfig{Path: notesDir, DailyDir: "daily"},
+		Tmux:  config.TmuxConfig{SessionPrefix: "rig-"},
+	}
+	sessionManager := &fakeEnvMultiplexer{
+		env: map[string]map[string]string{
+			"rig-proj-1": {"RIG_TICKET": "proj-1", "RIG_WORKTREE": "/repo/proj/proj-1"},
+			"rig-other":  {},
+		},
+	}
+
+	got := collectSwitchCandidates(cfg, sessionManager, []string{"rig-proj-1", "rig-other", "unrelated"})
+	if len(got) != 1 {
+		t.Fatalf("collectSwitchCandidates() = %+v, want only rig-proj-1", got)
+	}
+	if got[0].Ticket != "proj-1" || got[0].Worktree != "/repo/proj/proj-1" {
+		t.Errorf("candidate = %+v", got[0])
+	}
+	if got[0].Summary != "Fix login timeout" {
+		t.Errorf("Summary = %q, want %q", got[0].Summary, "Fix login timeout")
+	}
+
+	// Backends without session environments are matched to worktrees
+	if got := collectSwitchCandidates(cfg, fakeMultiplexer{}, []string{"rig-proj-1"}); len(got) != 0 {
+		t.Errorf("sessions without a worktree should be skipped, got %+v", got)
+	}
+}
@@ -3,6 +3,8 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -10,6 +12,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"thoreinstein.com/rig/pkg/config"
+	"thoreinstein.com/rig/pkg/git"
 	"thoreinstein.com/rig/pkg/jira"
 	"thoreinstein.com/rig/pkg/notes"
 )
@@ -22,19 +25,25 @@ var syncCmd = &cobra.Command{
 
 This command can:
 - Update a specific ticket note with fresh JIRA information
-- Refresh daily note entries
+- Create today's daily note, carrying over open tasks from the last one,
+  or refresh its list of active tickets
 - Sync multiple tickets at once
 
+If the ticket is omitted, it is inferred from $RIG_TICKET, the current
+worktree path, or the current branch name.
+
 Examples:
-  rig sync                    # Interactive mode - prompts for ticket
+  rig sync                    # Sync the current ticket
   rig sync proj-123           # Sync specific ticket
   rig sync proj-123 --jira    # Force JIRA refresh
-  rig sync --daily            # Update today's daily note`,
+  rig sync --daily            # Create or refresh today's daily note`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ticket := ""
 		if len(args) > 0 {
 			ticket = args[0]
+		} else if !syncDaily {
+			ticket, _ = detectCurrentTicket()
 		}
 		return runSyncCommand(ticket)
 	},
@@ -50,7 +59,7 @@ func init() {
 	rootCmd.AddCommand(syncCmd)
 
 	syncCmd.Flags().BoolVar(&syncJira, "jira", false, "Force refresh of JIRA information")
-	syncCmd.Flags().BoolVar(&syncDaily, "daily", false, "Update daily note")
+	syncCmd.Flags().BoolVar(&syncDaily, "daily", false, "Create today's daily note or refresh its active tickets")
 	syncCmd.Flags().BoolVar(&syncForce, "force", false, "Force update even if note was recently modified")
 }
 
@@ -86,12 +95,7 @@ func syncTicketNote(cfg *config.Config, ticket string) error {
 	}
 
 	// Initialize note manager
-	noteManager := notes.NewManager(
-		cfg.Notes.Path,
-		cfg.Notes.DailyDir,
-		cfg.Notes.TemplateDir,
-		verbose,
-	)
+	noteManager := newNoteManager(cfg, verbose)
 
 	// Get note path
 	notePath := noteManager.GetNotePath(ticketInfo.Type, ticketInfo.Full)
@@ -104,9 +108,11 @@ func syncTicketNote(cfg *config.Config, ticket string) error {
 	}
 
 	var updated bool
+	var jiraInfo *jira.TicketInfo
+	previousStatus := noteManager.TicketStatus(ticketInfo.Type, ticketInfo.Full)
 
 	// Update JIRA information if requested or if it's a non-incident ticket
-	if syncJira || ticketInfo.Type != "incident" {
+	if syncJira || ticketInfo.Type != notes.IncidentType {
 		if cfg.Jira.Enabled {
 			if verbose {
 				fmt.Println("Refreshing JIRA information...")
@@ -118,12 +124,13 @@ func syncTicketNote(cfg *config.Config, ticket string) error {
 					fmt.Printf("Warning: Invalid JIRA CLI command: %v\n", err)
 				}
 			} else {
-				jiraInfo, err := jiraClient.FetchTicketDetails(ticketInfo.Full)
+				fetched, err := jiraClient.FetchTicketDetails(ticketInfo.Full)
 				if err != nil {
 					if verbose {
 						fmt.Printf("Warning: Could not fetch JIRA details: %v\n", err)
 					}
 				} else {
+					jiraInfo = fetched
 					// Update note with fresh JIRA info
 					err = updateNoteWithJiraInfo(notePath, jiraInfo)
 					if err != nil {
@@ -136,17 +143,32 @@ func syncTicketNote(cfg *config.Config, ticket string) error {
 		}
 	}
 
+	// Refresh the front matter, adding it to notes created without it
+	changed, err := syncFrontMatter(cfg, noteManager, ticketInfo, jiraInfo)
+	if err != nil {
+		fmt.Printf("Warning: Could not update front matter: %v\n", err)
+	} else if changed {
+		fmt.Println("Front matter updated")
+		updated = true
+	}
+
 	// Update daily note entry
 	if verbose {
 		fmt.Println("Updating daily note entry...")
 	}
 
-	err = noteManager.UpdateDailyNote(ticketInfo.Full, ticketInfo.Type)
+	// Record tracker status changes for 'rig report'
+	event := notes.LogEvent{Event: notes.EventSynced, Ticket: ticketInfo.Full, Type: ticketInfo.Type}
+	if jiraInfo != nil && previousStatus != "" && jiraInfo.Status != "" && jiraInfo.Status != previousStatus {
+		event = notes.StatusEvent(ticketInfo.Type, ticketInfo.Full, previousStatus, jiraInfo.Status)
+	}
+
+	added, err := noteManager.AddDailyEvent(event)
 	if err != nil {
 		if verbose {
 			fmt.Printf("Warning: Could not update daily note: %v\n", err)
 		}
-	} else {
+	} else if added {
 		fmt.Println("Daily note updated")
 		updated = true
 	}
@@ -154,6 +176,7 @@ func syncTicketNote(cfg *config.Config, ticket string) error {
 	if !updated {
 		fmt.Println("No updates were made.")
 	} else {
+		refreshIndexNotes(cfg, noteManager)
 		fmt.Printf("Sync completed for: %s\n", ticketInfo.Full)
 	}
 
@@ -165,30 +188,109 @@ func syncDailyNote(cfg *config.Config) error {
 		fmt.Println("Syncing today's daily note...")
 	}
 
-	noteManager := notes.NewManager(
-		cfg.Notes.Path,
-		cfg.Notes.DailyDir,
-		cfg.Notes.TemplateDir,
-		verbose,
-	)
+	noteManager := newNoteManager(cfg, verbose)
+
+	data, err := noteManager.NewDailyData(time.Now())
+	if err != nil {
+		return err
+	}
+	data.ActiveTickets = activeTickets(cfg, noteManager)
+
+	created, err := noteManager.WriteDailyNote(data)
+	if err != nil {
+		return err
+	}
 
-	// For now, just verify the daily note exists
-	today := time.Now().Format("2006-01-02")
 	dailyNotePath := noteManager.GetDailyNotePath()
+	if !created {
+		fmt.Printf("Refreshed active tickets in daily note: %s\n", dailyNotePath)
+		return nil
+	}
 
-	// Use the noteManager for any future daily note operations
-	_ = today
+	fmt.Printf("Created daily note: %s\n", dailyNotePath)
+	if len(data.CarriedOver) > 0 {
+		fmt.Printf("Carried over %d open task(s) from %s\n", len(data.CarriedOver), data.Previous)
+	}
+	return nil
+}
 
-	if _, err := os.Stat(dailyNotePath); os.IsNotExist(err) {
-		fmt.Printf("Daily note not found: %s\n", dailyNotePath)
-		fmt.Println("Create the daily note first or use 'rig work' to create a ticket.")
+// activeTickets returns the tickets that have both a worktree and a
+// session, for the daily note. Sessions that can't be listed mean none.
+func activeTickets(cfg *config.Config, noteManager *notes.Manager) []notes.DailyTicket {
+	sessionManager, err := newMultiplexer(cfg, nil)
+	if err != nil {
+		return nil
+	}
+	infos, err := listSessionInfo(sessionManager)
+	if err != nil {
+		if verbose {
+			fmt.Printf("Warning: Could not list sessions: %v\n", err)
+		}
 		return nil
 	}
+	return dailyTickets(noteManager, collectSessionRows(cfg, sessionManager, infos))
+}
 
-	fmt.Printf("Daily note exists: %s\n", dailyNotePath)
-	fmt.Println("Daily note sync completed.")
+// dailyTickets lists the tickets of the sessions that have a worktree,
+// sorted by ticket
+func dailyTickets(noteManager *notes.Manager, rows []sessionRow) []notes.DailyTicket {
+	var tickets []notes.DailyTicket
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		if row.Orphan || row.Ticket == "" || seen[row.Ticket] {
+			continue
+		}
+		seen[row.Ticket] = true
+
+		ticketType := filepath.Base(filepath.Dir(row.Worktree))
+		tickets = append(tickets, notes.DailyTicket{
+			Ticket:  row.Ticket,
+			Type:    ticketType,
+			Summary: row.Summary,
+			Status:  row.Status,
+			Link:    noteManager.DailyLink(ticketType, row.Ticket),
+		})
+	}
 
-	return nil
+	sort.Slice(tickets, func(i, j int) bool { return tickets[i].Ticket < tickets[j].Ticket })
+	return tickets
+}
+
+// syncFrontMatter writes the ticket's tracker status and link, and its
+// worktree details when the worktree is in the current repository, to the
+// note's front matter. Reports whether the note changed.
+func syncFrontMatter(cfg *config.Config, noteManager *notes.Manager, ticketInfo *TicketInfo, jiraInfo *jira.TicketInfo) (bool, error) {
+	update := notes.FrontMatter{
+		Ticket: ticketInfo.Full,
+		Type:   ticketInfo.Type,
+	}
+	// Hacks aren't tracker tickets
+	if ticketInfo.Type != "hack" {
+		update.URL = jira.TicketURL(cfg.Jira.BaseURL, ticketInfo.Full)
+	}
+	if jiraInfo != nil {
+		update.Status = jiraInfo.Status
+	}
+
+	if current, err := noteManager.TicketFrontMatter(ticketInfo.Type, ticketInfo.Full); err == nil {
+		// Tag notes that predate front matter; later syncs leave tags alone
+		if current.Ticket == "" {
+			update.Tags = []string{"rig", ticketInfo.Type}
+		}
+		// Date tickets the tracker finished, for the index notes
+		if current.Finished == "" && notes.IsFinishedStatus(update.Status) {
+			update.Finished = time.Now().Format("2006-01-02")
+		}
+	}
+
+	gitManager := git.NewWorktreeManager(cfg.Git.BaseBranch, false)
+	if worktreePath, err := findTicketWorktree(gitManager, ticketInfo.Full); err == nil {
+		update.Worktree = worktreePath
+		update.Branch, _ = gitManager.BranchForWorktree(worktreePath)
+		update.Repo, _ = gitManager.GetRepoName()
+	}
+
+	return noteManager.UpdateTicketFrontMatter(ticketInfo.Type, ticketInfo.Full, update)
 }
 
 // updateNoteWithJiraInfo updates a note file with fresh JIRA information
@@ -199,7 +301,8 @@ func updateNoteWithJiraInfo(notePath string, jiraInfo *jira.TicketInfo) error {
 		return errors.Wrap(err, "failed to read note")
 	}
 
-	noteContent := string(content)
+	// Only the body is rewritten; front matter is refreshed separately
+	frontMatter, noteContent := notes.SplitFrontMatter(string(content))
 
 	// Update the title if we have a summary
 	if jiraInfo.Summary != "" {
@@ -210,7 +313,7 @@ func updateNoteWithJiraInfo(notePath string, jiraInfo *jira.TicketInfo) error {
 	noteContent = updateJiraDetailsSection(noteContent, jiraInfo)
 
 	// Write back to file with restricted permissions
-	err = os.WriteFile(notePath, []byte(noteContent), 0600)
+	err = os.WriteFile(notePath, []byte(frontMatter+noteContent), 0600)
 	if err != nil {
 		return errors.Wrap(err, "failed to write updated note")
 	}
@@ -302,7 +405,8 @@ func updateJiraDetailsSection(content string, jiraInfo *jira.TicketInfo) string
 	return strings.Join(result, "\n")
 }
 
-// buildJiraDetailsSection builds the JIRA details section content
+// buildJiraDetailsSection builds the JIRA details section content. The
+// status is kept in the front matter only, see syncFrontMatter.
 func buildJiraDetailsSection(jiraInfo *jira.TicketInfo) string {
 	var section strings.Builder
 
@@ -310,10 +414,6 @@ func buildJiraDetailsSection(jiraInfo *jira.TicketInfo) string {
 		section.WriteString(fmt.Sprintf("**Type:** %s\n", jiraInfo.Type))
 	}
 
-	if jiraInfo.Status != "" {
-		section.WriteString(fmt.Sprintf("**Status:** %s\n", jiraInfo.Status))
-	}
-
 	if jiraInfo.Description != "" {
 		section.WriteString("\n**Description:**\n" + jiraInfo.Description)
 	}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"github.com/spf13/viper"
 
 	"thoreinstein.com/rig/pkg/jira"
+	"thoreinstein.com/rig/pkg/notes"
 )
 
 func TestUpdateNoteTitle(t *testing.T) {
@@ -89,11 +91,10 @@ func TestBuildJiraDetailsSection(t *testing.T) {
 			},
 			contains: []string{
 				"**Type:** Bug",
-				"**Status:** In Progress",
 				"**Description:**",
 				"This is a bug description.",
 			},
-			missing: []string{},
+			missing: []string{"**Status:**"},
 		},
 		{
 			name: "only type",
@@ -108,8 +109,8 @@ func TestBuildJiraDetailsSection(t *testing.T) {
 			jiraInfo: &jira.TicketInfo{
 				Status: "Done",
 			},
-			contains: []string{"**Status:** Done"},
-			missing:  []string{"**Type:**", "**Description:**"},
+			contains: []string{},
+			missing:  []string{"**Status:**", "**Type:**", "**Description:**"},
 		},
 		{
 			name: "only description",
@@ -163,6 +164,7 @@ func TestUpdateJiraDetailsSection(t *testing.T) {
 		content  string
 		jiraInfo *jira.TicketInfo
 		contains []string
+		missing  []string
 	}{
 		{
 			name: "update existing JIRA section",
@@ -187,10 +189,11 @@ Some notes.`,
 			contains: []string{
 				"## JIRA Details",
 				"**Type:** New Type",
-				"**Status:** New Status",
 				"## Notes",
 				"Some notes.",
 			},
+			// The status lives in the front matter
+			missing: []string{"**Status:**"},
 		},
 		{
 			name: "insert JIRA section after Summary",
@@ -211,9 +214,9 @@ Some notes.`,
 				"## Summary",
 				"## JIRA Details",
 				"**Type:** Bug",
-				"**Status:** Open",
 				"## Notes",
 			},
+			missing: []string{"**Status:**"},
 		},
 		{
 			name: "append JIRA section at end",
@@ -251,6 +254,11 @@ Just some content without Summary section.`,
 					t.Errorf("updateJiraDetailsSection() should contain %q\nGot:\n%s", s, result)
 				}
 			}
+			for _, s := range tt.missing {
+				if strings.Contains(result, s) {
+					t.Errorf("updateJiraDetailsSection() should not contain %q\nGot:\n%s", s, result)
+				}
+			}
 		})
 	}
 }
@@ -350,8 +358,8 @@ Some notes here.`
 		t.Error("Type should be in JIRA section")
 	}
 
-	if !strings.Contains(contentStr, "**Status:** In Progress") {
-		t.Error("Status should be in JIRA section")
+	if strings.Contains(contentStr, "**Status:**") {
+		t.Error("Status should be left to the front matter")
 	}
 
 	// Original content should be preserved
@@ -612,6 +620,61 @@ Some notes.`
 	}
 }
 
+func TestRunSyncCommand_AddsFrontMatter(t *testing.T) {
+	notesDir := t.TempDir()
+	setupSyncTestConfig(t, notesDir)
+	viper.Set("jira.base_url", "https://jira.example.com")
+	defer viper.Reset()
+
+	syncJira = false
+	syncDaily = false
+	syncForce = false
+
+	notePath := filepath.Join(notesDir, "proj", "proj-123.md")
+	if err := os.MkdirAll(filepath.Dir(notePath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	body := "# proj-123\n\n## Summary\n\nInitial content.\n"
+	if err := os.WriteFile(notePath, []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := captureOutput(func() {
+		if err := runSyncCommand("proj-123"); err != nil {
+			t.Errorf("runSyncCommand() unexpected error: %v", err)
+		}
+	})
+	if !strings.Contains(output, "Front matter updated") {
+		t.Errorf("output should report the front matter update:\n%s", output)
+	}
+
+	content, err := os.ReadFile(notePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "---\nticket: proj-123\ntype: proj\nurl: https://jira.example.com/browse/PROJ-123\ntags: [rig, proj]\n---\n" + body
+	if string(content) != want {
+		t.Errorf("note =\n%s\nwant\n%s", content, want)
+	}
+}
+
+func TestUpdateNoteWithJiraInfo_KeepsFrontMatter(t *testing.T) {
+	notePath := filepath.Join(t.TempDir(), "proj-1.md")
+	frontMatter := "---\n# managed by rig\nticket: proj-1\n---\n"
+	if err := os.WriteFile(notePath, []byte(frontMatter+"# proj-1\n\n## Summary\n\nText\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := updateNoteWithJiraInfo(notePath, &jira.TicketInfo{Summary: "Fix login", Status: "Open"}); err != nil {
+		t.Fatal(err)
+	}
+
+	content, _ := os.ReadFile(notePath)
+	if !strings.HasPrefix(string(content), frontMatter+"# Fix login\n") {
+		t.Errorf("front matter should be kept and only the body title replaced:\n%s", content)
+	}
+}
+
 func TestRunSyncCommand_WithDailyFlag(t *testing.T) {
 	notesDir := t.TempDir()
 	setupSyncTestConfig(t, notesDir)
@@ -850,7 +913,7 @@ func TestSyncTicketNote_IncidentTypeSkipsJira(t *testing.T) {
 // Tests for syncDailyNote
 // ============================================================================
 
-func TestSyncDailyNote_NotFound(t *testing.T) {
+func TestSyncDailyNote_CreatesNote(t *testing.T) {
 	notesDir := t.TempDir()
 	setupSyncTestConfig(t, notesDir)
 	defer viper.Reset()
@@ -860,12 +923,40 @@ func TestSyncDailyNote_NotFound(t *testing.T) {
 	syncForce = false
 	defer func() { syncDaily = false }()
 
-	// Don't create the daily note
-	err := runSyncCommand("")
+	// The most recent earlier note has open tasks to carry over
+	dailyDir := filepath.Join(notesDir, "daily")
+	if err := os.MkdirAll(dailyDir, 0755); err != nil {
+		t.Fatalf("Failed to create daily dir: %v", err)
+	}
+	previous := time.Now().AddDate(0, 0, -3).Format("2006-01-02")
+	if err := os.WriteFile(filepath.Join(dailyDir, previous+".md"), []byte("## Tasks\n- [ ] Follow up on review\n- [x] Ship it\n"), 0644); err != nil {
+		t.Fatalf("Failed to write previous daily note: %v", err)
+	}
 
-	// The function returns nil and prints a message when daily note doesn't exist
+	var err error
+	output := captureOutput(func() {
+		err = runSyncCommand("")
+	})
 	if err != nil {
-		t.Errorf("syncDailyNote() should not error for missing daily note: %v", err)
+		t.Fatalf("syncDailyNote() error: %v", err)
+	}
+	if !strings.Contains(output, "Created daily note") || !strings.Contains(output, "Carried over 1 open task(s) from "+previous) {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+
+	today := time.Now().Format("2006-01-02")
+	content, err := os.ReadFile(filepath.Join(dailyDir, today+".md"))
+	if err != nil {
+		t.Fatalf("daily note should be created: %v", err)
+	}
+	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	for _, want := range []string{"# " + today, "[" + yesterday + "](" + yesterday + ".md)", "- [ ] Follow up on review", "## Active Tickets", "## Log"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("daily note missing %q:\n%s", want, content)
+		}
+	}
+	if strings.Contains(string(content), "Ship it") {
+		t.Errorf("checked items should not be carried over:\n%s", content)
 	}
 }
 
@@ -892,10 +983,39 @@ func TestSyncDailyNote_Exists(t *testing.T) {
 		t.Fatalf("Failed to write daily note: %v", err)
 	}
 
-	err := runSyncCommand("")
+	var err error
+	captureOutput(func() {
+		err = runSyncCommand("")
+	})
 	if err != nil {
 		t.Errorf("syncDailyNote() should not error when daily note exists: %v", err)
 	}
+
+	// A note without an Active Tickets section is left alone
+	got, err := os.ReadFile(dailyNotePath)
+	if err != nil || string(got) != content {
+		t.Errorf("existing daily note should be unchanged, got %q, %v", got, err)
+	}
+}
+
+func TestDailyTickets(t *testing.T) {
+	noteManager := notes.NewManager("/notes", "daily", "", false)
+	rows := []sessionRow{
+		{Name: "proj-2", Ticket: "proj-2", Worktree: "/src/repo/proj/proj-2", Summary: "Fix login", Status: "In Progress"},
+		{Name: "orphan", Ticket: "proj-9", Worktree: "/gone/proj/proj-9", Orphan: true},
+		{Name: "scratch"},
+		{Name: "ops-1", Ticket: "ops-1", Worktree: "/src/repo/ops/ops-1"},
+		{Name: "proj-2-again", Ticket: "proj-2", Worktree: "/src/repo/proj/proj-2"},
+	}
+
+	got := dailyTickets(noteManager, rows)
+	want := []notes.DailyTicket{
+		{Ticket: "ops-1", Type: "ops", Link: "[ops-1](../ops/ops-1.md)"},
+		{Ticket: "proj-2", Type: "proj", Summary: "Fix login", Status: "In Progress", Link: "[proj-2](../proj/proj-2.md)"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("dailyTickets() =\n%+v\nwant\n%+v", got, want)
+	}
 }
 
 // ============================================================================
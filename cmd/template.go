@@ -0,0 +1,273 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+
+	"thoreinstein.com/rig/pkg/config"
+	"thoreinstein.com/rig/pkg/notes"
+)
+
+var templateShowRaw bool
+
+// templateCmd groups the note template commands
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Inspect and check note templates",
+	Long: `Inspect and check the templates used to create notes.
+
+Templates are read from notes.template_dir, falling back to the built-in
+defaults. New ticket notes use <type>.md.tmpl (e.g. incident.md.tmpl) when it
+exists and ticket.md.tmpl otherwise, 'rig report' uses report-<kind>.md.tmpl,
+'rig incident close' uses postmortem.md.tmpl and 'rig notes index' uses
+index.md.tmpl.
+Files in the partials subdirectory can be included from any template.`,
+}
+
+// templateListCmd lists templates and partials
+var templateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List note templates and partials",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTemplateListCommand()
+	},
+}
+
+// templateShowCmd dry-renders a template
+var templateShowCmd = &cobra.Command{
+	Use:   "show <template|type>",
+	Short: "Render a note template with sample data",
+	Long: `Render a template with sample ticket data and print the result, or print
+its source with --raw.
+
+The argument is a template name (ticket.md.tmpl, daily, report-week,
+postmortem, index) or a ticket type, in which case the template new notes of that
+type would use is shown.
+
+Examples:
+  rig template show ticket
+  rig template show incident
+  rig template show daily --raw`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTemplateShowCommand(args[0])
+	},
+}
+
+// templateValidateCmd dry-renders templates and reports errors
+var templateValidateCmd = &cobra.Command{
+	Use:   "validate [template|type...]",
+	Short: "Check that note templates render",
+	Long: `Parse and render templates with sample ticket data, reporting any that
+fail. Without arguments every template is checked.
+
+Examples:
+  rig template validate
+  rig template validate incident`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTemplateValidateCommand(args)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(templateCmd)
+	templateCmd.AddCommand(templateListCmd)
+	templateCmd.AddCommand(templateShowCmd)
+	templateCmd.AddCommand(templateValidateCmd)
+
+	templateShowCmd.Flags().BoolVar(&templateShowRaw, "raw", false, "Print the template source instead of rendering it")
+}
+
+// templateNoteManager returns a note manager for the configured templates
+func templateNoteManager() (*notes.Manager, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load configuration")
+	}
+	return newNoteManager(cfg, false), nil
+}
+
+func runTemplateListCommand() error {
+	noteManager, err := templateNoteManager()
+	if err != nil {
+		return err
+	}
+
+	templates, err := noteManager.ListTemplates()
+	if err != nil {
+		return errors.Wrap(err, "failed to list templates")
+	}
+
+	fmt.Printf("Template directory: %s\n\n", orDash(shortenHome(noteManager.TemplateDir)))
+	writeTemplateTable(os.Stdout, templates)
+	return nil
+}
+
+// writeTemplateTable prints templates with where they come from and what
+// they are used for
+func writeTemplateTable(w io.Writer, templates []notes.TemplateInfo) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tUSED FOR\tSOURCE")
+
+	for _, info := range templates {
+		source := "built-in"
+		if info.Path != "" {
+			source = shortenHome(info.Path)
+			if info.Builtin {
+				source += " (overrides built-in)"
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", info.Name, templateUse(info), source)
+	}
+	_ = tw.Flush()
+}
+
+// templateUse describes which notes a template is used for
+func templateUse(info notes.TemplateInfo) string {
+	if info.Partial {
+		return "partial"
+	}
+	if kind := notes.ReportKind(info.Name); kind != "" {
+		return kind + " reports"
+	}
+	switch name := strings.TrimSuffix(info.Name, ".md.tmpl"); name {
+	case "daily":
+		return "daily notes"
+	case "hack":
+		return "hack notes"
+	case "index":
+		return "index notes"
+	case "postmortem":
+		return "incident postmortems"
+	case "ticket":
+		return "tickets without their own template"
+	default:
+		return name + " tickets"
+	}
+}
+
+// resolveTemplate maps a show/validate argument to a template name and the
+// ticket type to render it for. Names ending in .tmpl must exist; other
+// arguments are a template's base name or a ticket type.
+func resolveTemplate(noteManager *notes.Manager, arg string) (string, string, error) {
+	if strings.HasSuffix(arg, ".tmpl") {
+		if _, _, err := noteManager.TemplateSource(arg); err != nil {
+			return "", "", errors.Newf("template not found: %s", arg)
+		}
+		return arg, notes.TemplateType(arg), nil
+	}
+
+	if strings.HasPrefix(arg, "report-") {
+		return arg + ".md.tmpl", "", nil
+	}
+	switch arg {
+	case "daily":
+		return "daily.md.tmpl", "daily", nil
+	case "index":
+		return notes.IndexTemplateName, "", nil
+	case "postmortem":
+		return notes.PostmortemTemplateName, "", nil
+	case "ticket":
+		return "ticket.md.tmpl", notes.TemplateType("ticket.md.tmpl"), nil
+	}
+	return noteManager.TicketTemplateName(arg), arg, nil
+}
+
+// renderTemplateSample dry-renders a template the way rig would when
+// creating a note of ticketType
+func renderTemplateSample(noteManager *notes.Manager, name, ticketType string) (string, error) {
+	if kind := notes.ReportKind(name); kind != "" {
+		return noteManager.RenderReport(notes.SampleReport(kind))
+	}
+
+	switch name {
+	case notes.PostmortemTemplateName:
+		return noteManager.RenderPostmortem(notes.SamplePostmortem())
+	case notes.IndexTemplateName:
+		return noteManager.RenderIndex(notes.SampleIndex())
+	}
+	if ticketType == "daily" {
+		return noteManager.RenderDailyNote(notes.SampleDailyData())
+	}
+	return noteManager.RenderTicketNote(name, notes.SampleTicketData(ticketType))
+}
+
+func runTemplateShowCommand(arg string) error {
+	noteManager, err := templateNoteManager()
+	if err != nil {
+		return err
+	}
+
+	name, ticketType, err := resolveTemplate(noteManager, arg)
+	if err != nil {
+		return err
+	}
+
+	if templateShowRaw {
+		content, _, err := noteManager.TemplateSource(name)
+		if err != nil {
+			return err
+		}
+		fmt.Print(content)
+		return nil
+	}
+
+	content, err := renderTemplateSample(noteManager, name, ticketType)
+	if err != nil {
+		return err
+	}
+	fmt.Print(content)
+	return nil
+}
+
+func runTemplateValidateCommand(args []string) error {
+	noteManager, err := templateNoteManager()
+	if err != nil {
+		return err
+	}
+
+	type target struct{ name, ticketType string }
+	var targets []target
+	if len(args) == 0 {
+		templates, err := noteManager.ListTemplates()
+		if err != nil {
+			return errors.Wrap(err, "failed to list templates")
+		}
+		for _, info := range templates {
+			// Partials are checked as part of every template
+			if !info.Partial {
+				targets = append(targets, target{info.Name, notes.TemplateType(info.Name)})
+			}
+		}
+	} else {
+		for _, arg := range args {
+			name, ticketType, err := resolveTemplate(noteManager, arg)
+			if err != nil {
+				return err
+			}
+			targets = append(targets, target{name, ticketType})
+		}
+	}
+
+	failed := 0
+	for _, t := range targets {
+		if _, err := renderTemplateSample(noteManager, t.name, t.ticketType); err != nil {
+			fmt.Printf("✗ %s: %v\n", t.name, err)
+			failed++
+			continue
+		}
+		fmt.Printf("✓ %s\n", t.name)
+	}
+
+	if failed > 0 {
+		return errors.Newf("%d of %d template(s) failed validation", failed, len(targets))
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+
+	"thoreinstein.com/rig/pkg/notes"
+)
+
+// setupTemplateTestConfig points notes.template_dir at a fresh directory
+func setupTemplateTestConfig(t *testing.T) string {
+	t.Helper()
+	templateDir := t.TempDir()
+	viper.Reset()
+	viper.Set("notes.path", t.TempDir())
+	viper.Set("notes.daily_dir", "daily")
+	viper.Set("notes.template_dir", templateDir)
+	t.Cleanup(viper.Reset)
+	return templateDir
+}
+
+func TestTemplateCommandStructure(t *testing.T) {
+	names := make(map[string]bool)
+	for _, sub := range templateCmd.Commands() {
+		names[sub.Name()] = true
+	}
+	for _, want := range []string{"list", "show", "validate"} {
+		if !names[want] {
+			t.Errorf("template command missing subcommand %q", want)
+		}
+	}
+	if templateShowCmd.Flags().Lookup("raw") == nil {
+		t.Error("template show should have a --raw flag")
+	}
+	if err := templateShowCmd.Args(templateShowCmd, nil); err == nil {
+		t.Error("template show should require an argument")
+	}
+}
+
+func TestResolveTemplate(t *testing.T) {
+	templateDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(templateDir, "incident.md.tmpl"), []byte("# {{.Ticket}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	noteManager := notes.NewManager(t.TempDir(), "daily", templateDir, false)
+
+	tests := []struct {
+		arg, name, ticketType string
+	}{
+		{"daily", "daily.md.tmpl", "daily"},
+		{"ticket", "ticket.md.tmpl", "proj"},
+		{"incident", "incident.md.tmpl", "incident"},
+		{"ops", "ticket.md.tmpl", "ops"},
+		{"hack.md.tmpl", "hack.md.tmpl", "hack"},
+		{"report-week", "report-week.md.tmpl", ""},
+	}
+	for _, tt := range tests {
+		name, ticketType, err := resolveTemplate(noteManager, tt.arg)
+		if err != nil || name != tt.name || ticketType != tt.ticketType {
+			t.Errorf("resolveTemplate(%q) = %q, %q, %v, want %q, %q", tt.arg, name, ticketType, err, tt.name, tt.ticketType)
+		}
+	}
+
+	if _, _, err := resolveTemplate(noteManager, "missing.md.tmpl"); err == nil {
+		t.Error("an unknown template file name should fail")
+	}
+}
+
+func TestWriteTemplateTable(t *testing.T) {
+	var buf bytes.Buffer
+	writeTemplateTable(&buf, []notes.TemplateInfo{
+		{Name: "daily.md.tmpl", Builtin: true},
+		{Name: "ticket.md.tmpl", Builtin: true, Path: "/t/ticket.md.tmpl"},
+		{Name: "incident.md.tmpl", Path: "/t/incident.md.tmpl"},
+		{Name: "report-week.md.tmpl", Builtin: true},
+		{Name: "links", Path: "/t/partials/links.md.tmpl", Partial: true},
+	})
+	output := buf.String()
+
+	for _, want := range []string{"daily notes", "built-in", "/t/ticket.md.tmpl (overrides built-in)", "tickets without their own template", "incident tickets", "week reports", "partial"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("table missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestRunTemplateShowCommand(t *testing.T) {
+	templateDir := setupTemplateTestConfig(t)
+	if err := os.WriteFile(filepath.Join(templateDir, "incident.md.tmpl"), []byte("# {{.Ticket | upper}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := captureOutput(func() {
+		if err := runTemplateShowCommand("incident"); err != nil {
+			t.Errorf("runTemplateShowCommand() error: %v", err)
+		}
+	})
+	if !strings.Contains(output, "ticket: incident-123\n") || !strings.Contains(output, "# INCIDENT-123\n") {
+		t.Errorf("rendered sample should include front matter and the body:\n%s", output)
+	}
+
+	templateShowRaw = true
+	defer func() { templateShowRaw = false }()
+	output = captureOutput(func() {
+		if err := runTemplateShowCommand("incident"); err != nil {
+			t.Errorf("runTemplateShowCommand() error: %v", err)
+		}
+	})
+	if output != "# {{.Ticket | upper}}\n" {
+		t.Errorf("--raw should print the source, got %q", output)
+	}
+}
+
+func TestRunTemplateValidateCommand(t *testing.T) {
+	templateDir := setupTemplateTestConfig(t)
+
+	output := captureOutput(func() {
+		if err := runTemplateValidateCommand(nil); err != nil {
+			t.Errorf("built-in templates should validate: %v", err)
+		}
+	})
+	if !strings.Contains(output, "✓ ticket.md.tmpl") || !strings.Contains(output, "✓ daily.md.tmpl") || !strings.Contains(output, "✓ report-standup.md.tmpl") {
+		t.Errorf("validate output:\n%s", output)
+	}
+
+	if err := os.WriteFile(filepath.Join(templateDir, "ops.md.tmpl"), []byte("{{.Missing}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var err error
+	output = captureOutput(func() {
+		err = runTemplateValidateCommand([]string{"ops", "daily"})
+	})
+	if err == nil || !strings.Contains(err.Error(), "1 of 2") {
+		t.Errorf("runTemplateValidateCommand() error = %v, want 1 of 2 failed", err)
+	}
+	if !strings.Contains(output, "✗ ops.md.tmpl") || !strings.Contains(output, "Missing") {
+		t.Errorf("validate should report the failing template:\n%s", output)
+	}
+}
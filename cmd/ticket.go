@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"thoreinstein.com/rig/pkg/config"
+	"thoreinstein.com/rig/pkg/git"
+	"thoreinstein.com/rig/pkg/notes"
+)
+
+// ticketEnvVar is set in every rig session, see ticketContext.environment
+const ticketEnvVar = "RIG_TICKET"
+
+// resolveTicketArg returns the ticket given on the command line, or infers it
+// from the current environment when the argument was omitted.
+func resolveTicketArg(args []string) (string, error) {
+	if len(args) > 0 && strings.TrimSpace(args[0]) != "" {
+		return args[0], nil
+	}
+
+	ticket, source := detectCurrentTicket()
+	if ticket == "" {
+		return "", errors.Newf("ticket required: could not infer it from $%s, the worktree path, or the current branch", ticketEnvVar)
+	}
+
+	if verbose {
+		fmt.Printf("Using ticket %s (from %s)\n", ticket, source)
+	}
+
+	return ticket, nil
+}
+
+// detectCurrentTicket infers the current ticket, checking in order:
+// the RIG_TICKET environment variable, the worktree path layout under the
+// repo root ({repo}/{type}/{ticket}), and the current branch name.
+// Returns the ticket and a description of where it was found.
+func detectCurrentTicket() (ticket, source string) {
+	if ticket := strings.TrimSpace(os.Getenv(ticketEnvVar)); ticket != "" {
+		return ticket, "$" + ticketEnvVar
+	}
+
+	gitManager := git.NewWorktreeManager("", false)
+	if ticket := gitManager.DetectTicket(); ticket != "" {
+		return ticket, "current worktree"
+	}
+
+	return "", ""
+}
+
+// newNoteManager returns a note manager for the configured notes directory,
+// layout and flavour
+func newNoteManager(cfg *config.Config, verbose bool) *notes.Manager {
+	noteManager := notes.NewManager(cfg.Notes.Path, cfg.Notes.DailyDir, cfg.Notes.TemplateDir, verbose)
+	noteManager.Flavor = cfg.Notes.Flavor
+	noteManager.Subdir = cfg.Notes.Subdir
+	noteManager.TypeSubdirs = cfg.Notes.TypeSubdirs
+	noteManager.LogFormats = cfg.Notes.Log.Formats
+	// config.Load has validated the window
+	if window, err := time.ParseDuration(cfg.Notes.Log.DedupeWindow); err == nil {
+		noteManager.DedupeWindow = window
+	}
+	return noteManager
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestResolveTicketArg_ExplicitArgument(t *testing.T) {
+	t.Setenv(ticketEnvVar, "env-1")
+
+	ticket, err := resolveTicketArg([]string{"proj-123"})
+	if err != nil {
+		t.Fatalf("resolveTicketArg() error: %v", err)
+	}
+	if ticket != "proj-123" {
+		t.Errorf("resolveTicketArg() = %q, want explicit argument %q", ticket, "proj-123")
+	}
+}
+
+func TestResolveTicketArg_FromEnvironment(t *testing.T) {
+	t.Setenv(ticketEnvVar, "ops-42")
+	t.Chdir(t.TempDir())
+
+	ticket, err := resolveTicketArg(nil)
+	if err != nil {
+		t.Fatalf("resolveTicketArg() error: %v", err)
+	}
+	if ticket != "ops-42" {
+		t.Errorf("resolveTicketArg() = %q, want %q", ticket, "ops-42")
+	}
+}
+
+func TestResolveTicketArg_NoContext(t *testing.T) {
+	t.Setenv(ticketEnvVar, "")
+	t.Chdir(t.TempDir())
+
+	if _, err := resolveTicketArg([]string{}); err == nil {
+		t.Error("resolveTicketArg() should fail outside a ticket context")
+	}
+}
+
+func TestDetectCurrentTicket_Source(t *testing.T) {
+	t.Setenv(ticketEnvVar, "  proj-7  ")
+
+	ticket, source := detectCurrentTicket()
+	if ticket != "proj-7" {
+		t.Errorf("detectCurrentTicket() ticket = %q, want %q", ticket, "proj-7")
+	}
+	if source != "$"+ticketEnvVar {
+		t.Errorf("detectCurrentTicket() source = %q, want %q", source, "$"+ticketEnvVar)
+	}
+}
@@ -13,12 +13,11 @@ import (
 
 	"thoreinstein.com/rig/pkg/config"
 	"thoreinstein.com/rig/pkg/history"
-	"thoreinstein.com/rig/pkg/notes"
 )
 
 // timelineCmd represents the timeline command
 var timelineCmd = &cobra.Command{
-	Use:   "timeline <ticket>",
+	Use:   "timeline [ticket]",
 	Short: "Generate command timeline for a ticket",
 	Long: `Generate a timeline of commands executed for a specific ticket and export to Obsidian.
 
@@ -26,15 +25,22 @@ This command queries the history database (zsh-histdb or atuin) to find commands
 related to the specified ticket and generates a formatted timeline that can be
 inserted into the ticket's Obsidian note.
 
+If the ticket is omitted, it is inferred from $RIG_TICKET, the current
+worktree path, or the current branch name.
+
 Examples:
   rig timeline proj-123
   rig timeline proj-123 --since "2025-08-10 09:00"
   rig timeline proj-123 --until "2025-08-10 18:00"
   rig timeline proj-123 --failed-only
   rig timeline proj-123 --directory /path/to/worktree`,
-	Args: cobra.ExactArgs(1),
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runTimelineCommand(args[0])
+		ticket, err := resolveTicketArg(args)
+		if err != nil {
+			return err
+		}
+		return runTimelineCommand(ticket)
 	},
 }
 
@@ -331,7 +337,7 @@ func writeTimelineToFile(timeline, filename string) error {
 // updateTicketNoteWithTimeline updates the ticket's note with the timeline
 func updateTicketNoteWithTimeline(cfg *config.Config, ticketInfo *TicketInfo, timeline string) error {
 	// Get note path using notes manager
-	notesMgr := notes.NewManager(cfg.Notes.Path, cfg.Notes.DailyDir, cfg.Notes.TemplateDir, verbose)
+	notesMgr := newNoteManager(cfg, verbose)
 	notePath := notesMgr.GetNotePath(ticketInfo.Type, ticketInfo.Full)
 
 	// Check if note exists
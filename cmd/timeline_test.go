@@ -14,8 +14,8 @@ func TestTimelineCommandStructure(t *testing.T) {
 	// Not parallel - accesses global timelineCmd
 	cmd := timelineCmd
 
-	if cmd.Use != "timeline <ticket>" {
-		t.Errorf("timeline command Use = %q, want %q", cmd.Use, "timeline <ticket>")
+	if cmd.Use != "timeline [ticket]" {
+		t.Errorf("timeline command Use = %q, want %q", cmd.Use, "timeline [ticket]")
 	}
 
 	if cmd.Short == "" {
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 
@@ -12,12 +14,17 @@ import (
 	"thoreinstein.com/rig/pkg/git"
 	"thoreinstein.com/rig/pkg/jira"
 	"thoreinstein.com/rig/pkg/notes"
-	"thoreinstein.com/rig/pkg/tmux"
+)
+
+var (
+	workFrom    string
+	workStackOn string
+	workProfile string
 )
 
 // workCmd represents the work command
 var workCmd = &cobra.Command{
-	Use:   "work <ticket>",
+	Use:   "work [ticket]",
 	Short: "Start workflow for a ticket",
 	Long: `Start the complete workflow for a given ticket.
 
@@ -26,20 +33,43 @@ This command performs the following actions:
 - Creates git worktree and branch
 - Creates/updates markdown note with JIRA integration
 - Updates daily note with log entry
-- Creates tmux session with configured windows
+- Creates a tmux (or Zellij/WezTerm) session with configured windows
+
+If the ticket is omitted, it is inferred from $RIG_TICKET, the current
+worktree path, or the current branch name.
+
+The tmux session uses the profile given by --profile, else the first profile
+whose ticket_types or repos match, else tmux.windows. A .rig.toml in the
+repository root or worktree can add or override profiles.
+
+The branch starts from the default branch unless --from names another base
+or --stack-on names a ticket whose branch to build on. The chosen base is
+recorded so 'rig clean' and 'rig list' compare against it instead of main.
 
 Examples:
   rig work proj-123
   rig work ops-456
-  rig work incident-789`,
-	Args: cobra.ExactArgs(1),
+  rig work incident-789
+  rig work proj-123 --from release/2.4
+  rig work proj-124 --stack-on proj-123
+  rig work incident-789 --profile incident`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runWorkCommand(args[0])
+		ticket, err := resolveTicketArg(args)
+		if err != nil {
+			return err
+		}
+		return runWorkCommand(ticket)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(workCmd)
+
+	workCmd.Flags().StringVar(&workFrom, "from", "", "Base branch for the new worktree (default: repository default branch)")
+	workCmd.Flags().StringVar(&workStackOn, "stack-on", "", "Ticket whose branch the new worktree builds on")
+	workCmd.MarkFlagsMutuallyExclusive("from", "stack-on")
+	workCmd.Flags().StringVar(&workProfile, "profile", "", "Session profile (default: chosen by ticket type or repository)")
 }
 
 // TicketInfo holds parsed ticket information
@@ -66,7 +96,39 @@ func parseTicket(ticket string) (*TicketInfo, error) {
 	}, nil
 }
 
+// resolveWorkBase returns the base branch requested by --from or --stack-on,
+// or "" to use the repository default. A stacked ticket's branch is the ticket itself.
+func resolveWorkBase(from, stackOn string) (string, error) {
+	if from != "" && stackOn != "" {
+		return "", errors.New("--from and --stack-on cannot be used together")
+	}
+
+	if stackOn != "" {
+		parent, err := parseTicket(stackOn)
+		if err != nil {
+			return "", errors.Wrap(err, "invalid --stack-on ticket")
+		}
+		return parent.Full, nil
+	}
+
+	return strings.TrimSpace(from), nil
+}
+
+// workOptions are the settings of a work run beyond the ticket
+type workOptions struct {
+	From     string // Base branch, see --from
+	StackOn  string // Ticket to build on, see --stack-on
+	Profile  string // Session profile, see --profile
+	Severity string // Incident severity for a new note
+}
+
 func runWorkCommand(ticket string) error {
+	return startWork(ticket, workOptions{From: workFrom, StackOn: workStackOn, Profile: workProfile})
+}
+
+// startWork creates the worktree, note, daily note entry and session of a
+// ticket
+func startWork(ticket string, opts workOptions) error {
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
@@ -101,11 +163,23 @@ func runWorkCommand(ticket string) error {
 		return err
 	}
 
-	worktreePath, err := gitManager.CreateWorktree(ticketInfo.Type, ticketInfo.Full)
+	baseBranch, err := resolveWorkBase(opts.From, opts.StackOn)
+	if err != nil {
+		return err
+	}
+
+	// Coming back to an existing worktree resumes the work
+	_, statErr := os.Stat(filepath.Join(repoRoot, ticketInfo.Type, ticketInfo.Full))
+	resumed := statErr == nil
+
+	worktreePath, err := gitManager.CreateWorktreeFrom(ticketInfo.Type, ticketInfo.Full, ticketInfo.Full, baseBranch)
 	if err != nil {
 		return errors.Wrap(err, "failed to create git worktree")
 	}
 	fmt.Printf("Git worktree created at: %s\n", worktreePath)
+	if baseBranch != "" && !resumed {
+		fmt.Printf("Base branch: %s\n", baseBranch)
+	}
 
 	// Step 2: Fetch JIRA details (if enabled)
 	var jiraInfo *jira.TicketInfo
@@ -136,12 +210,7 @@ func runWorkCommand(ticket string) error {
 	if verbose {
 		fmt.Println("Creating note...")
 	}
-	noteManager := notes.NewManager(
-		cfg.Notes.Path,
-		cfg.Notes.DailyDir,
-		cfg.Notes.TemplateDir,
-		verbose,
-	)
+	noteManager := newNoteManager(cfg, verbose)
 
 	// Build ticket data for template
 	noteData := notes.TicketData{
@@ -150,7 +219,10 @@ func runWorkCommand(ticket string) error {
 		RepoName:     repoName,
 		RepoPath:     repoRoot,
 		WorktreePath: worktreePath,
+		URL:          jira.TicketURL(cfg.Jira.BaseURL, ticketInfo.Full),
+		Severity:     opts.Severity,
 	}
+	noteData.Branch, _ = gitManager.BranchForWorktree(worktreePath)
 
 	// Add JIRA info if available
 	if jiraInfo != nil {
@@ -169,41 +241,51 @@ func runWorkCommand(ticket string) error {
 	if verbose {
 		fmt.Println("Updating daily note...")
 	}
-	err = noteManager.UpdateDailyNote(ticketInfo.Full, ticketInfo.Type)
+	event := notes.LogEvent{Event: notes.EventStarted, Ticket: ticketInfo.Full, Type: ticketInfo.Type}
+	if resumed {
+		event.Event = notes.EventResumed
+	}
+	added, err := noteManager.AddDailyEvent(event)
 	if err != nil {
 		// Don't fail if daily note update fails
 		if verbose {
 			fmt.Printf("Warning: Could not update daily note: %v\n", err)
 		}
-	} else {
+	} else if added {
 		fmt.Println("Daily note updated")
 	}
+	refreshIndexNotes(cfg, noteManager)
 
-	// Step 5: Create tmux session
+	// Step 5: Create multiplexer session
 	if verbose {
-		fmt.Println("Creating tmux session...")
+		fmt.Println("Creating session...")
+	}
+
+	tmuxWindows, profileEnv, err := resolveSessionWindows(cfg, opts.Profile, ticketInfo.Type, repoRoot, repoName, worktreePath)
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve tmux profile")
+	}
+
+	sessionManager, err := newMultiplexer(cfg, tmuxWindows)
+	if err != nil {
+		return err
 	}
 
-	// Convert config windows to tmux windows
-	tmuxWindows := make([]tmux.WindowConfig, 0, len(cfg.Tmux.Windows))
-	for _, window := range cfg.Tmux.Windows {
-		tmuxWindows = append(tmuxWindows, tmux.WindowConfig{
-			Name:       window.Name,
-			Command:    window.Command,
-			WorkingDir: window.WorkingDir,
-		})
+	ticketContext := newTicketContext(cfg, gitManager, ticketInfo.Full, ticketInfo.Type, repoName, worktreePath, notePath, baseBranch)
+	if jiraInfo != nil && jiraInfo.Summary != "" {
+		ticketContext.Summary = jiraInfo.Summary
 	}
+	sessionManager.AddEnvironment(ticketContext.sessionEnvironment(profileEnv))
 
-	sessionManager := tmux.NewSessionManager(cfg.Tmux.SessionPrefix, tmuxWindows, verbose)
 	err = sessionManager.CreateSession(ticketInfo.Full, worktreePath, notePath)
 	if err != nil {
-		// Don't fail the entire process if tmux session creation fails
+		// Don't fail the entire process if session creation fails
 		if verbose {
-			fmt.Printf("Warning: Could not create tmux session: %v\n", err)
+			fmt.Printf("Warning: Could not create %s session: %v\n", sessionManager.Name(), err)
 		}
-		fmt.Println("Warning: Tmux session creation failed, but other steps completed successfully")
+		fmt.Printf("Warning: %s session creation failed, but other steps completed successfully\n", sessionManager.Name())
 	} else {
-		fmt.Println("Tmux session created successfully")
+		fmt.Printf("%s session created successfully\n", sessionManager.Name())
 	}
 
 	fmt.Printf("\nWorkflow initialization for %s completed successfully!\n", ticketInfo.Full)
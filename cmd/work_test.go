@@ -188,8 +188,8 @@ func TestTicketInfo(t *testing.T) {
 func TestWorkCommandDescription(t *testing.T) {
 	cmd := workCmd
 
-	if cmd.Use != "work <ticket>" {
-		t.Errorf("work command Use = %q, want %q", cmd.Use, "work <ticket>")
+	if cmd.Use != "work [ticket]" {
+		t.Errorf("work command Use = %q, want %q", cmd.Use, "work [ticket]")
 	}
 
 	if cmd.Short == "" {
@@ -217,10 +217,64 @@ func TestWorkCommandDescription(t *testing.T) {
 func TestWorkCommandArgs(t *testing.T) {
 	cmd := workCmd
 
-	// Command should require exactly 1 argument
+	// Command accepts at most 1 argument (ticket is inferred when omitted)
 	if cmd.Args == nil {
 		t.Error("work command should have Args validation")
 	}
+	if err := cmd.ValidateArgs([]string{}); err != nil {
+		t.Errorf("work command should accept an omitted ticket, got: %v", err)
+	}
+	if err := cmd.ValidateArgs([]string{"proj-1", "extra"}); err == nil {
+		t.Error("work command should reject more than one argument")
+	}
+}
+
+func TestWorkCommandBaseFlags(t *testing.T) {
+	for _, name := range []string{"from", "stack-on"} {
+		flag := workCmd.Flags().Lookup(name)
+		if flag == nil {
+			t.Errorf("work command should have --%s flag", name)
+			continue
+		}
+		if flag.DefValue != "" {
+			t.Errorf("--%s default should be empty, got %q", name, flag.DefValue)
+		}
+	}
+}
+
+func TestResolveWorkBase(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		stackOn string
+		want    string
+		wantErr bool
+	}{
+		{name: "default", want: ""},
+		{name: "from branch", from: "release/2.4", want: "release/2.4"},
+		{name: "stack on ticket", stackOn: "proj-123", want: "proj-123"},
+		{name: "stack on preserves case", stackOn: "PROJ-123", want: "PROJ-123"},
+		{name: "stack on invalid ticket", stackOn: "not a ticket", wantErr: true},
+		{name: "both flags", from: "main", stackOn: "proj-123", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveWorkBase(tt.from, tt.stackOn)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("resolveWorkBase(%q, %q) expected error", tt.from, tt.stackOn)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveWorkBase(%q, %q) error: %v", tt.from, tt.stackOn, err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveWorkBase(%q, %q) = %q, want %q", tt.from, tt.stackOn, got, tt.want)
+			}
+		})
+	}
 }
 
 func TestTicketTypeNormalization(t *testing.T) {
@@ -3,6 +3,9 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/cockroachdb/errors"
 	"github.com/spf13/viper"
@@ -14,18 +17,36 @@ type Config struct {
 	Notes   NotesConfig   `mapstructure:"notes"`
 	Git     GitConfig     `mapstructure:"git"`
 	Clone   CloneConfig   `mapstructure:"clone"`
+	Clean   CleanConfig   `mapstructure:"clean"`
+	Hooks   HooksConfig   `mapstructure:"hooks"`
 	History HistoryConfig `mapstructure:"history"`
 	Jira    JiraConfig    `mapstructure:"jira"`
+	Session SessionConfig `mapstructure:"session"`
 	Tmux    TmuxConfig    `mapstructure:"tmux"`
 }
 
 // NotesConfig holds markdown notes configuration
 type NotesConfig struct {
-	Path        string `mapstructure:"path"`         // Base directory for notes
-	DailyDir    string `mapstructure:"daily_dir"`    // Subdirectory for daily notes
-	TemplateDir string `mapstructure:"template_dir"` // Optional user template directory
+	Path        string            `mapstructure:"path"`         // Base directory for notes (e.g. an Obsidian vault)
+	DailyDir    string            `mapstructure:"daily_dir"`    // Subdirectory for daily notes
+	TemplateDir string            `mapstructure:"template_dir"` // Optional user template directory
+	Flavor      string            `mapstructure:"flavor"`       // "markdown" or "obsidian"
+	Subdir      string            `mapstructure:"subdir"`       // Optional subdirectory for ticket notes (e.g. "Areas/Work")
+	TypeSubdirs map[string]string `mapstructure:"type_subdirs"` // Per-type note directories (e.g. incident = "Areas/Incidents")
+	IndexPath   string            `mapstructure:"index_path"`   // Full-text search index for 'rig notes search'
+	AutoIndex   bool              `mapstructure:"auto_index"`   // Regenerate index notes after work, sync and done
+	Log         LogConfig         `mapstructure:"log"`          // Daily note log entries
 }
 
+// LogConfig controls the entries rig writes to the Log section of daily notes
+type LogConfig struct {
+	DedupeWindow string            `mapstructure:"dedupe_window"` // Skip repeats of an event within this long (e.g. "30m", "0" to keep all)
+	Formats      map[string]string `mapstructure:"formats"`       // Per-event entry templates (e.g. started = "Started {{.Ticket}}")
+}
+
+// notesFlavors lists the accepted notes.flavor values
+var notesFlavors = []string{"markdown", "obsidian"}
+
 // GitConfig holds optional git configuration overrides
 type GitConfig struct {
 	BaseBranch string `mapstructure:"base_branch"` // Optional override for default branch
@@ -36,6 +57,22 @@ type CloneConfig struct {
 	BasePath string `mapstructure:"base_path"` // Base directory for clones (default: ~/src)
 }
 
+// CleanConfig holds default cleanup policies for the clean command.
+// Command-line flags take precedence over these values.
+type CleanConfig struct {
+	MergedOnly   bool   `mapstructure:"merged_only"`   // Only remove worktrees whose branch is merged
+	OlderThan    string `mapstructure:"older_than"`    // Only remove worktrees inactive for this long (e.g., "30d")
+	NoSession    bool   `mapstructure:"no_session"`    // Skip worktrees with an active tmux session
+	TicketStatus string `mapstructure:"ticket_status"` // Only remove worktrees whose JIRA status matches (e.g., "Done")
+}
+
+// HooksConfig holds git hook configuration for commit message handling
+type HooksConfig struct {
+	Mode         string `mapstructure:"mode"`          // "prefix" adds the ticket key, "validate" only checks
+	Pattern      string `mapstructure:"pattern"`       // Regex commit messages must match
+	PrefixFormat string `mapstructure:"prefix_format"` // Prefix added in prefix mode ({ticket} is replaced)
+}
+
 // HistoryConfig holds command history configuration
 type HistoryConfig struct {
 	DatabasePath   string   `mapstructure:"database_path"`
@@ -46,19 +83,57 @@ type HistoryConfig struct {
 type JiraConfig struct {
 	Enabled    bool   `mapstructure:"enabled"`
 	CliCommand string `mapstructure:"cli_command"`
+	BaseURL    string `mapstructure:"base_url"` // e.g. "https://example.atlassian.net", for ticket links
 }
 
-// TmuxWindow represents a tmux window configuration
+// SessionConfig selects the terminal multiplexer that hosts ticket sessions.
+// Windows, profiles and allowed commands are shared by all backends and
+// configured under [tmux].
+type SessionConfig struct {
+	Backend string `mapstructure:"backend"` // "auto", "tmux", "zellij" or "wezterm"
+}
+
+// TmuxWindow represents a tmux window configuration.
+// Command and WorkingDir apply to the window's first pane; Panes adds more.
 type TmuxWindow struct {
-	Name       string `mapstructure:"name"`
+	Name       string     `mapstructure:"name"`
+	Command    string     `mapstructure:"command"`
+	WorkingDir string     `mapstructure:"working_dir"`
+	Layout     string     `mapstructure:"layout"` // Named layout (e.g. "main-vertical", "tiled") or custom layout string
+	Panes      []TmuxPane `mapstructure:"panes"`
+}
+
+// TmuxPane represents an additional pane split off a tmux window
+type TmuxPane struct {
+	Split      string `mapstructure:"split"` // "horizontal" (side by side) or "vertical" (stacked)
+	Size       int    `mapstructure:"size"`  // Percentage of the window, 0 for tmux's default
 	Command    string `mapstructure:"command"`
 	WorkingDir string `mapstructure:"working_dir"`
 }
 
 // TmuxConfig holds Tmux session configuration
 type TmuxConfig struct {
-	SessionPrefix string       `mapstructure:"session_prefix"`
-	Windows       []TmuxWindow `mapstructure:"windows"`
+	SessionPrefix string                 `mapstructure:"session_prefix"`
+	Windows       []TmuxWindow           `mapstructure:"windows"`  // Default session layout
+	Profiles      map[string]TmuxProfile `mapstructure:"profiles"` // Named alternatives, see ResolveProfile
+	Env           map[string]string      `mapstructure:"env"`      // Extra session variables for the default profile
+
+	// AllowedCommands extends the built-in command allowlist. Each entry is a
+	// command prefix such as "just" or "direnv exec".
+	AllowedCommands []string `mapstructure:"allowed_commands"`
+}
+
+// TmuxProfile is a named set of session windows selected by ticket type,
+// repository name, or explicitly with --profile
+type TmuxProfile struct {
+	Extends     string       `mapstructure:"extends"`      // Profile whose windows come first ("default" for tmux.windows)
+	TicketTypes []string     `mapstructure:"ticket_types"` // Ticket types that use this profile (e.g. "incident")
+	Repos       []string     `mapstructure:"repos"`        // Repository names that use this profile
+	Windows     []TmuxWindow `mapstructure:"windows"`
+
+	// Env adds session environment variables on top of the extended
+	// profile's. Values may use {ticket}, {worktree_path}, {branch}, ...
+	Env map[string]string `mapstructure:"env"`
 }
 
 // Load loads the configuration from file and environment variables
@@ -78,6 +153,14 @@ func Load() (*Config, error) {
 		return nil, errors.Wrap(err, "failed to expand paths")
 	}
 
+	if !slices.Contains(notesFlavors, config.Notes.Flavor) {
+		return nil, errors.Newf("invalid notes.flavor %q (use %s)", config.Notes.Flavor, strings.Join(notesFlavors, " or "))
+	}
+
+	if _, err := time.ParseDuration(config.Notes.Log.DedupeWindow); err != nil {
+		return nil, errors.Newf("invalid notes.log.dedupe_window %q (use a duration like 30m)", config.Notes.Log.DedupeWindow)
+	}
+
 	return config, nil
 }
 
@@ -93,6 +176,11 @@ func setDefaults() {
 	viper.SetDefault("notes.path", filepath.Join(homeDir, "Documents", "Notes"))
 	viper.SetDefault("notes.daily_dir", "daily")
 	viper.SetDefault("notes.template_dir", filepath.Join(homeDir, ".config", "rig", "templates"))
+	viper.SetDefault("notes.flavor", "markdown")
+	viper.SetDefault("notes.subdir", "")
+	viper.SetDefault("notes.index_path", filepath.Join(homeDir, ".cache", "rig", "notes-index.db"))
+	viper.SetDefault("notes.auto_index", false)
+	viper.SetDefault("notes.log.dedupe_window", "30m")
 
 	// Git defaults (empty means auto-detect)
 	viper.SetDefault("git.base_branch", "")
@@ -100,6 +188,17 @@ func setDefaults() {
 	// Clone defaults (empty means ~/src)
 	viper.SetDefault("clone.base_path", "")
 
+	// Clean defaults (empty/false means no filtering)
+	viper.SetDefault("clean.merged_only", false)
+	viper.SetDefault("clean.older_than", "")
+	viper.SetDefault("clean.no_session", false)
+	viper.SetDefault("clean.ticket_status", "")
+
+	// Hooks defaults
+	viper.SetDefault("hooks.mode", "prefix")
+	viper.SetDefault("hooks.pattern", `[A-Z][A-Z0-9]*-[0-9]+`)
+	viper.SetDefault("hooks.prefix_format", "{ticket}: ")
+
 	// History defaults
 	viper.SetDefault("history.database_path", filepath.Join(homeDir, ".histdb", "zsh-history.db"))
 	viper.SetDefault("history.ignore_patterns", []string{"ls", "cd", "pwd", "clear"})
@@ -107,6 +206,10 @@ func setDefaults() {
 	// JIRA defaults
 	viper.SetDefault("jira.enabled", true)
 	viper.SetDefault("jira.cli_command", "acli")
+	viper.SetDefault("jira.base_url", "")
+
+	// Session defaults ("auto" detects the multiplexer from the environment)
+	viper.SetDefault("session.backend", "auto")
 
 	// Tmux defaults
 	viper.SetDefault("tmux.session_prefix", "")
@@ -115,6 +218,15 @@ func setDefaults() {
 		{Name: "code", Command: "nvim", WorkingDir: "{worktree_path}"},
 		{Name: "term", WorkingDir: "{worktree_path}"},
 	})
+
+	// Built-in profile for 'rig incident start'; configuring
+	// tmux.profiles.incident overrides its keys
+	viper.SetDefault("tmux.profiles.incident.ticket_types", []string{"incident"})
+	viper.SetDefault("tmux.profiles.incident.windows", []TmuxWindow{
+		{Name: "note", Command: "nvim {note_path}"},
+		{Name: "term", WorkingDir: "{worktree_path}"},
+		{Name: "logs", WorkingDir: "{worktree_path}"},
+	})
 }
 
 // expandPaths expands ~ and environment variables in paths
@@ -131,6 +243,11 @@ func expandPaths(config *Config) error {
 		return err
 	}
 
+	config.Notes.IndexPath, err = expandPath(config.Notes.IndexPath)
+	if err != nil {
+		return err
+	}
+
 	config.History.DatabasePath, err = expandPath(config.History.DatabasePath)
 	if err != nil {
 		return err
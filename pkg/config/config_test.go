@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/spf13/viper"
@@ -74,6 +75,12 @@ func TestLoad_WithDefaults(t *testing.T) {
 	if config.Notes.DailyDir != "daily" {
 		t.Errorf("Expected notes.daily_dir to default to 'daily', got %q", config.Notes.DailyDir)
 	}
+	if config.Session.Backend != "auto" {
+		t.Errorf("Expected session.backend to default to 'auto', got %q", config.Session.Backend)
+	}
+	if config.Notes.Flavor != "markdown" {
+		t.Errorf("Expected notes.flavor to default to 'markdown', got %q", config.Notes.Flavor)
+	}
 
 	// Verify default tmux windows are properly loaded (regression test for type mismatch bug)
 	if len(config.Tmux.Windows) != 3 {
@@ -255,3 +262,192 @@ func TestConfigStructure(t *testing.T) {
 		t.Error("Tmux.Windows not set correctly")
 	}
 }
+
+func TestLoad_TmuxPanes(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	configContent := `
+[[tmux.windows]]
+name = "code"
+command = "nvim"
+layout = "main-vertical"
+
+[[tmux.windows.panes]]
+split = "horizontal"
+size = 30
+command = "go test ./..."
+working_dir = "{worktree_path}"
+
+[[tmux.windows.panes]]
+split = "vertical"
+`
+
+	configPath := filepath.Join(tmpDir, "config.toml")
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	viper.Reset()
+	viper.SetConfigFile(configPath)
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+
+	config, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if len(config.Tmux.Windows) != 1 {
+		t.Fatalf("Tmux.Windows = %d, want 1", len(config.Tmux.Windows))
+	}
+	window := config.Tmux.Windows[0]
+	if window.Layout != "main-vertical" {
+		t.Errorf("Layout = %q, want %q", window.Layout, "main-vertical")
+	}
+	if len(window.Panes) != 2 {
+		t.Fatalf("Panes = %d, want 2", len(window.Panes))
+	}
+
+	expected := TmuxPane{Split: "horizontal", Size: 30, Command: "go test ./...", WorkingDir: "{worktree_path}"}
+	if window.Panes[0] != expected {
+		t.Errorf("Panes[0] = %+v, want %+v", window.Panes[0], expected)
+	}
+	if window.Panes[1].Split != "vertical" || window.Panes[1].Size != 0 {
+		t.Errorf("Panes[1] = %+v", window.Panes[1])
+	}
+}
+
+func TestLoad_NotesFlavor(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	configContent := `
+[notes]
+flavor = "obsidian"
+subdir = "Areas/Work"
+
+[notes.type_subdirs]
+incident = "Areas/Incidents"
+`
+
+	configPath := filepath.Join(tmpDir, "config.toml")
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	viper.Reset()
+	viper.SetConfigFile(configPath)
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+
+	config, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if config.Notes.Flavor != "obsidian" {
+		t.Errorf("Notes.Flavor = %q, want %q", config.Notes.Flavor, "obsidian")
+	}
+	if config.Notes.Subdir != "Areas/Work" {
+		t.Errorf("Notes.Subdir = %q, want %q", config.Notes.Subdir, "Areas/Work")
+	}
+	if got := config.Notes.TypeSubdirs["incident"]; got != "Areas/Incidents" {
+		t.Errorf("Notes.TypeSubdirs[incident] = %q, want %q", got, "Areas/Incidents")
+	}
+}
+
+func TestLoad_NotesAutoIndex(t *testing.T) {
+	viper.Reset()
+
+	config, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if config.Notes.AutoIndex {
+		t.Error("Notes.AutoIndex should default to false")
+	}
+
+	viper.Set("notes.auto_index", true)
+	if config, err = Load(); err != nil || !config.Notes.AutoIndex {
+		t.Errorf("Notes.AutoIndex = %v, %v; want true", config.Notes.AutoIndex, err)
+	}
+}
+
+func TestLoad_NotesLog(t *testing.T) {
+	viper.Reset()
+
+	config, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if config.Notes.Log.DedupeWindow != "30m" {
+		t.Errorf("Notes.Log.DedupeWindow = %q, want the 30m default", config.Notes.Log.DedupeWindow)
+	}
+
+	viper.Set("notes.log.formats", map[string]string{"started": "Picked up {{.Ticket}}"})
+	viper.Set("notes.log.dedupe_window", "2h")
+	if config, err = Load(); err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if config.Notes.Log.DedupeWindow != "2h" || config.Notes.Log.Formats["started"] != "Picked up {{.Ticket}}" {
+		t.Errorf("Notes.Log = %+v", config.Notes.Log)
+	}
+
+	viper.Set("notes.log.dedupe_window", "soon")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "dedupe_window") {
+		t.Errorf("Load() error = %v, want an invalid dedupe_window error", err)
+	}
+}
+
+func TestLoad_InvalidNotesFlavor(t *testing.T) {
+	viper.Reset()
+	viper.Set("notes.flavor", "logseq")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("Load() should reject an unknown notes.flavor")
+	}
+	if !strings.Contains(err.Error(), "logseq") {
+		t.Errorf("error = %v, want it to name the flavor", err)
+	}
+}
+
+func TestLoad_IncidentProfile(t *testing.T) {
+	viper.Reset()
+
+	config, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	name, windows, err := config.Tmux.ResolveProfile("", "incident", "api")
+	if err != nil || name != IncidentProfile || windowNames(windows) != "note,term,logs" {
+		t.Errorf("ResolveProfile() = %q, %q, %v; want the built-in incident profile", name, windowNames(windows), err)
+	}
+
+	// Configured keys replace the built-in ones, the others are kept
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	content := `
+[tmux.profiles.incident]
+extends = "default"
+
+[[tmux.profiles.incident.windows]]
+name = "dashboards"
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	viper.Reset()
+	viper.SetConfigFile(configPath)
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	if config, err = Load(); err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	name, windows, err = config.Tmux.ResolveProfile("", "incident", "api")
+	if err != nil || name != IncidentProfile || windowNames(windows) != "note,code,term,dashboards" {
+		t.Errorf("ResolveProfile() = %q, %q, %v; want the configured incident profile", name, windowNames(windows), err)
+	}
+}
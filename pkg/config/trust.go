@@ -0,0 +1,232 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// ErrUntrusted is returned when a repository-local config file has not been
+// approved with 'rig config trust', or has changed since it was approved
+var ErrUntrusted = errors.New("repository config is not trusted")
+
+// TrustStatus describes whether a file matches its approved version
+type TrustStatus int
+
+const (
+	// Untrusted files have never been approved
+	Untrusted TrustStatus = iota
+	// Changed files were approved but their content has changed since
+	Changed
+	// Trusted files match the approved content hash
+	Trusted
+)
+
+// String returns a human-readable trust status
+func (s TrustStatus) String() string {
+	switch s {
+	case Trusted:
+		return "trusted"
+	case Changed:
+		return "changed"
+	default:
+		return "untrusted"
+	}
+}
+
+// TrustEntry records an approved file. The content is kept so that later
+// changes can be reviewed as a diff before being approved again.
+type TrustEntry struct {
+	SHA256    string    `json:"sha256"`
+	Content   string    `json:"content"`
+	TrustedAt time.Time `json:"trusted_at"`
+}
+
+// TrustStore pins repository-local config files to the content hash the user
+// approved, in the spirit of direnv's allow list
+type TrustStore struct {
+	Path    string
+	Entries map[string]TrustEntry
+}
+
+// DefaultTrustStorePath returns ~/.config/rig/trust.json
+func DefaultTrustStorePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get home directory")
+	}
+	return filepath.Join(homeDir, ".config", "rig", "trust.json"), nil
+}
+
+// LoadTrustStore reads the trust store at path. A missing file is an empty store.
+func LoadTrustStore(path string) (*TrustStore, error) {
+	store := &TrustStore{Path: path, Entries: make(map[string]TrustEntry)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read trust store")
+	}
+
+	if err := json.Unmarshal(data, &store.Entries); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse trust store %s", path)
+	}
+	if store.Entries == nil {
+		store.Entries = make(map[string]TrustEntry)
+	}
+
+	return store, nil
+}
+
+// Save writes the trust store, readable only by the owner
+func (s *TrustStore) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0700); err != nil {
+		return errors.Wrap(err, "failed to create trust store directory")
+	}
+
+	data, err := json.MarshalIndent(s.Entries, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode trust store")
+	}
+
+	if err := os.WriteFile(s.Path, append(data, '\n'), 0600); err != nil {
+		return errors.Wrap(err, "failed to write trust store")
+	}
+
+	return nil
+}
+
+// Status reports whether file matches its approved content.
+// Also returns the approved entry, if any, for reviewing changes.
+func (s *TrustStore) Status(file string) (TrustStatus, *TrustEntry, error) {
+	key, err := trustKey(file)
+	if err != nil {
+		return Untrusted, nil, err
+	}
+
+	entry, ok := s.Entries[key]
+	if !ok {
+		return Untrusted, nil, nil
+	}
+
+	data, err := os.ReadFile(key)
+	if err != nil {
+		return Untrusted, nil, errors.Wrapf(err, "failed to read %s", file)
+	}
+
+	return s.statusOf(key, data), &entry, nil
+}
+
+// ContentStatus is Status for data already read from file, so that what is
+// reviewed is what gets checked
+func (s *TrustStore) ContentStatus(file string, data []byte) (TrustStatus, *TrustEntry, error) {
+	key, err := trustKey(file)
+	if err != nil {
+		return Untrusted, nil, err
+	}
+
+	entry, ok := s.Entries[key]
+	if !ok {
+		return Untrusted, nil, nil
+	}
+	return s.statusOf(key, data), &entry, nil
+}
+
+// statusOf compares data against the approved hash for the canonical path key
+func (s *TrustStore) statusOf(key string, data []byte) TrustStatus {
+	if s == nil {
+		return Untrusted
+	}
+
+	entry, ok := s.Entries[key]
+	if !ok {
+		return Untrusted
+	}
+	if hashBytes(data) != entry.SHA256 {
+		return Changed
+	}
+	return Trusted
+}
+
+// Trust approves the current content of file
+func (s *TrustStore) Trust(file string) error {
+	key, err := trustKey(file)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(key)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", file)
+	}
+
+	s.Entries[key] = TrustEntry{SHA256: hashBytes(data), Content: string(data), TrustedAt: time.Now()}
+	return nil
+}
+
+// TrustContent approves data as the content of file. Use it to pin the bytes
+// the user reviewed, which the file may no longer hold.
+func (s *TrustStore) TrustContent(file string, data []byte) error {
+	key, err := trustKey(file)
+	if err != nil {
+		return err
+	}
+
+	s.Entries[key] = TrustEntry{SHA256: hashBytes(data), Content: string(data), TrustedAt: time.Now()}
+	return nil
+}
+
+// Revoke removes the approval for file. Returns false if it wasn't trusted.
+func (s *TrustStore) Revoke(file string) bool {
+	key, err := trustKey(file)
+	if err != nil {
+		// The file may be gone; fall back to the cleaned absolute path
+		key, err = filepath.Abs(file)
+		if err != nil {
+			return false
+		}
+	}
+
+	if _, ok := s.Entries[key]; !ok {
+		return false
+	}
+	delete(s.Entries, key)
+	return true
+}
+
+// Files returns the approved file paths in sorted order
+func (s *TrustStore) Files() []string {
+	files := make([]string, 0, len(s.Entries))
+	for file := range s.Entries {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// trustKey returns the canonical path used to key a file in the store
+func trustKey(file string) (string, error) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to resolve %s", file)
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to resolve %s", file)
+	}
+	return resolved, nil
+}
+
+// hashBytes returns the hex-encoded SHA-256 of data
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTrustStore(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, RepoConfigFile)
+	if err := os.WriteFile(file, []byte("[tmux]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	storePath := filepath.Join(dir, "config", "trust.json")
+	store, err := LoadTrustStore(storePath)
+	if err != nil {
+		t.Fatalf("LoadTrustStore() error: %v", err)
+	}
+
+	status, entry, err := store.Status(file)
+	if err != nil || status != Untrusted || entry != nil {
+		t.Fatalf("Status() = %v, %v, %v; want untrusted", status, entry, err)
+	}
+
+	if err := store.Trust(file); err != nil {
+		t.Fatalf("Trust() error: %v", err)
+	}
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	info, err := os.Stat(storePath)
+	if err != nil {
+		t.Fatalf("trust store not written: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("trust store permissions = %o, want 600", info.Mode().Perm())
+	}
+
+	// Reload from disk
+	store, err = LoadTrustStore(storePath)
+	if err != nil {
+		t.Fatalf("LoadTrustStore() error: %v", err)
+	}
+	if status, _, _ := store.Status(file); status != Trusted {
+		t.Errorf("Status() after trust = %v, want trusted", status)
+	}
+
+	// Relative and symlinked paths resolve to the same entry
+	link := filepath.Join(dir, "link.toml")
+	if err := os.Symlink(file, link); err != nil {
+		t.Fatal(err)
+	}
+	if status, _, _ := store.Status(link); status != Trusted {
+		t.Errorf("Status() via symlink = %v, want trusted", status)
+	}
+
+	if err := os.WriteFile(file, []byte("[tmux]\nallowed_commands = [\"curl\"]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	status, entry, err = store.Status(file)
+	if err != nil || status != Changed {
+		t.Fatalf("Status() after change = %v, %v; want changed", status, err)
+	}
+	if entry == nil || entry.Content != "[tmux]\n" {
+		t.Errorf("changed entry should keep the approved content, got %+v", entry)
+	}
+
+	if !store.Revoke(file) {
+		t.Error("Revoke() should report a removed entry")
+	}
+	if store.Revoke(file) {
+		t.Error("Revoke() of an untrusted file should return false")
+	}
+	if len(store.Files()) != 0 {
+		t.Errorf("Files() = %v, want empty", store.Files())
+	}
+}
+
+func TestTrustStore_TrustContent(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, RepoConfigFile)
+	if err := os.WriteFile(file, []byte("[tmux]\nallowed_commands = [\"curl\"]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := LoadTrustStore(filepath.Join(dir, "trust.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The reviewed bytes are pinned, not what the file holds now
+	reviewed := []byte("[tmux]\n")
+	if err := store.TrustContent(file, reviewed); err != nil {
+		t.Fatalf("TrustContent() error: %v", err)
+	}
+	if status, _, _ := store.ContentStatus(file, reviewed); status != Trusted {
+		t.Errorf("ContentStatus() of the reviewed bytes = %v, want trusted", status)
+	}
+	if status, _, _ := store.Status(file); status != Changed {
+		t.Errorf("Status() of the edited file = %v, want changed", status)
+	}
+}
+
+func TestLoadTrustStore_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trust.json")
+	if err := os.WriteFile(path, []byte("not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadTrustStore(path); err == nil {
+		t.Error("LoadTrustStore() should fail on invalid JSON")
+	}
+}
+
+func TestTrustStatusString(t *testing.T) {
+	tests := map[TrustStatus]string{
+		Untrusted: "untrusted",
+		Changed:   "changed",
+		Trusted:   "trusted",
+	}
+	for status, want := range tests {
+		if got := status.String(); got != want {
+			t.Errorf("%d.String() = %q, want %q", status, got, want)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package git
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ticketInTextPattern finds a ticket key (e.g., proj-123) inside a branch name
+// or path component. Digits followed by a dot are a version (release-2.4), not
+// a ticket number.
+var ticketInTextPattern = regexp.MustCompile(`(?:^|[^a-zA-Z0-9])(([a-zA-Z]+)-[0-9]+)(?:$|[^0-9.])`)
+
+// TicketFromWorktreePath derives the ticket from a worktree path laid out as
+// {repoRoot}/{type}/{ticket}. Returns an empty string if the path does not
+// follow that layout or its last component is not a ticket key.
+func TicketFromWorktreePath(repoRoot, worktreePath string) string {
+	if repoRoot == "" || worktreePath == "" {
+		return ""
+	}
+
+	// Normalize paths to handle symlink differences (e.g., /var vs /private/var on macOS)
+	if resolved, err := filepath.EvalSymlinks(repoRoot); err == nil {
+		repoRoot = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(worktreePath); err == nil {
+		worktreePath = resolved
+	}
+
+	rel, err := filepath.Rel(filepath.Clean(repoRoot), filepath.Clean(worktreePath))
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+
+	parts := strings.Split(rel, string(filepath.Separator))
+	if len(parts) < 2 {
+		return ""
+	}
+
+	return ticketFromText(parts[1])
+}
+
+// TicketFromBranch derives the ticket from a branch name such as
+// "proj-123", "feature/proj-123-add-auth" or "PROJ-123_fix". Only keys of
+// one of ticketTypes, compared case-insensitively, count, so that branches
+// like "v-1" or "winter-2025" aren't taken for tickets.
+func TicketFromBranch(branch string, ticketTypes []string) string {
+	// Each search restarts right after the previous key, so the separator
+	// that ends one key can start the next (release-1/proj-2)
+	for rest := branch; ; {
+		loc := ticketInTextPattern.FindStringSubmatchIndex(rest)
+		if loc == nil {
+			return ""
+		}
+		for _, ticketType := range ticketTypes {
+			if strings.EqualFold(rest[loc[4]:loc[5]], ticketType) {
+				return rest[loc[2]:loc[3]]
+			}
+		}
+		rest = rest[loc[3]:]
+	}
+}
+
+// ticketFromText returns the first ticket key found in text
+func ticketFromText(text string) string {
+	matches := ticketInTextPattern.FindStringSubmatch(text)
+	if len(matches) < 2 {
+		return ""
+	}
+	return matches[1]
+}
+
+// CommitMessageBody returns the commit message with git comment lines removed
+// and surrounding whitespace trimmed
+func CommitMessageBody(message string) string {
+	lines := strings.Split(message, "\n")
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
+
+// CommitMessageMatches reports whether the message (ignoring comments) matches pattern
+func CommitMessageMatches(message string, pattern *regexp.Regexp) bool {
+	return pattern.MatchString(CommitMessageBody(message))
+}
+
+// PrefixCommitMessage prepends prefix to the subject line of a commit message.
+// Comment lines are preserved; if the message has no subject yet the prefix is
+// placed on the first line so it is pre-filled in the editor.
+func PrefixCommitMessage(message, prefix string) string {
+	lines := strings.Split(message, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines[i] = prefix + line
+		return strings.Join(lines, "\n")
+	}
+
+	// No subject yet (e.g., a fresh message with only git's comment template)
+	if lines[0] == "" {
+		lines[0] = prefix
+		return strings.Join(lines, "\n")
+	}
+	return prefix + "\n" + message
+}
+
+// ConventionalCommit describes a conventional-commit message
+type ConventionalCommit struct {
+	Type        string // e.g., "feat", "fix"
+	Scope       string // Optional scope
+	Description string // Subject line description
+	Body        string // Optional body
+	Breaking    bool   // Marks a breaking change with "!"
+	Ticket      string // Ticket key, added as a "Refs:" footer unless already the scope
+}
+
+// String renders the conventional-commit message
+func (c ConventionalCommit) String() string {
+	var msg strings.Builder
+
+	msg.WriteString(c.Type)
+	if c.Scope != "" {
+		msg.WriteString(fmt.Sprintf("(%s)", c.Scope))
+	}
+	if c.Breaking {
+		msg.WriteString("!")
+	}
+	msg.WriteString(": ")
+	msg.WriteString(c.Description)
+
+	if c.Body != "" {
+		msg.WriteString("\n\n")
+		msg.WriteString(strings.TrimSpace(c.Body))
+	}
+
+	if c.Ticket != "" && c.Scope != c.Ticket {
+		msg.WriteString("\n\n")
+		msg.WriteString("Refs: " + c.Ticket)
+	}
+
+	return msg.String()
+}
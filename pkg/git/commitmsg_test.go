@@ -0,0 +1,162 @@
+package git
+
+import (
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestTicketFromWorktreePath(t *testing.T) {
+	tests := []struct {
+		name     string
+		repoRoot string
+		path     string
+		expected string
+	}{
+		{name: "ticket worktree", repoRoot: "/src/repo", path: "/src/repo/proj/proj-123", expected: "proj-123"},
+		{name: "release worktree", repoRoot: "/src/repo", path: "/src/repo/release/release-2.4", expected: ""},
+		{name: "subdirectory of worktree", repoRoot: "/src/repo", path: "/src/repo/ops/OPS-42/pkg/api", expected: "OPS-42"},
+		{name: "hack worktree", repoRoot: "/src/repo", path: "/src/repo/hack/experiment-auth", expected: ""},
+		{name: "repo root", repoRoot: "/src/repo", path: "/src/repo", expected: ""},
+		{name: "type directory only", repoRoot: "/src/repo", path: "/src/repo/proj", expected: ""},
+		{name: "outside repo", repoRoot: "/src/repo", path: "/src/other/proj/proj-1", expected: ""},
+		{name: "empty root", repoRoot: "", path: "/src/repo/proj/proj-1", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TicketFromWorktreePath(filepath.FromSlash(tt.repoRoot), filepath.FromSlash(tt.path))
+			if got != tt.expected {
+				t.Errorf("TicketFromWorktreePath(%q, %q) = %q, want %q", tt.repoRoot, tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTicketFromBranch(t *testing.T) {
+	types := []string{"proj", "ops"}
+	tests := []struct {
+		branch   string
+		expected string
+	}{
+		{branch: "proj-123", expected: "proj-123"},
+		{branch: "PROJ-123", expected: "PROJ-123"},
+		{branch: "feature/proj-123-add-auth", expected: "proj-123"},
+		{branch: "PROJ-123_fix", expected: "PROJ-123"},
+		{branch: "release-1/ops-9", expected: "ops-9"},
+		{branch: "main", expected: ""},
+		{branch: "release/2.4", expected: ""},
+		{branch: "release-2.4", expected: ""},
+		{branch: "proj-2.4", expected: ""},
+		{branch: "v-1", expected: ""},
+		{branch: "winter-2025", expected: ""},
+		{branch: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			if got := TicketFromBranch(tt.branch, types); got != tt.expected {
+				t.Errorf("TicketFromBranch(%q) = %q, want %q", tt.branch, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCommitMessageBody(t *testing.T) {
+	message := "Fix login\n\nDetails here\n# Please enter the commit message\n# On branch proj-1\n"
+	expected := "Fix login\n\nDetails here"
+
+	if got := CommitMessageBody(message); got != expected {
+		t.Errorf("CommitMessageBody() = %q, want %q", got, expected)
+	}
+}
+
+func TestCommitMessageMatches(t *testing.T) {
+	pattern := regexp.MustCompile(`[A-Z][A-Z0-9]*-[0-9]+`)
+
+	if !CommitMessageMatches("PROJ-1: fix login", pattern) {
+		t.Error("message with ticket key should match")
+	}
+	if CommitMessageMatches("fix login\n# On branch PROJ-1", pattern) {
+		t.Error("ticket key in a comment line should not count")
+	}
+}
+
+func TestPrefixCommitMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		expected string
+	}{
+		{
+			name:     "simple message",
+			message:  "fix login\n",
+			expected: "PROJ-1: fix login\n",
+		},
+		{
+			name:     "message with body and comments",
+			message:  "fix login\n\nbody\n# comment\n",
+			expected: "PROJ-1: fix login\n\nbody\n# comment\n",
+		},
+		{
+			name:     "editor template with no subject",
+			message:  "\n# Please enter the commit message\n",
+			expected: "PROJ-1: \n# Please enter the commit message\n",
+		},
+		{
+			name:     "leading comment before subject",
+			message:  "# comment\nfix login\n",
+			expected: "# comment\nPROJ-1: fix login\n",
+		},
+		{
+			name:     "only comments",
+			message:  "# comment\n",
+			expected: "PROJ-1: \n# comment\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PrefixCommitMessage(tt.message, "PROJ-1: "); got != tt.expected {
+				t.Errorf("PrefixCommitMessage() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConventionalCommitString(t *testing.T) {
+	tests := []struct {
+		name     string
+		commit   ConventionalCommit
+		expected string
+	}{
+		{
+			name:     "ticket as scope",
+			commit:   ConventionalCommit{Type: "feat", Scope: "PROJ-1", Description: "add login", Ticket: "PROJ-1"},
+			expected: "feat(PROJ-1): add login",
+		},
+		{
+			name:     "custom scope adds footer",
+			commit:   ConventionalCommit{Type: "fix", Scope: "auth", Description: "handle empty token", Ticket: "PROJ-1"},
+			expected: "fix(auth): handle empty token\n\nRefs: PROJ-1",
+		},
+		{
+			name:     "breaking with body",
+			commit:   ConventionalCommit{Type: "feat", Scope: "PROJ-1", Description: "drop v1 API", Body: "Clients must use v2.\n", Breaking: true, Ticket: "PROJ-1"},
+			expected: "feat(PROJ-1)!: drop v1 API\n\nClients must use v2.",
+		},
+		{
+			name:     "no scope or ticket",
+			commit:   ConventionalCommit{Type: "chore", Description: "tidy"},
+			expected: "chore: tidy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.commit.String(); got != tt.expected {
+				t.Errorf("String() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+// hookMarker identifies hook scripts installed by rig so they can be
+// updated or removed without touching user-authored hooks
+const hookMarker = "# Installed by rig"
+
+// ManagedHooks lists the git hooks rig installs
+var ManagedHooks = []string{"prepare-commit-msg", "commit-msg"}
+
+// HooksDir returns the hooks directory of the bare repository.
+// Hooks live in the shared git directory so every worktree picks them up.
+func (wm *WorktreeManager) HooksDir() (string, error) {
+	repoRoot, err := wm.GetRepoRoot()
+	if err != nil {
+		return "", err
+	}
+
+	// Respect core.hooksPath when it is an absolute path; a relative value is
+	// resolved per-worktree by git, which rig cannot manage consistently
+	output, err := wm.runner.Output(repoRoot, "git", "config", "--get", "core.hooksPath")
+	if err == nil {
+		hooksPath := strings.TrimSpace(string(output))
+		if hooksPath != "" {
+			if !filepath.IsAbs(hooksPath) {
+				return "", errors.Newf("core.hooksPath is set to relative path %q; set an absolute path or unset it to use rig hooks", hooksPath)
+			}
+			return hooksPath, nil
+		}
+	}
+
+	return filepath.Join(repoRoot, "hooks"), nil
+}
+
+// InstallHooks writes the managed hook scripts into the repository hooks directory.
+// rigPath is the executable the hooks call back into. Existing hooks that were not
+// installed by rig are left alone unless force is set.
+func (wm *WorktreeManager) InstallHooks(rigPath string, force bool) ([]string, error) {
+	hooksDir, err := wm.HooksDir()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		return nil, errors.Wrap(err, "failed to create hooks directory")
+	}
+
+	var installed []string
+	for _, name := range ManagedHooks {
+		hookPath := filepath.Join(hooksDir, name)
+
+		if !force && !isRigHook(hookPath) {
+			if _, err := os.Stat(hookPath); err == nil {
+				return installed, errors.Newf("hook %s already exists and was not installed by rig (use --force to overwrite)", hookPath)
+			}
+		}
+
+		//nolint:gosec // G306: hooks must be executable
+		if err := os.WriteFile(hookPath, []byte(hookScript(rigPath, name)), 0755); err != nil {
+			return installed, errors.Wrapf(err, "failed to write hook %s", name)
+		}
+
+		if wm.Verbose {
+			fmt.Printf("Installed hook: %s\n", hookPath)
+		}
+		installed = append(installed, hookPath)
+	}
+
+	return installed, nil
+}
+
+// UninstallHooks removes managed hook scripts that were installed by rig
+func (wm *WorktreeManager) UninstallHooks() ([]string, error) {
+	hooksDir, err := wm.HooksDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for _, name := range ManagedHooks {
+		hookPath := filepath.Join(hooksDir, name)
+		if !isRigHook(hookPath) {
+			continue
+		}
+
+		if err := os.Remove(hookPath); err != nil {
+			return removed, errors.Wrapf(err, "failed to remove hook %s", name)
+		}
+
+		if wm.Verbose {
+			fmt.Printf("Removed hook: %s\n", hookPath)
+		}
+		removed = append(removed, hookPath)
+	}
+
+	return removed, nil
+}
+
+// isRigHook reports whether the file at path is a hook installed by rig
+func isRigHook(path string) bool {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(content), hookMarker)
+}
+
+// hookScript returns the shell script for a managed hook.
+// The script delegates to `rig hooks run` so that all logic and configuration
+// stays in rig; commits still succeed if rig is not on PATH.
+func hookScript(rigPath, name string) string {
+	return fmt.Sprintf(`#!/bin/sh
+%s. Re-run 'rig hooks install' to update.
+RIG=%s
+if ! command -v "$RIG" >/dev/null 2>&1; then
+	exit 0
+fi
+exec "$RIG" hooks run %s "$@"
+`, hookMarker, shellQuote(rigPath), name)
+}
+
+// shellQuote quotes s for a POSIX shell, in single quotes so that $, `
+// and \ are taken literally
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,166 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newHooksTestManager returns a WorktreeManager whose repo root is a temp directory
+func newHooksTestManager(t *testing.T, hooksPath string) (*WorktreeManager, string) {
+	t.Helper()
+
+	repoRoot := t.TempDir()
+	mock := &MockCommandRunner{
+		OutputFunc: func(dir string, name string, args ...string) ([]byte, error) {
+			if len(args) > 1 && args[0] == "rev-parse" && args[1] == "--git-common-dir" {
+				return []byte(repoRoot + "\n"), nil
+			}
+			if len(args) > 2 && args[0] == "config" && args[2] == "core.hooksPath" {
+				if hooksPath == "" {
+					return nil, errors.New("exit status 1")
+				}
+				return []byte(hooksPath + "\n"), nil
+			}
+			return []byte{}, nil
+		},
+	}
+
+	return NewWorktreeManagerWithRunner("", false, mock), repoRoot
+}
+
+func TestHooksDir_Default(t *testing.T) {
+	wm, repoRoot := newHooksTestManager(t, "")
+
+	dir, err := wm.HooksDir()
+	if err != nil {
+		t.Fatalf("HooksDir() error: %v", err)
+	}
+	if dir != filepath.Join(repoRoot, "hooks") {
+		t.Errorf("HooksDir() = %q, want %q", dir, filepath.Join(repoRoot, "hooks"))
+	}
+}
+
+func TestHooksDir_CoreHooksPath(t *testing.T) {
+	wm, _ := newHooksTestManager(t, "/etc/git-hooks")
+
+	dir, err := wm.HooksDir()
+	if err != nil {
+		t.Fatalf("HooksDir() error: %v", err)
+	}
+	if dir != "/etc/git-hooks" {
+		t.Errorf("HooksDir() = %q, want %q", dir, "/etc/git-hooks")
+	}
+
+	wm, _ = newHooksTestManager(t, ".githooks")
+	if _, err := wm.HooksDir(); err == nil {
+		t.Error("HooksDir() should reject a relative core.hooksPath")
+	}
+}
+
+func TestInstallHooks(t *testing.T) {
+	wm, repoRoot := newHooksTestManager(t, "")
+
+	installed, err := wm.InstallHooks("/usr/local/bin/rig", false)
+	if err != nil {
+		t.Fatalf("InstallHooks() error: %v", err)
+	}
+	if len(installed) != len(ManagedHooks) {
+		t.Fatalf("InstallHooks() installed %d hooks, want %d", len(installed), len(ManagedHooks))
+	}
+
+	for _, name := range ManagedHooks {
+		hookPath := filepath.Join(repoRoot, "hooks", name)
+		info, err := os.Stat(hookPath)
+		if err != nil {
+			t.Fatalf("hook %s not written: %v", name, err)
+		}
+		if info.Mode().Perm()&0100 == 0 {
+			t.Errorf("hook %s should be executable, mode %v", name, info.Mode())
+		}
+
+		content, _ := os.ReadFile(hookPath)
+		if !strings.Contains(string(content), "hooks run "+name) {
+			t.Errorf("hook %s should delegate to 'rig hooks run', got:\n%s", name, content)
+		}
+		if !strings.Contains(string(content), "RIG='/usr/local/bin/rig'\n") {
+			t.Errorf("hook %s should reference the rig executable, got:\n%s", name, content)
+		}
+	}
+
+	// Re-installing over rig hooks is allowed without --force
+	if _, err := wm.InstallHooks("/usr/local/bin/rig", false); err != nil {
+		t.Errorf("InstallHooks() should update existing rig hooks: %v", err)
+	}
+}
+
+func TestHookScript_Quoting(t *testing.T) {
+	rigPath := "/opt/it's $HOME/`id`/back\\slash/rig"
+
+	var assignment string
+	for _, line := range strings.Split(hookScript(rigPath, "post-checkout"), "\n") {
+		if strings.HasPrefix(line, "RIG=") {
+			assignment = line
+		}
+	}
+
+	output, err := exec.Command("sh", "-c", assignment+`; printf '%s' "$RIG"`).Output()
+	if err != nil {
+		t.Fatalf("sh failed: %v", err)
+	}
+	if string(output) != rigPath {
+		t.Errorf("sh read RIG as %q, want %q", output, rigPath)
+	}
+}
+
+func TestInstallHooks_ExistingUserHook(t *testing.T) {
+	wm, repoRoot := newHooksTestManager(t, "")
+
+	hooksDir := filepath.Join(repoRoot, "hooks")
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	userHook := filepath.Join(hooksDir, "commit-msg")
+	if err := os.WriteFile(userHook, []byte("#!/bin/sh\nexit 0\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := wm.InstallHooks("rig", false); err == nil {
+		t.Error("InstallHooks() should refuse to overwrite a user hook")
+	}
+
+	if _, err := wm.InstallHooks("rig", true); err != nil {
+		t.Fatalf("InstallHooks(force) error: %v", err)
+	}
+	if !isRigHook(userHook) {
+		t.Error("InstallHooks(force) should overwrite the user hook")
+	}
+}
+
+func TestUninstallHooks(t *testing.T) {
+	wm, repoRoot := newHooksTestManager(t, "")
+
+	if _, err := wm.InstallHooks("rig", false); err != nil {
+		t.Fatalf("InstallHooks() error: %v", err)
+	}
+
+	// A user-authored hook must survive uninstall
+	userHook := filepath.Join(repoRoot, "hooks", "pre-push")
+	if err := os.WriteFile(userHook, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := wm.UninstallHooks()
+	if err != nil {
+		t.Fatalf("UninstallHooks() error: %v", err)
+	}
+	if len(removed) != len(ManagedHooks) {
+		t.Errorf("UninstallHooks() removed %d hooks, want %d", len(removed), len(ManagedHooks))
+	}
+	if _, err := os.Stat(userHook); err != nil {
+		t.Error("UninstallHooks() should not remove hooks it did not install")
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/cockroachdb/errors"
@@ -47,6 +49,7 @@ type WorktreeManager struct {
 	BaseBranchConfig string // Optional config override for base branch
 	runner           CommandRunner
 	getwd            func() (string, error) // For testing; defaults to os.Getwd
+	defaultBranch    string                 // Cached result of GetDefaultBranch
 }
 
 // NewWorktreeManager creates a new WorktreeManager
@@ -106,7 +109,22 @@ func (wm *WorktreeManager) GetRepoName() (string, error) {
 
 // GetDefaultBranch determines the default branch to use for new worktrees
 // Priority: config override > remote HEAD > main > master > first remote branch
+// The result is cached, so commands that compare many worktrees detect it once.
 func (wm *WorktreeManager) GetDefaultBranch() (string, error) {
+	if wm.defaultBranch != "" {
+		return wm.defaultBranch, nil
+	}
+
+	branch, err := wm.detectDefaultBranch()
+	if err != nil {
+		return "", err
+	}
+	wm.defaultBranch = branch
+	return branch, nil
+}
+
+// detectDefaultBranch implements GetDefaultBranch
+func (wm *WorktreeManager) detectDefaultBranch() (string, error) {
 	repoRoot, err := wm.GetRepoRoot()
 	if err != nil {
 		return "", err
@@ -161,6 +179,14 @@ func (wm *WorktreeManager) CreateWorktree(ticketType, ticket string) (string, er
 
 // CreateWorktreeWithBranch creates a new git worktree with a custom branch name
 func (wm *WorktreeManager) CreateWorktreeWithBranch(ticketType, name, branchName string) (string, error) {
+	return wm.CreateWorktreeFrom(ticketType, name, branchName, "")
+}
+
+// CreateWorktreeFrom creates a new git worktree whose branch starts from
+// baseBranch. An empty baseBranch uses GetDefaultBranch. An explicit base is
+// recorded in the branch config (see BranchBase) so later merge checks and
+// status output compare against it rather than the default branch.
+func (wm *WorktreeManager) CreateWorktreeFrom(ticketType, name, branchName, baseBranch string) (string, error) {
 	repoRoot, err := wm.GetRepoRoot()
 	if err != nil {
 		return "", err
@@ -181,19 +207,26 @@ func (wm *WorktreeManager) CreateWorktreeWithBranch(ticketType, name, branchName
 
 	// Check if worktree already exists
 	if _, err := os.Stat(worktreePath); err == nil {
-		if wm.Verbose {
+		// Its branch already has a start point, which can't be moved here
+		if baseBranch != "" {
+			fmt.Printf("Warning: worktree already exists at %s; base branch %s ignored\n", worktreePath, baseBranch)
+		} else if wm.Verbose {
 			fmt.Printf("Worktree already exists at %s\n", worktreePath)
 		}
 		return worktreePath, nil
 	}
 
 	// Determine base branch to use
-	baseBranch, err := wm.GetDefaultBranch()
-	if err != nil {
-		return "", errors.Wrap(err, "failed to determine base branch")
+	explicitBase := baseBranch != ""
+	if !explicitBase {
+		baseBranch, err = wm.GetDefaultBranch()
+		if err != nil {
+			return "", errors.Wrap(err, "failed to determine base branch")
+		}
 	}
 
-	// Fetch and pull latest changes before creating worktree
+	// Fetch and pull latest changes before creating worktree, so an explicit
+	// base that only exists on origin can be found
 	if err := wm.fetchAndPull(repoRoot, baseBranch); err != nil {
 		// Log warning but don't fail - repo might be offline or have no remote
 		if wm.Verbose {
@@ -201,20 +234,96 @@ func (wm *WorktreeManager) CreateWorktreeWithBranch(ticketType, name, branchName
 		}
 	}
 
+	args := []string{"worktree", "add", filepath.Join(ticketType, name), "-b", branchName}
+	startPoint := baseBranch
+	if explicitBase {
+		var ok bool
+		startPoint, ok = wm.resolveBranch(repoRoot, baseBranch)
+		if !ok {
+			return "", errors.Newf("base branch not found: %s", baseBranch)
+		}
+		// A branch started from origin/<base> must not push to or pull from it
+		if startPoint != baseBranch {
+			args = append(args, "--no-track")
+		}
+	}
+
 	if wm.Verbose {
-		fmt.Printf("Creating git worktree for %s using base branch %s...\n", name, baseBranch)
+		fmt.Printf("Creating git worktree for %s using base branch %s...\n", name, startPoint)
 	}
 
 	// Create the worktree with custom branch name
-	relativePath := filepath.Join(ticketType, name)
-	err = wm.runner.Run(repoRoot, "git", "worktree", "add", relativePath, "-b", branchName, baseBranch)
+	err = wm.runner.Run(repoRoot, "git", append(args, startPoint)...)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to create worktree")
 	}
 
+	if explicitBase {
+		if err := wm.SetBranchBase(branchName, baseBranch); err != nil {
+			return "", err
+		}
+	}
+
 	return worktreePath, nil
 }
 
+// branchBaseKey returns the git config key recording a branch's base branch
+func branchBaseKey(branch string) string {
+	return "branch." + branch + ".rig-base"
+}
+
+// SetBranchBase records baseBranch as the parent of branch in the repository config
+func (wm *WorktreeManager) SetBranchBase(branch, baseBranch string) error {
+	repoRoot, err := wm.GetRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	if err := wm.runner.Run(repoRoot, "git", "config", branchBaseKey(branch), baseBranch); err != nil {
+		return errors.Wrapf(err, "failed to record base branch for %s", branch)
+	}
+
+	return nil
+}
+
+// BranchBase returns the base branch recorded for branch, or "" if none was recorded
+func (wm *WorktreeManager) BranchBase(branch string) string {
+	if branch == "" {
+		return ""
+	}
+
+	repoRoot, err := wm.GetRepoRoot()
+	if err != nil {
+		return ""
+	}
+
+	output, err := wm.runner.Output(repoRoot, "git", "config", "--get", branchBaseKey(branch))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// BaseBranchFor returns the branch that branch should be compared against:
+// its recorded base if that still exists, locally or as origin/<base>,
+// otherwise the default branch
+func (wm *WorktreeManager) BaseBranchFor(branch string) (string, error) {
+	if base := wm.BranchBase(branch); base != "" {
+		repoRoot, err := wm.GetRepoRoot()
+		if err != nil {
+			return "", err
+		}
+		if ref, ok := wm.resolveBranch(repoRoot, base); ok {
+			return ref, nil
+		}
+		if wm.Verbose {
+			fmt.Printf("Warning: recorded base branch %q for %s not found, using default branch\n", base, branch)
+		}
+	}
+
+	return wm.GetDefaultBranch()
+}
+
 // ensureFetchRefspec ensures the fetch refspec is configured for the origin remote.
 // Bare repos created with `git clone --bare` don't have this configured by default,
 // which causes `git fetch` to not download remote-tracking branches.
@@ -275,6 +384,19 @@ func (wm *WorktreeManager) branchExists(repoRoot, branch string) bool {
 	return err == nil
 }
 
+// resolveBranch returns branch if it exists locally, else origin/<branch>
+// if it only exists on origin. Reports false if neither exists.
+func (wm *WorktreeManager) resolveBranch(repoRoot, branch string) (string, bool) {
+	if wm.branchExists(repoRoot, branch) {
+		return branch, true
+	}
+	err := wm.runner.Run(repoRoot, "git", "show-ref", "--verify", "--quiet", "refs/remotes/origin/"+branch)
+	if err == nil {
+		return "origin/" + branch, true
+	}
+	return "", false
+}
+
 // getFirstRemoteBranch gets the first available remote branch
 func (wm *WorktreeManager) getFirstRemoteBranch(repoRoot string) (string, error) {
 	output, err := wm.runner.Output(repoRoot, "git", "branch", "-r")
@@ -362,3 +484,211 @@ func (wm *WorktreeManager) RemoveWorktree(ticketType, ticket string) error {
 	relativePath := filepath.Join(ticketType, ticket)
 	return wm.runner.Run(repoRoot, "git", "worktree", "remove", relativePath)
 }
+
+// CurrentWorktree returns the top-level directory of the worktree containing
+// the current working directory
+func (wm *WorktreeManager) CurrentWorktree() (string, error) {
+	output, err := wm.runner.Output(".", "git", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", errors.New("not inside a git worktree")
+	}
+	return filepath.Clean(strings.TrimSpace(string(output))), nil
+}
+
+// CurrentBranch returns the branch checked out in the current worktree
+func (wm *WorktreeManager) CurrentBranch() (string, error) {
+	output, err := wm.runner.Output(".", "git", "symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return "", errors.New("no branch checked out (detached HEAD)")
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// DetectTicket derives the ticket for the current worktree from its path
+// layout under the repo root, falling back to the current branch name.
+// Branch names only yield tickets of a type the repo has worktrees for.
+// Returns an empty string if no ticket can be determined.
+func (wm *WorktreeManager) DetectTicket() string {
+	repoRoot, err := wm.GetRepoRoot()
+	if err != nil {
+		return ""
+	}
+	if worktree, err := wm.CurrentWorktree(); err == nil {
+		if ticket := TicketFromWorktreePath(repoRoot, worktree); ticket != "" {
+			return ticket
+		}
+	}
+
+	if branch, err := wm.CurrentBranch(); err == nil {
+		return TicketFromBranch(branch, wm.ticketTypes(repoRoot))
+	}
+
+	return ""
+}
+
+// ticketTypes returns the ticket types of the repo's ticket worktrees, laid
+// out as {repoRoot}/{type}/{ticket}
+func (wm *WorktreeManager) ticketTypes(repoRoot string) []string {
+	worktrees, err := wm.ListWorktrees()
+	if err != nil {
+		return nil
+	}
+
+	var types []string
+	for _, worktree := range worktrees {
+		ticket := TicketFromWorktreePath(repoRoot, worktree)
+		if ticket == "" {
+			continue
+		}
+		ticketType := filepath.Base(filepath.Dir(filepath.Clean(worktree)))
+		if !slices.Contains(types, ticketType) {
+			types = append(types, ticketType)
+		}
+	}
+	return types
+}
+
+// BranchForWorktree returns the branch checked out in the given worktree
+func (wm *WorktreeManager) BranchForWorktree(worktreePath string) (string, error) {
+	output, err := wm.runner.Output(worktreePath, "git", "symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return "", errors.Wrapf(err, "no branch checked out in %s", worktreePath)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// IsDirty reports whether the worktree has uncommitted changes, including
+// untracked files
+func (wm *WorktreeManager) IsDirty(worktreePath string) (bool, error) {
+	output, err := wm.runner.Output(worktreePath, "git", "status", "--porcelain")
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get status of %s", worktreePath)
+	}
+	return strings.TrimSpace(string(output)) != "", nil
+}
+
+// MoveWorktree moves the worktree at {repo}/{oldType}/{oldName} to
+// {repo}/{newType}/{newName} and returns the new path
+func (wm *WorktreeManager) MoveWorktree(oldType, oldName, newType, newName string) (string, error) {
+	repoRoot, err := wm.GetRepoRoot()
+	if err != nil {
+		return "", err
+	}
+
+	oldPath := filepath.Join(repoRoot, oldType, oldName)
+	newPath := filepath.Join(repoRoot, newType, newName)
+
+	// Validate paths stay within repo root (prevent path traversal)
+	for _, path := range []string{oldPath, newPath} {
+		if !strings.HasPrefix(path, repoRoot+string(filepath.Separator)) {
+			return "", errors.New("invalid path: worktree path escapes repository root")
+		}
+	}
+
+	if _, err := os.Stat(oldPath); os.IsNotExist(err) {
+		return "", errors.Newf("worktree does not exist: %s", oldPath)
+	}
+	if _, err := os.Stat(newPath); err == nil {
+		return "", errors.Newf("target worktree already exists: %s", newPath)
+	}
+
+	// Create type directory if it doesn't exist
+	if err := os.MkdirAll(filepath.Join(repoRoot, newType), 0755); err != nil {
+		return "", errors.Wrap(err, "failed to create type directory")
+	}
+
+	if wm.Verbose {
+		fmt.Printf("Moving worktree %s to %s...\n", oldPath, newPath)
+	}
+
+	err = wm.runner.Run(repoRoot, "git", "worktree", "move", filepath.Join(oldType, oldName), filepath.Join(newType, newName))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to move worktree")
+	}
+
+	return newPath, nil
+}
+
+// RenameBranch renames a local branch
+func (wm *WorktreeManager) RenameBranch(oldBranch, newBranch string) error {
+	repoRoot, err := wm.GetRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	if wm.branchExists(repoRoot, newBranch) {
+		return errors.Newf("branch already exists: %s", newBranch)
+	}
+
+	if wm.Verbose {
+		fmt.Printf("Renaming branch %s to %s...\n", oldBranch, newBranch)
+	}
+
+	if err := wm.runner.Run(repoRoot, "git", "branch", "-m", oldBranch, newBranch); err != nil {
+		return errors.Wrap(err, "failed to rename branch")
+	}
+
+	// git moves the branch's own config section; branches stacked on it
+	// still name the old branch as their base
+	for _, child := range wm.stackedBranches(repoRoot, oldBranch) {
+		if err := wm.runner.Run(repoRoot, "git", "config", branchBaseKey(child), newBranch); err != nil {
+			return errors.Wrapf(err, "failed to update base branch for %s", child)
+		}
+	}
+
+	return nil
+}
+
+// stackedBranches returns the branches whose recorded base is baseBranch
+func (wm *WorktreeManager) stackedBranches(repoRoot, baseBranch string) []string {
+	output, err := wm.runner.Output(repoRoot, "git", "config", "--get-regexp", `^branch\..*\.rig-base$`)
+	if err != nil {
+		return nil
+	}
+
+	var children []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		key, value, found := strings.Cut(line, " ")
+		if !found || strings.TrimSpace(value) != baseBranch {
+			continue
+		}
+		branch := strings.TrimSuffix(strings.TrimPrefix(key, "branch."), ".rig-base")
+		children = append(children, branch)
+	}
+	return children
+}
+
+// AheadBehind returns how many commits branch is ahead of and behind baseBranch
+func (wm *WorktreeManager) AheadBehind(branch, baseBranch string) (ahead, behind int, err error) {
+	repoRoot, err := wm.GetRepoRoot()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	output, err := wm.runner.Output(repoRoot, "git", "rev-list", "--left-right", "--count", baseBranch+"..."+branch)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "failed to compare %s with %s", branch, baseBranch)
+	}
+
+	fields := strings.Fields(string(output))
+	if len(fields) != 2 {
+		return 0, 0, errors.Newf("unexpected rev-list output: %q", strings.TrimSpace(string(output)))
+	}
+	if behind, err = strconv.Atoi(fields[0]); err != nil {
+		return 0, 0, errors.Wrap(err, "invalid behind count")
+	}
+	if ahead, err = strconv.Atoi(fields[1]); err != nil {
+		return 0, 0, errors.Wrap(err, "invalid ahead count")
+	}
+
+	return ahead, behind, nil
+}
+
+// BranchExists reports whether a local branch exists in the repository
+func (wm *WorktreeManager) BranchExists(branch string) bool {
+	repoRoot, err := wm.GetRepoRoot()
+	if err != nil {
+		return false
+	}
+	return wm.branchExists(repoRoot, branch)
+}
@@ -2,6 +2,8 @@ package git
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -1161,3 +1163,483 @@ func TestPathTraversalEdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestDetectTicket(t *testing.T) {
+	tests := []struct {
+		name     string
+		toplevel string
+		branch   string
+		expected string
+	}{
+		{name: "from worktree path", toplevel: "/src/repo/proj/proj-123", branch: "something-else", expected: "proj-123"},
+		{name: "falls back to branch", toplevel: "/src/repo/hack/spike", branch: "feature/ops-9-spike", expected: "ops-9"},
+		{name: "no ticket", toplevel: "/src/repo/hack/spike", branch: "main", expected: ""},
+		{name: "unknown type", toplevel: "/src/repo/hack/spike", branch: "v-1", expected: ""},
+		{name: "version", toplevel: "/src/repo/hack/spike", branch: "ops-2.4", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCommandRunner{
+				OutputFunc: func(dir string, name string, args ...string) ([]byte, error) {
+					switch {
+					case len(args) > 1 && args[1] == "--git-common-dir":
+						return []byte("/src/repo\n"), nil
+					case len(args) > 1 && args[1] == "--show-toplevel":
+						return []byte(tt.toplevel + "\n"), nil
+					case len(args) > 0 && args[0] == "symbolic-ref":
+						return []byte(tt.branch + "\n"), nil
+					case len(args) > 1 && args[0] == "worktree" && args[1] == "list":
+						return []byte("worktree /src/repo\nbare\n\nworktree /src/repo/ops/ops-1\nbranch refs/heads/ops-1\n\nworktree /src/repo/hack/spike\nbranch refs/heads/spike\n"), nil
+					}
+					return []byte{}, nil
+				},
+			}
+			wm := NewWorktreeManagerWithRunner("", false, mock)
+
+			if got := wm.DetectTicket(); got != tt.expected {
+				t.Errorf("DetectTicket() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMoveWorktree(t *testing.T) {
+	repoRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repoRoot, "hack", "spike"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	mock := &MockCommandRunner{
+		OutputFunc: func(dir string, name string, args ...string) ([]byte, error) {
+			if len(args) > 1 && args[1] == "--git-common-dir" {
+				return []byte(repoRoot + "\n"), nil
+			}
+			return []byte{}, nil
+		},
+	}
+	wm := NewWorktreeManagerWithRunner("", false, mock)
+
+	newPath, err := wm.MoveWorktree("hack", "spike", "proj", "proj-1")
+	if err != nil {
+		t.Fatalf("MoveWorktree() error: %v", err)
+	}
+	if newPath != filepath.Join(repoRoot, "proj", "proj-1") {
+		t.Errorf("MoveWorktree() = %q", newPath)
+	}
+
+	var moveCall *MockCall
+	for i := range mock.Calls {
+		if mock.Calls[i].Method == "Run" && len(mock.Calls[i].Args) > 1 && mock.Calls[i].Args[1] == "move" {
+			moveCall = &mock.Calls[i]
+		}
+	}
+	if moveCall == nil {
+		t.Fatal("expected git worktree move to be called")
+	}
+	expected := []string{"worktree", "move", filepath.Join("hack", "spike"), filepath.Join("proj", "proj-1")}
+	if strings.Join(moveCall.Args, " ") != strings.Join(expected, " ") {
+		t.Errorf("git args = %v, want %v", moveCall.Args, expected)
+	}
+	if moveCall.Dir != repoRoot {
+		t.Errorf("git dir = %q, want %q", moveCall.Dir, repoRoot)
+	}
+}
+
+func TestMoveWorktree_Errors(t *testing.T) {
+	repoRoot := t.TempDir()
+	for _, dir := range []string{filepath.Join("hack", "spike"), filepath.Join("proj", "proj-1")} {
+		if err := os.MkdirAll(filepath.Join(repoRoot, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mock := &MockCommandRunner{
+		OutputFunc: func(dir string, name string, args ...string) ([]byte, error) {
+			return []byte(repoRoot + "\n"), nil
+		},
+	}
+	wm := NewWorktreeManagerWithRunner("", false, mock)
+
+	if _, err := wm.MoveWorktree("hack", "missing", "proj", "proj-2"); err == nil {
+		t.Error("MoveWorktree() should fail for a missing worktree")
+	}
+	if _, err := wm.MoveWorktree("hack", "spike", "proj", "proj-1"); err == nil {
+		t.Error("MoveWorktree() should fail when the target exists")
+	}
+	if _, err := wm.MoveWorktree("hack", "spike", "..", "escape"); err == nil {
+		t.Error("MoveWorktree() should reject path traversal")
+	}
+}
+
+func TestRenameBranch(t *testing.T) {
+	mock := &MockCommandRunner{
+		OutputFunc: func(dir string, name string, args ...string) ([]byte, error) {
+			return []byte("/src/repo\n"), nil
+		},
+		RunFunc: func(dir string, name string, args ...string) error {
+			// show-ref fails: target branch doesn't exist yet
+			if len(args) > 0 && args[0] == "show-ref" {
+				return errors.New("not found")
+			}
+			return nil
+		},
+	}
+	wm := NewWorktreeManagerWithRunner("", false, mock)
+
+	if err := wm.RenameBranch("spike", "proj-1"); err != nil {
+		t.Fatalf("RenameBranch() error: %v", err)
+	}
+
+	renamed := false
+	for _, call := range mock.Calls {
+		if call.Method == "Run" && strings.Join(call.Args, " ") == "branch -m spike proj-1" {
+			renamed = true
+		}
+	}
+	if !renamed {
+		t.Error("expected git branch -m spike proj-1 to be called")
+	}
+
+	// Existing target branch is rejected
+	mock.RunFunc = nil
+	if err := wm.RenameBranch("spike", "proj-1"); err == nil {
+		t.Error("RenameBranch() should fail when the target branch exists")
+	}
+}
+
+func TestCreateWorktreeFrom_RecordsBase(t *testing.T) {
+	repoRoot := t.TempDir()
+
+	mock := &MockCommandRunner{
+		OutputFunc: func(dir string, name string, args ...string) ([]byte, error) {
+			if len(args) > 1 && args[0] == "rev-parse" && args[1] == "--git-common-dir" {
+				return []byte(repoRoot + "\n"), nil
+			}
+			return []byte{}, nil
+		},
+	}
+	wm := NewWorktreeManagerWithRunner("", false, mock)
+
+	if _, err := wm.CreateWorktreeFrom("proj", "proj-124", "proj-124", "proj-123"); err != nil {
+		t.Fatalf("CreateWorktreeFrom() error: %v", err)
+	}
+
+	var addArgs, configArgs []string
+	for _, call := range mock.Calls {
+		if call.Method != "Run" || len(call.Args) < 2 {
+			continue
+		}
+		if call.Args[0] == "worktree" && call.Args[1] == "add" {
+			addArgs = call.Args
+		}
+		if call.Args[0] == "config" && call.Args[1] == "branch.proj-124.rig-base" {
+			configArgs = call.Args
+		}
+	}
+
+	if strings.Join(addArgs, " ") != "worktree add "+filepath.Join("proj", "proj-124")+" -b proj-124 proj-123" {
+		t.Errorf("worktree add args = %v", addArgs)
+	}
+	if strings.Join(configArgs, " ") != "config branch.proj-124.rig-base proj-123" {
+		t.Errorf("base not recorded, config args = %v", configArgs)
+	}
+}
+
+func TestCreateWorktreeFrom_MissingBase(t *testing.T) {
+	repoRoot := t.TempDir()
+
+	mock := &MockCommandRunner{
+		OutputFunc: func(dir string, name string, args ...string) ([]byte, error) {
+			return []byte(repoRoot + "\n"), nil
+		},
+		RunFunc: func(dir string, name string, args ...string) error {
+			if len(args) > 0 && args[0] == "show-ref" {
+				return errors.New("not found")
+			}
+			return nil
+		},
+	}
+	wm := NewWorktreeManagerWithRunner("", false, mock)
+
+	_, err := wm.CreateWorktreeFrom("proj", "proj-1", "proj-1", "release/9.9")
+	if err == nil || !strings.Contains(err.Error(), "base branch not found") {
+		t.Errorf("CreateWorktreeFrom() error = %v, want base branch not found", err)
+	}
+}
+
+func TestCreateWorktreeFrom_RemoteBase(t *testing.T) {
+	repoRoot := t.TempDir()
+
+	fetched := false
+	mock := &MockCommandRunner{
+		OutputFunc: func(dir string, name string, args ...string) ([]byte, error) {
+			if len(args) > 1 && args[0] == "rev-parse" && args[1] == "--git-common-dir" {
+				return []byte(repoRoot + "\n"), nil
+			}
+			return []byte{}, nil
+		},
+		RunFunc: func(dir string, name string, args ...string) error {
+			switch {
+			case len(args) > 0 && args[0] == "fetch":
+				fetched = true
+			case len(args) > 3 && args[0] == "show-ref":
+				// The release branch only exists on origin, once fetched
+				if args[3] == "refs/heads/release/2.4" || !fetched {
+					return errors.New("not found")
+				}
+			}
+			return nil
+		},
+	}
+	wm := NewWorktreeManagerWithRunner("", false, mock)
+
+	if _, err := wm.CreateWorktreeFrom("proj", "proj-7", "proj-7", "release/2.4"); err != nil {
+		t.Fatalf("CreateWorktreeFrom() error: %v", err)
+	}
+
+	var addArgs, configArgs []string
+	for _, call := range mock.Calls {
+		if call.Method != "Run" || len(call.Args) < 2 {
+			continue
+		}
+		if call.Args[0] == "worktree" && call.Args[1] == "add" {
+			addArgs = call.Args
+		}
+		if call.Args[0] == "config" && call.Args[1] == "branch.proj-7.rig-base" {
+			configArgs = call.Args
+		}
+	}
+
+	if strings.Join(addArgs, " ") != "worktree add "+filepath.Join("proj", "proj-7")+" -b proj-7 --no-track origin/release/2.4" {
+		t.Errorf("worktree add args = %v", addArgs)
+	}
+	if strings.Join(configArgs, " ") != "config branch.proj-7.rig-base release/2.4" {
+		t.Errorf("base not recorded, config args = %v", configArgs)
+	}
+}
+
+func TestCreateWorktreeFrom_ExistingWorktree(t *testing.T) {
+	repoRoot := t.TempDir()
+	worktreePath := filepath.Join(repoRoot, "proj", "proj-7")
+	if err := os.MkdirAll(worktreePath, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	mock := &MockCommandRunner{
+		OutputFunc: func(dir string, name string, args ...string) ([]byte, error) {
+			return []byte(repoRoot + "\n"), nil
+		},
+	}
+	wm := NewWorktreeManagerWithRunner("", false, mock)
+
+	got, err := wm.CreateWorktreeFrom("proj", "proj-7", "proj-7", "release/2.4")
+	if err != nil || got != worktreePath {
+		t.Fatalf("CreateWorktreeFrom() = %q, %v; want the existing worktree", got, err)
+	}
+	for _, call := range mock.Calls {
+		if call.Method == "Run" && len(call.Args) > 1 && (call.Args[0] == "worktree" || call.Args[0] == "config") {
+			t.Errorf("an existing worktree should be left alone, got %v", call.Args)
+		}
+	}
+}
+
+func TestCreateWorktree_DefaultBaseNotRecorded(t *testing.T) {
+	repoRoot := t.TempDir()
+
+	mock := &MockCommandRunner{
+		OutputFunc: func(dir string, name string, args ...string) ([]byte, error) {
+			if len(args) > 1 && args[0] == "rev-parse" && args[1] == "--git-common-dir" {
+				return []byte(repoRoot + "\n"), nil
+			}
+			return []byte{}, nil
+		},
+	}
+	wm := NewWorktreeManagerWithRunner("main", false, mock)
+
+	if _, err := wm.CreateWorktree("proj", "proj-1"); err != nil {
+		t.Fatalf("CreateWorktree() error: %v", err)
+	}
+
+	for _, call := range mock.Calls {
+		if call.Method == "Run" && len(call.Args) > 1 && call.Args[0] == "config" && strings.HasSuffix(call.Args[1], ".rig-base") {
+			t.Errorf("default base should not be recorded, got %v", call.Args)
+		}
+	}
+}
+
+func TestBranchBase(t *testing.T) {
+	mock := &MockCommandRunner{
+		OutputFunc: func(dir string, name string, args ...string) ([]byte, error) {
+			if len(args) > 0 && args[0] == "config" {
+				if args[2] == "branch.proj-124.rig-base" {
+					return []byte("proj-123\n"), nil
+				}
+				return nil, errors.New("exit status 1")
+			}
+			return []byte("/src/repo\n"), nil
+		},
+	}
+	wm := NewWorktreeManagerWithRunner("", false, mock)
+
+	if got := wm.BranchBase("proj-124"); got != "proj-123" {
+		t.Errorf("BranchBase(proj-124) = %q, want proj-123", got)
+	}
+	if got := wm.BranchBase("proj-1"); got != "" {
+		t.Errorf("BranchBase(proj-1) = %q, want empty", got)
+	}
+	if got := wm.BranchBase(""); got != "" {
+		t.Errorf("BranchBase(\"\") = %q, want empty", got)
+	}
+}
+
+func TestBaseBranchFor(t *testing.T) {
+	mock := &MockCommandRunner{
+		OutputFunc: func(dir string, name string, args ...string) ([]byte, error) {
+			if len(args) > 0 && args[0] == "config" {
+				switch args[2] {
+				case "branch.stacked.rig-base":
+					return []byte("parent\n"), nil
+				case "branch.orphaned.rig-base":
+					return []byte("deleted\n"), nil
+				case "branch.hotfix.rig-base":
+					return []byte("release\n"), nil
+				}
+				return nil, errors.New("exit status 1")
+			}
+			return []byte("/src/repo\n"), nil
+		},
+		RunFunc: func(dir string, name string, args ...string) error {
+			if len(args) > 3 && args[0] == "show-ref" {
+				switch args[3] {
+				case "refs/heads/deleted", "refs/remotes/origin/deleted", "refs/heads/release":
+					return errors.New("not found")
+				}
+			}
+			return nil
+		},
+	}
+	wm := NewWorktreeManagerWithRunner("main", false, mock)
+
+	tests := []struct {
+		branch string
+		want   string
+	}{
+		{branch: "stacked", want: "parent"},
+		{branch: "orphaned", want: "main"},
+		{branch: "hotfix", want: "origin/release"},
+		{branch: "plain", want: "main"},
+	}
+
+	for _, tt := range tests {
+		got, err := wm.BaseBranchFor(tt.branch)
+		if err != nil {
+			t.Fatalf("BaseBranchFor(%q) error: %v", tt.branch, err)
+		}
+		if got != tt.want {
+			t.Errorf("BaseBranchFor(%q) = %q, want %q", tt.branch, got, tt.want)
+		}
+	}
+}
+
+func TestAheadBehind(t *testing.T) {
+	mock := &MockCommandRunner{
+		OutputFunc: func(dir string, name string, args ...string) ([]byte, error) {
+			if len(args) > 0 && args[0] == "rev-list" {
+				if args[3] != "proj-123...proj-124" {
+					t.Errorf("rev-list range = %q", args[3])
+				}
+				return []byte("2\t5\n"), nil
+			}
+			return []byte("/src/repo\n"), nil
+		},
+	}
+	wm := NewWorktreeManagerWithRunner("", false, mock)
+
+	ahead, behind, err := wm.AheadBehind("proj-124", "proj-123")
+	if err != nil {
+		t.Fatalf("AheadBehind() error: %v", err)
+	}
+	if ahead != 5 || behind != 2 {
+		t.Errorf("AheadBehind() = (%d, %d), want (5, 2)", ahead, behind)
+	}
+
+	mock.OutputFunc = func(dir string, name string, args ...string) ([]byte, error) {
+		if len(args) > 0 && args[0] == "rev-list" {
+			return []byte("garbage\n"), nil
+		}
+		return []byte("/src/repo\n"), nil
+	}
+	if _, _, err := wm.AheadBehind("proj-124", "proj-123"); err == nil {
+		t.Error("AheadBehind() should fail on unexpected output")
+	}
+}
+
+func TestRenameBranch_UpdatesStackedBranches(t *testing.T) {
+	mock := &MockCommandRunner{
+		OutputFunc: func(dir string, name string, args ...string) ([]byte, error) {
+			if len(args) > 1 && args[0] == "config" && args[1] == "--get-regexp" {
+				return []byte("branch.proj-124.rig-base proj-123\nbranch.proj-125.rig-base main\n"), nil
+			}
+			return []byte("/src/repo\n"), nil
+		},
+		RunFunc: func(dir string, name string, args ...string) error {
+			if len(args) > 0 && args[0] == "show-ref" {
+				return errors.New("not found")
+			}
+			return nil
+		},
+	}
+	wm := NewWorktreeManagerWithRunner("", false, mock)
+
+	if err := wm.RenameBranch("proj-123", "proj-200"); err != nil {
+		t.Fatalf("RenameBranch() error: %v", err)
+	}
+
+	var updates []string
+	for _, call := range mock.Calls {
+		if call.Method == "Run" && len(call.Args) == 3 && call.Args[0] == "config" {
+			updates = append(updates, strings.Join(call.Args, " "))
+		}
+	}
+	if len(updates) != 1 || updates[0] != "config branch.proj-124.rig-base proj-200" {
+		t.Errorf("stacked branch updates = %v, want only proj-124 re-pointed", updates)
+	}
+}
+
+func TestIsDirty(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		err     error
+		want    bool
+		wantErr bool
+	}{
+		{name: "clean", output: "", want: false},
+		{name: "modified", output: " M main.go\n", want: true},
+		{name: "untracked", output: "?? notes.txt\n", want: true},
+		{name: "not a worktree", err: errors.New("not a git repository"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCommandRunner{
+				OutputFunc: func(dir string, name string, args ...string) ([]byte, error) {
+					if dir != "/repo/proj/proj-1" || strings.Join(args, " ") != "status --porcelain" {
+						t.Errorf("unexpected command in %s: %s %v", dir, name, args)
+					}
+					return []byte(tt.output), tt.err
+				},
+			}
+			wm := NewWorktreeManagerWithRunner("", false, mock)
+
+			got, err := wm.IsDirty("/repo/proj/proj-1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("IsDirty() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("IsDirty() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package history
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultIdleGap is the longest pause between commands that ActiveTime
+// still counts as working time
+const DefaultIdleGap = 15 * time.Minute
+
+// ActiveTime estimates the time spent working from when commands ran: the
+// pauses between consecutive commands count when they are no longer than
+// idleGap. Longer pauses are treated as breaks.
+func ActiveTime(commands []Command, idleGap time.Duration) time.Duration {
+	times := make([]time.Time, 0, len(commands))
+	for _, cmd := range commands {
+		times = append(times, cmd.Timestamp)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	var active time.Duration
+	for i := 1; i < len(times); i++ {
+		if gap := times[i].Sub(times[i-1]); gap <= idleGap {
+			active += gap
+		}
+	}
+	return active
+}
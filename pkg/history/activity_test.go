@@ -0,0 +1,34 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+func TestActiveTime(t *testing.T) {
+	start := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	at := func(minutes int) Command {
+		return Command{Timestamp: start.Add(time.Duration(minutes) * time.Minute)}
+	}
+
+	tests := []struct {
+		name     string
+		commands []Command
+		want     time.Duration
+	}{
+		{"none", nil, 0},
+		{"single command", []Command{at(0)}, 0},
+		{"steady work", []Command{at(0), at(5), at(15)}, 15 * time.Minute},
+		{"break not counted", []Command{at(0), at(10), at(70), at(75)}, 15 * time.Minute},
+		{"unsorted", []Command{at(15), at(0), at(5)}, 15 * time.Minute},
+		{"gap at the limit", []Command{at(0), at(15)}, 15 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ActiveTime(tt.commands, DefaultIdleGap); got != tt.want {
+				t.Errorf("ActiveTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -124,3 +124,12 @@ func (c *Client) isNewField(line string) bool {
 	matched, _ := regexp.MatchString(`^[A-Z][a-zA-Z\s]*:`, line)
 	return matched
 }
+
+// TicketURL returns the browse URL for a ticket under baseURL, e.g.
+// https://example.atlassian.net/browse/PROJ-123. Returns "" without a baseURL.
+func TicketURL(baseURL, ticket string) string {
+	if baseURL == "" || ticket == "" {
+		return ""
+	}
+	return strings.TrimRight(baseURL, "/") + "/browse/" + strings.ToUpper(ticket)
+}
@@ -350,3 +350,21 @@ func TestFetchTicketDetails_UnavailableClient(t *testing.T) {
 		t.Error("FetchTicketDetails() should return error when CLI is unavailable")
 	}
 }
+
+func TestTicketURL(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		ticket  string
+		want    string
+	}{
+		{"https://example.atlassian.net", "proj-123", "https://example.atlassian.net/browse/PROJ-123"},
+		{"https://example.atlassian.net/", "PROJ-1", "https://example.atlassian.net/browse/PROJ-1"},
+		{"", "PROJ-1", ""},
+	}
+
+	for _, tt := range tests {
+		if got := TicketURL(tt.baseURL, tt.ticket); got != tt.want {
+			t.Errorf("TicketURL(%q, %q) = %q, want %q", tt.baseURL, tt.ticket, got, tt.want)
+		}
+	}
+}
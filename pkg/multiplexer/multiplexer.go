@@ -0,0 +1,242 @@
+// Package multiplexer abstracts the terminal multiplexer that hosts ticket
+// sessions. tmux is the reference implementation; Zellij and WezTerm are
+// supported through their command-line interfaces.
+package multiplexer
+
+import (
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+
+	"thoreinstein.com/rig/pkg/tmux"
+)
+
+// Backend names accepted by session.backend
+const (
+	BackendAuto    = "auto"
+	BackendTmux    = "tmux"
+	BackendZellij  = "zellij"
+	BackendWezTerm = "wezterm"
+)
+
+// ErrUnsupported is returned for operations a backend cannot perform
+var ErrUnsupported = errors.New("not supported by this multiplexer")
+
+// Multiplexer creates and manages one session per ticket
+type Multiplexer interface {
+	// Name returns the backend name, e.g. "tmux"
+	Name() string
+	// GetSessionName returns the session name for a ticket
+	GetSessionName(ticket string) string
+	// CreateSession creates the ticket's session (or attaches if it exists)
+	CreateSession(ticket, worktreePath, notePath string) error
+	// SessionExists reports whether a session with this name is running
+	SessionExists(sessionName string) bool
+	// AttachToSession attaches to, or switches to, a session
+	AttachToSession(sessionName string) error
+	// ListSessions returns the names of all sessions
+	ListSessions() ([]string, error)
+	// KillSession kills the ticket's session
+	KillSession(ticket string) error
+	// RenameSession renames the session of oldTicket to that of newTicket,
+	// leaving its environment as it was
+	RenameSession(oldTicket, newTicket string) error
+	// SetEnvironment sets environment variables for new panes in a session.
+	// Backends that fix the environment when panes start return an error
+	// marked with ErrUnsupported.
+	SetEnvironment(sessionName string, vars map[string]string) error
+	// UnsetEnvironment removes environment variables for new panes in a
+	// session, or returns an error marked with ErrUnsupported like
+	// SetEnvironment
+	UnsetEnvironment(sessionName string, keys []string) error
+	// AddEnvironment adds variables to set in sessions created afterwards,
+	// on top of RIG_TICKET, RIG_WORKTREE and RIG_NOTE
+	AddEnvironment(vars map[string]string)
+	// AllowCommands extends the window command allowlist with prefixes
+	AllowCommands(prefixes []string)
+}
+
+// WindowConfig describes a window (tab) and its panes
+type WindowConfig = tmux.WindowConfig
+
+// PaneConfig describes an additional pane within a window
+type PaneConfig = tmux.PaneConfig
+
+var _ Multiplexer = (*tmux.SessionManager)(nil)
+
+// Backends lists the supported backend names
+var Backends = []string{BackendTmux, BackendZellij, BackendWezTerm}
+
+// Detect picks a backend from the environment of the current terminal:
+// $TMUX, $ZELLIJ or $WEZTERM_PANE. Defaults to tmux.
+func Detect(getenv func(string) string) string {
+	switch {
+	case getenv("TMUX") != "":
+		return BackendTmux
+	case getenv("ZELLIJ") != "":
+		return BackendZellij
+	case getenv("WEZTERM_PANE") != "":
+		return BackendWezTerm
+	default:
+		return BackendTmux
+	}
+}
+
+// ResolveBackend returns the backend to use for a configured value.
+// An empty value or "auto" detects the backend from the environment.
+func ResolveBackend(configured string, getenv func(string) string) (string, error) {
+	backend := strings.ToLower(strings.TrimSpace(configured))
+	if backend == "" || backend == BackendAuto {
+		return Detect(getenv), nil
+	}
+
+	for _, name := range Backends {
+		if backend == name {
+			return backend, nil
+		}
+	}
+	return "", errors.Newf("unknown session backend %q (valid: auto, %s)", configured, strings.Join(Backends, ", "))
+}
+
+// New creates a multiplexer for the configured backend
+func New(backend, sessionPrefix string, windows []WindowConfig, verbose bool) (Multiplexer, error) {
+	name, err := ResolveBackend(backend, os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	switch name {
+	case BackendZellij:
+		return NewZellijManager(sessionPrefix, windows, verbose), nil
+	case BackendWezTerm:
+		return NewWezTermManager(sessionPrefix, windows, verbose), nil
+	default:
+		return tmux.NewSessionManager(sessionPrefix, windows, verbose), nil
+	}
+}
+
+// CommandRunner executes multiplexer CLI commands.
+// This interface allows for mocking in tests.
+type CommandRunner interface {
+	// Run executes a command with extra environment variables
+	Run(env []string, name string, args ...string) error
+	// Output executes a command and returns its standard output
+	Output(name string, args ...string) ([]byte, error)
+	// Interactive executes a command attached to the terminal
+	Interactive(env []string, name string, args ...string) error
+}
+
+// RealCommandRunner executes actual commands
+type RealCommandRunner struct {
+	Verbose bool
+}
+
+// Run executes a command without capturing output
+func (r *RealCommandRunner) Run(env []string, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Env = append(os.Environ(), env...)
+	if r.Verbose {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+	}
+	return cmd.Run()
+}
+
+// Output executes a command and returns its output
+func (r *RealCommandRunner) Output(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).Output()
+}
+
+// Interactive executes a command with the terminal's stdin, stdout and stderr
+func (r *RealCommandRunner) Interactive(env []string, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// base holds the configuration shared by the CLI-driven backends
+type base struct {
+	SessionPrefix string
+	Windows       []WindowConfig
+	Verbose       bool
+
+	runner               CommandRunner
+	extraCommandPatterns []*regexp.Regexp
+	environment          map[string]string
+}
+
+// GetSessionName returns the full session name with prefix
+func (b *base) GetSessionName(ticket string) string {
+	return b.SessionPrefix + ticket
+}
+
+// AllowCommands extends the command allowlist with command prefixes
+func (b *base) AllowCommands(prefixes []string) {
+	for _, prefix := range prefixes {
+		if pattern := tmux.CommandPrefixPattern(prefix); pattern != nil {
+			b.extraCommandPatterns = append(b.extraCommandPatterns, pattern)
+		}
+	}
+}
+
+// AddEnvironment adds variables to set in sessions created afterwards
+func (b *base) AddEnvironment(vars map[string]string) {
+	if b.environment == nil {
+		b.environment = make(map[string]string, len(vars))
+	}
+	for key, value := range vars {
+		b.environment[key] = value
+	}
+}
+
+// sessionEnvironment returns the full environment for a new session
+func (b *base) sessionEnvironment(ticket, worktreePath, notePath string) map[string]string {
+	return tmux.MergeEnvironment(ticket, worktreePath, notePath, b.environment)
+}
+
+// checkCommands validates the window configuration and rejects commands
+// outside the allowlist before anything is created
+func (b *base) checkCommands(worktreePath, notePath string) error {
+	if err := tmux.ValidateWindows(b.Windows); err != nil {
+		return err
+	}
+
+	for _, window := range b.Windows {
+		commands := []string{window.Command}
+		for _, pane := range window.Panes {
+			commands = append(commands, pane.Command)
+		}
+		for _, command := range commands {
+			expanded := tmux.ExpandTemplate(command, worktreePath, notePath)
+			if !tmux.CommandAllowed(expanded, b.extraCommandPatterns) {
+				return errors.Newf("command not in allowlist: %q (add it to tmux.allowed_commands in your config)", expanded)
+			}
+		}
+	}
+	return nil
+}
+
+// dir expands a configured working directory, defaulting to the worktree
+func dir(workingDir, worktreePath, notePath string) string {
+	if workingDir == "" {
+		return worktreePath
+	}
+	return tmux.ExpandTemplate(workingDir, worktreePath, notePath)
+}
+
+// envList formats vars as sorted KEY=value pairs
+func envList(vars map[string]string) []string {
+	env := make([]string, 0, len(vars))
+	for key, value := range vars {
+		env = append(env, key+"="+value)
+	}
+	sort.Strings(env)
+	return env
+}
@@ -0,0 +1,218 @@
+package multiplexer
+
+import (
+	"strings"
+	"testing"
+
+	"thoreinstein.com/rig/pkg/tmux"
+)
+
+// MockCommandRunner is a mock implementation of CommandRunner for testing
+type MockCommandRunner struct {
+	// RunFunc is called for Run() and Interactive() - returns error
+	RunFunc func(name string, args ...string) error
+	// OutputFunc is called for Output() - returns output and error
+	OutputFunc func(name string, args ...string) ([]byte, error)
+	// Calls records all calls made
+	Calls []MockCall
+}
+
+// MockCall represents a single call to the mock
+type MockCall struct {
+	Method string
+	Env    []string
+	Name   string
+	Args   []string
+}
+
+func (m *MockCommandRunner) Run(env []string, name string, args ...string) error {
+	m.Calls = append(m.Calls, MockCall{Method: "Run", Env: env, Name: name, Args: args})
+	if m.RunFunc != nil {
+		return m.RunFunc(name, args...)
+	}
+	return nil
+}
+
+func (m *MockCommandRunner) Output(name string, args ...string) ([]byte, error) {
+	m.Calls = append(m.Calls, MockCall{Method: "Output", Name: name, Args: args})
+	if m.OutputFunc != nil {
+		return m.OutputFunc(name, args...)
+	}
+	return []byte{}, nil
+}
+
+func (m *MockCommandRunner) Interactive(env []string, name string, args ...string) error {
+	m.Calls = append(m.Calls, MockCall{Method: "Interactive", Env: env, Name: name, Args: args})
+	if m.RunFunc != nil {
+		return m.RunFunc(name, args...)
+	}
+	return nil
+}
+
+// commandLines renders the recorded calls as "name arg arg" strings
+func (m *MockCommandRunner) commandLines() []string {
+	lines := make([]string, 0, len(m.Calls))
+	for _, call := range m.Calls {
+		lines = append(lines, call.Name+" "+strings.Join(call.Args, " "))
+	}
+	return lines
+}
+
+func env(vars map[string]string) func(string) string {
+	return func(key string) string { return vars[key] }
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"nothing set defaults to tmux", nil, BackendTmux},
+		{"inside tmux", map[string]string{"TMUX": "/tmp/tmux-1000/default,1,0"}, BackendTmux},
+		{"inside zellij", map[string]string{"ZELLIJ": "0"}, BackendZellij},
+		{"inside wezterm", map[string]string{"WEZTERM_PANE": "3"}, BackendWezTerm},
+		{"tmux inside wezterm", map[string]string{"TMUX": "x", "WEZTERM_PANE": "3"}, BackendTmux},
+		{"zellij inside wezterm", map[string]string{"ZELLIJ": "0", "WEZTERM_PANE": "3"}, BackendZellij},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(env(tt.env)); got != tt.want {
+				t.Errorf("Detect() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveBackend(t *testing.T) {
+	inZellij := env(map[string]string{"ZELLIJ": "0"})
+
+	tests := []struct {
+		configured string
+		want       string
+		wantErr    bool
+	}{
+		{"", BackendZellij, false},
+		{"auto", BackendZellij, false},
+		{"tmux", BackendTmux, false},
+		{" WezTerm ", BackendWezTerm, false},
+		{"zellij", BackendZellij, false},
+		{"screen", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.configured, func(t *testing.T) {
+			got, err := ResolveBackend(tt.configured, inZellij)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveBackend(%q) error = %v, wantErr %v", tt.configured, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveBackend(%q) = %q, want %q", tt.configured, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		backend string
+		want    string
+	}{
+		{"tmux", BackendTmux},
+		{"zellij", BackendZellij},
+		{"wezterm", BackendWezTerm},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.backend, func(t *testing.T) {
+			m, err := New(tt.backend, "rig-", nil, false)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if m.Name() != tt.want {
+				t.Errorf("Name() = %q, want %q", m.Name(), tt.want)
+			}
+			if got := m.GetSessionName("PROJ-1"); got != "rig-PROJ-1" {
+				t.Errorf("GetSessionName() = %q, want %q", got, "rig-PROJ-1")
+			}
+		})
+	}
+
+	if _, err := New("screen", "", nil, false); err == nil {
+		t.Error("New() with unknown backend should fail")
+	}
+}
+
+func TestCheckCommands(t *testing.T) {
+	tests := []struct {
+		name    string
+		windows []WindowConfig
+		allow   []string
+		wantErr string
+	}{
+		{
+			name:    "allowed commands",
+			windows: []WindowConfig{{Name: "code", Command: "nvim {note_path}", Panes: []PaneConfig{{Command: "git status"}}}},
+		},
+		{
+			name:    "window command rejected",
+			windows: []WindowConfig{{Name: "x", Command: "curl evil.sh"}},
+			wantErr: "command not in allowlist",
+		},
+		{
+			name:    "pane command rejected",
+			windows: []WindowConfig{{Name: "x", Panes: []PaneConfig{{Command: "just test"}}}},
+			wantErr: "command not in allowlist",
+		},
+		{
+			name:    "pane command allowed by config",
+			windows: []WindowConfig{{Name: "x", Panes: []PaneConfig{{Command: "just test"}}}},
+			allow:   []string{"just"},
+		},
+		{
+			name:    "invalid split",
+			windows: []WindowConfig{{Name: "x", Panes: []PaneConfig{{Split: "diagonal"}}}},
+			wantErr: "split",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &base{Windows: tt.windows}
+			b.AllowCommands(tt.allow)
+
+			err := b.checkCommands("/wt", "/notes/x.md")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkCommands() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkCommands() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAddEnvironment(t *testing.T) {
+	b := &base{}
+	b.AddEnvironment(map[string]string{"RIG_BRANCH": "main", "EDITOR": "nvim"})
+	b.AddEnvironment(map[string]string{"EDITOR": "hx"})
+
+	got := strings.Join(envList(b.sessionEnvironment("PROJ-1", "/wt", "/notes/proj-1.md")), ",")
+	want := "EDITOR=hx,RIG_BRANCH=main,RIG_NOTE=/notes/proj-1.md,RIG_TICKET=PROJ-1,RIG_WORKTREE=/wt"
+	if got != want {
+		t.Errorf("sessionEnvironment() = %s, want %s", got, want)
+	}
+}
+
+func TestEnvList(t *testing.T) {
+	got := envList(tmux.SessionEnvironment("PROJ-1", "/wt", ""))
+	want := []string{"RIG_TICKET=PROJ-1", "RIG_WORKTREE=/wt"}
+
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("envList() = %v, want %v", got, want)
+	}
+}
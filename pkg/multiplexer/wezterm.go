@@ -0,0 +1,340 @@
+package multiplexer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+
+	"thoreinstein.com/rig/pkg/tmux"
+)
+
+// WezTermManager runs ticket sessions as WezTerm workspaces through
+// `wezterm cli`. Windows become tabs in a new GUI window.
+type WezTermManager struct {
+	base
+	getenv func(string) string
+}
+
+// weztermPane is an entry of `wezterm cli list --format json`
+type weztermPane struct {
+	WindowID  int    `json:"window_id"`
+	TabID     int    `json:"tab_id"`
+	PaneID    int    `json:"pane_id"`
+	Workspace string `json:"workspace"`
+	Title     string `json:"title"`
+}
+
+// NewWezTermManager creates a WezTermManager
+func NewWezTermManager(sessionPrefix string, windows []WindowConfig, verbose bool) *WezTermManager {
+	return NewWezTermManagerWithRunner(sessionPrefix, windows, verbose, &RealCommandRunner{Verbose: verbose})
+}
+
+// NewWezTermManagerWithRunner creates a WezTermManager with a custom CommandRunner (for testing)
+func NewWezTermManagerWithRunner(sessionPrefix string, windows []WindowConfig, verbose bool, runner CommandRunner) *WezTermManager {
+	return &WezTermManager{
+		base: base{
+			SessionPrefix: sessionPrefix,
+			Windows:       windows,
+			Verbose:       verbose,
+			runner:        runner,
+		},
+		getenv: os.Getenv,
+	}
+}
+
+// Name returns the multiplexer backend name
+func (wm *WezTermManager) Name() string {
+	return BackendWezTerm
+}
+
+// CreateSession spawns a window in a new workspace with a tab per
+// configured window, then activates it
+func (wm *WezTermManager) CreateSession(ticket, worktreePath, notePath string) error {
+	sessionName := wm.GetSessionName(ticket)
+
+	if wm.SessionExists(sessionName) {
+		if wm.Verbose {
+			fmt.Printf("WezTerm workspace '%s' already exists. Activating...\n", sessionName)
+		}
+		return wm.AttachToSession(sessionName)
+	}
+
+	if _, err := os.Stat(worktreePath); os.IsNotExist(err) {
+		return errors.Newf("worktree path does not exist: %s", worktreePath)
+	}
+
+	if err := wm.checkCommands(worktreePath, notePath); err != nil {
+		return err
+	}
+
+	if wm.Verbose {
+		fmt.Printf("Creating wezterm workspace '%s'...\n", sessionName)
+	}
+
+	windows := wm.Windows
+	if len(windows) == 0 {
+		windows = []WindowConfig{{}}
+	}
+
+	shell := wm.shellCommand(wm.sessionEnvironment(ticket, worktreePath, notePath))
+	windowID := ""
+	firstPane := ""
+
+	for i, window := range windows {
+		windowDir := dir(window.WorkingDir, worktreePath, notePath)
+
+		args := []string{"spawn"}
+		if i == 0 {
+			args = append(args, "--new-window", "--workspace", sessionName)
+		} else {
+			args = append(args, "--window-id", windowID)
+		}
+		args = append(args, "--cwd", windowDir, "--")
+		args = append(args, shell...)
+
+		paneID, err := wm.paneOutput(args...)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create tab %s", window.Name)
+		}
+
+		if i == 0 {
+			firstPane = paneID
+			windowID, err = wm.windowOf(paneID)
+			if err != nil {
+				return err
+			}
+		}
+
+		if window.Name != "" {
+			if err := wm.cli("set-tab-title", "--pane-id", paneID, window.Name); err != nil {
+				return errors.Wrapf(err, "failed to name tab %s", window.Name)
+			}
+		}
+
+		if err := wm.sendCommand(paneID, window.Command, worktreePath, notePath); err != nil {
+			return errors.Wrapf(err, "failed to send command to tab %s", window.Name)
+		}
+
+		if window.Layout != "" && wm.Verbose {
+			fmt.Printf("Warning: WezTerm has no equivalent of layout %q; ignoring it for tab %s\n", window.Layout, window.Name)
+		}
+
+		// Like tmux split-window, each split divides the most recent pane
+		splitFrom := paneID
+		for j, pane := range window.Panes {
+			splitArgs := []string{"split-pane", "--pane-id", splitFrom}
+			if flag, _ := splitDirection(pane.Split); flag == tmux.SplitHorizontal {
+				splitArgs = append(splitArgs, "--right")
+			} else {
+				splitArgs = append(splitArgs, "--bottom")
+			}
+			if pane.Size > 0 {
+				splitArgs = append(splitArgs, "--percent", strconv.Itoa(pane.Size))
+			}
+			paneDir := windowDir
+			if pane.WorkingDir != "" {
+				paneDir = dir(pane.WorkingDir, worktreePath, notePath)
+			}
+			splitArgs = append(splitArgs, "--cwd", paneDir, "--")
+			splitArgs = append(splitArgs, shell...)
+
+			splitID, err := wm.paneOutput(splitArgs...)
+			if err != nil {
+				return errors.Wrapf(err, "failed to split pane %d in tab %s", j+1, window.Name)
+			}
+			if err := wm.sendCommand(splitID, pane.Command, worktreePath, notePath); err != nil {
+				return errors.Wrapf(err, "failed to send command to pane %d in tab %s", j+1, window.Name)
+			}
+			splitFrom = splitID
+		}
+	}
+
+	// Start on the first tab
+	return wm.cli("activate-pane", "--pane-id", firstPane)
+}
+
+// shellCommand returns the program spawned in each pane: the user's shell
+// started through env so that it carries the session variables
+func (wm *WezTermManager) shellCommand(vars map[string]string) []string {
+	shell := wm.getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	return append(append([]string{"env"}, envList(vars)...), shell)
+}
+
+// sendCommand types an allowlisted command into a pane
+func (wm *WezTermManager) sendCommand(paneID, command, worktreePath, notePath string) error {
+	if command == "" {
+		return nil
+	}
+	command = tmux.ExpandTemplate(command, worktreePath, notePath)
+	if wm.Verbose {
+		fmt.Printf("⚠️  Executing command from config: %s\n", command)
+	}
+	return wm.cli("send-text", "--pane-id", paneID, "--no-paste", command+"\n")
+}
+
+// windowOf returns the window ID that holds a pane
+func (wm *WezTermManager) windowOf(paneID string) (string, error) {
+	panes, err := wm.panes()
+	if err != nil {
+		return "", err
+	}
+	for _, pane := range panes {
+		if strconv.Itoa(pane.PaneID) == paneID {
+			return strconv.Itoa(pane.WindowID), nil
+		}
+	}
+	return "", errors.Newf("pane %s not found", paneID)
+}
+
+// panes lists every pane known to the WezTerm mux
+func (wm *WezTermManager) panes() ([]weztermPane, error) {
+	output, err := wm.runner.Output("wezterm", "cli", "list", "--format", "json")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list wezterm panes")
+	}
+
+	var panes []weztermPane
+	if err := json.Unmarshal(output, &panes); err != nil {
+		return nil, errors.Wrap(err, "failed to parse wezterm pane list")
+	}
+	return panes, nil
+}
+
+// workspacePanes returns the panes of a workspace
+func (wm *WezTermManager) workspacePanes(sessionName string) ([]weztermPane, error) {
+	panes, err := wm.panes()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []weztermPane
+	for _, pane := range panes {
+		if pane.Workspace == sessionName {
+			result = append(result, pane)
+		}
+	}
+	return result, nil
+}
+
+// paneOutput runs a wezterm cli command that prints a pane ID
+func (wm *WezTermManager) paneOutput(args ...string) (string, error) {
+	output, err := wm.runner.Output("wezterm", append([]string{"cli"}, args...)...)
+	if err != nil {
+		return "", err
+	}
+	paneID := strings.TrimSpace(string(output))
+	if paneID == "" {
+		return "", errors.New("wezterm did not return a pane id")
+	}
+	return paneID, nil
+}
+
+// cli runs a wezterm cli subcommand
+func (wm *WezTermManager) cli(args ...string) error {
+	return wm.runner.Run(nil, "wezterm", append([]string{"cli"}, args...)...)
+}
+
+// SessionExists checks if a workspace has any panes
+func (wm *WezTermManager) SessionExists(sessionName string) bool {
+	panes, err := wm.workspacePanes(sessionName)
+	return err == nil && len(panes) > 0
+}
+
+// AttachToSession activates the first pane of a workspace, which switches
+// the GUI to it
+func (wm *WezTermManager) AttachToSession(sessionName string) error {
+	panes, err := wm.workspacePanes(sessionName)
+	if err != nil {
+		return err
+	}
+	if len(panes) == 0 {
+		return errors.Newf("session does not exist: %s", sessionName)
+	}
+
+	if wm.Verbose {
+		fmt.Printf("Activating workspace: %s\n", sessionName)
+	}
+	return wm.cli("activate-pane", "--pane-id", strconv.Itoa(panes[0].PaneID))
+}
+
+// ListSessions returns the workspace names in the order WezTerm lists them
+func (wm *WezTermManager) ListSessions() ([]string, error) {
+	panes, err := wm.panes()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list sessions")
+	}
+
+	var sessions []string
+	seen := make(map[string]bool)
+	for _, pane := range panes {
+		if pane.Workspace == "" || seen[pane.Workspace] {
+			continue
+		}
+		seen[pane.Workspace] = true
+		sessions = append(sessions, pane.Workspace)
+	}
+	return sessions, nil
+}
+
+// KillSession kills every pane in the ticket's workspace
+func (wm *WezTermManager) KillSession(ticket string) error {
+	sessionName := wm.GetSessionName(ticket)
+
+	panes, err := wm.workspacePanes(sessionName)
+	if err != nil {
+		return err
+	}
+	if len(panes) == 0 {
+		return errors.Newf("session does not exist: %s", sessionName)
+	}
+
+	if wm.Verbose {
+		fmt.Printf("Killing session: %s\n", sessionName)
+	}
+	for _, pane := range panes {
+		if err := wm.cli("kill-pane", "--pane-id", strconv.Itoa(pane.PaneID)); err != nil {
+			return errors.Wrapf(err, "failed to kill pane %d", pane.PaneID)
+		}
+	}
+	return nil
+}
+
+// RenameSession renames the WezTerm workspace of oldTicket. Its panes keep
+// the environment they were spawned with, see SetEnvironment.
+func (wm *WezTermManager) RenameSession(oldTicket, newTicket string) error {
+	oldName := wm.GetSessionName(oldTicket)
+	newName := wm.GetSessionName(newTicket)
+
+	if !wm.SessionExists(oldName) {
+		return errors.Newf("session does not exist: %s", oldName)
+	}
+	if wm.SessionExists(newName) {
+		return errors.Newf("session already exists: %s", newName)
+	}
+
+	if wm.Verbose {
+		fmt.Printf("Renaming workspace %s to %s\n", oldName, newName)
+	}
+	if err := wm.cli("rename-workspace", "--workspace", oldName, newName); err != nil {
+		return errors.Wrap(err, "failed to rename workspace")
+	}
+	return nil
+}
+
+// SetEnvironment is not supported: WezTerm panes get their environment
+// when they are spawned
+func (wm *WezTermManager) SetEnvironment(sessionName string, vars map[string]string) error {
+	return errors.Wrapf(ErrUnsupported, "wezterm cannot change the environment of workspace %s", sessionName)
+}
+
+// UnsetEnvironment is not supported, see SetEnvironment
+func (wm *WezTermManager) UnsetEnvironment(sessionName string, keys []string) error {
+	return errors.Wrapf(ErrUnsupported, "wezterm cannot change the environment of workspace %s", sessionName)
+}
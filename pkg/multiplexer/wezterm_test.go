@@ -0,0 +1,152 @@
+package multiplexer
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+)
+
+// weztermMock simulates `wezterm cli`. list returns the pane list for each
+// call; spawned and split panes are numbered from 11.
+func weztermMock(list func() string) *MockCommandRunner {
+	nextPane := 10
+	return &MockCommandRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) {
+			switch args[1] {
+			case "list":
+				return []byte(list()), nil
+			case "spawn", "split-pane":
+				nextPane++
+				return []byte(strconv.Itoa(nextPane) + "\n"), nil
+			}
+			return nil, errors.Newf("unexpected command %v", args)
+		},
+	}
+}
+
+// staticList returns the same pane list on every call
+func staticList(list string) func() string {
+	return func() string { return list }
+}
+
+func TestWezTermCreateSession(t *testing.T) {
+	worktree := t.TempDir()
+	windows := []WindowConfig{
+		{Name: "note", Command: "nvim {note_path}"},
+		{
+			Name:    "code",
+			Command: "nvim",
+			Panes:   []PaneConfig{{Split: "horizontal", Size: 30, Command: "git status", WorkingDir: "{worktree_path}/logs"}},
+		},
+	}
+
+	// The workspace doesn't exist until the first tab is spawned
+	lists := 0
+	mock := weztermMock(func() string {
+		lists++
+		if lists == 1 {
+			return "[]"
+		}
+		return `[{"window_id": 4, "tab_id": 1, "pane_id": 11, "workspace": "rig-PROJ-1"}]`
+	})
+	wm := NewWezTermManagerWithRunner("rig-", windows, false, mock)
+	wm.getenv = env(map[string]string{"SHELL": "/bin/zsh"})
+
+	if err := wm.CreateSession("PROJ-1", worktree, "/notes/PROJ-1.md"); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+
+	shell := "env RIG_NOTE=/notes/PROJ-1.md RIG_TICKET=PROJ-1 RIG_WORKTREE=" + worktree + " /bin/zsh"
+	want := []string{
+		"wezterm cli list --format json",
+		"wezterm cli spawn --new-window --workspace rig-PROJ-1 --cwd " + worktree + " -- " + shell,
+		"wezterm cli list --format json",
+		"wezterm cli set-tab-title --pane-id 11 note",
+		"wezterm cli send-text --pane-id 11 --no-paste nvim /notes/PROJ-1.md\n",
+		"wezterm cli spawn --window-id 4 --cwd " + worktree + " -- " + shell,
+		"wezterm cli set-tab-title --pane-id 12 code",
+		"wezterm cli send-text --pane-id 12 --no-paste nvim\n",
+		"wezterm cli split-pane --pane-id 12 --right --percent 30 --cwd " + worktree + "/logs -- " + shell,
+		"wezterm cli send-text --pane-id 13 --no-paste git status\n",
+		"wezterm cli activate-pane --pane-id 11",
+	}
+
+	got := mock.commandLines()
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("commands =\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestWezTermSessions(t *testing.T) {
+	list := `[
+		{"window_id": 1, "tab_id": 1, "pane_id": 1, "workspace": "default"},
+		{"window_id": 2, "tab_id": 2, "pane_id": 5, "workspace": "rig-PROJ-1"},
+		{"window_id": 2, "tab_id": 3, "pane_id": 6, "workspace": "rig-PROJ-1"},
+		{"window_id": 3, "tab_id": 4, "pane_id": 7, "workspace": "rig-PROJ-2"}
+	]`
+	mock := weztermMock(staticList(list))
+	wm := NewWezTermManagerWithRunner("rig-", nil, false, mock)
+
+	sessions, err := wm.ListSessions()
+	if err != nil {
+		t.Fatalf("ListSessions() error = %v", err)
+	}
+	if strings.Join(sessions, ",") != "default,rig-PROJ-1,rig-PROJ-2" {
+		t.Errorf("ListSessions() = %v", sessions)
+	}
+
+	if !wm.SessionExists("rig-PROJ-2") || wm.SessionExists("rig-PROJ-3") {
+		t.Error("SessionExists() disagrees with the pane list")
+	}
+
+	mock.Calls = nil
+	if err := wm.AttachToSession("rig-PROJ-1"); err != nil {
+		t.Fatalf("AttachToSession() error = %v", err)
+	}
+	if last := mock.commandLines()[len(mock.Calls)-1]; last != "wezterm cli activate-pane --pane-id 5" {
+		t.Errorf("attach ran %q", last)
+	}
+
+	mock.Calls = nil
+	if err := wm.KillSession("PROJ-1"); err != nil {
+		t.Fatalf("KillSession() error = %v", err)
+	}
+	want := []string{
+		"wezterm cli list --format json",
+		"wezterm cli kill-pane --pane-id 5",
+		"wezterm cli kill-pane --pane-id 6",
+	}
+	if got := mock.commandLines(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("kill commands = %v, want %v", got, want)
+	}
+
+	if err := wm.KillSession("PROJ-3"); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("KillSession() missing session error = %v", err)
+	}
+
+	mock.Calls = nil
+	if err := wm.RenameSession("PROJ-1", "PROJ-3"); err != nil {
+		t.Fatalf("RenameSession() error = %v", err)
+	}
+	if last := mock.commandLines()[len(mock.Calls)-1]; last != "wezterm cli rename-workspace --workspace rig-PROJ-1 rig-PROJ-3" {
+		t.Errorf("rename ran %q", last)
+	}
+	if err := wm.RenameSession("PROJ-1", "PROJ-2"); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("RenameSession() onto a running workspace error = %v", err)
+	}
+}
+
+func TestWezTermSetEnvironment(t *testing.T) {
+	wm := NewWezTermManagerWithRunner("", nil, false, &MockCommandRunner{})
+
+	err := wm.SetEnvironment("PROJ-1", map[string]string{"A": "b"})
+	if !errors.Is(err, ErrUnsupported) {
+		t.Errorf("SetEnvironment() error = %v, want ErrUnsupported", err)
+	}
+	err = wm.UnsetEnvironment("PROJ-1", []string{"A"})
+	if !errors.Is(err, ErrUnsupported) {
+		t.Errorf("UnsetEnvironment() error = %v, want ErrUnsupported", err)
+	}
+}
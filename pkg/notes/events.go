@@ -0,0 +1,280 @@
+package notes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Daily log events
+const (
+	EventStarted  = "started"   // Work on a ticket began (new worktree)
+	EventResumed  = "resumed"   // Work on an existing worktree resumed
+	EventSynced   = "synced"    // The ticket note was synced
+	EventStatus   = "status"    // The tracker status changed; Detail is "From → To"
+	EventPROpened = "pr_opened" // A pull request was opened; Detail is its URL
+	EventDone     = "done"      // Work on the ticket finished
+)
+
+// LogEvents lists the daily log events in the order they usually happen
+var LogEvents = []string{EventStarted, EventResumed, EventSynced, EventStatus, EventPROpened, EventDone}
+
+// DefaultDedupeWindow is how long a repeat of the same event for a ticket
+// is suppressed by default
+const DefaultDedupeWindow = 30 * time.Minute
+
+// defaultLogFormats are the entry templates used for events without one in
+// LogFormats
+var defaultLogFormats = map[string]string{
+	EventStarted:  "Started",
+	EventResumed:  "Resumed",
+	EventSynced:   "Synced",
+	EventStatus:   "Status: {{.Detail}}",
+	EventPROpened: "Opened PR{{with .Detail}} {{.}}{{end}}",
+	EventDone:     "Done{{with .Detail}}: {{.}}{{end}}",
+}
+
+// statusSeparator separates the old and new status in a status event
+const statusSeparator = " → "
+
+// LogEvent is something that happened to a ticket, recorded in the Log
+// section of the daily note
+type LogEvent struct {
+	Event  string // One of LogEvents
+	Ticket string
+	Type   string
+	Detail string // Event specific, e.g. "In Progress → Done" or a PR URL
+	Time   time.Time
+}
+
+// logEventData is the data log entry templates are rendered with
+type logEventData struct {
+	Event  string
+	Ticket string
+	Type   string
+	Link   string // Link to the ticket note from the daily note
+	Detail string
+	Time   string // HH:MM
+}
+
+// StatusEvent returns the event recording a tracker status change
+func StatusEvent(ticketType, ticket, from, to string) LogEvent {
+	return LogEvent{Event: EventStatus, Ticket: ticket, Type: ticketType, Detail: from + statusSeparator + to}
+}
+
+// IsLogEvent reports whether name is a known daily log event
+func IsLogEvent(name string) bool {
+	return slices.Contains(LogEvents, name)
+}
+
+// logFormat returns the entry template for an event
+func (m *Manager) logFormat(event string) string {
+	if format, ok := m.LogFormats[event]; ok && format != "" {
+		return format
+	}
+	return defaultLogFormats[event]
+}
+
+// renderLogText renders the text of an event's log entry, which follows
+// the entry's time
+func (m *Manager) renderLogText(event string, data logEventData) (string, error) {
+	tmpl := template.New(event)
+	tmpl.Funcs(templateFuncs(tmpl))
+	if _, err := tmpl.Parse(m.logFormat(event)); err != nil {
+		return "", errors.Wrapf(err, "invalid notes.log.formats.%s", event)
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", errors.Wrapf(err, "failed to render notes.log.formats.%s", event)
+	}
+	return strings.Join(strings.Fields(b.String()), " "), nil
+}
+
+// ticketHeading is the Log subsection heading collecting a ticket's
+// entries: its daily link, e.g. [proj-123](../proj/proj-123.md) or
+// [[proj-123]]
+func (m *Manager) ticketHeading(ticketType, ticket string) string {
+	return m.DailyLink(ticketType, ticket)
+}
+
+// AddDailyEvent records an event in the ticket's subsection of the Log
+// section of today's daily note, creating the note if necessary. The
+// event is skipped if the same entry was logged for the ticket within
+// DedupeWindow. Reports whether the entry was added.
+func (m *Manager) AddDailyEvent(event LogEvent) (bool, error) {
+	if !IsLogEvent(event.Event) {
+		return false, errors.Newf("unknown log event %q (use %s)", event.Event, strings.Join(LogEvents, ", "))
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	text, err := m.renderLogText(event.Event, logEventData{
+		Event:  event.Event,
+		Ticket: event.Ticket,
+		Type:   event.Type,
+		Link:   m.DailyLink(event.Type, event.Ticket),
+		Detail: event.Detail,
+		Time:   event.Time.Format("15:04"),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	dailyNotePath := m.DailyNotePath(event.Time)
+	if m.Verbose {
+		fmt.Printf("Updating daily note at: %s\n", dailyNotePath)
+	}
+
+	content, err := m.readDailyNote(event.Time)
+	if err != nil {
+		return false, err
+	}
+
+	if m.isDuplicateEvent(content, event, text) {
+		if m.Verbose {
+			fmt.Printf("Skipping repeated %s entry for %s\n", event.Event, event.Ticket)
+		}
+		return false, nil
+	}
+
+	logEntry := fmt.Sprintf("- [%s] %s", event.Time.Format("15:04"), text)
+	updatedContent := m.insertLogEntry(content, logEntry, m.ticketHeading(event.Type, event.Ticket))
+
+	// Write back to file with restricted permissions
+	if err := os.WriteFile(dailyNotePath, []byte(updatedContent), 0600); err != nil {
+		return false, errors.Wrap(err, "failed to update daily note")
+	}
+
+	if m.Verbose {
+		fmt.Printf("Added log entry to daily note: %s\n", logEntry)
+	}
+	return true, nil
+}
+
+// readDailyNote returns the daily note for the day of t, rendering a new
+// one from the daily template if it doesn't exist yet
+func (m *Manager) readDailyNote(t time.Time) (string, error) {
+	dailyNotePath := m.DailyNotePath(t)
+
+	contentBytes, err := os.ReadFile(dailyNotePath)
+	if err == nil {
+		return string(contentBytes), nil
+	}
+	if !os.IsNotExist(err) {
+		return "", errors.Wrap(err, "failed to read daily note")
+	}
+
+	// Create the daily directory if needed (0700 for user-only access)
+	if err := os.MkdirAll(filepath.Dir(dailyNotePath), 0700); err != nil {
+		return "", errors.Wrap(err, "failed to create daily notes directory")
+	}
+
+	// Render daily template, carrying over the previous note's open tasks
+	data, err := m.NewDailyData(t)
+	if err != nil {
+		return "", err
+	}
+	rendered, err := m.RenderDailyNote(data)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to render daily template")
+	}
+
+	if m.Verbose {
+		fmt.Printf("Creating new daily note for %s\n", data.Date)
+	}
+	return rendered, nil
+}
+
+// isDuplicateEvent reports whether the daily note already has an entry with
+// the same text for the ticket within DedupeWindow before the event
+func (m *Manager) isDuplicateEvent(content string, event LogEvent, text string) bool {
+	if m.DedupeWindow <= 0 {
+		return false
+	}
+
+	date := event.Time.Format("2006-01-02")
+	for _, entry := range m.parseDailyEntries(date, content) {
+		if entry.Ticket != event.Ticket || entry.Text != text || entry.Time == "" {
+			continue
+		}
+		at, err := time.ParseInLocation("2006-01-02 15:04", date+" "+entry.Time, event.Time.Location())
+		if err != nil {
+			continue
+		}
+		if age := event.Time.Sub(at); age >= 0 && age < m.DedupeWindow {
+			return true
+		}
+	}
+	return false
+}
+
+// logEventMatcher recognises the text of log entries rendered from the
+// configured event templates, recovering the event and its detail
+type logEventMatcher struct {
+	events   []string
+	patterns []*regexp.Regexp
+}
+
+// Placeholders rendered into event templates to build their patterns.
+// They have no letters so case functions such as upper leave them alone.
+const (
+	detailPlaceholder = "\x00\x01\x00"
+	valuePlaceholder  = "\x00\x02\x00"
+)
+
+// newLogEventMatcher builds patterns for the current event templates. Each
+// template is rendered with and without a detail; the ticket fields and
+// time match anything.
+func (m *Manager) newLogEventMatcher() *logEventMatcher {
+	matcher := &logEventMatcher{}
+	for _, event := range LogEvents {
+		for _, detail := range []string{detailPlaceholder, ""} {
+			text, err := m.renderLogText(event, logEventData{
+				Event:  event,
+				Ticket: valuePlaceholder,
+				Type:   valuePlaceholder,
+				Link:   valuePlaceholder,
+				Detail: detail,
+				Time:   valuePlaceholder,
+			})
+			if err != nil {
+				continue
+			}
+
+			pattern := regexp.QuoteMeta(text)
+			pattern = strings.ReplaceAll(pattern, regexp.QuoteMeta(detailPlaceholder), `(.+)`)
+			pattern = strings.ReplaceAll(pattern, regexp.QuoteMeta(valuePlaceholder), `.+?`)
+			re, err := regexp.Compile("^" + pattern + "$")
+			if err != nil {
+				continue
+			}
+			matcher.events = append(matcher.events, event)
+			matcher.patterns = append(matcher.patterns, re)
+		}
+	}
+	return matcher
+}
+
+// match returns the event and detail of an entry's text, or "" if the text
+// wasn't rendered from an event template
+func (lm *logEventMatcher) match(text string) (string, string) {
+	for i, re := range lm.patterns {
+		if match := re.FindStringSubmatch(text); match != nil {
+			detail := ""
+			if len(match) > 1 {
+				detail = match[1]
+			}
+			return lm.events[i], detail
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,113 @@
+package notes
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInsertLogEntry_Subsections(t *testing.T) {
+	m := NewManager("/notes", "daily", "", false)
+
+	content := "# 2025-01-15\n\n## Log\n\n## Notes\n"
+	content = m.insertLogEntry(content, "- [09:00] Started", "[[proj-1]]")
+	content = m.insertLogEntry(content, "- [09:30] Started", "[[ops-2]]")
+	content = m.insertLogEntry(content, "- [10:00] Synced", "[[proj-1]]")
+
+	want := "# 2025-01-15\n\n## Log\n### [[proj-1]]\n- [09:00] Started\n- [10:00] Synced\n\n### [[ops-2]]\n- [09:30] Started\n\n## Notes\n"
+	if content != want {
+		t.Errorf("insertLogEntry() =\n%q\nwant\n%q", content, want)
+	}
+
+	// A Log section ending the note keeps a trailing newline
+	got := m.insertLogEntry("## Log\n- [08:00] [proj-1](../proj/proj-1.md)\n", "- [09:00] Started", "[[ops-2]]")
+	if got != "## Log\n- [08:00] [proj-1](../proj/proj-1.md)\n\n### [[ops-2]]\n- [09:00] Started\n" {
+		t.Errorf("insertLogEntry() at the end of the note = %q", got)
+	}
+
+	if got := m.insertLogEntry("# Day\n", "- [09:00] Started", "[[ops-2]]"); got != "# Day\n\n\n## Log\n### [[ops-2]]\n- [09:00] Started" {
+		t.Errorf("insertLogEntry() without a Log section = %q", got)
+	}
+}
+
+func TestAddDailyEvent_Dedupe(t *testing.T) {
+	m := NewManager(t.TempDir(), "daily", "", false)
+	at := time.Date(2025, 1, 15, 9, 0, 0, 0, time.Local)
+
+	add := func(event LogEvent) bool {
+		t.Helper()
+		added, err := m.AddDailyEvent(event)
+		if err != nil {
+			t.Fatalf("AddDailyEvent() error: %v", err)
+		}
+		return added
+	}
+
+	if !add(LogEvent{Event: EventSynced, Ticket: "proj-1", Type: "proj", Time: at}) {
+		t.Error("the first event should be added")
+	}
+	if add(LogEvent{Event: EventSynced, Ticket: "proj-1", Type: "proj", Time: at.Add(20 * time.Minute)}) {
+		t.Error("a repeat within the window should be skipped")
+	}
+	if !add(LogEvent{Event: EventSynced, Ticket: "ops-2", Type: "ops", Time: at.Add(20 * time.Minute)}) {
+		t.Error("the same event for another ticket should be added")
+	}
+	if !add(LogEvent{Event: EventStatus, Ticket: "proj-1", Type: "proj", Detail: "To Do → Doing", Time: at.Add(25 * time.Minute)}) {
+		t.Error("another event should be added")
+	}
+	if !add(LogEvent{Event: EventSynced, Ticket: "proj-1", Type: "proj", Time: at.Add(45 * time.Minute)}) {
+		t.Error("a repeat after the window should be added")
+	}
+
+	m.DedupeWindow = 0
+	if !add(LogEvent{Event: EventSynced, Ticket: "proj-1", Type: "proj", Time: at.Add(46 * time.Minute)}) {
+		t.Error("a zero window should keep repeats")
+	}
+
+	content, err := os.ReadFile(m.DailyNotePath(at))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "### [proj-1](../proj/proj-1.md)\n- [09:00] Synced\n- [09:25] Status: To Do → Doing\n- [09:45] Synced\n- [09:46] Synced\n\n### [ops-2](../ops/ops-2.md)\n- [09:20] Synced\n"
+	if !strings.Contains(string(content), want) {
+		t.Errorf("daily note log:\n%s\nwant it to contain\n%s", content, want)
+	}
+}
+
+func TestAddDailyEvent_Formats(t *testing.T) {
+	m := NewManager(t.TempDir(), "daily", "", false)
+	m.LogFormats = map[string]string{EventPROpened: "🔀 {{.Ticket | upper}} PR {{.Detail}} at {{.Time}}"}
+	at := time.Date(2025, 1, 15, 9, 0, 0, 0, time.Local)
+
+	if _, err := m.AddDailyEvent(LogEvent{Event: EventPROpened, Ticket: "proj-1", Type: "proj", Detail: "https://example.com/pull/1", Time: at}); err != nil {
+		t.Fatalf("AddDailyEvent() error: %v", err)
+	}
+	if _, err := m.AddDailyEvent(LogEvent{Event: EventDone, Ticket: "proj-1", Type: "proj", Time: at}); err != nil {
+		t.Fatalf("AddDailyEvent() error: %v", err)
+	}
+
+	content, err := os.ReadFile(m.DailyNotePath(at))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "- [09:00] 🔀 PROJ-1 PR https://example.com/pull/1 at 09:00\n- [09:00] Done") {
+		t.Errorf("daily note:\n%s", content)
+	}
+
+	entries, err := m.DailyEntries(at, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Event != EventPROpened || entries[0].Detail != "https://example.com/pull/1" || entries[1].Event != EventDone {
+		t.Errorf("DailyEntries() = %+v", entries)
+	}
+
+	m.LogFormats[EventSynced] = "{{.Missing"
+	if _, err := m.AddDailyEvent(LogEvent{Event: EventSynced, Ticket: "proj-1", Type: "proj", Time: at}); err == nil || !strings.Contains(err.Error(), "notes.log.formats.synced") {
+		t.Errorf("an invalid format should fail, got %v", err)
+	}
+	if _, err := m.AddDailyEvent(LogEvent{Event: "deployed", Ticket: "proj-1", Type: "proj"}); err == nil {
+		t.Error("an unknown event should fail")
+	}
+}
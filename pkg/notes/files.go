@@ -0,0 +1,144 @@
+package notes
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Note kinds returned by ListNotes
+const (
+	KindTicket = "ticket"
+	KindDaily  = "daily"
+)
+
+// NoteFile is a ticket or daily note found under the notes base path
+type NoteFile struct {
+	Path string // Absolute path of the note
+	Kind string // KindTicket or KindDaily
+	Type string // Ticket type ("hack" for hacks), or "daily"
+	Name string // Ticket or hack name, or the daily note's date
+}
+
+// ListNotes returns the ticket notes in the type directories and the daily
+// notes, sorted by type and name. Files that don't sit where GetNotePath or
+// GetDailyNotePath would put them are ignored, as are the other notes of a
+// vault, see isTicketNote.
+func (m *Manager) ListNotes() ([]NoteFile, error) {
+	dailyDir := filepath.Join(m.BasePath, m.DailyDir)
+
+	dirs := make(map[string]string) // directory -> ticket type
+	root := filepath.Join(m.BasePath, m.Subdir)
+	entries, err := os.ReadDir(root)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "failed to read %s", root)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		dir := filepath.Join(root, name)
+		if !entry.IsDir() || strings.HasPrefix(name, ".") || dir == dailyDir || dir == m.TemplateDir {
+			continue
+		}
+		if override := m.TypeSubdirs[name]; override != "" {
+			continue
+		}
+		dirs[dir] = name
+	}
+	for ticketType, subdir := range m.TypeSubdirs {
+		if subdir != "" {
+			dirs[filepath.Join(m.BasePath, subdir)] = ticketType
+		}
+	}
+
+	var notes []NoteFile
+	for dir, ticketType := range dirs {
+		files, err := markdownFiles(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range files {
+			// Index notes are generated from the others
+			if name == IndexNoteName {
+				continue
+			}
+			path := filepath.Join(dir, name+".md")
+			if m.GetNotePath(ticketType, name) != path {
+				continue
+			}
+			ok, err := isTicketNote(path, ticketType, name)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				notes = append(notes, NoteFile{Path: path, Kind: KindTicket, Type: ticketType, Name: name})
+			}
+		}
+	}
+
+	days, err := markdownFiles(dailyDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, day := range days {
+		if _, err := time.Parse("2006-01-02", day); err == nil {
+			notes = append(notes, NoteFile{Path: filepath.Join(dailyDir, day+".md"), Kind: KindDaily, Type: KindDaily, Name: day})
+		}
+	}
+
+	slices.SortFunc(notes, func(a, b NoteFile) int {
+		if c := strings.Compare(a.Type, b.Type); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return notes, nil
+}
+
+// ticketNamePattern matches a ticket name such as proj-123, see rig work
+var ticketNamePattern = regexp.MustCompile(`^([a-zA-Z]+)-[0-9]+$`)
+
+// isTicketNote reports whether the note at path, in the directory of
+// ticketType, is a note rig keeps for a ticket or hack: its front matter
+// names a ticket of that type, or, for notes that predate front matter, it is a hack or
+// named like a ticket of its type (proj/proj-123). Other notes of a vault,
+// such as Recipes/pancakes.md, are not.
+func isTicketNote(path, ticketType, name string) (bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to read %s", path)
+	}
+
+	fm, err := ParseFrontMatter(string(content))
+	if err == nil && fm.Ticket != "" {
+		return fm.Type == "" || fm.Type == ticketType, nil
+	}
+	if ticketType == "hack" {
+		return true, nil
+	}
+	match := ticketNamePattern.FindStringSubmatch(name)
+	return match != nil && strings.EqualFold(match[1], ticketType), nil
+}
+
+// markdownFiles returns the names, without .md, of the markdown files in dir
+func markdownFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", dir)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), ".md"); ok && !entry.IsDir() && !strings.HasPrefix(name, ".") {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,250 @@
+package notes
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"go.yaml.in/yaml/v3"
+)
+
+// frontMatterDelimiter opens and closes a note's YAML front matter
+const frontMatterDelimiter = "---"
+
+// FrontMatter holds the fields rig manages in a ticket note's YAML front
+// matter. Other keys in the front matter are left alone.
+type FrontMatter struct {
+	Ticket   string   `yaml:"ticket,omitempty"`
+	Type     string   `yaml:"type,omitempty"`
+	Status   string   `yaml:"status,omitempty"`
+	Repo     string   `yaml:"repo,omitempty"`
+	Branch   string   `yaml:"branch,omitempty"`
+	Worktree string   `yaml:"worktree,omitempty"`
+	Created  string   `yaml:"created,omitempty"`
+	Finished string   `yaml:"finished,omitempty"`
+	URL      string   `yaml:"url,omitempty"`
+	Severity string   `yaml:"severity,omitempty"`
+	Tags     []string `yaml:"tags,omitempty"`
+}
+
+// fields returns the scalar fields in the order they are written
+func (f FrontMatter) fields() [][2]string {
+	return [][2]string{
+		{"ticket", f.Ticket},
+		{"type", f.Type},
+		{"status", f.Status},
+		{"repo", f.Repo},
+		{"branch", f.Branch},
+		{"worktree", f.Worktree},
+		{"created", f.Created},
+		{"finished", f.Finished},
+		{"url", f.URL},
+		{"severity", f.Severity},
+	}
+}
+
+// SplitFrontMatter splits content into its front matter block, including
+// the --- delimiters, and the body. The front matter is "" if the content
+// doesn't start with a complete block.
+func SplitFrontMatter(content string) (string, string) {
+	first, rest, found := strings.Cut(content, "\n")
+	if !found || strings.TrimRight(first, "\r") != frontMatterDelimiter {
+		return "", content
+	}
+
+	offset := len(first) + 1
+	for rest != "" {
+		line, next, hasNewline := strings.Cut(rest, "\n")
+		end := offset + len(line)
+		if hasNewline {
+			end++
+		}
+		if trimmed := strings.TrimRight(line, "\r"); trimmed == frontMatterDelimiter || trimmed == "..." {
+			return content[:end], content[end:]
+		}
+		offset = end
+		rest = next
+	}
+
+	return "", content
+}
+
+// ParseFrontMatter returns the rig fields from content's front matter. A
+// note without front matter yields an empty FrontMatter.
+func ParseFrontMatter(content string) (FrontMatter, error) {
+	var fm FrontMatter
+
+	head, _ := SplitFrontMatter(content)
+	if head == "" {
+		return fm, nil
+	}
+
+	if err := yaml.Unmarshal([]byte(frontMatterYAML(head)), &fm); err != nil {
+		return fm, errors.Wrap(err, "invalid front matter")
+	}
+	return fm, nil
+}
+
+// SetFrontMatter returns content with the non-empty fields of update
+// written to its front matter, adding a block if there is none. Tags are
+// added to any existing ones rather than replacing them. Other keys,
+// comments and the body are kept, and content is returned unchanged when
+// nothing differs.
+func SetFrontMatter(content string, update FrontMatter) (string, error) {
+	head, body := SplitFrontMatter(content)
+
+	var doc yaml.Node
+	if head != "" {
+		if err := yaml.Unmarshal([]byte(frontMatterYAML(head)), &doc); err != nil {
+			return content, errors.Wrap(err, "invalid front matter")
+		}
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+
+	mapping := doc.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return content, errors.New("front matter is not a mapping")
+	}
+
+	changed := false
+	for _, field := range update.fields() {
+		if field[1] != "" && setScalar(mapping, field[0], field[1]) {
+			changed = true
+		}
+	}
+	if addTags(mapping, update.Tags) {
+		changed = true
+	}
+
+	if !changed {
+		return content, nil
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return content, errors.Wrap(err, "failed to encode front matter")
+	}
+	if err := encoder.Close(); err != nil {
+		return content, errors.Wrap(err, "failed to encode front matter")
+	}
+
+	return frontMatterDelimiter + "\n" + buf.String() + frontMatterDelimiter + "\n" + body, nil
+}
+
+// frontMatterYAML strips the delimiters from a front matter block
+func frontMatterYAML(head string) string {
+	_, inner, _ := strings.Cut(head, "\n")
+	if i := strings.LastIndex(strings.TrimRight(inner, "\r\n"), "\n"); i >= 0 {
+		return inner[:i+1]
+	}
+	return ""
+}
+
+// setScalar sets key to value in mapping, appending the key if missing.
+// Reports whether the mapping changed.
+func setScalar(mapping *yaml.Node, key, value string) bool {
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	// Keep dates unquoted so Dataview and other tools see a date
+	if _, err := time.Parse("2006-01-02", value); err == nil {
+		node.Tag = "!!timestamp"
+	}
+
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != key {
+			continue
+		}
+		current := mapping.Content[i+1]
+		if current.Kind == yaml.ScalarNode && current.Value == value {
+			return false
+		}
+		node.HeadComment = current.HeadComment
+		node.LineComment = current.LineComment
+		mapping.Content[i+1] = node
+		return true
+	}
+
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, node)
+	return true
+}
+
+// addTags adds the missing tags to the mapping's tags list, creating it if
+// needed. A tags value that isn't a list is left alone. Reports whether the
+// mapping changed.
+func addTags(mapping *yaml.Node, tags []string) bool {
+	if len(tags) == 0 {
+		return false
+	}
+
+	var list *yaml.Node
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == "tags" {
+			list = mapping.Content[i+1]
+			break
+		}
+	}
+	if list == nil {
+		list = &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "tags"}, list)
+	} else if list.Kind != yaml.SequenceNode {
+		return false
+	}
+
+	existing := make(map[string]bool)
+	for _, item := range list.Content {
+		existing[item.Value] = true
+	}
+
+	changed := false
+	for _, tag := range tags {
+		if tag == "" || existing[tag] {
+			continue
+		}
+		list.Content = append(list.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: tag})
+		existing[tag] = true
+		changed = true
+	}
+	return changed
+}
+
+// TicketFrontMatter returns the front matter of a ticket's note
+func (m *Manager) TicketFrontMatter(ticketType, ticket string) (FrontMatter, error) {
+	content, err := os.ReadFile(m.GetNotePath(ticketType, ticket))
+	if err != nil {
+		return FrontMatter{}, errors.Wrap(err, "failed to read note")
+	}
+	return ParseFrontMatter(string(content))
+}
+
+// UpdateTicketFrontMatter writes the non-empty fields of update to the
+// front matter of a ticket's note, leaving the body untouched. Reports
+// whether the note changed.
+func (m *Manager) UpdateTicketFrontMatter(ticketType, ticket string, update FrontMatter) (bool, error) {
+	notePath := m.GetNotePath(ticketType, ticket)
+
+	content, err := os.ReadFile(notePath)
+	if os.IsNotExist(err) {
+		return false, errors.Newf("ticket note not found: %s", notePath)
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "failed to read note")
+	}
+
+	updated, err := SetFrontMatter(string(content), update)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to update %s", notePath)
+	}
+	if updated == string(content) {
+		return false, nil
+	}
+
+	if err := os.WriteFile(notePath, []byte(updated), 0600); err != nil {
+		return false, errors.Wrap(err, "failed to update note")
+	}
+	return true, nil
+}
@@ -0,0 +1,147 @@
+package notes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitFrontMatter(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantHead string
+		wantBody string
+	}{
+		{"none", "# proj-1\n", "", "# proj-1\n"},
+		{"block", "---\nticket: proj-1\n---\n# proj-1\n", "---\nticket: proj-1\n---\n", "# proj-1\n"},
+		{"empty block", "---\n---\nbody", "---\n---\n", "body"},
+		{"dots close", "---\na: b\n...\nbody", "---\na: b\n...\n", "body"},
+		{"unterminated", "---\na: b\n# title\n", "", "---\na: b\n# title\n"},
+		{"rule later", "# title\n---\nmore\n---\n", "", "# title\n---\nmore\n---\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			head, body := SplitFrontMatter(tt.content)
+			if head != tt.wantHead || body != tt.wantBody {
+				t.Errorf("SplitFrontMatter() = %q, %q, want %q, %q", head, body, tt.wantHead, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestParseFrontMatter(t *testing.T) {
+	content := "---\nticket: proj-1\ntype: proj\nstatus: In Progress\ncreated: 2025-01-15\ntags: [rig, proj]\nalias: Login\n---\n# Fix login\n"
+
+	fm, err := ParseFrontMatter(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fm.Ticket != "proj-1" || fm.Type != "proj" || fm.Status != "In Progress" || fm.Created != "2025-01-15" {
+		t.Errorf("ParseFrontMatter() = %+v", fm)
+	}
+	if strings.Join(fm.Tags, ",") != "rig,proj" {
+		t.Errorf("tags = %v", fm.Tags)
+	}
+
+	if fm, err := ParseFrontMatter("# no front matter\n"); err != nil || fm.Ticket != "" {
+		t.Errorf("note without front matter = %+v, %v", fm, err)
+	}
+	if _, err := ParseFrontMatter("---\n: [\n---\n"); err == nil {
+		t.Error("invalid YAML should fail")
+	}
+}
+
+func TestSetFrontMatter_AddsBlock(t *testing.T) {
+	body := "# proj-1\n\n## Summary\n"
+
+	got, err := SetFrontMatter(body, FrontMatter{Ticket: "proj-1", Status: "Done: won't fix", Created: "2025-01-15", Tags: []string{"rig", "proj"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "---\nticket: proj-1\nstatus: 'Done: won''t fix'\ncreated: 2025-01-15\ntags: [rig, proj]\n---\n" + body
+	if got != want {
+		t.Errorf("SetFrontMatter() =\n%s\nwant\n%s", got, want)
+	}
+
+	fm, err := ParseFrontMatter(got)
+	if err != nil || fm.Status != "Done: won't fix" {
+		t.Errorf("round trip = %+v, %v", fm, err)
+	}
+}
+
+func TestSetFrontMatter_UpdatesInPlace(t *testing.T) {
+	content := `---
+# managed by rig
+ticket: proj-1
+status: Open # from JIRA
+aliases: [Login bug]
+tags:
+  - rig
+  - urgent
+---
+# Fix login
+
+status: not front matter
+`
+
+	got, err := SetFrontMatter(content, FrontMatter{Status: "In Review", Branch: "proj-1", Tags: []string{"rig", "proj"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"# managed by rig\n", "status: In Review # from JIRA\n", "aliases: [Login bug]\n", "  - urgent\n  - proj\n", "branch: proj-1\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("updated front matter missing %q:\n%s", want, got)
+		}
+	}
+	if !strings.HasSuffix(got, "---\n# Fix login\n\nstatus: not front matter\n") {
+		t.Errorf("body changed:\n%s", got)
+	}
+
+	again, err := SetFrontMatter(got, FrontMatter{Status: "In Review", Tags: []string{"proj"}})
+	if err != nil || again != got {
+		t.Errorf("unchanged fields should leave content as-is, got\n%s", again)
+	}
+
+	if _, err := SetFrontMatter("---\n- a\n---\n", FrontMatter{Ticket: "x"}); err == nil {
+		t.Error("non-mapping front matter should fail")
+	}
+}
+
+func TestUpdateTicketFrontMatter(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := NewManager(tmpDir, "daily", "", false)
+
+	if _, err := m.UpdateTicketFrontMatter("proj", "proj-1", FrontMatter{Status: "Done"}); err == nil {
+		t.Error("missing note should fail")
+	}
+
+	notePath := m.GetNotePath("proj", "proj-1")
+	if err := os.MkdirAll(filepath.Dir(notePath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(notePath, []byte("# proj-1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := m.UpdateTicketFrontMatter("proj", "proj-1", FrontMatter{Ticket: "proj-1", Status: "Done"})
+	if err != nil || !changed {
+		t.Fatalf("UpdateTicketFrontMatter() = %v, %v", changed, err)
+	}
+	changed, err = m.UpdateTicketFrontMatter("proj", "proj-1", FrontMatter{Status: "Done"})
+	if err != nil || changed {
+		t.Errorf("repeat update = %v, %v, want unchanged", changed, err)
+	}
+
+	fm, err := m.TicketFrontMatter("proj", "proj-1")
+	if err != nil || fm.Status != "Done" {
+		t.Errorf("TicketFrontMatter() = %+v, %v", fm, err)
+	}
+	if got := m.TicketStatus("proj", "proj-1"); got != "Done" {
+		t.Errorf("TicketStatus() = %q, want front matter status", got)
+	}
+}
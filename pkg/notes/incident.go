@@ -0,0 +1,269 @@
+package notes
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	// IncidentType is the ticket type of incidents
+	IncidentType = "incident"
+
+	// TimelineSection is the incident note section 'rig incident event'
+	// adds to
+	TimelineSection = "Timeline"
+
+	// PostmortemTemplateName is the template postmortem drafts are rendered
+	// from
+	PostmortemTemplateName = "postmortem.md.tmpl"
+
+	// postmortemSuffix is added to the incident name for its postmortem
+	// draft, e.g. incident-789-postmortem.md
+	postmortemSuffix = "-postmortem"
+)
+
+// timelineEntryPattern matches a timeline entry, "- [2025-01-15 14:30] ..."
+var timelineEntryPattern = regexp.MustCompile(`^[-*]\s+\[(\d{4}-\d{2}-\d{2} \d{1,2}:\d{2})\]\s*(.*)$`)
+
+// TimelineEntry is an entry from the Timeline section of an incident note
+type TimelineEntry struct {
+	Time time.Time
+	Text string
+}
+
+// Postmortem holds the data postmortem templates are rendered with
+type Postmortem struct {
+	Ticket      string
+	Type        string
+	Summary     string
+	Severity    string // e.g. "2", "" if unknown
+	Link        string // Link to the incident note from the postmortem
+	Started     string // YYYY-MM-DD HH:MM of the first timeline entry
+	Resolved    string // YYYY-MM-DD HH:MM the incident was closed
+	Duration    string // Resolved - Started, e.g. "1h20m"
+	Impact      []string
+	Timeline    []PostmortemEvent
+	ActionItems []string
+	Commands    []PostmortemCommand
+	History     bool   // Whether command history was available
+	Generated   string // YYYY-MM-DD HH:MM
+}
+
+// PostmortemEvent is a timeline entry as shown in a postmortem
+type PostmortemEvent struct {
+	Time string // HH:MM, or YYYY-MM-DD HH:MM on a later day than the start
+	Text string
+}
+
+// PostmortemCommand is a command run during the incident
+type PostmortemCommand struct {
+	Time      string // HH:MM:SS
+	Command   string
+	Directory string
+	ExitCode  int
+}
+
+// PostmortemPath returns the path of the postmortem draft of an incident,
+// next to its note
+func (m *Manager) PostmortemPath(ticketType, ticket string) string {
+	return m.GetNotePath(ticketType, ticket+postmortemSuffix)
+}
+
+// siblingLink returns a link to the note named name from a note in the
+// same directory
+func (m *Manager) siblingLink(name string) string {
+	if m.isObsidian() {
+		return "[[" + name + "]]"
+	}
+	return fmt.Sprintf("[%s](%s.md)", name, name)
+}
+
+// ParseTimeline returns the entries of the Timeline section of an incident
+// note's content, in the order they appear. Lines that aren't timestamped
+// entries and fenced blocks are skipped.
+func ParseTimeline(content string) []TimelineEntry {
+	var entries []TimelineEntry
+	fence := ""
+	for _, line := range sectionLines(content, TimelineSection) {
+		if fence != "" {
+			if strings.HasPrefix(strings.TrimSpace(line), fence) {
+				fence = ""
+			}
+			continue
+		}
+		if marker := fenceMarker(line); marker != "" {
+			fence = marker
+			continue
+		}
+
+		match := timelineEntryPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		at, err := time.ParseInLocation("2006-01-02 15:04", match[1], time.Local)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, TimelineEntry{Time: at, Text: match[2]})
+	}
+	return entries
+}
+
+// sectionItems returns the list items of a note section that have some
+// text after the template's "Label:" placeholder
+func sectionItems(content, section string) []string {
+	var items []string
+	for _, line := range sectionLines(content, section) {
+		item, ok := strings.CutPrefix(strings.TrimSpace(line), "- ")
+		if !ok || strings.HasSuffix(item, ":") || strings.TrimSpace(item) == "" {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// sectionLines returns the lines of a note section after its heading, or
+// nil if the note has no such section
+func sectionLines(content, section string) []string {
+	lines := strings.Split(content, "\n")
+	start, end := findSection(lines, section)
+	if start < 0 {
+		return nil
+	}
+	return lines[start+1 : end]
+}
+
+// NewPostmortem returns the postmortem data of an incident closed at
+// resolved, from its note: the timeline entries, impact and action items.
+// The incident started with its first timeline entry, else the day its
+// note was created.
+func (m *Manager) NewPostmortem(ticketType, ticket string, resolved time.Time) (Postmortem, error) {
+	notePath := m.GetNotePath(ticketType, ticket)
+	contentBytes, err := os.ReadFile(notePath)
+	if os.IsNotExist(err) {
+		return Postmortem{}, errors.Newf("incident note not found: %s", notePath)
+	}
+	if err != nil {
+		return Postmortem{}, errors.Wrap(err, "failed to read note")
+	}
+	content := string(contentBytes)
+
+	fm, err := ParseFrontMatter(content)
+	if err != nil {
+		return Postmortem{}, errors.Wrapf(err, "failed to read %s", notePath)
+	}
+
+	pm := Postmortem{
+		Ticket:      ticket,
+		Type:        ticketType,
+		Summary:     m.TicketSummary(ticketType, ticket),
+		Severity:    fm.Severity,
+		Link:        m.siblingLink(ticket),
+		Resolved:    resolved.Format("2006-01-02 15:04"),
+		ActionItems: sectionItems(content, "Action Items"),
+		Generated:   time.Now().Format("2006-01-02 15:04"),
+	}
+
+	// The severity heads the postmortem already
+	for _, item := range sectionItems(content, "Impact") {
+		if !strings.HasPrefix(item, "Severity:") {
+			pm.Impact = append(pm.Impact, item)
+		}
+	}
+
+	timeline := ParseTimeline(content)
+	started := resolved
+	switch {
+	case len(timeline) > 0:
+		started = timeline[0].Time
+	case fm.Created != "":
+		if created, err := time.ParseInLocation("2006-01-02", fm.Created, time.Local); err == nil {
+			started = created
+		}
+	}
+	pm.Started = started.Format("2006-01-02 15:04")
+	pm.Duration = FormatDuration(resolved.Sub(started))
+
+	startDay := started.Format("2006-01-02")
+	for _, entry := range timeline {
+		at := entry.Time.Format("15:04")
+		if day := entry.Time.Format("2006-01-02"); day != startDay {
+			at = day + " " + at
+		}
+		pm.Timeline = append(pm.Timeline, PostmortemEvent{Time: at, Text: entry.Text})
+	}
+
+	return pm, nil
+}
+
+// StartedAt returns the time the incident of a postmortem started
+func (p Postmortem) StartedAt() time.Time {
+	at, _ := time.ParseInLocation("2006-01-02 15:04", p.Started, time.Local)
+	return at
+}
+
+// RenderPostmortem renders a postmortem draft with its template, adding
+// front matter that ties it to the incident
+func (m *Manager) RenderPostmortem(pm Postmortem) (string, error) {
+	content, err := m.executeTemplate(PostmortemTemplateName, pm)
+	if err != nil {
+		return "", err
+	}
+
+	content, err = SetFrontMatter(content, FrontMatter{
+		Ticket:   pm.Ticket,
+		Type:     pm.Type,
+		Severity: pm.Severity,
+		Tags:     []string{"rig", pm.Type, "postmortem"},
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "template %s", PostmortemTemplateName)
+	}
+	return content, nil
+}
+
+// WritePostmortem renders the postmortem draft of an incident and writes
+// it to PostmortemPath, replacing any earlier draft. Returns the path.
+func (m *Manager) WritePostmortem(pm Postmortem) (string, error) {
+	path := m.PostmortemPath(pm.Type, pm.Ticket)
+
+	content, err := m.RenderPostmortem(pm)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to render postmortem")
+	}
+
+	// Restricted permissions, the draft quotes command history
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		return "", errors.Wrap(err, "failed to write postmortem")
+	}
+	return path, nil
+}
+
+// SamplePostmortem returns placeholder data for dry-rendering postmortem
+// templates
+func SamplePostmortem() Postmortem {
+	now := time.Now()
+	started := now.Add(-95 * time.Minute)
+	return Postmortem{
+		Ticket:      "incident-123",
+		Type:        IncidentType,
+		Summary:     "API returning 502s",
+		Severity:    "2",
+		Link:        "[incident-123](incident-123.md)",
+		Started:     started.Format("2006-01-02 15:04"),
+		Resolved:    now.Format("2006-01-02 15:04"),
+		Duration:    "1h35m",
+		Impact:      []string{"Affected: checkout API"},
+		Timeline:    []PostmortemEvent{{Time: started.Format("15:04"), Text: "Declared SEV2"}, {Time: now.Format("15:04"), Text: "Resolved"}},
+		ActionItems: []string{"[ ] Alert on 5xx rate"},
+		Commands:    []PostmortemCommand{{Time: started.Add(5 * time.Minute).Format("15:04:05"), Command: "kubectl rollout undo deploy/api", Directory: "/path/to/myrepo/incident/incident-123"}},
+		History:     true,
+		Generated:   now.Format("2006-01-02 15:04"),
+	}
+}
@@ -0,0 +1,127 @@
+package notes
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const incidentNote = `---
+ticket: incident-7
+type: incident
+created: 2025-01-15
+severity: "2"
+---
+# incident-7
+
+## Summary
+
+Checkout API returning 502s
+
+## Impact
+
+- Severity: SEV2
+- Affected: checkout
+- Customer facing:
+
+## Timeline
+- [2025-01-15 23:40] Declared SEV2
+Paged the on-call DBA
+- [2025-01-16 00:15] Rolled back
+
+` + "```" + `
+- [2025-01-16 00:20] not an entry
+` + "```" + `
+
+## Action Items
+- [ ] Alert on 5xx rate
+
+## Log
+`
+
+func TestParseTimeline(t *testing.T) {
+	want := []TimelineEntry{
+		{Time: time.Date(2025, 1, 15, 23, 40, 0, 0, time.Local), Text: "Declared SEV2"},
+		{Time: time.Date(2025, 1, 16, 0, 15, 0, 0, time.Local), Text: "Rolled back"},
+	}
+	if got := ParseTimeline(incidentNote); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseTimeline() = %+v, want %+v", got, want)
+	}
+
+	if got := ParseTimeline("# No timeline\n"); got != nil {
+		t.Errorf("ParseTimeline() without a Timeline section = %+v", got)
+	}
+}
+
+func TestNewPostmortem(t *testing.T) {
+	basePath := t.TempDir()
+	m := NewManager(basePath, "daily", "", false)
+	writeTemplate(t, filepath.Join(basePath, "incident"), "incident-7.md", incidentNote)
+
+	resolved := time.Date(2025, 1, 16, 1, 0, 0, 0, time.Local)
+	pm, err := m.NewPostmortem("incident", "incident-7", resolved)
+	if err != nil {
+		t.Fatalf("NewPostmortem() error: %v", err)
+	}
+
+	if pm.Summary != "Checkout API returning 502s" || pm.Severity != "2" || pm.Link != "[incident-7](incident-7.md)" {
+		t.Errorf("summary, severity, link = %q, %q, %q", pm.Summary, pm.Severity, pm.Link)
+	}
+	if pm.Started != "2025-01-15 23:40" || pm.Resolved != "2025-01-16 01:00" || pm.Duration != "1h20m" {
+		t.Errorf("started %q, resolved %q, duration %q", pm.Started, pm.Resolved, pm.Duration)
+	}
+	if !pm.StartedAt().Equal(time.Date(2025, 1, 15, 23, 40, 0, 0, time.Local)) {
+		t.Errorf("StartedAt() = %v", pm.StartedAt())
+	}
+	if !reflect.DeepEqual(pm.Impact, []string{"Affected: checkout"}) || !reflect.DeepEqual(pm.ActionItems, []string{"[ ] Alert on 5xx rate"}) {
+		t.Errorf("impact %q, action items %q", pm.Impact, pm.ActionItems)
+	}
+	if len(pm.Timeline) != 2 || pm.Timeline[0].Time != "23:40" || pm.Timeline[1].Time != "2025-01-16 00:15" {
+		t.Errorf("timeline = %+v", pm.Timeline)
+	}
+
+	// Without a timeline the incident started the day its note was created
+	writeTemplate(t, filepath.Join(basePath, "incident"), "incident-8.md", "---\ncreated: 2025-01-15\n---\n# incident-8\n")
+	if pm, err := m.NewPostmortem("incident", "incident-8", resolved); err != nil || pm.Started != "2025-01-15 00:00" || pm.Timeline != nil {
+		t.Errorf("NewPostmortem() without a timeline = %+v, %v", pm, err)
+	}
+
+	if _, err := m.NewPostmortem("incident", "incident-9", resolved); err == nil {
+		t.Error("NewPostmortem() should fail without an incident note")
+	}
+}
+
+func TestWritePostmortem(t *testing.T) {
+	basePath := t.TempDir()
+	m := newObsidianManager(basePath, "")
+	writeTemplate(t, filepath.Dir(m.GetNotePath("incident", "incident-7")), "incident-7.md", incidentNote)
+
+	pm, err := m.NewPostmortem("incident", "incident-7", time.Date(2025, 1, 16, 1, 0, 0, 0, time.Local))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := m.WritePostmortem(pm)
+	if err != nil {
+		t.Fatalf("WritePostmortem() error: %v", err)
+	}
+	if path != filepath.Join(basePath, "Areas/Incidents", "incident-7-postmortem.md") {
+		t.Errorf("path = %s", path)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fm, err := ParseFrontMatter(string(content))
+	if err != nil || fm.Ticket != "incident-7" || fm.Severity != "2" || !reflect.DeepEqual(fm.Tags, []string{"rig", "incident", "postmortem"}) {
+		t.Errorf("front matter = %+v, %v", fm, err)
+	}
+	for _, want := range []string{"# Postmortem: incident-7 - Checkout API returning 502s", "- Incident: [[incident-7]]", "- **2025-01-16 00:15** Rolled back", "Command history was not available."} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("postmortem missing %q:\n%s", want, content)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package notes
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	// IndexNoteName is the file name, without .md, of index notes. No ticket
+	// or hack can have it.
+	IndexNoteName = "_index"
+
+	// IndexTemplateName is the template index notes are rendered from
+	IndexTemplateName = "index.md.tmpl"
+
+	// indexNotesSection is the hand-edited section of an index note, kept
+	// when the index is regenerated
+	indexNotesSection = "Notes"
+
+	// noStatus groups tickets whose note records no status
+	noStatus = "No status"
+
+	// doneStatus groups finished tickets whose note records no status
+	doneStatus = "Done"
+)
+
+// finishedStatuses are tracker statuses that mean the work is over
+var finishedStatuses = []string{"done", "closed", "resolved", "cancelled", "canceled", "won't do"}
+
+// Index holds the data index note templates are rendered with
+type Index struct {
+	Title     string
+	Type      string // Ticket type, "" for the overall index
+	Generated string // YYYY-MM-DD HH:MM
+	Total     int
+	Groups    []IndexGroup
+	Types     []IndexType // Per-type indexes, in the overall index
+}
+
+// IndexGroup is the tickets with one status
+type IndexGroup struct {
+	Status   string
+	Finished bool // Whether all its tickets are finished
+	Tickets  []IndexTicket
+}
+
+// IndexType links the overall index to a per-type index
+type IndexType struct {
+	Type  string
+	Link  string
+	Total int
+}
+
+// IndexTicket is a ticket listed in an index note
+type IndexTicket struct {
+	Ticket   string
+	Type     string
+	Summary  string
+	Status   string
+	Created  string // YYYY-MM-DD, "" if unknown
+	Finished string // YYYY-MM-DD, "" if not finished
+	Link     string // Link to the ticket note from the index note
+	Path     string
+}
+
+// IndexNoteResult reports an index note written by WriteIndexNotes
+type IndexNoteResult struct {
+	Path    string
+	Type    string // "" for the overall index
+	Tickets int
+	Changed bool
+}
+
+// IndexNotePath returns the path of the index note of a ticket type, in
+// the type's note directory, or of the overall index for ""
+func (m *Manager) IndexNotePath(ticketType string) string {
+	if ticketType == "" {
+		return filepath.Join(m.BasePath, m.Subdir, IndexNoteName+".md")
+	}
+	return m.GetNotePath(ticketType, IndexNoteName)
+}
+
+// indexLink returns a link from the index note at indexPath to the note at
+// path: a wikilink in the Obsidian flavour, else a relative markdown link
+func (m *Manager) indexLink(indexPath, path, text string) string {
+	if m.isObsidian() {
+		target := strings.TrimSuffix(filepath.Base(path), ".md")
+		if target != text {
+			// Index notes share a name, so link them by vault path
+			rel, err := filepath.Rel(m.BasePath, path)
+			if err == nil {
+				target = filepath.ToSlash(strings.TrimSuffix(rel, ".md"))
+			}
+			return "[[" + target + "|" + text + "]]"
+		}
+		return "[[" + target + "]]"
+	}
+
+	rel, err := filepath.Rel(filepath.Dir(indexPath), path)
+	if err != nil {
+		rel = path
+	}
+	return fmt.Sprintf("[%s](%s)", text, filepath.ToSlash(rel))
+}
+
+// IndexTickets returns the tickets and hacks with a note, from each note's
+// front matter and body. Notes whose front matter doesn't name their ticket,
+// such as postmortem drafts, are skipped.
+func (m *Manager) IndexTickets() ([]IndexTicket, error) {
+	files, err := m.ListNotes()
+	if err != nil {
+		return nil, err
+	}
+
+	var tickets []IndexTicket
+	for _, file := range files {
+		if file.Kind != KindTicket {
+			continue
+		}
+
+		contentBytes, err := os.ReadFile(file.Path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s", file.Path)
+		}
+		content := string(contentBytes)
+
+		// Only notes with rig's front matter are listed; 'rig sync' adds it
+		// to older notes
+		fm, err := ParseFrontMatter(content)
+		if err != nil || fm.Ticket != file.Name {
+			continue
+		}
+
+		tickets = append(tickets, IndexTicket{
+			Ticket:   file.Name,
+			Type:     file.Type,
+			Summary:  noteSummary(content, file.Name),
+			Status:   noteStatus(content),
+			Created:  fm.Created,
+			Finished: fm.Finished,
+			Path:     file.Path,
+		})
+	}
+	return tickets, nil
+}
+
+// IsFinishedStatus reports whether a tracker status, such as Done or
+// Closed, means the work on a ticket is over
+func IsFinishedStatus(status string) bool {
+	return slices.Contains(finishedStatuses, strings.ToLower(status))
+}
+
+// isFinished reports whether a ticket is finished: it has a finished date
+// or a finished status
+func (t IndexTicket) isFinished() bool {
+	return t.Finished != "" || IsFinishedStatus(t.Status)
+}
+
+// groupStatus is the status a ticket is listed under
+func (t IndexTicket) groupStatus() string {
+	switch {
+	case t.Status != "":
+		return t.Status
+	case t.Finished != "":
+		return doneStatus
+	}
+	return noStatus
+}
+
+// groupTickets groups tickets by status: groups with unfinished tickets
+// first, then those of finished ones, each by name. Tickets are listed
+// newest first.
+func groupTickets(tickets []IndexTicket) []IndexGroup {
+	var groups []IndexGroup
+	index := make(map[string]int)
+	for _, ticket := range tickets {
+		status := ticket.groupStatus()
+		i, ok := index[status]
+		if !ok {
+			i = len(groups)
+			index[status] = i
+			groups = append(groups, IndexGroup{Status: status, Finished: true})
+		}
+		groups[i].Tickets = append(groups[i].Tickets, ticket)
+		groups[i].Finished = groups[i].Finished && ticket.isFinished()
+	}
+
+	for _, group := range groups {
+		slices.SortFunc(group.Tickets, func(a, b IndexTicket) int {
+			if c := cmp.Compare(b.Created, a.Created); c != 0 {
+				return c
+			}
+			return strings.Compare(a.Ticket, b.Ticket)
+		})
+	}
+	slices.SortFunc(groups, func(a, b IndexGroup) int {
+		if a.Finished != b.Finished {
+			if a.Finished {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(a.Status, b.Status)
+	})
+	return groups
+}
+
+// BuildIndexes returns the overall index followed by one index per ticket
+// type, by type
+func (m *Manager) BuildIndexes(now time.Time) ([]Index, error) {
+	tickets, err := m.IndexTickets()
+	if err != nil {
+		return nil, err
+	}
+
+	byType := make(map[string][]IndexTicket)
+	for _, ticket := range tickets {
+		byType[ticket.Type] = append(byType[ticket.Type], ticket)
+	}
+	types := make([]string, 0, len(byType))
+	for ticketType := range byType {
+		types = append(types, ticketType)
+	}
+	slices.Sort(types)
+
+	generated := now.Format("2006-01-02 15:04")
+	overall := Index{Title: "Tickets", Generated: generated, Total: len(tickets)}
+	overall.Groups = groupTickets(m.linkTickets(m.IndexNotePath(""), tickets))
+	for _, ticketType := range types {
+		overall.Types = append(overall.Types, IndexType{
+			Type:  ticketType,
+			Link:  m.indexLink(m.IndexNotePath(""), m.IndexNotePath(ticketType), ticketType),
+			Total: len(byType[ticketType]),
+		})
+	}
+
+	indexes := []Index{overall}
+	for _, ticketType := range types {
+		typeTickets := byType[ticketType]
+		indexes = append(indexes, Index{
+			Title:     ticketType + " tickets",
+			Type:      ticketType,
+			Generated: generated,
+			Total:     len(typeTickets),
+			Groups:    groupTickets(m.linkTickets(m.IndexNotePath(ticketType), typeTickets)),
+		})
+	}
+	return indexes, nil
+}
+
+// linkTickets returns copies of tickets linked from the index note at
+// indexPath
+func (m *Manager) linkTickets(indexPath string, tickets []IndexTicket) []IndexTicket {
+	linked := slices.Clone(tickets)
+	for i := range linked {
+		linked[i].Link = m.indexLink(indexPath, linked[i].Path, linked[i].Ticket)
+	}
+	return linked
+}
+
+// RenderIndex renders an index note with its template
+func (m *Manager) RenderIndex(index Index) (string, error) {
+	return m.executeTemplate(IndexTemplateName, index)
+}
+
+// WriteIndexNotes regenerates the overall and per-type index notes. The
+// Notes section of an existing index note is kept, and notes are only
+// written when their content changes.
+func (m *Manager) WriteIndexNotes(now time.Time) ([]IndexNoteResult, error) {
+	indexes, err := m.BuildIndexes(now)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]IndexNoteResult, 0, len(indexes))
+	for _, index := range indexes {
+		path := m.IndexNotePath(index.Type)
+		rendered, err := m.RenderIndex(index)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to render index note")
+		}
+
+		existing, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, errors.Wrap(err, "failed to read index note")
+		}
+		if err == nil {
+			rendered = replaceSection(rendered, string(existing), indexNotesSection)
+		}
+
+		result := IndexNoteResult{Path: path, Type: index.Type, Tickets: index.Total}
+		if rendered != string(existing) {
+			if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+				return nil, errors.Wrap(err, "failed to create note directory")
+			}
+			if err := os.WriteFile(path, []byte(rendered), 0600); err != nil {
+				return nil, errors.Wrap(err, "failed to write index note")
+			}
+			result.Changed = true
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// SampleIndex returns placeholder data for dry-rendering index templates
+func SampleIndex() Index {
+	now := time.Now()
+	today := now.Format("2006-01-02")
+	lastWeek := now.AddDate(0, 0, -7).Format("2006-01-02")
+	return Index{
+		Title:     "Tickets",
+		Generated: now.Format("2006-01-02 15:04"),
+		Total:     2,
+		Types: []IndexType{
+			{Type: "proj", Link: "[proj](proj/_index.md)", Total: 2},
+		},
+		Groups: []IndexGroup{
+			{Status: "In Progress", Tickets: []IndexTicket{
+				{Ticket: "proj-124", Type: "proj", Summary: "Sample ticket summary", Status: "In Progress", Created: today, Link: "[proj-124](proj/proj-124.md)"},
+			}},
+			{Status: "Done", Finished: true, Tickets: []IndexTicket{
+				{Ticket: "proj-123", Type: "proj", Summary: "Earlier sample ticket", Status: "Done", Created: lastWeek, Finished: today, Link: "[proj-123](proj/proj-123.md)"},
+			}},
+		},
+	}
+}
@@ -0,0 +1,139 @@
+package notes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGroupTickets(t *testing.T) {
+	tickets := []IndexTicket{
+		{Ticket: "proj-1", Status: "Done", Created: "2025-01-02"},
+		{Ticket: "proj-2", Status: "In Progress", Created: "2025-01-03"},
+		{Ticket: "proj-3", Status: "In Progress", Created: "2025-01-05"},
+		{Ticket: "spike", Finished: "2025-01-04"},
+		{Ticket: "proj-4", Status: "Backlog"},
+		{Ticket: "proj-5"},
+		{Ticket: "proj-6", Status: "closed", Created: "2025-01-01"},
+	}
+
+	var got []string
+	for _, group := range groupTickets(tickets) {
+		names := make([]string, 0, len(group.Tickets))
+		for _, ticket := range group.Tickets {
+			names = append(names, ticket.Ticket)
+		}
+		got = append(got, group.Status+":"+strings.Join(names, ","))
+	}
+
+	want := "Backlog:proj-4 In Progress:proj-3,proj-2 No status:proj-5 Done:proj-1,spike closed:proj-6"
+	if strings.Join(got, " ") != want {
+		t.Errorf("groupTickets() = %s, want %s", strings.Join(got, " "), want)
+	}
+}
+
+func TestWriteIndexNotes(t *testing.T) {
+	base := t.TempDir()
+	m := NewManager(base, "daily", "", false)
+
+	writeNote(t, m.GetNotePath("proj", "proj-1"), "---\nticket: proj-1\nstatus: In Progress\ncreated: 2025-01-10\n---\n# Fix login\n")
+	writeNote(t, m.GetNotePath("proj", "proj-2"), "---\nticket: proj-2\nstatus: Done\ncreated: 2025-01-02\nfinished: 2025-01-08\n---\n# Add caching\n")
+	writeNote(t, m.GetNotePath("incident", "incident-7"), "---\nticket: incident-7\nstatus: Resolved\ncreated: 2025-01-15\n---\n# incident-7\n")
+	writeNote(t, m.PostmortemPath("incident", "incident-7"), "---\nticket: incident-7\n---\n# Postmortem: incident-7\n")
+	writeNote(t, filepath.Join(base, "daily", "2025-01-15.md"), "## Log\n")
+	writeNote(t, m.GetNotePath("proj", "proj-9"), "# proj-9\n")
+	writeNote(t, filepath.Join(base, "Recipes", "pancakes.md"), "# Pancakes\n")
+
+	now := time.Date(2025, 1, 16, 9, 0, 0, 0, time.Local)
+	results, err := m.WriteIndexNotes(now)
+	if err != nil {
+		t.Fatalf("WriteIndexNotes() error: %v", err)
+	}
+	if len(results) != 3 || results[0].Path != filepath.Join(base, "_index.md") || results[0].Tickets != 3 || !results[0].Changed {
+		t.Fatalf("WriteIndexNotes() = %+v", results)
+	}
+
+	overall, err := os.ReadFile(m.IndexNotePath(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"## Types\n\n- [incident](incident/_index.md) (1)\n- [proj](proj/_index.md) (2)\n",
+		"## In Progress (1)\n\n- [proj-1](proj/proj-1.md) Fix login · created 2025-01-10\n",
+		"## Done (1)\n\n- [proj-2](proj/proj-2.md) Add caching · created 2025-01-02 · finished 2025-01-08\n",
+		"## Resolved (1)\n\n- [incident-7](incident/incident-7.md) · created 2025-01-15\n",
+	} {
+		if !strings.Contains(string(overall), want) {
+			t.Errorf("overall index missing %q:\n%s", want, overall)
+		}
+	}
+	for _, unwanted := range []string{"postmortem", "proj-9", "Recipes", "pancakes"} {
+		if strings.Contains(string(overall), unwanted) {
+			t.Errorf("overall index should not list %q:\n%s", unwanted, overall)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(base, "Recipes", "_index.md")); !os.IsNotExist(err) {
+		t.Errorf("other vault folders should get no index note, got %v", err)
+	}
+
+	// The Notes section is kept, and unchanged indexes aren't rewritten
+	projIndex := m.IndexNotePath("proj")
+	content, err := os.ReadFile(projIndex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := strings.Replace(string(content), "## Notes\n", "## Notes\n\nQ1 goals: ship login.\n", 1)
+	writeNote(t, projIndex, edited)
+	writeNote(t, m.GetNotePath("proj", "proj-3"), "---\nticket: proj-3\nstatus: In Progress\ncreated: 2025-01-16\n---\n# Rate limits\n")
+
+	results, err = m.WriteIndexNotes(now)
+	if err != nil {
+		t.Fatalf("WriteIndexNotes() error: %v", err)
+	}
+	if !results[0].Changed || results[1].Changed || !results[2].Changed {
+		t.Errorf("changed = %v, %v, %v; want only the overall and proj indexes", results[0].Changed, results[1].Changed, results[2].Changed)
+	}
+	content, _ = os.ReadFile(projIndex)
+	if !strings.Contains(string(content), "## Notes\n\nQ1 goals: ship login.\n\n## In Progress (2)\n\n- [proj-3](proj-3.md) Rate limits · created 2025-01-16\n- [proj-1](proj-1.md)") {
+		t.Errorf("unexpected proj index:\n%s", content)
+	}
+}
+
+func TestWriteIndexNotes_Obsidian(t *testing.T) {
+	base := t.TempDir()
+	m := newObsidianManager(base, "")
+	writeNote(t, m.GetNotePath("incident", "incident-7"), "---\nticket: incident-7\n---\n# incident-7\n")
+
+	if _, err := m.WriteIndexNotes(time.Now()); err != nil {
+		t.Fatalf("WriteIndexNotes() error: %v", err)
+	}
+
+	overall, err := os.ReadFile(filepath.Join(base, "Areas/Work/_index.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"- [[Areas/Incidents/_index|incident]] (1)", "## No status (1)\n\n- [[incident-7]]\n"} {
+		if !strings.Contains(string(overall), want) {
+			t.Errorf("overall index missing %q:\n%s", want, overall)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(base, "Areas/Incidents/_index.md")); err != nil {
+		t.Errorf("incident index should be written: %v", err)
+	}
+}
+
+func TestWriteIndexNotes_Empty(t *testing.T) {
+	base := t.TempDir()
+	m := NewManager(base, "daily", "", false)
+
+	results, err := m.WriteIndexNotes(time.Now())
+	if err != nil || len(results) != 1 {
+		t.Fatalf("WriteIndexNotes() = %+v, %v", results, err)
+	}
+	content, _ := os.ReadFile(results[0].Path)
+	if string(content) != "# Tickets\n\n## Notes\n\nNo ticket notes yet.\n" {
+		t.Errorf("unexpected empty index:\n%q", content)
+	}
+}
@@ -1,13 +1,12 @@
 package notes
 
 import (
-	"bytes"
 	"embed"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
-	"text/template"
 	"time"
 
 	"github.com/cockroachdb/errors"
@@ -16,12 +15,27 @@ import (
 //go:embed templates/*.tmpl
 var defaultTemplates embed.FS
 
+// Note flavours select how notes link to each other and which templates
+// they accept
+const (
+	// FlavorMarkdown uses relative markdown links
+	FlavorMarkdown = "markdown"
+	// FlavorObsidian uses [[wikilinks]] and resolves Templater placeholders
+	FlavorObsidian = "obsidian"
+)
+
 // Manager handles markdown note operations
 type Manager struct {
-	BasePath    string // Root path for notes
-	DailyDir    string // Relative path for daily notes
-	TemplateDir string // Optional user template directory
-	Verbose     bool
+	BasePath    string            // Root path for notes
+	DailyDir    string            // Relative path for daily notes
+	TemplateDir string            // Optional user template directory
+	Flavor      string            // FlavorMarkdown (the default) or FlavorObsidian
+	Subdir      string            // Optional relative directory holding ticket type directories
+	TypeSubdirs map[string]string // Relative directories for specific ticket types
+	LogFormats  map[string]string // Daily log entry templates by event, overriding the defaults
+
+	DedupeWindow time.Duration // Repeats of a daily log entry within this are skipped
+	Verbose      bool
 }
 
 // TicketData holds data for template rendering
@@ -36,27 +50,80 @@ type TicketData struct {
 	RepoName     string // e.g., "myrepo"
 	RepoPath     string // e.g., "/Users/jim/src/myorg/myrepo"
 	WorktreePath string // e.g., "/Users/jim/src/myorg/myrepo/proj/proj-123"
+	Branch       string // e.g., "proj-123"
+	URL          string // Tracker link (if jira.base_url is set)
+	Severity     string // Incident severity, e.g. "2" (rig incident start)
+}
+
+// frontMatter returns the front matter fields for a new note
+func (d TicketData) frontMatter() FrontMatter {
+	return FrontMatter{
+		Ticket:   d.Ticket,
+		Type:     d.TicketType,
+		Status:   d.Status,
+		Repo:     d.RepoName,
+		Branch:   d.Branch,
+		Worktree: d.WorktreePath,
+		Created:  d.Date,
+		URL:      d.URL,
+		Severity: d.Severity,
+		Tags:     []string{"rig", d.TicketType},
+	}
 }
 
 // NewManager creates a new note Manager
 func NewManager(basePath, dailyDir, templateDir string, verbose bool) *Manager {
 	return &Manager{
-		BasePath:    basePath,
-		DailyDir:    dailyDir,
-		TemplateDir: templateDir,
-		Verbose:     verbose,
+		BasePath:     basePath,
+		DailyDir:     dailyDir,
+		TemplateDir:  templateDir,
+		DedupeWindow: DefaultDedupeWindow,
+		Verbose:      verbose,
 	}
 }
 
 // GetNotePath returns the path for a ticket note
 func (m *Manager) GetNotePath(ticketType, ticket string) string {
-	return filepath.Join(m.BasePath, ticketType, ticket+".md")
+	return filepath.Join(m.BasePath, m.typeDir(ticketType), ticket+".md")
+}
+
+// typeDir returns the directory for notes of ticketType, relative to the
+// base path: its type_subdirs entry, else the type under Subdir
+func (m *Manager) typeDir(ticketType string) string {
+	if dir, ok := m.TypeSubdirs[ticketType]; ok && dir != "" {
+		return dir
+	}
+	return filepath.Join(m.Subdir, ticketType)
+}
+
+// isObsidian reports whether notes use the Obsidian flavour
+func (m *Manager) isObsidian() bool {
+	return m.Flavor == FlavorObsidian
+}
+
+// dailyLinkTarget returns the path of a ticket note relative to the daily
+// notes directory, as used in markdown links
+func (m *Manager) dailyLinkTarget(ticketType, ticket string) string {
+	dailyDir := filepath.Join(m.BasePath, m.DailyDir)
+	rel, err := filepath.Rel(dailyDir, m.GetNotePath(ticketType, ticket))
+	if err != nil {
+		rel = filepath.Join("..", m.typeDir(ticketType), ticket+".md")
+	}
+	return filepath.ToSlash(rel)
+}
+
+// DailyLink returns a link to a ticket note from a daily note: a wikilink
+// in the Obsidian flavour, else a relative markdown link
+func (m *Manager) DailyLink(ticketType, ticket string) string {
+	if m.isObsidian() {
+		return "[[" + ticket + "]]"
+	}
+	return fmt.Sprintf("[%s](%s)", ticket, m.dailyLinkTarget(ticketType, ticket))
 }
 
 // GetDailyNotePath returns the path for today's daily note
 func (m *Manager) GetDailyNotePath() string {
-	today := time.Now().Format("2006-01-02")
-	return filepath.Join(m.BasePath, m.DailyDir, today+".md")
+	return m.DailyNotePath(time.Now())
 }
 
 // CreateTicketNote creates or returns existing ticket note
@@ -87,10 +154,7 @@ func (m *Manager) CreateTicketNote(data TicketData) (string, error) {
 	}
 
 	// Determine which template to use
-	templateName := "ticket.md.tmpl"
-	if data.TicketType == "hack" {
-		templateName = "hack.md.tmpl"
-	}
+	templateName := m.TicketTemplateName(data.TicketType)
 
 	// Fill in date/time if not set
 	if data.Date == "" {
@@ -101,7 +165,7 @@ func (m *Manager) CreateTicketNote(data TicketData) (string, error) {
 	}
 
 	// Render template
-	content, err := m.renderTemplate(templateName, data)
+	content, err := m.RenderTicketNote(templateName, data)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to render template")
 	}
@@ -118,139 +182,376 @@ func (m *Manager) CreateTicketNote(data TicketData) (string, error) {
 	return notePath, nil
 }
 
-// UpdateDailyNote adds an entry to the daily note, creating it if necessary
-func (m *Manager) UpdateDailyNote(ticket, ticketType string) error {
-	today := time.Now().Format("2006-01-02")
-	currentTime := time.Now().Format("15:04")
-	dailyNotePath := m.GetDailyNotePath()
+// RenderTicketNote renders a ticket note from the named template, adding
+// rig's front matter to any the template wrote
+func (m *Manager) RenderTicketNote(templateName string, data TicketData) (string, error) {
+	content, err := m.RenderTemplate(templateName, data)
+	if err != nil {
+		return "", err
+	}
 
-	if m.Verbose {
-		fmt.Printf("Updating daily note at: %s\n", dailyNotePath)
+	content, err = SetFrontMatter(content, data.frontMatter())
+	if err != nil {
+		return "", errors.Wrapf(err, "template %s", templateName)
 	}
+	return content, nil
+}
 
-	var content string
+// insertLogEntry inserts a log entry into the Log section of the note
+// content. With a subsection, the entry goes at the end of the section's
+// "### subsection" heading, which is added at the end of the Log section
+// if it is missing.
+func (m *Manager) insertLogEntry(content, logEntry, subsection string) string {
+	lines := strings.Split(content, "\n")
 
-	// Check if daily note exists, create if not
-	if _, statErr := os.Stat(dailyNotePath); os.IsNotExist(statErr) {
-		// Create the daily directory if needed (0700 for user-only access)
-		dailyDir := filepath.Dir(dailyNotePath)
-		if err := os.MkdirAll(dailyDir, 0700); err != nil {
-			return errors.Wrap(err, "failed to create daily notes directory")
+	// Look for ## Log section
+	start, end := findSection(lines, "Log")
+	if start < 0 {
+		// If no ## Log section found, add it at the end
+		if subsection != "" {
+			logEntry = "### " + subsection + "\n" + logEntry
 		}
+		return content + "\n\n## Log\n" + logEntry
+	}
 
-		// Render daily template
-		data := TicketData{Date: today}
-		rendered, err := m.renderTemplate("daily.md.tmpl", data)
-		if err != nil {
-			return errors.Wrap(err, "failed to render daily template")
-		}
-		content = rendered
+	if subsection == "" {
+		return strings.Join(slices.Insert(lines, end, logEntry), "\n")
+	}
 
-		if m.Verbose {
-			fmt.Printf("Creating new daily note for %s\n", today)
+	heading := "### " + subsection
+	subStart := slices.Index(lines[start+1:end], heading)
+	if subStart < 0 {
+		// Start a new subsection after the section's last line
+		last := lastContentLine(lines, start, end)
+		insert := []string{heading, logEntry}
+		if last > start {
+			insert = slices.Insert(insert, 0, "")
 		}
-	} else {
-		// Read existing content
-		contentBytes, err := os.ReadFile(dailyNotePath)
-		if err != nil {
-			return errors.Wrap(err, "failed to read daily note")
+		if last+1 == len(lines) {
+			// The entry ends the note
+			insert = append(insert, "")
 		}
-		content = string(contentBytes)
+		return strings.Join(slices.Insert(lines, last+1, insert...), "\n")
 	}
 
-	// Calculate relative path from daily note to ticket note
-	// Daily note: {base}/daily/2025-01-15.md
-	// Ticket note: {base}/proj/proj-123.md
-	// Relative path: ../proj/proj-123.md
-	relativePath := filepath.Join("..", ticketType, ticket+".md")
+	subStart += start + 1
+	subEnd := end
+	for i := subStart + 1; i < end; i++ {
+		if strings.HasPrefix(lines[i], "### ") {
+			subEnd = i
+			break
+		}
+	}
+	last := lastContentLine(lines, subStart, subEnd)
+	return strings.Join(slices.Insert(lines, last+1, logEntry), "\n")
+}
 
-	// Create log entry with relative markdown link
-	logEntry := fmt.Sprintf("- [%s] [%s](%s)", currentTime, ticket, relativePath)
+// lastContentLine returns the index of the last non-blank line from start
+// up to end
+func lastContentLine(lines []string, start, end int) int {
+	for end > start+1 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	return end - 1
+}
 
-	// Update the daily note
-	updatedContent := m.insertLogEntry(content, logEntry)
+// insertListItem adds a one-line list item to the end of the "## section"
+// section of the note content, directly after the section's last item, and
+// adds the section at the end if it is missing
+func (m *Manager) insertListItem(content, section, item string) string {
+	content = strings.TrimRight(content, "\n")
+	lines := strings.Split(content, "\n")
 
-	// Write back to file with restricted permissions
-	if err := os.WriteFile(dailyNotePath, []byte(updatedContent), 0600); err != nil {
-		return errors.Wrap(err, "failed to update daily note")
+	start, end := findSection(lines, section)
+	if start < 0 {
+		return content + "\n\n## " + section + "\n" + item + "\n"
 	}
 
-	if m.Verbose {
-		fmt.Printf("Added log entry to daily note: %s\n", logEntry)
+	// Skip the blank lines separating the section from the next one
+	for end > start+1 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
 	}
-
-	return nil
+	return strings.Join(slices.Insert(lines, end, item), "\n") + "\n"
 }
 
-// renderTemplate renders a template with the given data
-// It checks user template directory first, then falls back to embedded templates
-func (m *Manager) renderTemplate(name string, data TicketData) (string, error) {
-	var tmplContent []byte
-	var err error
-
-	// Try user template directory first
-	if m.TemplateDir != "" {
-		userTemplatePath := filepath.Join(m.TemplateDir, name)
-		if _, statErr := os.Stat(userTemplatePath); statErr == nil {
-			tmplContent, err = os.ReadFile(userTemplatePath)
-			if err != nil {
-				return "", errors.Wrapf(err, "failed to read user template %s", userTemplatePath)
+// findSection returns the index of the "## section" heading line and of
+// the line ending the section: the next level-2 heading or the end of the
+// lines. Returns -1, -1 if there is no such section.
+func findSection(lines []string, section string) (int, int) {
+	heading := "## " + section
+
+	for i, line := range lines {
+		if !strings.HasPrefix(line, heading) {
+			continue
+		}
+
+		// Find the end of the section, ignoring headings inside fenced
+		// blocks such as captured terminal output
+		fence := ""
+		for j := i + 1; j < len(lines); j++ {
+			if fence != "" {
+				if strings.HasPrefix(strings.TrimSpace(lines[j]), fence) {
+					fence = ""
+				}
+				continue
+			}
+			if marker := fenceMarker(lines[j]); marker != "" {
+				fence = marker
+				continue
 			}
-			if m.Verbose {
-				fmt.Printf("Using user template: %s\n", userTemplatePath)
+			if strings.HasPrefix(lines[j], "## ") {
+				return i, j
 			}
 		}
+		return i, len(lines)
 	}
 
-	// Fall back to embedded template
-	if tmplContent == nil {
-		tmplContent, err = defaultTemplates.ReadFile("templates/" + name)
-		if err != nil {
-			return "", errors.Wrapf(err, "failed to read embedded template %s", name)
+	return -1, -1
+}
+
+// fenceMarker returns the backtick or tilde run opening a fenced code
+// block on line, or "" if line doesn't open one
+func fenceMarker(line string) string {
+	trimmed := strings.TrimSpace(line)
+	for _, c := range []string{"`", "~"} {
+		n := len(trimmed) - len(strings.TrimLeft(trimmed, c))
+		if n >= 3 {
+			return strings.Repeat(c, n)
+		}
+	}
+	return ""
+}
+
+// FencedBlock wraps text in a fenced code block, using a fence longer than
+// any backtick run in text so the block can't be closed early
+func FencedBlock(lang, text string) string {
+	longest := 0
+	run := 0
+	for _, r := range text {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
 		}
 	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	return fence + lang + "\n" + strings.TrimRight(text, "\n") + "\n" + fence
+}
 
-	// Parse and execute template
-	tmpl, err := template.New(name).Parse(string(tmplContent))
+// AppendTicketLog adds entry, which may span several lines, to the end of
+// the Log section of a ticket's note. Returns the note path.
+func (m *Manager) AppendTicketLog(ticketType, ticket, entry string) (string, error) {
+	return m.updateTicketNote(ticketType, ticket, func(content string) string {
+		// Keep a blank line between the entry and whatever follows it
+		return m.insertLogEntry(content, strings.TrimRight(entry, "\n")+"\n", "")
+	})
+}
+
+// AddTicketEntry adds a one-line entry, such as a log bullet or a checklist
+// item, after the last item of a section of a ticket's note. The section is
+// added at the end of the note if it is missing. Returns the note path.
+func (m *Manager) AddTicketEntry(ticketType, ticket, section, entry string) (string, error) {
+	return m.updateTicketNote(ticketType, ticket, func(content string) string {
+		return m.insertListItem(content, section, entry)
+	})
+}
+
+// updateTicketNote rewrites a ticket's note with update. Returns the note
+// path.
+func (m *Manager) updateTicketNote(ticketType, ticket string, update func(string) string) (string, error) {
+	notePath := m.GetNotePath(ticketType, ticket)
+
+	content, err := os.ReadFile(notePath)
+	if os.IsNotExist(err) {
+		return "", errors.Newf("ticket note not found: %s", notePath)
+	}
 	if err != nil {
-		return "", errors.Wrapf(err, "failed to parse template %s", name)
+		return "", errors.Wrap(err, "failed to read note")
 	}
 
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", errors.Wrapf(err, "failed to execute template %s", name)
+	// Write back with restricted permissions (may contain command output)
+	if err := os.WriteFile(notePath, []byte(update(string(content))), 0600); err != nil {
+		return "", errors.Wrap(err, "failed to update note")
 	}
 
-	return buf.String(), nil
+	return notePath, nil
 }
 
-// insertLogEntry inserts a log entry into the note content
-func (m *Manager) insertLogEntry(content, logEntry string) string {
-	lines := strings.Split(content, "\n")
+// TicketSummary returns a one-line summary of a ticket from its note: the
+// title once sync has replaced the ticket name with the JIRA summary, else
+// the first line of the Summary section. Returns "" if there is no note or
+// it only holds the template's placeholder text.
+func (m *Manager) TicketSummary(ticketType, ticket string) string {
+	content, err := os.ReadFile(m.GetNotePath(ticketType, ticket))
+	if err != nil {
+		return ""
+	}
 
-	// Look for ## Log section
+	return noteSummary(string(content), ticket)
+}
+
+// noteSummary returns the summary of a ticket from its note's content, see
+// TicketSummary
+func noteSummary(content, ticket string) string {
+	_, body := SplitFrontMatter(content)
+
+	inSummary := false
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "# "):
+			if title := strings.TrimSpace(strings.TrimPrefix(line, "# ")); title != ticket {
+				return title
+			}
+		case strings.HasPrefix(line, "## "):
+			if inSummary {
+				return ""
+			}
+			inSummary = line == "## Summary"
+		case inSummary && line != "":
+			if strings.HasPrefix(line, "Work on ") {
+				return ""
+			}
+			return line
+		}
+	}
+
+	return ""
+}
+
+// TicketStatus returns the tracker status recorded in a ticket's note: the
+// front matter status, else a "**Status:** ..." line in older notes. Returns
+// "" if there is none.
+func (m *Manager) TicketStatus(ticketType, ticket string) string {
+	content, err := os.ReadFile(m.GetNotePath(ticketType, ticket))
+	if err != nil {
+		return ""
+	}
+
+	return noteStatus(string(content))
+}
+
+// noteStatus returns the status recorded in a note's content, see
+// TicketStatus
+func noteStatus(content string) string {
+	if fm, err := ParseFrontMatter(content); err == nil && fm.Status != "" {
+		return fm.Status
+	}
+
+	_, body := SplitFrontMatter(content)
+	for _, line := range strings.Split(body, "\n") {
+		if status, ok := strings.CutPrefix(strings.TrimSpace(line), "**Status:**"); ok {
+			return strings.TrimSpace(status)
+		}
+	}
+
+	return ""
+}
+
+// RenameTicketNote moves a ticket note to its new type/ticket location and
+// rewrites links to it in daily notes. The note's title is updated if it
+// still carries the old ticket name. Returns the new note path and the
+// number of daily notes that were updated.
+func (m *Manager) RenameTicketNote(oldType, oldTicket, newType, newTicket string) (string, int, error) {
+	oldPath := m.GetNotePath(oldType, oldTicket)
+	newPath := m.GetNotePath(newType, newTicket)
+
+	if _, err := os.Stat(oldPath); os.IsNotExist(err) {
+		return "", 0, errors.Newf("note does not exist: %s", oldPath)
+	}
+	if _, err := os.Stat(newPath); err == nil {
+		return "", 0, errors.Newf("target note already exists: %s", newPath)
+	}
+
+	content, err := os.ReadFile(oldPath)
+	if err != nil {
+		return "", 0, errors.Wrap(err, "failed to read note")
+	}
+
+	// Retitle the note if the heading is still the old ticket name, and
+	// move any front matter along with it
+	head, body := SplitFrontMatter(string(content))
+	if head != "" {
+		head, err = SetFrontMatter(head, FrontMatter{Ticket: newTicket, Type: newType})
+		if err != nil {
+			return "", 0, errors.Wrapf(err, "failed to update %s", oldPath)
+		}
+	}
+	lines := strings.Split(body, "\n")
 	for i, line := range lines {
-		if strings.HasPrefix(line, "## Log") {
-			// Find the end of the log section
-			insertIndex := len(lines) // Default to end of file
-
-			for j := i + 1; j < len(lines); j++ {
-				if strings.HasPrefix(lines[j], "## ") {
-					insertIndex = j
-					break
-				}
+		if strings.HasPrefix(line, "# ") {
+			if strings.TrimSpace(strings.TrimPrefix(line, "# ")) == oldTicket {
+				lines[i] = "# " + newTicket
 			}
+			break
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(newPath), 0700); err != nil {
+		return "", 0, errors.Wrap(err, "failed to create note directory")
+	}
+	if err := os.WriteFile(newPath, []byte(head+strings.Join(lines, "\n")), 0600); err != nil {
+		return "", 0, errors.Wrap(err, "failed to write note")
+	}
+	if err := os.Remove(oldPath); err != nil {
+		return "", 0, errors.Wrap(err, "failed to remove old note")
+	}
+
+	if m.Verbose {
+		fmt.Printf("Moved note %s to %s\n", oldPath, newPath)
+	}
+
+	updated, err := m.rewriteDailyLinks(oldType, oldTicket, newType, newTicket)
+	if err != nil {
+		return newPath, updated, errors.Wrap(err, "failed to update daily note links")
+	}
+
+	return newPath, updated, nil
+}
+
+// rewriteDailyLinks replaces links to the old ticket note in all daily notes
+func (m *Manager) rewriteDailyLinks(oldType, oldTicket, newType, newTicket string) (int, error) {
+	dailyDir := filepath.Join(m.BasePath, m.DailyDir)
+	entries, err := os.ReadDir(dailyDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
 
-			// Insert the log entry
-			newLines := make([]string, 0, len(lines)+1)
-			newLines = append(newLines, lines[:insertIndex]...)
-			newLines = append(newLines, logEntry)
-			newLines = append(newLines, lines[insertIndex:]...)
+	oldLink := fmt.Sprintf("[%s](%s)", oldTicket, m.dailyLinkTarget(oldType, oldTicket))
+	newLink := fmt.Sprintf("[%s](%s)", newTicket, m.dailyLinkTarget(newType, newTicket))
+	oldTarget := "(" + m.dailyLinkTarget(oldType, oldTicket) + ")"
+	newTarget := "(" + m.dailyLinkTarget(newType, newTicket) + ")"
+
+	updated := 0
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".md" {
+			continue
+		}
+
+		path := filepath.Join(dailyDir, entry.Name())
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return updated, errors.Wrapf(err, "failed to read %s", path)
+		}
+
+		// Replace full links first so the link text follows the rename,
+		// then any remaining links with custom text
+		rewritten := strings.ReplaceAll(string(content), oldLink, newLink)
+		rewritten = strings.ReplaceAll(rewritten, oldTarget, newTarget)
+		rewritten = replaceWikilinks(rewritten, oldTicket, newTicket)
+		if rewritten == string(content) {
+			continue
+		}
 
-			return strings.Join(newLines, "\n")
+		if err := os.WriteFile(path, []byte(rewritten), 0600); err != nil {
+			return updated, errors.Wrapf(err, "failed to write %s", path)
 		}
+		updated++
 	}
 
-	// If no ## Log section found, add it at the end
-	return content + "\n\n## Log\n" + logEntry
+	return updated, nil
 }
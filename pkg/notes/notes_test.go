@@ -103,6 +103,39 @@ func TestCreateTicketNote_Success(t *testing.T) {
 	if !strings.Contains(string(content), "2025-01-15") {
 		t.Error("Note content missing date")
 	}
+
+	fm, err := ParseFrontMatter(string(content))
+	if err != nil {
+		t.Fatalf("ParseFrontMatter() error = %v", err)
+	}
+	if fm.Ticket != "proj-123" || fm.Type != "proj" || fm.Worktree != "/path/to/worktree" || fm.Created != "2025-01-15" {
+		t.Errorf("front matter = %+v", fm)
+	}
+	if strings.Join(fm.Tags, ",") != "rig,proj" {
+		t.Errorf("front matter tags = %v", fm.Tags)
+	}
+}
+
+func TestCreateTicketNote_MergesTemplateFrontMatter(t *testing.T) {
+	tmpDir := t.TempDir()
+	templateDir := t.TempDir()
+	userTemplate := "---\naliases: [\"{{.Summary}}\"]\ntags: [work]\n---\n# {{.Ticket}}\n"
+	if err := os.WriteFile(filepath.Join(templateDir, "ticket.md.tmpl"), []byte(userTemplate), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewManager(tmpDir, "daily", templateDir, false)
+	notePath, err := m.CreateTicketNote(TicketData{Ticket: "proj-1", TicketType: "proj", Summary: "Fix login", Status: "Open", URL: "https://jira.example.com/browse/PROJ-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, _ := os.ReadFile(notePath)
+	for _, want := range []string{"aliases: [\"Fix login\"]\n", "tags: [work, rig, proj]\n", "status: Open\n", "url: https://jira.example.com/browse/PROJ-1\n", "---\n# proj-1\n"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("note missing %q:\n%s", want, content)
+		}
+	}
 }
 
 func TestCreateTicketNote_AlreadyExists(t *testing.T) {
@@ -193,14 +226,14 @@ func TestCreateTicketNote_BasePathNotExists(t *testing.T) {
 	}
 }
 
-func TestUpdateDailyNote_CreateNew(t *testing.T) {
+func TestAddDailyEvent_CreateNew(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	m := NewManager(tmpDir, "daily", "", false)
 
-	err := m.UpdateDailyNote("proj-123", "proj")
+	_, err := m.AddDailyEvent(LogEvent{Event: EventStarted, Ticket: "proj-123", Type: "proj"})
 	if err != nil {
-		t.Fatalf("UpdateDailyNote() error = %v, want nil", err)
+		t.Fatalf("AddDailyEvent() error = %v, want nil", err)
 	}
 
 	// Verify daily note was created
@@ -216,7 +249,7 @@ func TestUpdateDailyNote_CreateNew(t *testing.T) {
 	}
 }
 
-func TestUpdateDailyNote_AppendToExisting(t *testing.T) {
+func TestAddDailyEvent_AppendToExisting(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	m := NewManager(tmpDir, "daily", "", false)
@@ -233,9 +266,9 @@ func TestUpdateDailyNote_AppendToExisting(t *testing.T) {
 		t.Fatalf("Failed to create existing daily note: %v", err)
 	}
 
-	err := m.UpdateDailyNote("proj-123", "proj")
+	_, err := m.AddDailyEvent(LogEvent{Event: EventStarted, Ticket: "proj-123", Type: "proj"})
 	if err != nil {
-		t.Fatalf("UpdateDailyNote() error = %v, want nil", err)
+		t.Fatalf("AddDailyEvent() error = %v, want nil", err)
 	}
 
 	content, err := os.ReadFile(dailyPath)
@@ -258,7 +291,7 @@ func TestInsertLogEntry_WithLogSection(t *testing.T) {
 	content := "# 2025-01-15\n\n## Notes\n\n## Log\n\n## Other\n"
 	entry := "- [14:30] [proj-123](../proj/proj-123.md)"
 
-	result := m.insertLogEntry(content, entry)
+	result := m.insertLogEntry(content, entry, "")
 
 	// Entry should be after ## Log but before ## Other
 	logIdx := strings.Index(result, "## Log")
@@ -276,7 +309,7 @@ func TestInsertLogEntry_NoLogSection(t *testing.T) {
 	content := "# 2025-01-15\n\n## Notes\n\nSome content.\n"
 	entry := "- [14:30] [proj-123](../proj/proj-123.md)"
 
-	result := m.insertLogEntry(content, entry)
+	result := m.insertLogEntry(content, entry, "")
 
 	// Should have added ## Log section at end
 	if !strings.Contains(result, "## Log\n"+entry) {
@@ -284,6 +317,109 @@ func TestInsertLogEntry_NoLogSection(t *testing.T) {
 	}
 }
 
+func TestInsertLogEntry_IgnoresHeadingsInFences(t *testing.T) {
+	m := NewManager("/notes", "daily", "", false)
+
+	content := "## Log\n\n```text\n## not a heading\n```\n\n## Other\n"
+	result := m.insertLogEntry(content, "- entry", "")
+
+	if !strings.Contains(result, "```\n\n- entry\n## Other") {
+		t.Errorf("entry should go after the fenced block, got:\n%s", result)
+	}
+}
+
+func TestFencedBlock(t *testing.T) {
+	if got := FencedBlock("text", "ok\n"); got != "```text\nok\n```" {
+		t.Errorf("FencedBlock() = %q", got)
+	}
+
+	got := FencedBlock("", "has ``` fence")
+	if !strings.HasPrefix(got, "````\n") || !strings.HasSuffix(got, "\n````") {
+		t.Errorf("fence should outgrow backtick runs, got %q", got)
+	}
+}
+
+func TestAppendTicketLog(t *testing.T) {
+	notesDir := t.TempDir()
+	m := NewManager(notesDir, "daily", "", false)
+
+	if _, err := m.AppendTicketLog("proj", "proj-1", "- entry"); err == nil {
+		t.Error("AppendTicketLog() should fail without a note")
+	}
+
+	notePath, err := m.CreateTicketNote(TicketData{Ticket: "proj-1", TicketType: "proj"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := "- [2025-01-15 10:00] Captured `term`\n\n```text\n$ make\n## ok\n```"
+	got, err := m.AppendTicketLog("proj", "proj-1", block)
+	if err != nil {
+		t.Fatalf("AppendTicketLog() error: %v", err)
+	}
+	if got != notePath {
+		t.Errorf("AppendTicketLog() path = %q, want %q", got, notePath)
+	}
+	if _, err := m.AppendTicketLog("proj", "proj-1", "- second"); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(notePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIdx := strings.Index(string(content), "## Log")
+	blockIdx := strings.Index(string(content), block)
+	secondIdx := strings.Index(string(content), "- second")
+	if logIdx < 0 || blockIdx < logIdx || secondIdx < blockIdx {
+		t.Errorf("entries should follow ## Log in order, got:\n%s", content)
+	}
+}
+
+func TestInsertListItem(t *testing.T) {
+	m := NewManager("/notes", "daily", "", false)
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"empty section at end", "# t\n\n## Log\n\n", "# t\n\n## Log\n- item\n"},
+		{"after last item", "## Log\n- a\n\n## Tasks\n- [ ] x\n", "## Log\n- a\n- item\n\n## Tasks\n- [ ] x\n"},
+		{"after fenced block", "## Log\n```\n## not a heading\n```\n", "## Log\n```\n## not a heading\n```\n- item\n"},
+		{"missing section", "# t\n\n## Notes\n", "# t\n\n## Notes\n\n## Log\n- item\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.insertListItem(tt.content, "Log", "- item"); got != tt.want {
+				t.Errorf("insertListItem() =\n%q\nwant:\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddTicketEntry(t *testing.T) {
+	m := NewManager(t.TempDir(), "daily", "", false)
+
+	if _, err := m.AddTicketEntry("proj", "proj-1", "Tasks", "- [ ] x"); err == nil {
+		t.Error("AddTicketEntry() should fail without a note")
+	}
+
+	notePath, err := m.CreateTicketNote(TicketData{Ticket: "proj-1", TicketType: "proj"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.AddTicketEntry("proj", "proj-1", "Tasks", "- [ ] write docs"); err != nil {
+		t.Fatalf("AddTicketEntry() error: %v", err)
+	}
+
+	content, _ := os.ReadFile(notePath)
+	if !strings.HasSuffix(string(content), "\n\n## Tasks\n- [ ] write docs\n") {
+		t.Errorf("Tasks section should be added at the end, got:\n%s", content)
+	}
+}
+
 func TestRenderTemplate_EmbeddedTemplate(t *testing.T) {
 	m := NewManager("/notes", "daily", "", false)
 
@@ -295,9 +431,9 @@ func TestRenderTemplate_EmbeddedTemplate(t *testing.T) {
 		WorktreePath: "/path/to/worktree",
 	}
 
-	content, err := m.renderTemplate("ticket.md.tmpl", data)
+	content, err := m.RenderTemplate("ticket.md.tmpl", data)
 	if err != nil {
-		t.Fatalf("renderTemplate() error = %v, want nil", err)
+		t.Fatalf("RenderTemplate() error = %v, want nil", err)
 	}
 
 	if !strings.Contains(content, "# proj-123") {
@@ -324,9 +460,9 @@ func TestRenderTemplate_UserOverride(t *testing.T) {
 		Summary: "Test summary",
 	}
 
-	content, err := m.renderTemplate("ticket.md.tmpl", data)
+	content, err := m.RenderTemplate("ticket.md.tmpl", data)
 	if err != nil {
-		t.Fatalf("renderTemplate() error = %v, want nil", err)
+		t.Fatalf("RenderTemplate() error = %v, want nil", err)
 	}
 
 	if !strings.Contains(content, "# Custom: proj-123") {
@@ -340,8 +476,146 @@ func TestRenderTemplate_UserOverride(t *testing.T) {
 func TestRenderTemplate_InvalidTemplate(t *testing.T) {
 	m := NewManager("/notes", "daily", "", false)
 
-	_, err := m.renderTemplate("nonexistent.md.tmpl", TicketData{})
+	_, err := m.RenderTemplate("nonexistent.md.tmpl", TicketData{})
 	if err == nil {
 		t.Fatal("renderTemplate() expected error for nonexistent template, got nil")
 	}
 }
+
+func TestRenameTicketNote(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := NewManager(tmpDir, "daily", "", false)
+
+	oldPath := m.GetNotePath("hack", "experiment-auth")
+	if err := os.MkdirAll(filepath.Dir(oldPath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(oldPath, []byte("---\nticket: experiment-auth\ntype: hack\n---\n# experiment-auth\n\n## Log\n- did things\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	dailyDir := filepath.Join(tmpDir, "daily")
+	if err := os.MkdirAll(dailyDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	linked := filepath.Join(dailyDir, "2026-01-05.md")
+	if err := os.WriteFile(linked, []byte("## Log\n- [09:00] [experiment-auth](../hack/experiment-auth.md)\n- see [the spike](../hack/experiment-auth.md)\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	unrelated := filepath.Join(dailyDir, "2026-01-06.md")
+	unrelatedContent := "## Log\n- [09:00] [proj-1](../proj/proj-1.md)\n"
+	if err := os.WriteFile(unrelated, []byte(unrelatedContent), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	newPath, updated, err := m.RenameTicketNote("hack", "experiment-auth", "proj", "proj-456")
+	if err != nil {
+		t.Fatalf("RenameTicketNote() error: %v", err)
+	}
+
+	if newPath != m.GetNotePath("proj", "proj-456") {
+		t.Errorf("newPath = %q, want %q", newPath, m.GetNotePath("proj", "proj-456"))
+	}
+	if updated != 1 {
+		t.Errorf("updated = %d, want 1", updated)
+	}
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Error("old note should be removed")
+	}
+
+	content, _ := os.ReadFile(newPath)
+	if !strings.HasPrefix(string(content), "---\nticket: proj-456\ntype: proj\n---\n# proj-456\n") {
+		t.Errorf("note title not updated:\n%s", content)
+	}
+	if !strings.Contains(string(content), "- did things") {
+		t.Error("note body should be preserved")
+	}
+
+	daily, _ := os.ReadFile(linked)
+	if !strings.Contains(string(daily), "[proj-456](../proj/proj-456.md)") {
+		t.Errorf("daily link not rewritten:\n%s", daily)
+	}
+	if !strings.Contains(string(daily), "[the spike](../proj/proj-456.md)") {
+		t.Errorf("custom link text should keep its text but point at the new note:\n%s", daily)
+	}
+
+	other, _ := os.ReadFile(unrelated)
+	if string(other) != unrelatedContent {
+		t.Error("unrelated daily note should not change")
+	}
+}
+
+func TestRenameTicketNote_Errors(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := NewManager(tmpDir, "daily", "", false)
+
+	if _, _, err := m.RenameTicketNote("proj", "proj-1", "proj", "proj-2"); err == nil {
+		t.Error("RenameTicketNote() should fail when the note does not exist")
+	}
+
+	for _, ticket := range []string{"proj-1", "proj-2"} {
+		path := m.GetNotePath("proj", ticket)
+		_ = os.MkdirAll(filepath.Dir(path), 0700)
+		_ = os.WriteFile(path, []byte("# "+ticket+"\n"), 0600)
+	}
+
+	if _, _, err := m.RenameTicketNote("proj", "proj-1", "proj", "proj-2"); err == nil {
+		t.Error("RenameTicketNote() should not overwrite an existing note")
+	}
+}
+
+func TestTicketSummary(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "title synced from JIRA",
+			content: "# Fix login timeout\n\n## Summary\n\nWork on proj ticket: proj-123\n",
+			want:    "Fix login timeout",
+		},
+		{
+			name:    "summary section",
+			content: "# proj-123\n\n## Summary\n\nUsers are logged out after 5 minutes\n\n## Notes\n",
+			want:    "Users are logged out after 5 minutes",
+		},
+		{
+			name:    "template placeholder",
+			content: "# proj-123\n\n## Summary\n\nWork on proj ticket: proj-123\n",
+			want:    "",
+		},
+		{
+			name:    "front matter comment",
+			content: "---\n# managed by rig\nticket: proj-123\n---\n# proj-123\n\n## Summary\n\nUsers are logged out\n",
+			want:    "Users are logged out",
+		},
+		{
+			name:    "empty summary section",
+			content: "# proj-123\n\n## Summary\n\n## Notes\n\nsomething\n",
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager(t.TempDir(), "daily", "", false)
+			path := m.GetNotePath("proj", "proj-123")
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := m.TicketSummary("proj", "proj-123"); got != tt.want {
+				t.Errorf("TicketSummary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	m := NewManager(t.TempDir(), "daily", "", false)
+	if got := m.TicketSummary("proj", "missing"); got != "" {
+		t.Errorf("TicketSummary() for missing note = %q, want empty", got)
+	}
+}
@@ -0,0 +1,180 @@
+package notes
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// templaterPattern matches the Templater commands rig can resolve when it
+// creates a note: tp.file.title, tp.file.creation_date and the tp.date
+// helpers, with an optional format and day offset
+var templaterPattern = regexp.MustCompile(`<%[-_]?\s*tp\.(file\.title|file\.creation_date|date\.now|date\.today|date\.yesterday|date\.tomorrow)(?:\(\s*(?:(?:"([^"]*)"|'([^']*)')\s*(?:,\s*(-?\d+)\s*)?)?\))?\s*[-_]?%>`)
+
+// expandTemplater resolves Templater commands in a rendered note, so
+// templates shared with Obsidian give the same result when rig creates the
+// note. title is the note's file name without .md and now its creation
+// time. Other commands are left for Templater to run.
+func expandTemplater(content, title string, now time.Time) string {
+	return templaterPattern.ReplaceAllStringFunc(content, func(match string) string {
+		parts := templaterPattern.FindStringSubmatch(match)
+		command, format := parts[1], parts[2]+parts[3]
+		offset, _ := strconv.Atoi(parts[4])
+
+		switch command {
+		case "file.title":
+			return title
+		case "file.creation_date":
+			if format == "" {
+				format = "YYYY-MM-DD HH:mm"
+			}
+			return formatMoment(now, format)
+		case "date.yesterday":
+			offset = -1
+		case "date.tomorrow":
+			offset = 1
+		}
+
+		if format == "" {
+			format = "YYYY-MM-DD"
+		}
+		return formatMoment(now.AddDate(0, 0, offset), format)
+	})
+}
+
+// momentTokens are the Moment.js format tokens formatMoment understands,
+// longest first so e.g. MMMM wins over MM
+var momentTokens = []string{
+	"YYYY", "YY",
+	"MMMM", "MMM", "MM", "M",
+	"dddd", "ddd",
+	"Do", "DD", "D",
+	"HH", "H", "hh", "h",
+	"mm", "m", "ss", "s",
+	"A", "a",
+}
+
+// formatMoment formats t with a Moment.js format string as used by
+// Templater, e.g. "YYYY-MM-DD" or "dddd, MMMM Do". Text in [brackets] is
+// copied as-is.
+func formatMoment(t time.Time, format string) string {
+	var b strings.Builder
+	for i := 0; i < len(format); {
+		if format[i] == '[' {
+			if end := strings.IndexByte(format[i:], ']'); end > 0 {
+				b.WriteString(format[i+1 : i+end])
+				i += end + 1
+				continue
+			}
+		}
+
+		token := ""
+		for _, candidate := range momentTokens {
+			if strings.HasPrefix(format[i:], candidate) {
+				token = candidate
+				break
+			}
+		}
+		if token == "" {
+			b.WriteByte(format[i])
+			i++
+			continue
+		}
+
+		b.WriteString(momentValue(t, token))
+		i += len(token)
+	}
+	return b.String()
+}
+
+// momentValue returns the value of a single Moment.js token for t
+func momentValue(t time.Time, token string) string {
+	hour12 := t.Hour() % 12
+	if hour12 == 0 {
+		hour12 = 12
+	}
+
+	switch token {
+	case "YYYY":
+		return fmt.Sprintf("%04d", t.Year())
+	case "YY":
+		return fmt.Sprintf("%02d", t.Year()%100)
+	case "MMMM":
+		return t.Month().String()
+	case "MMM":
+		return t.Month().String()[:3]
+	case "MM":
+		return fmt.Sprintf("%02d", int(t.Month()))
+	case "M":
+		return strconv.Itoa(int(t.Month()))
+	case "dddd":
+		return t.Weekday().String()
+	case "ddd":
+		return t.Weekday().String()[:3]
+	case "Do":
+		return ordinal(t.Day())
+	case "DD":
+		return fmt.Sprintf("%02d", t.Day())
+	case "D":
+		return strconv.Itoa(t.Day())
+	case "HH":
+		return fmt.Sprintf("%02d", t.Hour())
+	case "H":
+		return strconv.Itoa(t.Hour())
+	case "hh":
+		return fmt.Sprintf("%02d", hour12)
+	case "h":
+		return strconv.Itoa(hour12)
+	case "mm":
+		return fmt.Sprintf("%02d", t.Minute())
+	case "m":
+		return strconv.Itoa(t.Minute())
+	case "ss":
+		return fmt.Sprintf("%02d", t.Second())
+	case "s":
+		return strconv.Itoa(t.Second())
+	case "A":
+		return t.Format("PM")
+	case "a":
+		return t.Format("pm")
+	}
+	return token
+}
+
+// ordinal formats n with its English ordinal suffix, e.g. 1st or 22nd
+func ordinal(n int) string {
+	suffix := "th"
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return strconv.Itoa(n) + suffix
+}
+
+// replaceWikilinks points [[oldTicket]] wikilinks, including ones with an
+// alias or heading, at newTicket
+func replaceWikilinks(content, oldTicket, newTicket string) string {
+	for _, suffix := range []string{"]]", "|", "#"} {
+		content = strings.ReplaceAll(content, "[["+oldTicket+suffix, "[["+newTicket+suffix)
+	}
+	return content
+}
+
+// noteTime returns the creation time recorded in data, falling back to now
+func noteTime(data TicketData) time.Time {
+	if t, err := time.ParseInLocation("2006-01-02 15:04", data.Date+" "+data.Time, time.Local); err == nil {
+		return t
+	}
+	if t, err := time.ParseInLocation("2006-01-02", data.Date, time.Local); err == nil {
+		return t
+	}
+	return time.Now()
+}
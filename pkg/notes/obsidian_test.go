@@ -0,0 +1,198 @@
+package notes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpandTemplater(t *testing.T) {
+	now := time.Date(2026, 3, 2, 14, 5, 0, 0, time.Local)
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"title", "# <% tp.file.title %>", "# proj-123"},
+		{"creation date default", "<% tp.file.creation_date() %>", "2026-03-02 14:05"},
+		{"creation date format", `<% tp.file.creation_date("HH:mm") %>`, "14:05"},
+		{"now", "<% tp.date.now() %>", "2026-03-02"},
+		{"now with format and offset", `<% tp.date.now("YYYY-MM-DD", -7) %>`, "2026-02-23"},
+		{"single quotes", "<% tp.date.now('dddd') %>", "Monday"},
+		{"yesterday", "[[<% tp.date.yesterday() %>]]", "[[2026-03-01]]"},
+		{"tomorrow with format", `<% tp.date.tomorrow("MMM D") %>`, "Mar 3"},
+		{"whitespace control", "<%- tp.file.title -%>", "proj-123"},
+		{"unknown command kept", "<% tp.system.prompt(\"x\") %>", "<% tp.system.prompt(\"x\") %>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandTemplater(tt.content, "proj-123", now); got != tt.want {
+				t.Errorf("expandTemplater(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatMoment(t *testing.T) {
+	tm := time.Date(2026, 1, 22, 9, 7, 3, 0, time.UTC)
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"YYYY-MM-DD", "2026-01-22"},
+		{"YY/M/D", "26/1/22"},
+		{"dddd, MMMM Do", "Thursday, January 22nd"},
+		{"ddd MMM", "Thu Jan"},
+		{"HH:mm:ss", "09:07:03"},
+		{"h:mm A", "9:07 AM"},
+		{"hh a", "09 am"},
+		{"[Week of] YYYY-MM-DD", "Week of 2026-01-22"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			if got := formatMoment(tm, tt.format); got != tt.want {
+				t.Errorf("formatMoment(%q) = %q, want %q", tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrdinal(t *testing.T) {
+	tests := map[int]string{
+		1: "1st", 2: "2nd", 3: "3rd", 4: "4th",
+		11: "11th", 12: "12th", 13: "13th",
+		21: "21st", 22: "22nd", 23: "23rd", 31: "31st",
+	}
+	for n, want := range tests {
+		if got := ordinal(n); got != want {
+			t.Errorf("ordinal(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestReplaceWikilinks(t *testing.T) {
+	content := "[[proj-1]] [[proj-1|the fix]] [[proj-1#Log]] [[proj-10]]"
+	want := "[[proj-2]] [[proj-2|the fix]] [[proj-2#Log]] [[proj-10]]"
+
+	if got := replaceWikilinks(content, "proj-1", "proj-2"); got != want {
+		t.Errorf("replaceWikilinks() = %q, want %q", got, want)
+	}
+}
+
+func newObsidianManager(basePath, templateDir string) *Manager {
+	m := NewManager(basePath, "Daily", templateDir, false)
+	m.Flavor = FlavorObsidian
+	m.Subdir = "Areas/Work"
+	m.TypeSubdirs = map[string]string{"incident": "Areas/Incidents"}
+	return m
+}
+
+func TestGetNotePath_Subdirs(t *testing.T) {
+	m := newObsidianManager("/vault", "")
+
+	if got := m.GetNotePath("proj", "proj-1"); got != "/vault/Areas/Work/proj/proj-1.md" {
+		t.Errorf("GetNotePath(proj) = %q", got)
+	}
+	if got := m.GetNotePath("incident", "incident-1"); got != "/vault/Areas/Incidents/incident-1.md" {
+		t.Errorf("GetNotePath(incident) = %q", got)
+	}
+}
+
+func TestDailyLink(t *testing.T) {
+	m := NewManager("/notes", "daily", "", false)
+	m.Subdir = "work"
+	if got := m.DailyLink("proj", "proj-1"); got != "[proj-1](../work/proj/proj-1.md)" {
+		t.Errorf("markdown DailyLink() = %q", got)
+	}
+
+	m.Flavor = FlavorObsidian
+	if got := m.DailyLink("proj", "proj-1"); got != "[[proj-1]]" {
+		t.Errorf("obsidian DailyLink() = %q", got)
+	}
+}
+
+func TestAddDailyEvent_Obsidian(t *testing.T) {
+	m := newObsidianManager(t.TempDir(), "")
+
+	if _, err := m.AddDailyEvent(LogEvent{Event: EventStarted, Ticket: "proj-123", Type: "proj"}); err != nil {
+		t.Fatalf("AddDailyEvent() error = %v", err)
+	}
+
+	content, err := os.ReadFile(m.GetDailyNotePath())
+	if err != nil {
+		t.Fatalf("Failed to read daily note: %v", err)
+	}
+	if !strings.Contains(string(content), "[[proj-123]]") {
+		t.Errorf("daily note should contain a wikilink, got:\n%s", content)
+	}
+	if strings.Contains(string(content), "](") {
+		t.Errorf("daily note should not contain markdown links, got:\n%s", content)
+	}
+}
+
+func TestRenameTicketNote_Wikilinks(t *testing.T) {
+	m := newObsidianManager(t.TempDir(), "")
+
+	oldPath := m.GetNotePath("hack", "spike")
+	if err := os.MkdirAll(filepath.Dir(oldPath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(oldPath, []byte("# spike\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	daily := filepath.Join(m.BasePath, "Daily", "2026-01-05.md")
+	if err := os.MkdirAll(filepath.Dir(daily), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(daily, []byte("## Log\n- [09:00] [[spike]]\n- see [[spike|the spike]]\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, updated, err := m.RenameTicketNote("hack", "spike", "proj", "proj-9")
+	if err != nil {
+		t.Fatalf("RenameTicketNote() error: %v", err)
+	}
+	if updated != 1 {
+		t.Errorf("updated = %d, want 1", updated)
+	}
+
+	content, _ := os.ReadFile(daily)
+	if string(content) != "## Log\n- [09:00] [[proj-9]]\n- see [[proj-9|the spike]]\n" {
+		t.Errorf("wikilinks not rewritten:\n%s", content)
+	}
+}
+
+func TestCreateTicketNote_VaultTemplate(t *testing.T) {
+	templateDir := t.TempDir()
+	writeTemplate(t, templateDir, "ticket.md", "# <% tp.file.title %>\n\nStarted <% tp.file.creation_date(\"YYYY-MM-DD\") %>\n")
+
+	m := newObsidianManager(t.TempDir(), templateDir)
+	data := TicketData{Ticket: "proj-7", TicketType: "proj", Date: "2026-02-03", Time: "10:30"}
+
+	notePath, err := m.CreateTicketNote(data)
+	if err != nil {
+		t.Fatalf("CreateTicketNote() error: %v", err)
+	}
+
+	content, _ := os.ReadFile(notePath)
+	_, body := SplitFrontMatter(string(content))
+	if body != "# proj-7\n\nStarted 2026-02-03\n" {
+		t.Errorf("body = %q", body)
+	}
+
+	// Plain .md templates are only picked up by the Obsidian flavour
+	m.Flavor = FlavorMarkdown
+	if name := m.TicketTemplateName("proj"); name != "ticket.md.tmpl" {
+		t.Fatalf("TicketTemplateName() = %q", name)
+	}
+	if _, path, _ := m.TemplateSource("ticket.md.tmpl"); path != "" {
+		t.Errorf("markdown flavour should use the built-in template, got %s", path)
+	}
+}
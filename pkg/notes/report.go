@@ -0,0 +1,270 @@
+package notes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// reportTemplatePrefix starts the names of report templates, e.g.
+// report-standup.md.tmpl
+const reportTemplatePrefix = "report-"
+
+// Report kinds
+const (
+	ReportStandup = "standup"
+	ReportWeek    = "week"
+)
+
+var (
+	// logTimePattern matches the time of a daily log entry, "[14:30] ..."
+	logTimePattern = regexp.MustCompile(`^\[(\d{1,2}:\d{2})\]\s*`)
+
+	// logLinkPattern matches the ticket link of a Log subsection heading or
+	// starting a flat daily log entry: [proj-123](../proj/proj-123.md) or
+	// [[proj-123]]
+	logLinkPattern = regexp.MustCompile(`^(?:\[\[([^\]|#]+)[^\]]*\]\]|\[([^\]]+)\]\([^)]*\))\s*`)
+)
+
+// DailyEntry is an entry from the Log section of a daily note
+type DailyEntry struct {
+	Date       string // Day of the daily note, YYYY-MM-DD
+	Time       string // HH:MM, "" if the entry has no time
+	Ticket     string // Ticket or hack the entry is about, "" if none
+	Text       string // Entry text after the time and ticket link
+	Event      string // Log event the text was rendered from, "" for other text
+	Detail     string // The event's detail
+	StatusFrom string // Previous tracker status, for status changes
+	StatusTo   string // New tracker status, for status changes
+}
+
+// Report holds the data report templates are rendered with
+type Report struct {
+	Kind      string // ReportStandup or ReportWeek
+	From      string // First day covered, YYYY-MM-DD
+	To        string // Last day covered, YYYY-MM-DD
+	Generated string // YYYY-MM-DD HH:MM
+	Days      []ReportDay
+	Tickets   []ReportTicket
+	History   bool // Whether command history was available for active times
+}
+
+// ReportDay is one day of a report
+type ReportDay struct {
+	Date    string // YYYY-MM-DD
+	Label   string // "Today", "Yesterday" or the weekday
+	Entries []DailyEntry
+	Tickets []ReportTicket // Work on each ticket that day
+	Notes   []string       // Entries not about a ticket
+}
+
+// ReportTicket summarises the work on one ticket over a day or a whole
+// report
+type ReportTicket struct {
+	Ticket        string
+	Type          string
+	Summary       string        // From the ticket note
+	Status        string        // Current status from the ticket note
+	Entries       int           // Daily log entries for the ticket
+	Started       bool          // Whether work on the ticket started
+	Done          bool          // Whether the ticket was marked done
+	StatusChanges []string      // e.g. "In Progress → Done"
+	PRs           []string      // Pull requests opened (their URLs, if logged)
+	Notes         []string      // Text of the ticket's other entries
+	Commands      int           // Commands run in the ticket's worktree
+	Active        time.Duration // Time spent running commands there
+	ActiveTime    string        // Active formatted, e.g. "1h20m"
+}
+
+// SummarizeEntries groups daily log entries by ticket, in the order the
+// tickets first appear, and returns the text of entries without a ticket
+func SummarizeEntries(entries []DailyEntry) ([]ReportTicket, []string) {
+	var tickets []ReportTicket
+	var notes []string
+	index := make(map[string]int)
+
+	for _, entry := range entries {
+		if entry.Ticket == "" {
+			if entry.Text != "" {
+				notes = append(notes, entry.Text)
+			}
+			continue
+		}
+
+		i, ok := index[entry.Ticket]
+		if !ok {
+			i = len(tickets)
+			index[entry.Ticket] = i
+			tickets = append(tickets, ReportTicket{Ticket: entry.Ticket})
+		}
+
+		t := &tickets[i]
+		t.Entries++
+		switch entry.Event {
+		case EventStarted:
+			t.Started = true
+		case EventDone:
+			t.Done = true
+		case EventStatus:
+			t.StatusChanges = append(t.StatusChanges, entry.Detail)
+		case EventPROpened:
+			t.PRs = append(t.PRs, entry.Detail)
+		case "":
+			if entry.Text != "" {
+				t.Notes = append(t.Notes, entry.Text)
+			}
+		}
+	}
+	return tickets, notes
+}
+
+// DailyEntries returns the Log entries of the daily notes from the day of
+// from through the day of to, oldest first. Days without a note are skipped.
+func (m *Manager) DailyEntries(from, to time.Time) ([]DailyEntry, error) {
+	matcher := m.newLogEventMatcher()
+	var entries []DailyEntry
+	for day := startOfDay(from); !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		content, err := os.ReadFile(filepath.Join(m.BasePath, m.DailyDir, date+".md"))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read daily note %s", date)
+		}
+		entries = append(entries, matcher.parseDailyEntries(date, string(content))...)
+	}
+	return entries, nil
+}
+
+// HasDailyNote reports whether there is a daily note for the day of t
+func (m *Manager) HasDailyNote(t time.Time) bool {
+	_, err := os.Stat(filepath.Join(m.BasePath, m.DailyDir, t.Format("2006-01-02")+".md"))
+	return err == nil
+}
+
+// parseDailyEntries returns the top-level bullets of a daily note's Log
+// section
+func (m *Manager) parseDailyEntries(date, content string) []DailyEntry {
+	return m.newLogEventMatcher().parseDailyEntries(date, content)
+}
+
+// parseDailyEntries returns the top-level bullets of a daily note's Log
+// section. Entries under a "### <ticket link>" subsection are about that
+// ticket; older flat entries start with the ticket link.
+func (lm *logEventMatcher) parseDailyEntries(date, content string) []DailyEntry {
+	lines := strings.Split(content, "\n")
+	start, end := findSection(lines, "Log")
+	if start < 0 {
+		return nil
+	}
+
+	var entries []DailyEntry
+	subsection := ""
+	for _, line := range lines[start+1 : end] {
+		if heading, ok := strings.CutPrefix(line, "### "); ok {
+			heading = strings.TrimSpace(heading)
+			if match := logLinkPattern.FindStringSubmatch(heading); match != nil {
+				heading = match[1] + match[2]
+			}
+			subsection = heading
+			continue
+		}
+
+		text, ok := strings.CutPrefix(line, "- ")
+		if !ok {
+			continue
+		}
+
+		entry := DailyEntry{Date: date, Ticket: subsection}
+		if match := logTimePattern.FindStringSubmatch(text); match != nil {
+			entry.Time = match[1]
+			text = text[len(match[0]):]
+		}
+		if match := logLinkPattern.FindStringSubmatch(text); match != nil {
+			entry.Ticket = match[1] + match[2]
+			text = text[len(match[0]):]
+		}
+		entry.Text = strings.TrimSpace(text)
+		entry.Event, entry.Detail = lm.match(entry.Text)
+		if entry.Event == EventStatus {
+			entry.StatusFrom, entry.StatusTo, _ = strings.Cut(entry.Detail, statusSeparator)
+		}
+
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// startOfDay returns midnight at the start of t's day
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// ReportTemplateName returns the template for a kind of report
+func ReportTemplateName(kind string) string {
+	return reportTemplatePrefix + kind + templateExt
+}
+
+// ReportKind returns the report kind of a report template name, or "" if
+// name isn't a report template
+func ReportKind(name string) string {
+	kind, ok := strings.CutPrefix(strings.TrimSuffix(name, templateExt), reportTemplatePrefix)
+	if !ok {
+		return ""
+	}
+	return kind
+}
+
+// RenderReport renders a report with its report-<kind>.md.tmpl template
+func (m *Manager) RenderReport(report Report) (string, error) {
+	return m.executeTemplate(ReportTemplateName(report.Kind), report)
+}
+
+// FormatDuration formats d as hours and minutes, e.g. "1h20m" or "45m".
+// Durations under a minute are "<1m".
+func FormatDuration(d time.Duration) string {
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	switch {
+	case minutes < 1:
+		return "<1m"
+	case minutes < 60:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes%60 == 0:
+		return fmt.Sprintf("%dh", minutes/60)
+	}
+	return fmt.Sprintf("%dh%dm", minutes/60, minutes%60)
+}
+
+// SampleReport returns report data for dry-running report templates
+func SampleReport(kind string) Report {
+	now := time.Now()
+	today := now.Format("2006-01-02")
+	yesterday := now.AddDate(0, 0, -1).Format("2006-01-02")
+
+	return Report{
+		Kind:      kind,
+		From:      yesterday,
+		To:        today,
+		Generated: now.Format("2006-01-02 15:04"),
+		Days: []ReportDay{
+			{Date: yesterday, Label: "Yesterday", Tickets: []ReportTicket{
+				{Ticket: "proj-123", Type: "proj", Summary: "Sample ticket summary", Status: "In Review", Entries: 3, Started: true, StatusChanges: []string{"In Progress → In Review"}, PRs: []string{"https://github.com/example/myrepo/pull/7"}, Commands: 42, Active: 80 * time.Minute, ActiveTime: "1h20m"},
+			}},
+			{Date: today, Label: "Today", Tickets: []ReportTicket{
+				{Ticket: "ops-45", Type: "ops", Summary: "Sample ops ticket", Status: "In Progress", Entries: 1, Notes: []string{"Rotated the staging certificates"}},
+			}, Notes: []string{"Team planning"}},
+		},
+		Tickets: []ReportTicket{
+			{Ticket: "proj-123", Type: "proj", Summary: "Sample ticket summary", Status: "In Review", Entries: 3, Started: true, StatusChanges: []string{"In Progress → In Review"}, PRs: []string{"https://github.com/example/myrepo/pull/7"}, Commands: 42, Active: 80 * time.Minute, ActiveTime: "1h20m"},
+			{Ticket: "ops-45", Type: "ops", Summary: "Sample ops ticket", Status: "In Progress", Entries: 1, Notes: []string{"Rotated the staging certificates"}},
+		},
+		History: true,
+	}
+}
@@ -0,0 +1,151 @@
+package notes
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDailyEntries(t *testing.T) {
+	m := NewManager(t.TempDir(), "daily", "", false)
+	content := `# 2025-01-14
+
+## Log
+- [08:00] [proj-7](../proj/proj-7.md)
+- Team planning
+
+### [proj-123](../proj/proj-123.md)
+- [09:10] Started
+- [14:00] Status: In Progress → In Review
+- [14:05] Opened PR https://example.com/pull/1
+
+### [[ops-45]]
+- [11:30] Rotated certificates
+  - nested detail
+- [17:00] Done
+
+## Notes
+- [10:00] [proj-999](../proj/proj-999.md)
+`
+	got := m.parseDailyEntries("2025-01-14", content)
+	want := []DailyEntry{
+		{Date: "2025-01-14", Time: "08:00", Ticket: "proj-7"},
+		{Date: "2025-01-14", Text: "Team planning"},
+		{Date: "2025-01-14", Time: "09:10", Ticket: "proj-123", Text: "Started", Event: EventStarted},
+		{Date: "2025-01-14", Time: "14:00", Ticket: "proj-123", Text: "Status: In Progress → In Review", Event: EventStatus, Detail: "In Progress → In Review", StatusFrom: "In Progress", StatusTo: "In Review"},
+		{Date: "2025-01-14", Time: "14:05", Ticket: "proj-123", Text: "Opened PR https://example.com/pull/1", Event: EventPROpened, Detail: "https://example.com/pull/1"},
+		{Date: "2025-01-14", Time: "11:30", Ticket: "ops-45", Text: "Rotated certificates"},
+		{Date: "2025-01-14", Time: "17:00", Ticket: "ops-45", Text: "Done", Event: EventDone},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseDailyEntries() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// Entries rendered from custom formats are recognised too
+	m.LogFormats = map[string]string{EventStatus: "🔀 {{.Ticket}} moved {{.Detail}}"}
+	entries := m.parseDailyEntries("2025-01-14", "## Log\n### [[proj-1]]\n- [10:00] 🔀 proj-1 moved To Do → Doing\n")
+	if len(entries) != 1 || entries[0].StatusFrom != "To Do" || entries[0].StatusTo != "Doing" {
+		t.Errorf("custom status entry = %+v", entries)
+	}
+
+	if entries := m.parseDailyEntries("2025-01-14", "# 2025-01-14\n\nNo log here\n"); entries != nil {
+		t.Errorf("a note without a Log section should have no entries, got %+v", entries)
+	}
+}
+
+func TestSummarizeEntries(t *testing.T) {
+	tickets, notes := SummarizeEntries([]DailyEntry{
+		{Ticket: "proj-123", Text: "Started", Event: EventStarted},
+		{Ticket: "ops-45", Text: "Rotated certificates"},
+		{Text: "Team planning"},
+		{Ticket: "proj-123", Text: "Synced", Event: EventSynced},
+		{Ticket: "proj-123", Event: EventStatus, Detail: "In Progress → Done"},
+		{Ticket: "proj-123", Event: EventPROpened, Detail: "https://example.com/pull/1"},
+		{Ticket: "proj-123", Event: EventDone},
+	})
+
+	want := []ReportTicket{
+		{Ticket: "proj-123", Entries: 5, Started: true, Done: true, StatusChanges: []string{"In Progress → Done"}, PRs: []string{"https://example.com/pull/1"}},
+		{Ticket: "ops-45", Entries: 1, Notes: []string{"Rotated certificates"}},
+	}
+	if !reflect.DeepEqual(tickets, want) {
+		t.Errorf("tickets =\n%+v\nwant\n%+v", tickets, want)
+	}
+	if !reflect.DeepEqual(notes, []string{"Team planning"}) {
+		t.Errorf("notes = %v", notes)
+	}
+}
+
+func TestDailyEntries(t *testing.T) {
+	basePath := t.TempDir()
+	m := NewManager(basePath, "daily", "", false)
+	writeTemplate(t, filepath.Join(basePath, "daily"), "2025-01-13.md", "## Log\n- [09:00] [[proj-1]]\n")
+	writeTemplate(t, filepath.Join(basePath, "daily"), "2025-01-15.md", "## Log\n- [10:00] [[proj-2]]\n")
+
+	from := time.Date(2025, 1, 13, 12, 0, 0, 0, time.Local)
+	to := time.Date(2025, 1, 15, 0, 0, 0, 0, time.Local)
+	entries, err := m.DailyEntries(from, to)
+	if err != nil {
+		t.Fatalf("DailyEntries() error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Ticket != "proj-1" || entries[1].Date != "2025-01-15" {
+		t.Errorf("DailyEntries() = %+v", entries)
+	}
+
+	if !m.HasDailyNote(from) || m.HasDailyNote(from.AddDate(0, 0, 1)) {
+		t.Error("HasDailyNote() should only report days with a note")
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		20 * time.Second:  "<1m",
+		45 * time.Minute:  "45m",
+		2 * time.Hour:     "2h",
+		70 * time.Minute:  "1h10m",
+		125 * time.Minute: "2h5m",
+	}
+	for d, want := range tests {
+		if got := FormatDuration(d); got != want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestReportKind(t *testing.T) {
+	if got := ReportTemplateName(ReportWeek); got != "report-week.md.tmpl" {
+		t.Errorf("ReportTemplateName() = %q", got)
+	}
+	if got := ReportKind("report-standup.md.tmpl"); got != ReportStandup {
+		t.Errorf("ReportKind() = %q, want %q", got, ReportStandup)
+	}
+	if got := ReportKind("daily.md.tmpl"); got != "" {
+		t.Errorf("ReportKind() of a non-report template = %q", got)
+	}
+}
+
+func TestRenderReport(t *testing.T) {
+	m := NewManager(t.TempDir(), "daily", t.TempDir(), false)
+
+	for _, kind := range []string{ReportStandup, ReportWeek} {
+		output, err := m.RenderReport(SampleReport(kind))
+		if err != nil {
+			t.Fatalf("RenderReport(%s) error: %v", kind, err)
+		}
+		for _, want := range []string{"proj-123", "Sample ticket summary", "1h20m", "In Progress → In Review", "Rotated the staging certificates"} {
+			if !strings.Contains(output, want) {
+				t.Errorf("%s report missing %q:\n%s", kind, want, output)
+			}
+		}
+	}
+
+	templateDir := t.TempDir()
+	writeTemplate(t, templateDir, "report-standup.md.tmpl", "{{range .Tickets}}{{.Ticket}} {{end}}")
+	m = NewManager(t.TempDir(), "daily", templateDir, false)
+	output, err := m.RenderReport(SampleReport(ReportStandup))
+	if err != nil || output != "proj-123 ops-45 " {
+		t.Errorf("a user report template should override the built-in one, got %q, %v", output, err)
+	}
+}
@@ -0,0 +1,57 @@
+package notes
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces secrets removed by RedactSecrets
+const Redacted = "[REDACTED]"
+
+// ansiPattern matches CSI and OSC terminal escape sequences and other
+// two-character escapes
+var ansiPattern = regexp.MustCompile(`\x1b(\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(\x07|\x1b\\)|[@-Z\\-_])`)
+
+// secretPatterns match common credential formats. The secret is replaced
+// and any capture groups around it (e.g. the key name) are kept.
+var secretPatterns = []*regexp.Regexp{
+	// PEM private keys
+	regexp.MustCompile(`(?s)(-----BEGIN [A-Z ]*PRIVATE KEY-----).*?-----END [A-Z ]*PRIVATE KEY-----`),
+	// Authorization headers
+	regexp.MustCompile(`(?i)(\b(?:authorization|proxy-authorization)\s*:\s*(?:bearer|basic|token)\s+)\S+`),
+	regexp.MustCompile(`(?i)(\bbearer\s+)[A-Za-z0-9._~+/-]{16,}=*`),
+	// Credentials in URLs
+	regexp.MustCompile(`(://[^/\s:@]+:)[^/\s@]+(@)`),
+	// key=value and key: value assignments of secret-looking names
+	regexp.MustCompile(`(?i)(\b[\w.-]*(?:password|passwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|credentials?)[\w.-]*["']?\s*[:=]\s*)(?:"[^"\n]*"|'[^'\n]*'|[^\s,;]+)`),
+	// Provider tokens
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+	regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`),
+	regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}\b`),
+	regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}\b`),
+	regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}\b`),
+	// JSON Web Tokens
+	regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]+`),
+}
+
+// StripANSI removes terminal escape sequences and carriage returns
+func StripANSI(text string) string {
+	text = ansiPattern.ReplaceAllString(text, "")
+	return strings.ReplaceAll(text, "\r", "")
+}
+
+// RedactSecrets replaces passwords, tokens, keys and other credentials
+// with Redacted, so captured terminal output can be kept in notes
+func RedactSecrets(text string) string {
+	for _, pattern := range secretPatterns {
+		switch pattern.NumSubexp() {
+		case 0:
+			text = pattern.ReplaceAllString(text, Redacted)
+		case 1:
+			text = pattern.ReplaceAllString(text, "${1}"+Redacted)
+		default:
+			text = pattern.ReplaceAllString(text, "${1}"+Redacted+"${2}")
+		}
+	}
+	return text
+}
@@ -0,0 +1,381 @@
+package notes
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	_ "modernc.org/sqlite"
+)
+
+// searchSchemaVersion is stored as the index's user_version. An index
+// built with another version is dropped and rebuilt.
+const searchSchemaVersion = 1
+
+// searchSchema creates the index tables. notes tracks each indexed file
+// and its metadata; notes_fts holds the searchable text.
+const searchSchema = `
+CREATE TABLE IF NOT EXISTS notes (
+	path     TEXT PRIMARY KEY,
+	mod_time INTEGER NOT NULL,
+	size     INTEGER NOT NULL,
+	kind     TEXT NOT NULL,
+	type     TEXT NOT NULL,
+	name     TEXT NOT NULL,
+	status   TEXT NOT NULL,
+	date     TEXT NOT NULL
+);
+CREATE VIRTUAL TABLE IF NOT EXISTS notes_fts USING fts5(
+	path UNINDEXED,
+	title,
+	body,
+	tokenize = 'porter unicode61'
+);
+`
+
+// SearchIndex is an on-disk full-text index of ticket and daily notes,
+// kept in SQLite (FTS5). Update only re-reads notes whose size or
+// modification time changed since they were indexed.
+type SearchIndex struct {
+	manager *Manager
+	db      *sql.DB
+}
+
+// IndexStats reports what an index update changed
+type IndexStats struct {
+	Added   int
+	Updated int
+	Removed int
+	Total   int // Notes in the index afterwards
+}
+
+// SearchOptions filters search results. Zero values don't filter.
+type SearchOptions struct {
+	Type   string    // Ticket type, or "daily" for daily notes
+	Status string    // Note status (front matter), case-insensitive
+	Since  time.Time // Notes dated on or after this day
+	Until  time.Time // Notes dated on or before this day
+	Limit  int
+}
+
+// SearchResult is a note matching a search, best matches first
+type SearchResult struct {
+	Path    string
+	Kind    string // KindTicket or KindDaily
+	Type    string
+	Name    string
+	Status  string
+	Date    string // Creation date of a ticket note, or the daily note's day
+	Title   string
+	Snippet string // Matching text, with matches wrapped in **
+}
+
+// OpenSearchIndex opens the search index at path, creating it if needed
+func (m *Manager) OpenSearchIndex(path string) (*SearchIndex, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, errors.Wrap(err, "failed to create index directory")
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open search index")
+	}
+	// A single connection keeps transactions and pragmas on one database
+	db.SetMaxOpenConns(1)
+
+	idx := &SearchIndex{manager: m, db: db}
+	if err := idx.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return idx, nil
+}
+
+// Close closes the index database
+func (idx *SearchIndex) Close() error {
+	return idx.db.Close()
+}
+
+// migrate creates the schema, dropping an index of another version
+func (idx *SearchIndex) migrate() error {
+	var version int
+	if err := idx.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return errors.Wrap(err, "failed to read search index version")
+	}
+
+	if version != searchSchemaVersion {
+		if _, err := idx.db.Exec("DROP TABLE IF EXISTS notes; DROP TABLE IF EXISTS notes_fts"); err != nil {
+			return errors.Wrap(err, "failed to reset search index")
+		}
+	}
+	if _, err := idx.db.Exec(searchSchema); err != nil {
+		return errors.Wrap(err, "failed to create search index")
+	}
+	if _, err := idx.db.Exec(fmt.Sprintf("PRAGMA user_version = %d", searchSchemaVersion)); err != nil {
+		return errors.Wrap(err, "failed to set search index version")
+	}
+	return nil
+}
+
+// Rebuild clears the index and indexes every note again
+func (idx *SearchIndex) Rebuild() (IndexStats, error) {
+	if _, err := idx.db.Exec("DELETE FROM notes; DELETE FROM notes_fts"); err != nil {
+		return IndexStats{}, errors.Wrap(err, "failed to clear search index")
+	}
+	return idx.Update()
+}
+
+// Update indexes new and changed notes and drops deleted ones
+func (idx *SearchIndex) Update() (IndexStats, error) {
+	var stats IndexStats
+
+	files, err := idx.manager.ListNotes()
+	if err != nil {
+		return stats, err
+	}
+
+	type fileState struct{ modTime, size int64 }
+	indexed := make(map[string]fileState)
+	rows, err := idx.db.Query("SELECT path, mod_time, size FROM notes")
+	if err != nil {
+		return stats, errors.Wrap(err, "failed to read search index")
+	}
+	for rows.Next() {
+		var path string
+		var state fileState
+		if err := rows.Scan(&path, &state.modTime, &state.size); err != nil {
+			rows.Close()
+			return stats, errors.Wrap(err, "failed to read search index")
+		}
+		indexed[path] = state
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return stats, errors.Wrap(err, "failed to read search index")
+	}
+
+	tx, err := idx.db.Begin()
+	if err != nil {
+		return stats, errors.Wrap(err, "failed to update search index")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, file := range files {
+		info, err := os.Stat(file.Path)
+		if err != nil {
+			continue
+		}
+
+		state, known := indexed[file.Path]
+		delete(indexed, file.Path)
+		if known && state.modTime == info.ModTime().UnixNano() && state.size == info.Size() {
+			continue
+		}
+
+		content, err := os.ReadFile(file.Path)
+		if err != nil {
+			return stats, errors.Wrapf(err, "failed to read %s", file.Path)
+		}
+		if err := indexNote(tx, file, info, string(content)); err != nil {
+			return stats, err
+		}
+
+		if known {
+			stats.Updated++
+		} else {
+			stats.Added++
+		}
+	}
+
+	// Whatever is left was deleted or moved since the last update
+	for path := range indexed {
+		if err := removeNote(tx, path); err != nil {
+			return stats, err
+		}
+		stats.Removed++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return stats, errors.Wrap(err, "failed to update search index")
+	}
+
+	if err := idx.db.QueryRow("SELECT count(*) FROM notes").Scan(&stats.Total); err != nil {
+		return stats, errors.Wrap(err, "failed to count indexed notes")
+	}
+	return stats, nil
+}
+
+// indexNote replaces a note's rows in the index
+func indexNote(tx *sql.Tx, file NoteFile, info os.FileInfo, content string) error {
+	if err := removeNote(tx, file.Path); err != nil {
+		return err
+	}
+
+	_, body := SplitFrontMatter(content)
+	date := file.Name
+	if file.Kind == KindTicket {
+		date = info.ModTime().Format("2006-01-02")
+		if fm, err := ParseFrontMatter(content); err == nil && fm.Created != "" {
+			date = fm.Created
+		}
+	}
+
+	title := file.Name
+	for _, line := range strings.Split(body, "\n") {
+		if heading, ok := strings.CutPrefix(line, "# "); ok {
+			if heading = strings.TrimSpace(heading); heading != "" && heading != file.Name {
+				title = file.Name + ": " + heading
+			}
+			break
+		}
+	}
+
+	if _, err := tx.Exec(
+		"INSERT INTO notes (path, mod_time, size, kind, type, name, status, date) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		file.Path, info.ModTime().UnixNano(), info.Size(), file.Kind, file.Type, file.Name, noteStatus(content), date,
+	); err != nil {
+		return errors.Wrapf(err, "failed to index %s", file.Path)
+	}
+	if _, err := tx.Exec("INSERT INTO notes_fts (path, title, body) VALUES (?, ?, ?)", file.Path, title, body); err != nil {
+		return errors.Wrapf(err, "failed to index %s", file.Path)
+	}
+	return nil
+}
+
+// removeNote drops a note from the index
+func removeNote(tx *sql.Tx, path string) error {
+	if _, err := tx.Exec("DELETE FROM notes WHERE path = ?", path); err != nil {
+		return errors.Wrapf(err, "failed to remove %s from index", path)
+	}
+	if _, err := tx.Exec("DELETE FROM notes_fts WHERE path = ?", path); err != nil {
+		return errors.Wrapf(err, "failed to remove %s from index", path)
+	}
+	return nil
+}
+
+// Search returns the indexed notes matching query, best matches first.
+// Words must all match, in any form ("fixing" matches "fixed"); "quoted
+// phrases", prefix* terms and OR/NOT are supported.
+func (idx *SearchIndex) Search(query string, opts SearchOptions) ([]SearchResult, error) {
+	match := ftsQuery(query)
+	if match == "" {
+		return nil, errors.New("search query cannot be empty")
+	}
+
+	// Title matches rank above body matches
+	sqlQuery := `SELECT n.path, n.kind, n.type, n.name, n.status, n.date, f.title,
+		snippet(notes_fts, 2, '**', '**', '…', 16)
+		FROM notes_fts f JOIN notes n ON n.path = f.path
+		WHERE notes_fts MATCH ?`
+	args := []any{match}
+
+	if opts.Type != "" {
+		sqlQuery += " AND n.type = ?"
+		args = append(args, opts.Type)
+	}
+	if opts.Status != "" {
+		sqlQuery += " AND lower(n.status) = lower(?)"
+		args = append(args, opts.Status)
+	}
+	if !opts.Since.IsZero() {
+		sqlQuery += " AND n.date >= ?"
+		args = append(args, opts.Since.Format("2006-01-02"))
+	}
+	if !opts.Until.IsZero() {
+		sqlQuery += " AND substr(n.date, 1, 10) <= ?"
+		args = append(args, opts.Until.Format("2006-01-02"))
+	}
+
+	sqlQuery += " ORDER BY bm25(notes_fts, 0.0, 5.0, 1.0)"
+	if opts.Limit > 0 {
+		sqlQuery += " LIMIT ?"
+		args = append(args, opts.Limit)
+	}
+
+	rows, err := idx.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid search query %q", query)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var r SearchResult
+		if err := rows.Scan(&r.Path, &r.Kind, &r.Type, &r.Name, &r.Status, &r.Date, &r.Title, &r.Snippet); err != nil {
+			return nil, errors.Wrap(err, "failed to read search results")
+		}
+		r.Snippet = strings.Join(strings.Fields(r.Snippet), " ")
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrapf(err, "invalid search query %q", query)
+	}
+
+	return results, nil
+}
+
+// ftsQuery turns a search query into an FTS5 match expression. Each word
+// or "quoted phrase" becomes an FTS5 string, so punctuation such as the
+// hyphen in proj-123 is matched rather than parsed; a trailing * keeps
+// prefix matching and AND, OR and NOT between words are kept as operators.
+func ftsQuery(query string) string {
+	type token struct {
+		text     string
+		operator bool
+	}
+
+	var tokens []token
+	for query = strings.TrimSpace(query); query != ""; query = strings.TrimSpace(query) {
+		var term string
+		quoted := false
+		if rest, ok := strings.CutPrefix(query, `"`); ok {
+			term, query, _ = strings.Cut(rest, `"`)
+			quoted = true
+		} else {
+			end := strings.IndexAny(query, " \t\n")
+			if end < 0 {
+				end = len(query)
+			}
+			term, query = query[:end], query[end:]
+		}
+
+		if !quoted && (term == "AND" || term == "OR" || term == "NOT") {
+			tokens = append(tokens, token{term, true})
+			continue
+		}
+
+		prefix := false
+		if rest, ok := strings.CutPrefix(query, "*"); ok && quoted {
+			prefix, query = true, rest
+		} else if trimmed, ok := strings.CutSuffix(term, "*"); ok && !quoted {
+			prefix, term = true, trimmed
+		}
+		if strings.TrimSpace(term) == "" {
+			continue
+		}
+
+		term = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+		if prefix {
+			term += "*"
+		}
+		tokens = append(tokens, token{term, false})
+	}
+
+	// Operators need a term on both sides; others are searched as words
+	var terms []string
+	for i, t := range tokens {
+		if t.operator {
+			valid := i > 0 && !tokens[i-1].operator && i+1 < len(tokens) && !tokens[i+1].operator
+			if !valid {
+				t.text = `"` + t.text + `"`
+				tokens[i].operator = false
+			}
+		}
+		terms = append(terms, t.text)
+	}
+	return strings.Join(terms, " ")
+}
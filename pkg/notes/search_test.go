@@ -0,0 +1,175 @@
+package notes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeNote(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestListNotes(t *testing.T) {
+	base := t.TempDir()
+	m := NewManager(base, "daily", filepath.Join(base, "templates"), false)
+	m.TypeSubdirs = map[string]string{"incident": "Incidents"}
+
+	writeNote(t, filepath.Join(base, "proj", "proj-1.md"), "# proj-1\n")
+	writeNote(t, filepath.Join(base, "hack", "spike.md"), "# spike\n")
+	writeNote(t, filepath.Join(base, "Incidents", "incident-2.md"), "# incident-2\n")
+	writeNote(t, filepath.Join(base, "daily", "2025-01-15.md"), "## Log\n")
+	writeNote(t, filepath.Join(base, "daily", "scratch.md"), "not a daily note\n")
+	writeNote(t, filepath.Join(base, "templates", "ticket.md"), "# <% tp.file.title %>\n")
+	writeNote(t, filepath.Join(base, ".obsidian", "workspace.md"), "\n")
+	writeNote(t, filepath.Join(base, "README.md"), "\n")
+	writeNote(t, filepath.Join(base, "proj", "_index.md"), "# proj tickets\n")
+	writeNote(t, filepath.Join(base, "proj", "proj-2.md"), "---\nticket: proj-2\ntype: proj\n---\n# proj-2\n")
+	writeNote(t, filepath.Join(base, "proj", "meeting.md"), "# Planning meeting\n")
+	writeNote(t, filepath.Join(base, "Recipes", "pancakes.md"), "# Pancakes\n")
+	writeNote(t, filepath.Join(base, "Recipes", "recipes-1.md"), "---\nticket: recipes-1\ntype: recipe\n---\n")
+
+	got, err := m.ListNotes()
+	if err != nil {
+		t.Fatalf("ListNotes() error: %v", err)
+	}
+
+	want := []NoteFile{
+		{Path: filepath.Join(base, "daily", "2025-01-15.md"), Kind: KindDaily, Type: "daily", Name: "2025-01-15"},
+		{Path: filepath.Join(base, "hack", "spike.md"), Kind: KindTicket, Type: "hack", Name: "spike"},
+		{Path: filepath.Join(base, "Incidents", "incident-2.md"), Kind: KindTicket, Type: "incident", Name: "incident-2"},
+		{Path: filepath.Join(base, "proj", "proj-1.md"), Kind: KindTicket, Type: "proj", Name: "proj-1"},
+		{Path: filepath.Join(base, "proj", "proj-2.md"), Kind: KindTicket, Type: "proj", Name: "proj-2"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ListNotes() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ListNotes()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestFTSQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"etcd quorum", `"etcd" "quorum"`},
+		{"proj-123", `"proj-123"`},
+		{`"quorum lost" etcd`, `"quorum lost" "etcd"`},
+		{"deploy*", `"deploy"*`},
+		{`"roll back"*`, `"roll back"*`},
+		{"etcd OR consul", `"etcd" OR "consul"`},
+		{"etcd NOT consul", `"etcd" NOT "consul"`},
+		{"OR etcd", `"OR" "etcd"`},
+		{"etcd OR", `"etcd" "OR"`},
+		{`say "hi""`, `"say" "hi"`},
+		{`it's`, `"it's"`},
+		{"  ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := ftsQuery(tt.query); got != tt.want {
+				t.Errorf("ftsQuery(%q) = %s, want %s", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchIndex(t *testing.T) {
+	base := t.TempDir()
+	m := NewManager(base, "daily", "", false)
+
+	writeNote(t, filepath.Join(base, "ops", "ops-1.md"), "---\nticket: ops-1\nstatus: Done\ncreated: 2025-01-10\n---\n# Restore etcd quorum\n\n## Log\n- Fixed the etcd quorum issue by replacing a member\n")
+	writeNote(t, filepath.Join(base, "proj", "proj-2.md"), "---\nstatus: In Progress\ncreated: 2025-03-01\n---\n# proj-2\n\nMentions etcd once while fixing the login page.\n")
+	writeNote(t, filepath.Join(base, "daily", "2025-01-11.md"), "## Log\n- [09:00] etcd quorum follow-up\n")
+
+	idx, err := m.OpenSearchIndex(filepath.Join(t.TempDir(), "index", "notes.db"))
+	if err != nil {
+		t.Fatalf("OpenSearchIndex() error: %v", err)
+	}
+	defer idx.Close()
+
+	stats, err := idx.Update()
+	if err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+	if stats != (IndexStats{Added: 3, Total: 3}) {
+		t.Errorf("first Update() = %+v, want 3 added", stats)
+	}
+
+	results, err := idx.Search("fixing etcd", SearchOptions{})
+	if err != nil {
+		t.Fatalf("Search() error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Search() = %d results, want 2: %+v", len(results), results)
+	}
+	first := results[0]
+	if first.Name != "ops-1" || first.Title != "ops-1: Restore etcd quorum" || first.Status != "Done" || first.Date != "2025-01-10" {
+		t.Errorf("best result = %+v, want ops-1 with its title, status and date", first)
+	}
+	if first.Snippet == "" || first.Kind != KindTicket {
+		t.Errorf("result should have a snippet and kind, got %+v", first)
+	}
+
+	filtered := []struct {
+		name string
+		opts SearchOptions
+		want string
+	}{
+		{"type", SearchOptions{Type: "daily"}, "2025-01-11"},
+		{"status", SearchOptions{Status: "in progress"}, "proj-2"},
+		{"since", SearchOptions{Since: time.Date(2025, 2, 1, 0, 0, 0, 0, time.Local)}, "proj-2"},
+		{"until", SearchOptions{Until: time.Date(2025, 1, 10, 0, 0, 0, 0, time.Local)}, "ops-1"},
+		{"limit", SearchOptions{Limit: 1}, "ops-1"},
+	}
+	for _, tt := range filtered {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := idx.Search("etcd", tt.opts)
+			if err != nil {
+				t.Fatalf("Search() error: %v", err)
+			}
+			if len(results) != 1 || results[0].Name != tt.want {
+				t.Errorf("Search(%+v) = %+v, want only %s", tt.opts, results, tt.want)
+			}
+		})
+	}
+
+	// Incremental update: one note changed, one deleted, the rest untouched
+	writeNote(t, filepath.Join(base, "proj", "proj-2.md"), "# proj-2\n\nNow about consul.\n")
+	if err := os.Remove(filepath.Join(base, "daily", "2025-01-11.md")); err != nil {
+		t.Fatal(err)
+	}
+	stats, err = idx.Update()
+	if err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+	if stats != (IndexStats{Updated: 1, Removed: 1, Total: 2}) {
+		t.Errorf("second Update() = %+v, want 1 updated and 1 removed", stats)
+	}
+
+	results, _ = idx.Search("etcd", SearchOptions{})
+	if len(results) != 1 || results[0].Name != "ops-1" {
+		t.Errorf("Search() after update = %+v, want only ops-1", results)
+	}
+
+	stats, err = idx.Rebuild()
+	if err != nil || stats != (IndexStats{Added: 2, Total: 2}) {
+		t.Errorf("Rebuild() = %+v, %v", stats, err)
+	}
+
+	if _, err := idx.Search("  ", SearchOptions{}); err == nil {
+		t.Error("Search() should reject an empty query")
+	}
+}
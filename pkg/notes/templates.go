@@ -0,0 +1,422 @@
+package notes
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	// templateExt is the extension of note templates
+	templateExt = ".md.tmpl"
+
+	// partialsDir holds partial templates inside the user template directory
+	partialsDir = "partials"
+)
+
+// TemplateInfo describes a note template or partial
+type TemplateInfo struct {
+	Name    string // e.g. "ticket.md.tmpl", or "links" for a partial
+	Path    string // User template file, "" if only the built-in exists
+	Builtin bool   // Whether rig ships a default with this name
+	Partial bool   // Whether this is a partial from the partials directory
+}
+
+// TicketTemplateName returns the template used for new notes of
+// ticketType: "<type>.md.tmpl" from the user template directory or the
+// built-ins, falling back to "ticket.md.tmpl"
+func (m *Manager) TicketTemplateName(ticketType string) string {
+	name := ticketType + templateExt
+	if ticketType != "" && m.templateExists(name) {
+		return name
+	}
+	return "ticket" + templateExt
+}
+
+// templateExists reports whether a user or built-in template named name exists
+func (m *Manager) templateExists(name string) bool {
+	if m.userTemplatePath(name) != "" {
+		return true
+	}
+	_, err := fs.Stat(defaultTemplates, "templates/"+name)
+	return err == nil
+}
+
+// userTemplatePath returns the user template file for name, or "" if there
+// is none. The Obsidian flavour also accepts plain .md files, as kept in a
+// vault's templates folder (e.g. ticket.md for ticket.md.tmpl).
+func (m *Manager) userTemplatePath(name string) string {
+	if m.TemplateDir == "" {
+		return ""
+	}
+
+	candidates := []string{name}
+	if m.isObsidian() && strings.HasSuffix(name, templateExt) {
+		candidates = append(candidates, strings.TrimSuffix(name, ".tmpl"))
+	}
+	for _, candidate := range candidates {
+		path := filepath.Join(m.TemplateDir, candidate)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// TemplateSource returns the content of a template and the user file it
+// was read from, or "" for the built-in
+func (m *Manager) TemplateSource(name string) (string, string, error) {
+	// Try user template directory first
+	if userTemplatePath := m.userTemplatePath(name); userTemplatePath != "" {
+		content, err := os.ReadFile(userTemplatePath)
+		if err != nil {
+			return "", "", errors.Wrapf(err, "failed to read user template %s", userTemplatePath)
+		}
+		return string(content), userTemplatePath, nil
+	}
+
+	// Fall back to embedded template
+	content, err := defaultTemplates.ReadFile("templates/" + name)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to read embedded template %s", name)
+	}
+	return string(content), "", nil
+}
+
+// ListTemplates returns the built-in and user templates, followed by the
+// user partials, sorted by name
+func (m *Manager) ListTemplates() ([]TemplateInfo, error) {
+	byName := make(map[string]*TemplateInfo)
+
+	builtins, err := fs.Glob(defaultTemplates, "templates/*"+templateExt)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range builtins {
+		name := filepath.Base(path)
+		byName[name] = &TemplateInfo{Name: name, Builtin: true}
+	}
+
+	if m.TemplateDir != "" {
+		userTemplates, err := filepath.Glob(filepath.Join(m.TemplateDir, "*"+templateExt))
+		if err != nil {
+			return nil, err
+		}
+		if m.isObsidian() {
+			vaultTemplates, err := filepath.Glob(filepath.Join(m.TemplateDir, "*.md"))
+			if err != nil {
+				return nil, err
+			}
+			userTemplates = append(userTemplates, vaultTemplates...)
+		}
+
+		for _, path := range userTemplates {
+			name := filepath.Base(path)
+			if !strings.HasSuffix(name, templateExt) {
+				name += ".tmpl"
+			}
+			if info, ok := byName[name]; !ok {
+				byName[name] = &TemplateInfo{Name: name, Path: path}
+			} else if info.Path == "" {
+				// A .md.tmpl file takes precedence over a plain .md one
+				info.Path = path
+			}
+		}
+	}
+
+	templates := make([]TemplateInfo, 0, len(byName))
+	for _, info := range byName {
+		templates = append(templates, *info)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+
+	partials, err := m.partialFiles()
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range partials {
+		templates = append(templates, TemplateInfo{Name: partialName(path), Path: path, Partial: true})
+	}
+
+	return templates, nil
+}
+
+// partialFiles returns the partial templates in the user template directory
+func (m *Manager) partialFiles() ([]string, error) {
+	if m.TemplateDir == "" {
+		return nil, nil
+	}
+	paths, err := filepath.Glob(filepath.Join(m.TemplateDir, partialsDir, "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// partialName returns the name a partial is included by: its file name
+// without the .tmpl and .md extensions
+func partialName(path string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(filepath.Base(path), ".tmpl"), ".md")
+}
+
+// RenderTemplate renders a template with the given data. It checks the
+// user template directory first, then falls back to embedded templates.
+// Partials from the partials directory are available to every template.
+func (m *Manager) RenderTemplate(name string, data TicketData) (string, error) {
+	rendered, err := m.executeTemplate(name, data)
+	if err != nil {
+		return "", err
+	}
+
+	if m.isObsidian() {
+		title := data.Ticket
+		if title == "" {
+			title = data.Date // Daily notes are named by date
+		}
+		return expandTemplater(rendered, title, noteTime(data)), nil
+	}
+	return rendered, nil
+}
+
+// executeTemplate parses a template with the partials and executes it
+// with data
+func (m *Manager) executeTemplate(name string, data any) (string, error) {
+	content, path, err := m.TemplateSource(name)
+	if err != nil {
+		return "", err
+	}
+	if path != "" && m.Verbose {
+		fmt.Printf("Using user template: %s\n", path)
+	}
+
+	// Parse and execute template
+	tmpl := template.New(name)
+	tmpl.Funcs(templateFuncs(tmpl))
+
+	partials, err := m.partialFiles()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to list partials")
+	}
+	for _, partialPath := range partials {
+		partial, err := os.ReadFile(partialPath)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to read partial %s", partialPath)
+		}
+		if _, err := tmpl.New(partialName(partialPath)).Parse(string(partial)); err != nil {
+			return "", errors.Wrapf(err, "failed to parse partial %s", partialPath)
+		}
+	}
+
+	if _, err := tmpl.Parse(content); err != nil {
+		return "", errors.Wrapf(err, "failed to parse template %s", name)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", errors.Wrapf(err, "failed to execute template %s", name)
+	}
+	return buf.String(), nil
+}
+
+// templateFuncs returns the functions available in note templates. Values
+// come last so functions can be used in pipelines, e.g.
+// {{.Summary | trunc 40 | slug}}.
+func templateFuncs(tmpl *template.Template) template.FuncMap {
+	return template.FuncMap{
+		// Strings
+		"lower":      strings.ToLower,
+		"upper":      strings.ToUpper,
+		"title":      titleWords,
+		"trim":       strings.TrimSpace,
+		"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"replace":    func(old, replacement, s string) string { return strings.ReplaceAll(s, old, replacement) },
+		"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"split":      func(sep, s string) []string { return strings.Split(s, sep) },
+		"join":       func(sep string, parts []string) string { return strings.Join(parts, sep) },
+		"trunc":      truncateRunes,
+		"indent":     indentLines,
+		"default":    defaultValue,
+		"slug":       Slugify,
+
+		// Dates
+		"now":     time.Now,
+		"date":    formatDate,
+		"addDays": addDays,
+
+		// Partials
+		"include": func(name string, data any) (string, error) {
+			partial := tmpl.Lookup(name)
+			if partial == nil {
+				return "", errors.Newf("no partial named %q", name)
+			}
+			var buf bytes.Buffer
+			if err := partial.Execute(&buf, data); err != nil {
+				return "", err
+			}
+			return buf.String(), nil
+		},
+		"hasPartial": func(name string) bool { return tmpl.Lookup(name) != nil },
+	}
+}
+
+// Slugify lowercases s and joins its words with hyphens, e.g.
+// "Fix: login timeout!" becomes "fix-login-timeout"
+func Slugify(s string) string {
+	var b strings.Builder
+	pending := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pending && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			pending = false
+		} else {
+			pending = true
+		}
+	}
+	return b.String()
+}
+
+// titleWords upper-cases the first letter of each word
+func titleWords(s string) string {
+	runes := []rune(s)
+	for i, r := range runes {
+		if i == 0 || unicode.IsSpace(runes[i-1]) {
+			runes[i] = unicode.ToUpper(r)
+		}
+	}
+	return string(runes)
+}
+
+// truncateRunes shortens s to at most n runes
+func truncateRunes(n int, s string) string {
+	runes := []rune(s)
+	if n < 0 || len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+// indentLines prefixes every non-empty line of s with n spaces
+func indentLines(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = pad + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// defaultValue returns value, or fallback if value is empty
+func defaultValue(fallback, value any) any {
+	switch v := value.(type) {
+	case nil:
+		return fallback
+	case string:
+		if v == "" {
+			return fallback
+		}
+	case []string:
+		if len(v) == 0 {
+			return fallback
+		}
+	}
+	return value
+}
+
+// toTime converts a time.Time or a "2006-01-02" or RFC 3339 string
+func toTime(value any) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		for _, layout := range []string{"2006-01-02", time.RFC3339} {
+			if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, errors.Newf("unable to parse date %q", v)
+	default:
+		return time.Time{}, errors.Newf("unsupported date value %v", value)
+	}
+}
+
+// formatDate formats a date using a Go layout, e.g. {{date "Jan 2" .Date}}
+func formatDate(layout string, value any) (string, error) {
+	t, err := toTime(value)
+	if err != nil {
+		return "", err
+	}
+	return t.Format(layout), nil
+}
+
+// addDays returns the date n days after value, e.g. {{addDays -1 .Date}}
+func addDays(n int, value any) (time.Time, error) {
+	t, err := toTime(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.AddDate(0, 0, n), nil
+}
+
+// SampleTicketData returns placeholder data for dry-rendering templates
+// for ticketType; "daily" gives the data daily notes are rendered with
+func SampleTicketData(ticketType string) TicketData {
+	now := time.Now()
+	if ticketType == "daily" {
+		return TicketData{Date: now.Format("2006-01-02"), Time: now.Format("15:04")}
+	}
+
+	ticket := ticketType + "-123"
+	if ticketType == "hack" {
+		ticket = "sample-experiment"
+	}
+
+	severity := ""
+	if ticketType == "incident" {
+		severity = "2"
+	}
+
+	repoPath := filepath.Join("/path/to", "myrepo")
+	return TicketData{
+		Ticket:       ticket,
+		TicketType:   ticketType,
+		Date:         now.Format("2006-01-02"),
+		Time:         now.Format("15:04"),
+		Summary:      "Sample ticket summary",
+		Status:       "In Progress",
+		Description:  "Sample ticket description.",
+		RepoName:     "myrepo",
+		RepoPath:     repoPath,
+		WorktreePath: filepath.Join(repoPath, ticketType, ticket),
+		Branch:       ticket,
+		URL:          "https://jira.example.com/browse/" + strings.ToUpper(ticket),
+		Severity:     severity,
+	}
+}
+
+// TemplateType returns the ticket type a template is meant for: the part of
+// its name before .md.tmpl, with "ticket" standing for any type ("proj")
+func TemplateType(name string) string {
+	ticketType := strings.TrimSuffix(name, templateExt)
+	if ticketType == "ticket" {
+		return "proj"
+	}
+	return ticketType
+}
@@ -0,0 +1,174 @@
+package notes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTemplate writes a template file under dir, creating parent directories
+func writeTemplate(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTicketTemplateName(t *testing.T) {
+	templateDir := t.TempDir()
+	writeTemplate(t, templateDir, "incident.md.tmpl", "# {{.Ticket}}\n")
+	m := NewManager(t.TempDir(), "daily", templateDir, false)
+
+	tests := map[string]string{
+		"incident": "incident.md.tmpl",
+		"hack":     "hack.md.tmpl",
+		"proj":     "ticket.md.tmpl",
+		"":         "ticket.md.tmpl",
+	}
+	for ticketType, want := range tests {
+		if got := m.TicketTemplateName(ticketType); got != want {
+			t.Errorf("TicketTemplateName(%q) = %q, want %q", ticketType, got, want)
+		}
+	}
+}
+
+func TestCreateTicketNote_TypeTemplate(t *testing.T) {
+	templateDir := t.TempDir()
+	writeTemplate(t, templateDir, "ops.md.tmpl", "# Ops: {{.Ticket}}\n\n## Log\n")
+	m := NewManager(t.TempDir(), "daily", templateDir, false)
+
+	notePath, err := m.CreateTicketNote(TicketData{Ticket: "ops-1", TicketType: "ops"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(notePath)
+	if !strings.Contains(string(content), "# Ops: ops-1") {
+		t.Errorf("ops note should use ops.md.tmpl:\n%s", content)
+	}
+}
+
+func TestRenderTemplate_Funcs(t *testing.T) {
+	templateDir := t.TempDir()
+	writeTemplate(t, templateDir, "funcs.md.tmpl", strings.Join([]string{
+		`{{.Summary | slug}}`,
+		`{{.Summary | title}}`,
+		`{{.Ticket | upper}} {{.Ticket | lower}}`,
+		`{{.Summary | trunc 9}}`,
+		`{{.Status | default "Unknown"}}`,
+		`{{replace "-" "_" .Ticket}} {{trimPrefix "proj-" .Ticket}}`,
+		`{{if hasPrefix "proj" .Ticket}}project{{end}} {{if contains "login" .Summary}}login{{end}}`,
+		`{{split "," "a,b" | join "+"}}`,
+		`{{date "Mon Jan 2" .Date}} {{addDays 1 .Date | date "2006-01-02"}}`,
+		`{{"a\nb" | indent 2}}`,
+	}, "\n"))
+	m := NewManager(t.TempDir(), "daily", templateDir, false)
+
+	got, err := m.RenderTemplate("funcs.md.tmpl", TicketData{Ticket: "PROJ-7", Summary: "fix the login: timeout!", Date: "2025-01-15"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.Join([]string{
+		"fix-the-login-timeout",
+		"Fix The Login: Timeout!",
+		"PROJ-7 proj-7",
+		"fix the l",
+		"Unknown",
+		"PROJ_7 PROJ-7",
+		" login",
+		"a+b",
+		"Wed Jan 15 2025-01-16",
+		"  a\n  b",
+	}, "\n")
+	if got != want {
+		t.Errorf("RenderTemplate() =\n%s\nwant\n%s", got, want)
+	}
+
+	writeTemplate(t, templateDir, "baddate.md.tmpl", `{{date "2006" .Ticket}}`)
+	if _, err := m.RenderTemplate("baddate.md.tmpl", TicketData{Ticket: "proj-1"}); err == nil {
+		t.Error("date of a non-date should fail")
+	}
+}
+
+func TestRenderTemplate_Partials(t *testing.T) {
+	templateDir := t.TempDir()
+	writeTemplate(t, templateDir, "partials/links.md.tmpl", "- [{{.Ticket}}]({{.URL}})")
+	writeTemplate(t, templateDir, "ticket.md.tmpl", `{{template "links" .}}
+{{include "links" . | upper}}
+{{if hasPartial "oncall"}}{{include "oncall" .}}{{else}}no oncall{{end}}`)
+	m := NewManager(t.TempDir(), "daily", templateDir, false)
+
+	got, err := m.RenderTemplate("ticket.md.tmpl", TicketData{Ticket: "proj-1", URL: "https://x/PROJ-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "- [proj-1](https://x/PROJ-1)\n- [PROJ-1](HTTPS://X/PROJ-1)\nno oncall"
+	if got != want {
+		t.Errorf("RenderTemplate() =\n%s\nwant\n%s", got, want)
+	}
+
+	writeTemplate(t, templateDir, "missing.md.tmpl", `{{include "nope" .}}`)
+	if _, err := m.RenderTemplate("missing.md.tmpl", TicketData{}); err == nil || !strings.Contains(err.Error(), "nope") {
+		t.Errorf("including a missing partial should fail, got %v", err)
+	}
+
+	writeTemplate(t, templateDir, "partials/broken.md.tmpl", "{{if}}")
+	if _, err := m.RenderTemplate("ticket.md.tmpl", TicketData{}); err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("a broken partial should fail rendering, got %v", err)
+	}
+}
+
+func TestListTemplates(t *testing.T) {
+	templateDir := t.TempDir()
+	writeTemplate(t, templateDir, "ticket.md.tmpl", "# custom\n")
+	writeTemplate(t, templateDir, "incident.md.tmpl", "# incident\n")
+	writeTemplate(t, templateDir, "partials/links.md.tmpl", "links")
+	writeTemplate(t, templateDir, "README.md", "not a template")
+	m := NewManager(t.TempDir(), "daily", templateDir, false)
+
+	templates, err := m.ListTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, info := range templates {
+		entry := info.Name
+		if info.Builtin {
+			entry += ":builtin"
+		}
+		if info.Path != "" {
+			entry += ":user"
+		}
+		if info.Partial {
+			entry += ":partial"
+		}
+		got = append(got, entry)
+	}
+
+	want := "daily.md.tmpl:builtin,hack.md.tmpl:builtin,incident.md.tmpl:builtin:user,index.md.tmpl:builtin,postmortem.md.tmpl:builtin,report-standup.md.tmpl:builtin,report-week.md.tmpl:builtin,ticket.md.tmpl:builtin:user,links:user:partial"
+	if strings.Join(got, ",") != want {
+		t.Errorf("ListTemplates() = %s, want %s", strings.Join(got, ","), want)
+	}
+}
+
+func TestSampleTicketData(t *testing.T) {
+	data := SampleTicketData("incident")
+	if data.Ticket != "incident-123" || data.TicketType != "incident" || data.URL == "" || data.Date == "" {
+		t.Errorf("SampleTicketData(incident) = %+v", data)
+	}
+	if daily := SampleTicketData("daily"); daily.Ticket != "" || daily.Date == "" {
+		t.Errorf("SampleTicketData(daily) = %+v", daily)
+	}
+	if got := TemplateType("ticket.md.tmpl"); got != "proj" {
+		t.Errorf("TemplateType(ticket.md.tmpl) = %q", got)
+	}
+	if got := TemplateType("ops.md.tmpl"); got != "ops" {
+		t.Errorf("TemplateType(ops.md.tmpl) = %q", got)
+	}
+}
@@ -0,0 +1,54 @@
+package tmux
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+// CapturePane returns the last lines of a window's active pane, including
+// scrollback. window is a window name or index; "" means the session's
+// current window. Wrapped lines are joined and trailing blank lines dropped.
+func (sm *SessionManager) CapturePane(sessionName, window string, lines int) (string, error) {
+	if lines <= 0 {
+		return "", errors.Newf("invalid line count: %d", lines)
+	}
+	if !sm.sessionExists(sessionName) {
+		return "", errors.Newf("session does not exist: %s", sessionName)
+	}
+
+	target := sessionName + ":" + window
+	output, err := sm.tmuxCmd("capture-pane", "-p", "-J", "-t", target, "-S", "-"+strconv.Itoa(lines)).Output()
+	if err != nil {
+		if window != "" {
+			return "", errors.Wrapf(err, "failed to capture window %s of %s", window, sessionName)
+		}
+		return "", errors.Wrapf(err, "failed to capture %s", sessionName)
+	}
+
+	return lastLines(string(output), lines), nil
+}
+
+// WindowName returns the name of a window, or of the session's current
+// window when window is ""
+func (sm *SessionManager) WindowName(sessionName, window string) (string, error) {
+	output, err := sm.tmuxCmd("display-message", "-p", "-t", sessionName+":"+window, "#{window_name}").Output()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to find window %s:%s", sessionName, window)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// lastLines returns at most n lines from the end of output, ignoring the
+// blank lines tmux pads the visible area with
+func lastLines(output string, n int) string {
+	lines := strings.Split(strings.TrimRight(output, " \t\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.Join(lines, "\n")
+}
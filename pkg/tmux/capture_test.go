@@ -0,0 +1,73 @@
+package tmux
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLastLines(t *testing.T) {
+	tests := []struct {
+		output string
+		n      int
+		want   string
+	}{
+		{"a\nb\nc\n\n\n", 2, "b\nc"},
+		{"a  \nb\n", 5, "a\nb"},
+		{"", 3, ""},
+	}
+
+	for _, tt := range tests {
+		if got := lastLines(tt.output, tt.n); got != tt.want {
+			t.Errorf("lastLines(%q, %d) = %q, want %q", tt.output, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestCapturePane_Integration(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not found in PATH, skipping integration test")
+	}
+
+	sm := newTestSessionManager(t, "test-", nil)
+	sessionName := sm.GetSessionName("capture")
+	_ = exec.Command("tmux", "-L", testSocket(t), "kill-session", "-t", sessionName).Run()
+
+	// A window running a fixed program avoids waiting on shell startup
+	err := exec.Command("tmux", "-L", testSocket(t), "new-session", "-d", "-s", sessionName, "-n", "out",
+		"printf 'one\\ntwo\\nthree\\n'; sleep 30").Run()
+	if err != nil {
+		t.Fatalf("Failed to create test session: %v", err)
+	}
+	defer func() {
+		_ = exec.Command("tmux", "-L", testSocket(t), "kill-session", "-t", sessionName).Run()
+	}()
+
+	var got string
+	for i := 0; i < 50 && !strings.Contains(got, "three"); i++ {
+		time.Sleep(50 * time.Millisecond)
+		got, err = sm.CapturePane(sessionName, "out", 2)
+		if err != nil {
+			t.Fatalf("CapturePane() error: %v", err)
+		}
+	}
+	if got != "two\nthree" {
+		t.Errorf("CapturePane() = %q, want %q", got, "two\nthree")
+	}
+
+	name, err := sm.WindowName(sessionName, "")
+	if err != nil || name != "out" {
+		t.Errorf("WindowName() = %q, %v; want out", name, err)
+	}
+
+	if _, err := sm.CapturePane(sessionName, "missing", 10); err == nil {
+		t.Error("CapturePane() should fail for an unknown window")
+	}
+	if _, err := sm.CapturePane("test-no-such-session", "", 10); err == nil {
+		t.Error("CapturePane() should fail for a missing session")
+	}
+	if _, err := sm.CapturePane(sessionName, "", 0); err == nil {
+		t.Error("CapturePane() should reject a non-positive line count")
+	}
+}
@@ -6,9 +6,11 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/cockroachdb/errors"
 )
@@ -19,7 +21,7 @@ import (
 //
 // SECURITY: The config file is user-controlled and trusted. This allowlist exists as
 // defense-in-depth to limit blast radius if a config file is somehow compromised.
-// Users who need additional commands should add patterns here.
+// Users who need additional commands list them in tmux.allowed_commands (see AllowCommands).
 var AllowedCommandPatterns = []*regexp.Regexp{
 	// Editor commands
 	regexp.MustCompile(`^(nvim|vim|vi|emacs|nano|code|hx)(\s|$)`),
@@ -44,18 +46,49 @@ type SessionManager struct {
 	ValidateCommands bool   // Validate commands against allowlist
 	SocketName       string // Optional socket name for tmux -L isolation (used in tests)
 
+	// Environment holds extra session variables, such as ticket context
+	// and profile env, set alongside the RIG_* defaults (see AddEnvironment)
+	Environment map[string]string
+
+	// extraCommandPatterns extends AllowedCommandPatterns for this manager
+	extraCommandPatterns []*regexp.Regexp
+
 	// baseIndex caches the tmux base-index option (0 or 1 typically)
 	baseIndex     int
 	baseIndexOnce sync.Once
 }
 
-// WindowConfig represents a tmux window configuration
+// WindowConfig represents a tmux window configuration.
+// Command and WorkingDir apply to the first pane; Panes are split off in order.
 type WindowConfig struct {
 	Name       string
 	Command    string
 	WorkingDir string
+	Layout     string // Named layout or custom layout string, applied after splitting
+	Panes      []PaneConfig
 }
 
+// PaneConfig represents an additional pane within a tmux window
+type PaneConfig struct {
+	Split      string // SplitHorizontal or SplitVertical (default)
+	Size       int    // Percentage of the window (1-99), 0 for tmux's default
+	Command    string
+	WorkingDir string
+}
+
+// Pane split directions, using tmux's terminology
+const (
+	SplitHorizontal = "horizontal" // Side by side (split-window -h)
+	SplitVertical   = "vertical"   // Stacked (split-window -v)
+)
+
+// NamedLayouts lists the preset layouts accepted by tmux select-layout
+var NamedLayouts = []string{"even-horizontal", "even-vertical", "main-horizontal", "main-vertical", "tiled"}
+
+// customLayoutPattern matches a layout string as printed by #{window_layout},
+// e.g. "5e8b,159x48,0,0{79x48,0,0,1,79x48,80,0,2}"
+var customLayoutPattern = regexp.MustCompile(`^[0-9a-f]{4},[0-9]+x[0-9]+,[0-9]+,[0-9]+[\[{,0-9x\]}]*$`)
+
 // NewSessionManager creates a new SessionManager
 func NewSessionManager(sessionPrefix string, windows []WindowConfig, verbose bool) *SessionManager {
 	sm := &SessionManager{
@@ -72,6 +105,32 @@ func NewSessionManager(sessionPrefix string, windows []WindowConfig, verbose boo
 	return sm
 }
 
+// AllowCommands extends the command allowlist with command prefixes from
+// config, e.g. "just" or "direnv exec". A prefix matches the command itself
+// or the command followed by arguments.
+func (sm *SessionManager) AllowCommands(prefixes []string) {
+	for _, prefix := range prefixes {
+		if pattern := CommandPrefixPattern(prefix); pattern != nil {
+			sm.extraCommandPatterns = append(sm.extraCommandPatterns, pattern)
+		}
+	}
+}
+
+// CommandPrefixPattern returns the allowlist pattern for a command prefix,
+// or nil for an empty prefix. Whitespace within the prefix matches any run
+// of whitespace.
+func CommandPrefixPattern(prefix string) *regexp.Regexp {
+	words := strings.Fields(prefix)
+	if len(words) == 0 {
+		return nil
+	}
+
+	for i, word := range words {
+		words[i] = regexp.QuoteMeta(word)
+	}
+	return regexp.MustCompile(`^` + strings.Join(words, `\s+`) + `(\s|$)`)
+}
+
 // tmuxCmd creates an exec.Cmd for tmux with optional socket isolation.
 // When SocketName is set, commands run on a separate tmux server.
 func (sm *SessionManager) tmuxCmd(args ...string) *exec.Cmd {
@@ -126,27 +185,46 @@ func (sm *SessionManager) CreateSession(ticket, worktreePath, notePath string) e
 		fmt.Printf("Creating tmux session '%s'...\n", sessionName)
 	}
 
+	if err := sm.buildSession(sessionName, ticket, worktreePath, notePath); err != nil {
+		return err
+	}
+
+	// Attach to the session if we're in a tmux session, otherwise switch
+	return sm.attachToSession(sessionName)
+}
+
+// buildSession creates a detached session with the configured windows and
+// the RIG_* environment, leaving the first window selected
+func (sm *SessionManager) buildSession(sessionName, ticket, worktreePath, notePath string) error {
 	// Verify worktree directory exists
 	if _, err := os.Stat(worktreePath); os.IsNotExist(err) {
 		return errors.Newf("worktree path does not exist: %s", worktreePath)
 	}
 
-	// Create session with first window
-	err := sm.createInitialSession(sessionName, worktreePath)
+	// Reject bad pane/layout configuration before creating anything
+	if err := ValidateWindows(sm.Windows); err != nil {
+		return err
+	}
+
+	env := sm.sessionEnvironment(ticket, worktreePath, notePath)
+
+	// Create session with first window, whose shell gets the environment
+	// directly since it starts before set-environment can run
+	err := sm.createInitialSession(sessionName, worktreePath, env)
 	if err != nil {
 		return errors.Wrap(err, "failed to create initial session")
 	}
 
-	// Create additional windows
-	err = sm.createWindows(sessionName, worktreePath, notePath)
+	// Set environment variables for the session, inherited by later windows and panes
+	err = sm.SetEnvironment(sessionName, env)
 	if err != nil {
-		return errors.Wrap(err, "failed to create windows")
+		return errors.Wrap(err, "failed to set environment variables")
 	}
 
-	// Set environment variables for the session
-	err = sm.setEnvironmentVars(sessionName, ticket, worktreePath)
+	// Create additional windows
+	err = sm.createWindows(sessionName, worktreePath, notePath)
 	if err != nil {
-		return errors.Wrap(err, "failed to set environment variables")
+		return errors.Wrap(err, "failed to create windows")
 	}
 
 	// Start on the first window (note)
@@ -155,8 +233,7 @@ func (sm *SessionManager) CreateSession(ticket, worktreePath, notePath string) e
 		return errors.Wrap(err, "failed to select first window")
 	}
 
-	// Attach to the session if we're in a tmux session, otherwise switch
-	return sm.attachToSession(sessionName)
+	return nil
 }
 
 // GetSessionName returns the full session name with prefix
@@ -184,7 +261,7 @@ func (sm *SessionManager) sessionExists(sessionName string) bool {
 }
 
 // createInitialSession creates the initial tmux session
-func (sm *SessionManager) createInitialSession(sessionName, worktreePath string) error {
+func (sm *SessionManager) createInitialSession(sessionName, worktreePath string, env map[string]string) error {
 	// Determine the initial working directory (use vault path if available from first window)
 	var initialDir string
 	if len(sm.Windows) > 0 && sm.Windows[0].WorkingDir != "" {
@@ -193,7 +270,11 @@ func (sm *SessionManager) createInitialSession(sessionName, worktreePath string)
 		initialDir = worktreePath
 	}
 
-	cmd := sm.tmuxCmd("new-session", "-d", "-s", sessionName, "-c", initialDir)
+	args := []string{"new-session", "-d", "-s", sessionName, "-c", initialDir}
+	for _, key := range sortedKeys(env) {
+		args = append(args, "-e", key+"="+env[key])
+	}
+	cmd := sm.tmuxCmd(args...)
 
 	if sm.Verbose {
 		cmd.Stdout = os.Stdout
@@ -211,6 +292,11 @@ func (sm *SessionManager) createWindows(sessionName, worktreePath, notePath stri
 		windowIndex := baseIndex + i
 		windowTarget := fmt.Sprintf("%s:%d", sessionName, windowIndex)
 
+		workingDir := sm.expandPath(window.WorkingDir, worktreePath, notePath)
+		if workingDir == "" {
+			workingDir = worktreePath
+		}
+
 		if i == 0 {
 			// Rename the first window that was created with the session
 			err := sm.renameWindow(windowTarget, window.Name)
@@ -219,11 +305,6 @@ func (sm *SessionManager) createWindows(sessionName, worktreePath, notePath stri
 			}
 		} else {
 			// Create new window
-			workingDir := sm.expandPath(window.WorkingDir, worktreePath, notePath)
-			if workingDir == "" {
-				workingDir = worktreePath
-			}
-
 			err := sm.createWindow(sessionName, windowIndex, window.Name, workingDir)
 			if err != nil {
 				return errors.Wrapf(err, "failed to create window %d", windowIndex)
@@ -238,13 +319,160 @@ func (sm *SessionManager) createWindows(sessionName, worktreePath, notePath stri
 				return errors.Wrapf(err, "failed to send command to window %s", window.Name)
 			}
 		}
+
+		if len(window.Panes) > 0 || window.Layout != "" {
+			if err := sm.createPanes(windowTarget, window, workingDir, worktreePath, notePath); err != nil {
+				return errors.Wrapf(err, "failed to create panes in window %s", window.Name)
+			}
+		}
 	}
 
 	return nil
 }
 
+// createPanes splits a window into the configured panes, applies its layout
+// and leaves the first pane selected. windowDir is the default pane directory.
+func (sm *SessionManager) createPanes(windowTarget string, window WindowConfig, windowDir, worktreePath, notePath string) error {
+	firstPane, err := sm.activePane(windowTarget)
+	if err != nil {
+		return err
+	}
+
+	for i, pane := range window.Panes {
+		workingDir := sm.expandPath(pane.WorkingDir, worktreePath, notePath)
+		if workingDir == "" {
+			workingDir = windowDir
+		}
+
+		paneID, err := sm.splitWindow(windowTarget, pane, workingDir)
+		if err != nil {
+			return errors.Wrapf(err, "failed to split pane %d", i+1)
+		}
+
+		if pane.Command != "" {
+			command := sm.expandPath(pane.Command, worktreePath, notePath)
+			if err := sm.sendCommand(paneID, command); err != nil {
+				return errors.Wrapf(err, "failed to send command to pane %d", i+1)
+			}
+		}
+	}
+
+	if window.Layout != "" {
+		if err := sm.selectLayout(windowTarget, window.Layout); err != nil {
+			return errors.Wrapf(err, "failed to apply layout %q", window.Layout)
+		}
+	}
+
+	return sm.selectPane(firstPane)
+}
+
+// ValidateWindows checks pane split directions, sizes and layouts
+func ValidateWindows(windows []WindowConfig) error {
+	for _, window := range windows {
+		if window.Layout != "" && !isValidLayout(window.Layout) {
+			return errors.Newf("window %q: invalid layout %q (expected one of %s, or a custom layout string)", window.Name, window.Layout, strings.Join(NamedLayouts, ", "))
+		}
+
+		for i, pane := range window.Panes {
+			if _, err := splitFlag(pane.Split); err != nil {
+				return errors.Wrapf(err, "window %q pane %d", window.Name, i+1)
+			}
+			if pane.Size < 0 || pane.Size > 99 {
+				return errors.Newf("window %q pane %d: size must be a percentage between 1 and 99, got %d", window.Name, i+1, pane.Size)
+			}
+		}
+	}
+
+	return nil
+}
+
+// isValidLayout reports whether layout is a named tmux layout or a custom layout string
+func isValidLayout(layout string) bool {
+	for _, named := range NamedLayouts {
+		if layout == named {
+			return true
+		}
+	}
+	return customLayoutPattern.MatchString(layout)
+}
+
+// splitFlag maps a split direction to the split-window flag
+func splitFlag(split string) (string, error) {
+	switch strings.ToLower(split) {
+	case "", SplitVertical, "v":
+		return "-v", nil
+	case SplitHorizontal, "h":
+		return "-h", nil
+	default:
+		return "", errors.Newf("invalid split %q (expected %q or %q)", split, SplitHorizontal, SplitVertical)
+	}
+}
+
+// activePane returns the ID of the active pane in a window
+func (sm *SessionManager) activePane(windowTarget string) (string, error) {
+	output, err := sm.tmuxCmd("display-message", "-p", "-t", windowTarget, "#{pane_id}").Output()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get active pane")
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// splitWindow splits the active pane of a window and returns the new pane's ID
+func (sm *SessionManager) splitWindow(windowTarget string, pane PaneConfig, workingDir string) (string, error) {
+	flag, err := splitFlag(pane.Split)
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{"split-window", flag, "-t", windowTarget, "-c", workingDir, "-P", "-F", "#{pane_id}"}
+	if pane.Size > 0 {
+		args = append(args, "-l", fmt.Sprintf("%d%%", pane.Size))
+	}
+
+	cmd := sm.tmuxCmd(args...)
+	if sm.Verbose {
+		cmd.Stderr = os.Stderr
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// selectLayout applies a named or custom layout to a window
+func (sm *SessionManager) selectLayout(windowTarget, layout string) error {
+	cmd := sm.tmuxCmd("select-layout", "-t", windowTarget, layout)
+
+	if sm.Verbose {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+	}
+
+	return cmd.Run()
+}
+
+// selectPane makes a pane the active pane of its window
+func (sm *SessionManager) selectPane(paneTarget string) error {
+	cmd := sm.tmuxCmd("select-pane", "-t", paneTarget)
+
+	if sm.Verbose {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+	}
+
+	return cmd.Run()
+}
+
 // expandPath expands template variables in paths and commands
 func (sm *SessionManager) expandPath(template, worktreePath, notePath string) string {
+	return ExpandTemplate(template, worktreePath, notePath)
+}
+
+// ExpandTemplate expands {worktree_path}, {note_path} and a leading ~/ in
+// window paths and commands
+func ExpandTemplate(template, worktreePath, notePath string) string {
 	result := template
 	result = strings.ReplaceAll(result, "{worktree_path}", worktreePath)
 	result = strings.ReplaceAll(result, "{note_path}", notePath)
@@ -286,13 +514,13 @@ func (sm *SessionManager) createWindow(sessionName string, windowNum int, name,
 	return cmd.Run()
 }
 
-// sendCommand sends a command to a tmux window
+// sendCommand sends a command to a tmux window or pane
 // SECURITY: Commands come from user-controlled config files. While the config
 // is trusted (user creates it), we validate against an allowlist as defense-in-depth.
 func (sm *SessionManager) sendCommand(windowTarget, command string) error {
 	// Validate command against allowlist if enabled
 	if sm.ValidateCommands && !sm.isCommandAllowed(command) {
-		return errors.Newf("command not in allowlist: %q (see AllowedCommandPatterns in pkg/tmux/session.go)", command)
+		return errors.Newf("command not in allowlist: %q (add it to tmux.allowed_commands in your config)", command)
 	}
 
 	// Warn about command execution if enabled
@@ -312,7 +540,12 @@ func (sm *SessionManager) sendCommand(windowTarget, command string) error {
 
 // isCommandAllowed checks if a command matches any allowed pattern
 func (sm *SessionManager) isCommandAllowed(command string) bool {
-	// Empty commands are always allowed (no-op)
+	return CommandAllowed(command, sm.extraCommandPatterns)
+}
+
+// CommandAllowed reports whether command matches AllowedCommandPatterns or
+// one of the extra patterns. Empty commands are always allowed (no-op).
+func CommandAllowed(command string, extra []*regexp.Regexp) bool {
 	if strings.TrimSpace(command) == "" {
 		return true
 	}
@@ -322,17 +555,70 @@ func (sm *SessionManager) isCommandAllowed(command string) bool {
 			return true
 		}
 	}
+	for _, pattern := range extra {
+		if pattern.MatchString(command) {
+			return true
+		}
+	}
 	return false
 }
 
-// setEnvironmentVars sets environment variables for the tmux session
-func (sm *SessionManager) setEnvironmentVars(sessionName, ticket, worktreePath string) error {
-	vars := map[string]string{
+// SessionEnvironment returns the RIG_* variables set in every session.
+// RIG_NOTE is only set when the ticket has a note.
+func SessionEnvironment(ticket, worktreePath, notePath string) map[string]string {
+	env := map[string]string{
 		"RIG_TICKET":   ticket,
 		"RIG_WORKTREE": worktreePath,
 	}
+	if notePath != "" {
+		env["RIG_NOTE"] = notePath
+	}
+	return env
+}
+
+// MergeEnvironment returns the RIG_* defaults overlaid with extra
+func MergeEnvironment(ticket, worktreePath, notePath string, extra map[string]string) map[string]string {
+	env := SessionEnvironment(ticket, worktreePath, notePath)
+	for key, value := range extra {
+		env[key] = value
+	}
+	return env
+}
 
+// AddEnvironment adds variables to set in sessions created by this manager
+func (sm *SessionManager) AddEnvironment(vars map[string]string) {
+	if sm.Environment == nil {
+		sm.Environment = make(map[string]string, len(vars))
+	}
 	for key, value := range vars {
+		sm.Environment[key] = value
+	}
+}
+
+// sessionEnvironment returns the full environment for a new session
+func (sm *SessionManager) sessionEnvironment(ticket, worktreePath, notePath string) map[string]string {
+	return MergeEnvironment(ticket, worktreePath, notePath, sm.Environment)
+}
+
+// sortedKeys returns the keys of vars in sorted order
+func sortedKeys(vars map[string]string) []string {
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Name returns the multiplexer backend name
+func (sm *SessionManager) Name() string {
+	return "tmux"
+}
+
+// SetEnvironment sets session environment variables inherited by new panes
+func (sm *SessionManager) SetEnvironment(sessionName string, vars map[string]string) error {
+	for _, key := range sortedKeys(vars) {
+		value := vars[key]
 		cmd := sm.tmuxCmd("set-environment", "-t", sessionName, key, value)
 
 		if sm.Verbose {
@@ -349,6 +635,26 @@ func (sm *SessionManager) setEnvironmentVars(sessionName, ticket, worktreePath s
 	return nil
 }
 
+// UnsetEnvironment removes session environment variables, so that new panes
+// no longer inherit them
+func (sm *SessionManager) UnsetEnvironment(sessionName string, keys []string) error {
+	for _, key := range keys {
+		cmd := sm.tmuxCmd("set-environment", "-u", "-t", sessionName, key)
+
+		if sm.Verbose {
+			fmt.Printf("Unsetting %s\n", key)
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stderr
+		}
+
+		if err := cmd.Run(); err != nil {
+			return errors.Wrapf(err, "failed to unset %s", key)
+		}
+	}
+
+	return nil
+}
+
 // selectWindow selects a specific window in the session
 func (sm *SessionManager) selectWindow(sessionName string, windowNum int) error {
 	windowTarget := fmt.Sprintf("%s:%d", sessionName, windowNum)
@@ -363,24 +669,26 @@ func (sm *SessionManager) selectWindow(sessionName string, windowNum int) error
 	return cmd.Run()
 }
 
-// AttachToSession attaches to or switches to a tmux session
+// AttachToSession attaches to or switches to a tmux session. Inside a tmux
+// client ($TMUX is set) the client is switched to the session, because
+// attach-session would nest a second client in the current pane.
 func (sm *SessionManager) AttachToSession(sessionName string) error {
-	// Check if we're already in a tmux session
-	if os.Getenv("TMUX") != "" {
-		// We're in tmux, switch to the session
-		cmd := sm.tmuxCmd("switch-client", "-t", sessionName)
+	inside := InsideTmux()
+	cmd := sm.tmuxCmd(attachArgs(sessionName, inside)...)
 
+	if inside {
 		if sm.Verbose {
 			fmt.Printf("Switching to session: %s\n", sessionName)
 			cmd.Stdout = os.Stdout
 			cmd.Stderr = os.Stderr
 		}
 
-		return cmd.Run()
+		if err := cmd.Run(); err != nil {
+			return errors.Wrapf(err, "failed to switch client to session %s", sessionName)
+		}
+		return nil
 	}
 
-	// We're not in tmux, attach to the session
-	cmd := sm.tmuxCmd("attach-session", "-t", sessionName)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -392,6 +700,20 @@ func (sm *SessionManager) AttachToSession(sessionName string) error {
 	return cmd.Run()
 }
 
+// InsideTmux reports whether rig is running inside a tmux client
+func InsideTmux() bool {
+	return os.Getenv("TMUX") != ""
+}
+
+// attachArgs returns the tmux command that brings a client to sessionName:
+// switch-client from inside tmux, attach-session otherwise
+func attachArgs(sessionName string, insideTmux bool) []string {
+	if insideTmux {
+		return []string{"switch-client", "-t", sessionName}
+	}
+	return []string{"attach-session", "-t", sessionName}
+}
+
 // attachToSession is a private helper method
 func (sm *SessionManager) attachToSession(sessionName string) error {
 	return sm.AttachToSession(sessionName)
@@ -418,6 +740,72 @@ func (sm *SessionManager) ListSessions() ([]string, error) {
 	return result, nil
 }
 
+// SessionInfo describes a running session
+type SessionInfo struct {
+	Name     string
+	Attached int // Number of attached clients
+	Windows  int
+	Created  time.Time
+	Activity time.Time // Last activity in any of the session's windows
+}
+
+// ListSessionInfo returns details of all sessions
+func (sm *SessionManager) ListSessionInfo() ([]SessionInfo, error) {
+	output, err := sm.tmuxCmd("list-sessions", "-F",
+		"#{session_name}\t#{session_attached}\t#{session_windows}\t#{session_created}\t#{session_activity}").Output()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list sessions")
+	}
+	return parseSessionInfo(string(output)), nil
+}
+
+// parseSessionInfo parses ListSessionInfo's list-sessions output
+func parseSessionInfo(output string) []SessionInfo {
+	var sessions []SessionInfo
+	for _, line := range strings.Split(strings.Trim(output, "\n"), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 5 || fields[0] == "" {
+			continue
+		}
+		info := SessionInfo{Name: fields[0]}
+		info.Attached, _ = strconv.Atoi(fields[1])
+		info.Windows, _ = strconv.Atoi(fields[2])
+		info.Created = unixTime(fields[3])
+		info.Activity = unixTime(fields[4])
+		sessions = append(sessions, info)
+	}
+	return sessions
+}
+
+// unixTime parses a tmux timestamp format, which is seconds since the epoch
+func unixTime(value string) time.Time {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds == 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
+
+// GetEnvironment returns a variable from a session's environment, or ""
+// if it isn't set
+func (sm *SessionManager) GetEnvironment(sessionName, key string) (string, error) {
+	output, err := sm.tmuxCmd("show-environment", "-t", sessionName, key).Output()
+	if err != nil {
+		// tmux exits non-zero for variables that were never set
+		if sm.sessionExists(sessionName) {
+			return "", nil
+		}
+		return "", errors.Wrapf(err, "failed to read environment of session %s", sessionName)
+	}
+
+	// Set variables print as KEY=value, removed ones as -KEY
+	value, found := strings.CutPrefix(strings.TrimSpace(string(output)), key+"=")
+	if !found {
+		return "", nil
+	}
+	return value, nil
+}
+
 // KillSession kills a tmux session
 func (sm *SessionManager) KillSession(ticket string) error {
 	sessionName := sm.getSessionName(ticket)
@@ -436,3 +824,32 @@ func (sm *SessionManager) KillSession(ticket string) error {
 
 	return cmd.Run()
 }
+
+// RenameSession renames the session for oldTicket to the session name for
+// newTicket. Its environment still describes oldTicket; update it with
+// SetEnvironment and UnsetEnvironment.
+func (sm *SessionManager) RenameSession(oldTicket, newTicket string) error {
+	oldName := sm.getSessionName(oldTicket)
+	newName := sm.getSessionName(newTicket)
+
+	if !sm.sessionExists(oldName) {
+		return errors.Newf("session does not exist: %s", oldName)
+	}
+	if sm.sessionExists(newName) {
+		return errors.Newf("session already exists: %s", newName)
+	}
+
+	cmd := sm.tmuxCmd("rename-session", "-t", oldName, newName)
+
+	if sm.Verbose {
+		fmt.Printf("Renaming session %s to %s\n", oldName, newName)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+	}
+
+	if err := cmd.Run(); err != nil {
+		return errors.Wrap(err, "failed to rename session")
+	}
+
+	return nil
+}
@@ -1,11 +1,15 @@
 package tmux
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestMain(m *testing.M) {
@@ -145,7 +149,7 @@ func TestSessionExists_NoTmux(t *testing.T) {
 		t.Skip("tmux not found in PATH, skipping test")
 	}
 
-	sm := NewTestSessionManager("", nil)
+	sm := newTestSessionManager(t, "", nil)
 
 	// Test with a session name that definitely doesn't exist
 	exists := sm.SessionExists("nonexistent-session-xyz-123456")
@@ -169,14 +173,14 @@ func TestCreateAndKillSession_Integration(t *testing.T) {
 		{Name: "test", WorkingDir: "{worktree_path}"},
 	}
 
-	sm := NewTestSessionManager("test-", windows)
+	sm := newTestSessionManager(t, "test-", windows)
 	sessionName := sm.GetSessionName("integration-test")
 
 	// Clean up any existing session first
-	_ = exec.Command("tmux", "-L", TestSocketName, "kill-session", "-t", sessionName).Run()
+	_ = exec.Command("tmux", "-L", testSocket(t), "kill-session", "-t", sessionName).Run()
 
 	// Create a detached session for testing (we can't attach in test)
-	cmd := exec.Command("tmux", "-L", TestSocketName, "new-session", "-d", "-s", sessionName, "-c", tmpDir)
+	cmd := exec.Command("tmux", "-L", testSocket(t), "new-session", "-d", "-s", sessionName, "-c", tmpDir)
 	if err := cmd.Run(); err != nil {
 		t.Fatalf("Failed to create test session: %v", err)
 	}
@@ -203,7 +207,7 @@ func TestListSessions_Integration(t *testing.T) {
 		t.Skip("tmux not found in PATH, skipping integration test")
 	}
 
-	sm := NewTestSessionManager("", nil)
+	sm := newTestSessionManager(t, "", nil)
 
 	// This test just verifies ListSessions doesn't error
 	// We can't guarantee any sessions exist
@@ -226,7 +230,7 @@ func TestKillSession_NonExistent(t *testing.T) {
 		t.Skip("tmux not found in PATH, skipping test")
 	}
 
-	sm := NewTestSessionManager("", nil)
+	sm := newTestSessionManager(t, "", nil)
 
 	err := sm.KillSession("definitely-does-not-exist-xyz-999")
 	if err == nil {
@@ -240,7 +244,7 @@ func TestCreateSession_NonExistentWorktree(t *testing.T) {
 		t.Skip("tmux not found in PATH, skipping test")
 	}
 
-	sm := NewTestSessionManager("test-", nil)
+	sm := newTestSessionManager(t, "test-", nil)
 
 	err := sm.CreateSession("test-ticket", "/nonexistent/path/that/does/not/exist", "")
 	if err == nil {
@@ -491,22 +495,22 @@ func TestCreateSession_CreatesAllWindows(t *testing.T) {
 		{Name: "term", WorkingDir: "{worktree_path}"},
 	}
 
-	sm := NewTestSessionManager("test-", windows)
+	sm := newTestSessionManager(t, "test-", windows)
 	sessionName := sm.GetSessionName("window-test")
 
 	// Clean up any existing session first
-	_ = exec.Command("tmux", "-L", TestSocketName, "kill-session", "-t", sessionName).Run()
+	_ = exec.Command("tmux", "-L", testSocket(t), "kill-session", "-t", sessionName).Run()
 
 	// We need to test createWindows directly since CreateSession tries to attach
 	// First create the initial session
-	cmd := exec.Command("tmux", "-L", TestSocketName, "new-session", "-d", "-s", sessionName, "-c", tmpDir)
+	cmd := exec.Command("tmux", "-L", testSocket(t), "new-session", "-d", "-s", sessionName, "-c", tmpDir)
 	if err := cmd.Run(); err != nil {
 		t.Fatalf("Failed to create test session: %v", err)
 	}
 
 	// Clean up session when done
 	defer func() {
-		_ = exec.Command("tmux", "-L", TestSocketName, "kill-session", "-t", sessionName).Run()
+		_ = exec.Command("tmux", "-L", testSocket(t), "kill-session", "-t", sessionName).Run()
 	}()
 
 	// Now call createWindows to add the configured windows
@@ -516,7 +520,7 @@ func TestCreateSession_CreatesAllWindows(t *testing.T) {
 	}
 
 	// Verify window count using tmux list-windows
-	listCmd := exec.Command("tmux", "-L", TestSocketName, "list-windows", "-t", sessionName, "-F", "#{window_name}")
+	listCmd := exec.Command("tmux", "-L", testSocket(t), "list-windows", "-t", sessionName, "-F", "#{window_name}")
 	output, err := listCmd.Output()
 	if err != nil {
 		t.Fatalf("list-windows failed: %v", err)
@@ -573,3 +577,558 @@ func TestAllowedCommandPatterns_Coverage(t *testing.T) {
 		}
 	}
 }
+
+func TestRenameSession_Integration(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not found in PATH, skipping integration test")
+	}
+
+	tmpDir := t.TempDir()
+	sm := newTestSessionManager(t, "test-", nil)
+	oldName := sm.GetSessionName("rename-old")
+	newName := sm.GetSessionName("rename-new")
+
+	for _, name := range []string{oldName, newName} {
+		_ = exec.Command("tmux", "-L", testSocket(t), "kill-session", "-t", name).Run()
+	}
+
+	cmd := exec.Command("tmux", "-L", testSocket(t), "new-session", "-d", "-s", oldName, "-c", tmpDir)
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to create test session: %v", err)
+	}
+	defer func() {
+		_ = exec.Command("tmux", "-L", testSocket(t), "kill-session", "-t", oldName).Run()
+		_ = exec.Command("tmux", "-L", testSocket(t), "kill-session", "-t", newName).Run()
+	}()
+
+	if err := sm.RenameSession("rename-old", "rename-new"); err != nil {
+		t.Fatalf("RenameSession() error: %v", err)
+	}
+
+	if sm.SessionExists(oldName) {
+		t.Error("old session should no longer exist")
+	}
+	if !sm.SessionExists(newName) {
+		t.Fatal("renamed session should exist")
+	}
+
+	// The environment is replaced separately, once the new ticket's is known
+	old := map[string]string{"RIG_TICKET": "rename-old", "RIG_TICKET_TYPE": "hack", "RIG_URL": "https://example.com/old"}
+	if err := sm.SetEnvironment(newName, old); err != nil {
+		t.Fatalf("SetEnvironment() error: %v", err)
+	}
+	if err := sm.SetEnvironment(newName, map[string]string{"RIG_TICKET": "rename-new", "RIG_TICKET_TYPE": "proj", "RIG_NOTE": "/notes/proj/rename-new.md"}); err != nil {
+		t.Fatalf("SetEnvironment() error: %v", err)
+	}
+	if err := sm.UnsetEnvironment(newName, []string{"RIG_URL"}); err != nil {
+		t.Fatalf("UnsetEnvironment() error: %v", err)
+	}
+	for key, want := range map[string]string{"RIG_TICKET": "rename-new", "RIG_TICKET_TYPE": "proj", "RIG_NOTE": "/notes/proj/rename-new.md", "RIG_URL": ""} {
+		if got, err := sm.GetEnvironment(newName, key); err != nil || got != want {
+			t.Errorf("GetEnvironment(%s) = %q, %v; want %q", key, got, err, want)
+		}
+	}
+
+	// Renaming a missing session fails
+	if err := sm.RenameSession("rename-old", "rename-other"); err == nil {
+		t.Error("RenameSession() should fail for a missing session")
+	}
+}
+
+func TestValidateWindows(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		window  WindowConfig
+		wantErr bool
+	}{
+		{name: "no panes", window: WindowConfig{Name: "code"}},
+		{name: "named layout", window: WindowConfig{Name: "code", Layout: "main-vertical"}},
+		{name: "tiled layout", window: WindowConfig{Name: "code", Layout: "tiled"}},
+		{name: "custom layout", window: WindowConfig{Name: "code", Layout: "5e8b,159x48,0,0{79x48,0,0,1,79x48,80,0,2}"}},
+		{name: "nested custom layout", window: WindowConfig{Name: "code", Layout: "a1b2,80x24,0,0[80x12,0,0,1,80x11,0,13{40x11,0,13,2,39x11,41,13,3}]"}},
+		{name: "unknown layout", window: WindowConfig{Name: "code", Layout: "sideways"}, wantErr: true},
+		{name: "layout with shell characters", window: WindowConfig{Name: "code", Layout: "tiled; rm -rf /"}, wantErr: true},
+		{
+			name: "valid panes",
+			window: WindowConfig{Name: "code", Panes: []PaneConfig{
+				{Split: "horizontal", Size: 30},
+				{Split: "vertical"},
+				{Split: "h"},
+				{},
+			}},
+		},
+		{name: "invalid split", window: WindowConfig{Name: "code", Panes: []PaneConfig{{Split: "diagonal"}}}, wantErr: true},
+		{name: "size too large", window: WindowConfig{Name: "code", Panes: []PaneConfig{{Size: 100}}}, wantErr: true},
+		{name: "negative size", window: WindowConfig{Name: "code", Panes: []PaneConfig{{Size: -10}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := ValidateWindows([]WindowConfig{tt.window})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateWindows() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSplitFlag(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		split string
+		want  string
+	}{
+		{split: "", want: "-v"},
+		{split: "vertical", want: "-v"},
+		{split: "v", want: "-v"},
+		{split: "horizontal", want: "-h"},
+		{split: "Horizontal", want: "-h"},
+		{split: "h", want: "-h"},
+	}
+
+	for _, tt := range tests {
+		got, err := splitFlag(tt.split)
+		if err != nil {
+			t.Errorf("splitFlag(%q) error: %v", tt.split, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("splitFlag(%q) = %q, want %q", tt.split, got, tt.want)
+		}
+	}
+}
+
+// testSockets holds the tmux socket of each running test
+var (
+	testSocketsMu sync.Mutex
+	testSockets   = map[*testing.T]string{}
+)
+
+// testSocket returns the test's own tmux socket, so that tests never see
+// each other's sessions, and kills its server when the test ends
+func testSocket(t *testing.T) string {
+	t.Helper()
+	testSocketsMu.Lock()
+	defer testSocketsMu.Unlock()
+
+	if socket, ok := testSockets[t]; ok {
+		return socket
+	}
+	socket := fmt.Sprintf("%s-%d", testSocketName, len(testSockets)+1)
+	testSockets[t] = socket
+	t.Cleanup(func() {
+		_ = exec.Command("tmux", "-L", socket, "kill-server").Run()
+		// tmux leaves the socket file behind when its server exits
+		dir := os.Getenv("TMUX_TMPDIR")
+		if dir == "" {
+			dir = "/tmp"
+		}
+		_ = os.Remove(filepath.Join(dir, fmt.Sprintf("tmux-%d", os.Getuid()), socket))
+	})
+	return socket
+}
+
+// newTestSessionManager creates a SessionManager on the test's own tmux
+// server, see testSocket
+func newTestSessionManager(t *testing.T, prefix string, windows []WindowConfig) *SessionManager {
+	t.Helper()
+	sm := NewSessionManager(prefix, windows, false)
+	sm.SocketName = testSocket(t)
+	return sm
+}
+
+// tmuxTestOutput runs a tmux command on the test server and returns its trimmed output
+func tmuxTestOutput(t *testing.T, args ...string) string {
+	t.Helper()
+	output, err := exec.Command("tmux", append([]string{"-L", testSocket(t)}, args...)...).Output()
+	if err != nil {
+		t.Fatalf("tmux %v failed: %v", args, err)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func TestCreateWindows_Panes_Integration(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not found in PATH, skipping integration test")
+	}
+
+	tmpDir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	logsDir := filepath.Join(tmpDir, "logs")
+	if err := os.Mkdir(logsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	windows := []WindowConfig{
+		{Name: "note", WorkingDir: "{worktree_path}"},
+		{
+			Name:       "code",
+			WorkingDir: "{worktree_path}",
+			Panes: []PaneConfig{
+				{Split: SplitHorizontal, Size: 30, WorkingDir: "{worktree_path}/logs", Command: "ls"},
+				{Split: SplitVertical},
+			},
+		},
+	}
+
+	sm := newTestSessionManager(t, "test-", windows)
+	sessionName := sm.GetSessionName("pane-test")
+
+	_ = exec.Command("tmux", "-L", testSocket(t), "kill-session", "-t", sessionName).Run()
+	cmd := exec.Command("tmux", "-L", testSocket(t), "new-session", "-d", "-x", "200", "-y", "50", "-s", sessionName, "-c", tmpDir)
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to create test session: %v", err)
+	}
+	defer func() {
+		_ = exec.Command("tmux", "-L", testSocket(t), "kill-session", "-t", sessionName).Run()
+	}()
+
+	if err := sm.createWindows(sessionName, tmpDir, ""); err != nil {
+		t.Fatalf("createWindows failed: %v", err)
+	}
+
+	codeWindow := fmt.Sprintf("%s:%d", sessionName, sm.getBaseIndex()+1)
+	noteWindow := fmt.Sprintf("%s:%d", sessionName, sm.getBaseIndex())
+
+	if panes := tmuxTestOutput(t, "list-panes", "-t", noteWindow, "-F", "#{pane_id}"); len(strings.Split(panes, "\n")) != 1 {
+		t.Errorf("note window should have 1 pane, got %v", panes)
+	}
+
+	panes := strings.Split(tmuxTestOutput(t, "list-panes", "-t", codeWindow, "-F", "#{pane_index} #{pane_active} #{pane_width} #{pane_current_path}"), "\n")
+	if len(panes) != 3 {
+		t.Fatalf("code window should have 3 panes, got %d: %v", len(panes), panes)
+	}
+
+	fields := strings.Fields(panes[0])
+	if fields[1] != "1" {
+		t.Errorf("first pane should be active after splitting, panes: %v", panes)
+	}
+
+	// The horizontal split takes ~30% of the 200 column window
+	fields = strings.Fields(panes[1])
+	width, err := strconv.Atoi(fields[2])
+	if err != nil {
+		t.Fatalf("invalid pane width %q", fields[2])
+	}
+	if width < 55 || width > 65 {
+		t.Errorf("split pane width = %d, want ~60 (30%% of 200)", width)
+	}
+	if fields[3] != logsDir {
+		t.Errorf("split pane working dir = %q, want %q", fields[3], logsDir)
+	}
+
+	// Panes without a working dir inherit the window's
+	if fields = strings.Fields(panes[2]); fields[3] != tmpDir {
+		t.Errorf("third pane working dir = %q, want %q", fields[3], tmpDir)
+	}
+}
+
+func TestCreateWindows_Layout_Integration(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not found in PATH, skipping integration test")
+	}
+
+	tmpDir := t.TempDir()
+	windows := []WindowConfig{
+		{Name: "main", Layout: "main-vertical", Panes: []PaneConfig{{}, {}}},
+		{Name: "grid", Layout: "tiled", Panes: []PaneConfig{{}, {}, {}}},
+	}
+
+	sm := newTestSessionManager(t, "test-", windows)
+	sessionName := sm.GetSessionName("layout-test")
+
+	_ = exec.Command("tmux", "-L", testSocket(t), "kill-session", "-t", sessionName).Run()
+	cmd := exec.Command("tmux", "-L", testSocket(t), "new-session", "-d", "-x", "200", "-y", "50", "-s", sessionName, "-c", tmpDir)
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to create test session: %v", err)
+	}
+	defer func() {
+		_ = exec.Command("tmux", "-L", testSocket(t), "kill-session", "-t", sessionName).Run()
+	}()
+
+	if err := sm.createWindows(sessionName, tmpDir, ""); err != nil {
+		t.Fatalf("createWindows failed: %v", err)
+	}
+
+	mainWindow := fmt.Sprintf("%s:%d", sessionName, sm.getBaseIndex())
+	gridWindow := fmt.Sprintf("%s:%d", sessionName, sm.getBaseIndex()+1)
+
+	// main-vertical: the first pane spans the full window height
+	windowHeight := tmuxTestOutput(t, "display-message", "-p", "-t", mainWindow, "#{window_height}")
+	firstPaneHeight := tmuxTestOutput(t, "display-message", "-p", "-t", mainWindow+".0", "#{pane_height}")
+	if firstPaneHeight != windowHeight {
+		t.Errorf("main-vertical first pane height = %s, want window height %s", firstPaneHeight, windowHeight)
+	}
+
+	// tiled: four panes in a 2x2 grid share two distinct left offsets
+	lefts := map[string]bool{}
+	for _, left := range strings.Split(tmuxTestOutput(t, "list-panes", "-t", gridWindow, "-F", "#{pane_left}"), "\n") {
+		lefts[left] = true
+	}
+	if len(lefts) != 2 {
+		t.Errorf("tiled layout should produce 2 columns, got pane_left values %v", lefts)
+	}
+
+	// Layout strings reported by tmux are accepted as custom layouts
+	layout := tmuxTestOutput(t, "display-message", "-p", "-t", gridWindow, "#{window_layout}")
+	if !isValidLayout(layout) {
+		t.Errorf("isValidLayout(%q) = false, want true for a tmux-generated layout", layout)
+	}
+
+	custom := []WindowConfig{{Name: "custom", Layout: layout, Panes: []PaneConfig{{}, {}, {}}}}
+	customSM := newTestSessionManager(t, "test-", custom)
+	customSession := customSM.GetSessionName("custom-layout-test")
+	_ = exec.Command("tmux", "-L", testSocket(t), "kill-session", "-t", customSession).Run()
+	cmd = exec.Command("tmux", "-L", testSocket(t), "new-session", "-d", "-x", "200", "-y", "50", "-s", customSession, "-c", tmpDir)
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to create test session: %v", err)
+	}
+	defer func() {
+		_ = exec.Command("tmux", "-L", testSocket(t), "kill-session", "-t", customSession).Run()
+	}()
+
+	if err := customSM.createWindows(customSession, tmpDir, ""); err != nil {
+		t.Fatalf("createWindows with custom layout failed: %v", err)
+	}
+}
+
+func TestAllowCommands(t *testing.T) {
+	t.Parallel()
+
+	sm := NewSessionManager("", nil, false)
+
+	extra := []string{"just", "lazygit", "direnv exec", "k9s", "  ", ""}
+	for _, command := range []string{"just build", "lazygit", "direnv exec . nvim", "k9s -n prod"} {
+		if sm.isCommandAllowed(command) {
+			t.Errorf("isCommandAllowed(%q) should be false before AllowCommands", command)
+		}
+	}
+
+	sm.AllowCommands(extra)
+
+	tests := []struct {
+		command string
+		allowed bool
+	}{
+		{command: "just", allowed: true},
+		{command: "just build", allowed: true},
+		{command: "lazygit", allowed: true},
+		{command: "direnv exec . nvim", allowed: true},
+		{command: "direnv  exec . nvim", allowed: true},
+		{command: "k9s -n prod", allowed: true},
+		// Prefixes match whole words only
+		{command: "justfoo", allowed: false},
+		{command: "direnv allow", allowed: false},
+		{command: "direnv", allowed: false},
+		// Built-in patterns still apply
+		{command: "nvim", allowed: true},
+		{command: "rm -rf /", allowed: false},
+	}
+
+	for _, tt := range tests {
+		if got := sm.isCommandAllowed(tt.command); got != tt.allowed {
+			t.Errorf("isCommandAllowed(%q) = %v, want %v", tt.command, got, tt.allowed)
+		}
+	}
+
+	// Extensions are per manager
+	if NewSessionManager("", nil, false).isCommandAllowed("just build") {
+		t.Error("AllowCommands should not affect other session managers")
+	}
+}
+
+func TestCommandPrefixPattern(t *testing.T) {
+	t.Parallel()
+
+	if CommandPrefixPattern("   ") != nil {
+		t.Error("CommandPrefixPattern() of a blank prefix should be nil")
+	}
+
+	// Regex metacharacters are matched literally
+	pattern := CommandPrefixPattern("c++")
+	if !pattern.MatchString("c++ main.cc") {
+		t.Error("pattern should match the literal prefix")
+	}
+	if pattern.MatchString("cc main.cc") {
+		t.Error("pattern should not treat + as a regex operator")
+	}
+}
+
+func TestAttachArgs(t *testing.T) {
+	tests := []struct {
+		name   string
+		inside bool
+		want   string
+	}{
+		{"outside tmux attaches", false, "attach-session -t proj-1"},
+		{"inside tmux switches client", true, "switch-client -t proj-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Join(attachArgs("proj-1", tt.inside), " "); got != tt.want {
+				t.Errorf("attachArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInsideTmux(t *testing.T) {
+	t.Setenv("TMUX", "")
+	if InsideTmux() {
+		t.Error("InsideTmux() = true with empty $TMUX")
+	}
+
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1234,0")
+	if !InsideTmux() {
+		t.Error("InsideTmux() = false with $TMUX set")
+	}
+}
+
+func TestGetEnvironment_Integration(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not found in PATH, skipping integration test")
+	}
+
+	tmpDir := t.TempDir()
+	sm := newTestSessionManager(t, "test-", nil)
+	sessionName := sm.GetSessionName("getenv")
+
+	_ = exec.Command("tmux", "-L", testSocket(t), "kill-session", "-t", sessionName).Run()
+	if err := exec.Command("tmux", "-L", testSocket(t), "new-session", "-d", "-s", sessionName, "-c", tmpDir).Run(); err != nil {
+		t.Fatalf("Failed to create test session: %v", err)
+	}
+	defer func() {
+		_ = exec.Command("tmux", "-L", testSocket(t), "kill-session", "-t", sessionName).Run()
+	}()
+
+	if err := sm.SetEnvironment(sessionName, SessionEnvironment("getenv", tmpDir, "")); err != nil {
+		t.Fatalf("SetEnvironment() error: %v", err)
+	}
+
+	got, err := sm.GetEnvironment(sessionName, "RIG_WORKTREE")
+	if err != nil {
+		t.Fatalf("GetEnvironment() error: %v", err)
+	}
+	if got != tmpDir {
+		t.Errorf("GetEnvironment(RIG_WORKTREE) = %q, want %q", got, tmpDir)
+	}
+
+	got, err = sm.GetEnvironment(sessionName, "RIG_UNSET")
+	if err != nil || got != "" {
+		t.Errorf("GetEnvironment(RIG_UNSET) = %q, %v; want empty, nil", got, err)
+	}
+
+	if _, err := sm.GetEnvironment("test-no-such-session", "RIG_TICKET"); err == nil {
+		t.Error("GetEnvironment() on a missing session should fail")
+	}
+}
+
+func TestSessionEnvironment(t *testing.T) {
+	env := SessionEnvironment("proj-1", "/wt", "")
+	if _, ok := env["RIG_NOTE"]; ok {
+		t.Error("RIG_NOTE should be omitted without a note")
+	}
+
+	env = MergeEnvironment("proj-1", "/wt", "/notes/proj-1.md", map[string]string{"RIG_BRANCH": "proj-1"})
+	if env["RIG_NOTE"] != "/notes/proj-1.md" || env["RIG_BRANCH"] != "proj-1" || env["RIG_TICKET"] != "proj-1" {
+		t.Errorf("MergeEnvironment() = %v", env)
+	}
+}
+
+func TestBuildSession_Environment_Integration(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not found in PATH, skipping integration test")
+	}
+
+	tmpDir := t.TempDir()
+	sm := newTestSessionManager(t, "test-", []WindowConfig{{Name: "note"}, {Name: "code"}})
+	sm.AddEnvironment(map[string]string{"RIG_BRANCH": "feature/env"})
+	sessionName := sm.GetSessionName("env")
+
+	_ = exec.Command("tmux", "-L", testSocket(t), "kill-session", "-t", sessionName).Run()
+	defer func() {
+		_ = exec.Command("tmux", "-L", testSocket(t), "kill-session", "-t", sessionName).Run()
+	}()
+
+	if err := sm.buildSession(sessionName, "env", tmpDir, "/notes/env.md"); err != nil {
+		t.Fatalf("buildSession() error: %v", err)
+	}
+
+	for key, want := range map[string]string{"RIG_BRANCH": "feature/env", "RIG_NOTE": "/notes/env.md", "RIG_TICKET": "env"} {
+		got, err := sm.GetEnvironment(sessionName, key)
+		if err != nil || got != want {
+			t.Errorf("GetEnvironment(%s) = %q, %v; want %q", key, got, err, want)
+		}
+	}
+
+	// The first pane's shell starts before set-environment runs, so it
+	// needs the variables from new-session -e
+	target := fmt.Sprintf("%s:%d", sessionName, sm.getBaseIndex())
+	output, err := exec.Command("tmux", "-L", testSocket(t), "display-message", "-p", "-t", target, "#{pane_pid}").Output()
+	if err != nil {
+		t.Fatalf("display-message error: %v", err)
+	}
+	environ, err := os.ReadFile(filepath.Join("/proc", strings.TrimSpace(string(output)), "environ"))
+	if err != nil {
+		t.Skipf("cannot read pane environment: %v", err)
+	}
+	if !strings.Contains("\x00"+string(environ), "\x00RIG_BRANCH=feature/env\x00") {
+		t.Error("first pane should start with RIG_BRANCH in its environment")
+	}
+}
+
+func TestParseSessionInfo(t *testing.T) {
+	output := "rig-proj-1\t1\t3\t1700000000\t1700003600\nbad line\nscratch\t0\t1\t0\t\n"
+
+	got := parseSessionInfo(output)
+
+	if len(got) != 2 {
+		t.Fatalf("parseSessionInfo() = %+v, want 2 sessions", got)
+	}
+	if got[0].Name != "rig-proj-1" || got[0].Attached != 1 || got[0].Windows != 3 {
+		t.Errorf("session = %+v", got[0])
+	}
+	if !got[0].Created.Equal(time.Unix(1700000000, 0)) || !got[0].Activity.Equal(time.Unix(1700003600, 0)) {
+		t.Errorf("times = %v, %v", got[0].Created, got[0].Activity)
+	}
+	if !got[1].Created.IsZero() || !got[1].Activity.IsZero() {
+		t.Errorf("missing times should be zero, got %+v", got[1])
+	}
+}
+
+func TestListSessionInfo_Integration(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not found in PATH, skipping integration test")
+	}
+
+	sm := newTestSessionManager(t, "test-", nil)
+	sessionName := sm.GetSessionName("info")
+	_ = exec.Command("tmux", "-L", testSocket(t), "kill-session", "-t", sessionName).Run()
+	if err := exec.Command("tmux", "-L", testSocket(t), "new-session", "-d", "-s", sessionName, "sleep 30").Run(); err != nil {
+		t.Fatalf("Failed to create test session: %v", err)
+	}
+	defer func() {
+		_ = exec.Command("tmux", "-L", testSocket(t), "kill-session", "-t", sessionName).Run()
+	}()
+
+	infos, err := sm.ListSessionInfo()
+	if err != nil {
+		t.Fatalf("ListSessionInfo() error: %v", err)
+	}
+	for _, info := range infos {
+		if info.Name == sessionName {
+			if info.Windows != 1 || info.Created.IsZero() {
+				t.Errorf("session info = %+v", info)
+			}
+			return
+		}
+	}
+	t.Errorf("ListSessionInfo() = %+v, missing %s", infos, sessionName)
+}
@@ -0,0 +1,350 @@
+package tmux
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// ErrSessionExists is returned when restoring a session that is already running
+var ErrSessionExists = errors.New("session already exists")
+
+// SessionSnapshot records the shape of a running session so that it can be
+// rebuilt after the tmux server is gone, e.g. after a reboot
+type SessionSnapshot struct {
+	Name        string            `json:"name"`
+	Ticket      string            `json:"ticket,omitempty"`
+	Worktree    string            `json:"worktree,omitempty"`
+	Environment map[string]string `json:"environment,omitempty"` // Variables rig set: RIG_* and profile env
+	SavedAt     time.Time         `json:"saved_at"`
+	Windows     []WindowSnapshot  `json:"windows"`
+}
+
+// WindowSnapshot records a window, its layout and its panes in order
+type WindowSnapshot struct {
+	Name   string         `json:"name"`
+	Layout string         `json:"layout,omitempty"` // As printed by #{window_layout}
+	Active bool           `json:"active,omitempty"`
+	Panes  []PaneSnapshot `json:"panes"`
+}
+
+// PaneSnapshot records a pane's directory and the command running in it.
+// Command is empty when the pane was sitting at a shell prompt.
+type PaneSnapshot struct {
+	WorkingDir string `json:"working_dir"`
+	Command    string `json:"command,omitempty"`
+}
+
+// SnapshotStore holds saved sessions keyed by session name
+type SnapshotStore struct {
+	Path     string
+	Sessions map[string]SessionSnapshot
+}
+
+// shells are foreground processes that mean "nothing to restore"
+var shells = map[string]bool{
+	"bash": true, "zsh": true, "fish": true, "sh": true, "dash": true,
+	"ksh": true, "tcsh": true, "csh": true, "nu": true, "login": true,
+}
+
+// DefaultSnapshotPath returns $XDG_STATE_HOME/rig/sessions.json, defaulting
+// to ~/.local/state/rig/sessions.json
+func DefaultSnapshotPath() (string, error) {
+	if stateHome := os.Getenv("XDG_STATE_HOME"); stateHome != "" {
+		return filepath.Join(stateHome, "rig", "sessions.json"), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get home directory")
+	}
+	return filepath.Join(homeDir, ".local", "state", "rig", "sessions.json"), nil
+}
+
+// LoadSnapshotStore reads the snapshot file at path. A missing file is an empty store.
+func LoadSnapshotStore(path string) (*SnapshotStore, error) {
+	store := &SnapshotStore{Path: path, Sessions: make(map[string]SessionSnapshot)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read session snapshots")
+	}
+
+	if err := json.Unmarshal(data, &store.Sessions); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse session snapshots %s", path)
+	}
+	if store.Sessions == nil {
+		store.Sessions = make(map[string]SessionSnapshot)
+	}
+
+	return store, nil
+}
+
+// Save writes the snapshot file, readable only by the owner since it
+// records commands and paths
+func (s *SnapshotStore) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0700); err != nil {
+		return errors.Wrap(err, "failed to create snapshot directory")
+	}
+
+	data, err := json.MarshalIndent(s.Sessions, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode session snapshots")
+	}
+
+	if err := os.WriteFile(s.Path, append(data, '\n'), 0600); err != nil {
+		return errors.Wrap(err, "failed to write session snapshots")
+	}
+
+	return nil
+}
+
+// Names returns the saved session names in sorted order
+func (s *SnapshotStore) Names() []string {
+	names := make([]string, 0, len(s.Sessions))
+	for name := range s.Sessions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Snapshot records the windows, layouts, pane directories and foreground
+// commands of a running session
+func (sm *SessionManager) Snapshot(sessionName string) (*SessionSnapshot, error) {
+	if !sm.sessionExists(sessionName) {
+		return nil, errors.Newf("session does not exist: %s", sessionName)
+	}
+
+	snapshot := &SessionSnapshot{Name: sessionName, SavedAt: time.Now()}
+	snapshot.Ticket, _ = sm.GetEnvironment(sessionName, "RIG_TICKET")
+	snapshot.Worktree, _ = sm.GetEnvironment(sessionName, "RIG_WORKTREE")
+
+	environment, err := sm.sessionVariables(sessionName)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Environment = environment
+
+	windowOutput, err := sm.tmuxCmd("list-windows", "-t", sessionName, "-F",
+		"#{window_index}\t#{window_name}\t#{window_layout}\t#{window_active}").Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list windows of %s", sessionName)
+	}
+
+	paneOutput, err := sm.tmuxCmd("list-panes", "-s", "-t", sessionName, "-F",
+		"#{window_index}\t#{pane_pid}\t#{pane_current_command}\t#{pane_current_path}").Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list panes of %s", sessionName)
+	}
+
+	snapshot.Windows = parseSnapshot(string(windowOutput), string(paneOutput), foregroundCommands())
+	return snapshot, nil
+}
+
+// sessionVariables returns the variables set in a session's environment,
+// leaving out those tmux copies from the client (update-environment, e.g.
+// SSH_AUTH_SOCK), which would be stale in a restored session
+func (sm *SessionManager) sessionVariables(sessionName string) (map[string]string, error) {
+	output, err := sm.tmuxCmd("show-environment", "-t", sessionName).Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read environment of session %s", sessionName)
+	}
+
+	// Without the option every variable is kept, which is only wrong for
+	// client variables
+	updated, _ := sm.tmuxCmd("show-options", "-gv", "update-environment").Output()
+	return parseSessionVariables(string(output), strings.Fields(string(updated))), nil
+}
+
+// parseSessionVariables parses show-environment output, whose set variables
+// print as KEY=value and removed ones as -KEY, skipping the keys in skip
+func parseSessionVariables(output string, skip []string) map[string]string {
+	vars := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || key == "" || strings.HasPrefix(key, "-") || slices.Contains(skip, key) {
+			continue
+		}
+		vars[key] = value
+	}
+	return vars
+}
+
+// parseSnapshot builds window snapshots from list-windows and list-panes
+// output. children maps a pane's shell PID to the command line of the
+// process running under it.
+func parseSnapshot(windowOutput, paneOutput string, children map[int]string) []WindowSnapshot {
+	var windows []WindowSnapshot
+	byIndex := make(map[string]int)
+
+	for _, line := range strings.Split(strings.TrimSpace(windowOutput), "\n") {
+		fields := strings.SplitN(line, "\t", 4)
+		if len(fields) < 4 {
+			continue
+		}
+		byIndex[fields[0]] = len(windows)
+		windows = append(windows, WindowSnapshot{
+			Name:   fields[1],
+			Layout: fields[2],
+			Active: fields[3] == "1",
+		})
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(paneOutput), "\n") {
+		fields := strings.SplitN(line, "\t", 4)
+		if len(fields) < 4 {
+			continue
+		}
+		i, ok := byIndex[fields[0]]
+		if !ok {
+			continue
+		}
+
+		pane := PaneSnapshot{WorkingDir: fields[3]}
+		if current := fields[2]; !shells[current] {
+			// pane_current_command is only the program name; prefer the full
+			// command line of the process the pane's shell is running
+			pane.Command = current
+			if pid, err := strconv.Atoi(fields[1]); err == nil && children[pid] != "" {
+				pane.Command = children[pid]
+			}
+		}
+		windows[i].Panes = append(windows[i].Panes, pane)
+	}
+
+	// A single pane needs no layout, and its saved geometry would only
+	// be wrong for a different terminal size
+	for i := range windows {
+		if len(windows[i].Panes) < 2 {
+			windows[i].Layout = ""
+		}
+	}
+
+	return windows
+}
+
+// foregroundCommands maps each process ID to the command line of its first
+// child, which for a pane's shell is the program it is running
+func foregroundCommands() map[int]string {
+	output, err := exec.Command("ps", "-A", "-o", "pid=,ppid=,args=").Output()
+	if err != nil {
+		return nil
+	}
+	return parseProcessTable(string(output))
+}
+
+// parseProcessTable parses `ps -o pid=,ppid=,args=` output into a map from
+// parent PID to the command line of its lowest-numbered child
+func parseProcessTable(output string) map[int]string {
+	children := make(map[int]string)
+	lowest := make(map[int]int)
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		ppid, err2 := strconv.Atoi(fields[1])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		if existing, ok := lowest[ppid]; ok && existing < pid {
+			continue
+		}
+		lowest[ppid] = pid
+		children[ppid] = strings.Join(fields[2:], " ")
+	}
+
+	return children
+}
+
+// WindowsFromSnapshot converts a snapshot back into window configuration.
+// Commands outside the allowlist are dropped with a warning rather than
+// failing the restore.
+func (sm *SessionManager) WindowsFromSnapshot(snapshot *SessionSnapshot) []WindowConfig {
+	windows := make([]WindowConfig, 0, len(snapshot.Windows))
+
+	for _, saved := range snapshot.Windows {
+		window := WindowConfig{Name: saved.Name, Layout: saved.Layout}
+		for i, pane := range saved.Panes {
+			command := pane.Command
+			if command != "" && sm.ValidateCommands && !sm.isCommandAllowed(command) {
+				fmt.Printf("Warning: Not restarting %q in %s:%s (not in the command allowlist)\n", command, snapshot.Name, saved.Name)
+				command = ""
+			}
+
+			if i == 0 {
+				window.Command = command
+				window.WorkingDir = pane.WorkingDir
+				continue
+			}
+			window.Panes = append(window.Panes, PaneConfig{Command: command, WorkingDir: pane.WorkingDir})
+		}
+		windows = append(windows, window)
+	}
+
+	return windows
+}
+
+// RestoreSession rebuilds a saved session without attaching to it.
+// Returns ErrSessionExists if a session with that name is already running.
+func (sm *SessionManager) RestoreSession(snapshot *SessionSnapshot) error {
+	if sm.sessionExists(snapshot.Name) {
+		return errors.Wrapf(ErrSessionExists, "%s", snapshot.Name)
+	}
+	if len(snapshot.Windows) == 0 {
+		return errors.Newf("snapshot of %s has no windows", snapshot.Name)
+	}
+
+	worktreePath := snapshot.Worktree
+	if worktreePath == "" && len(snapshot.Windows[0].Panes) > 0 {
+		worktreePath = snapshot.Windows[0].Panes[0].WorkingDir
+	}
+
+	configured := sm.Windows
+	sm.Windows = sm.WindowsFromSnapshot(snapshot)
+	defer func() { sm.Windows = configured }()
+
+	if sm.Verbose {
+		fmt.Printf("Restoring tmux session '%s'...\n", snapshot.Name)
+	}
+
+	ticket := snapshot.Ticket
+	if ticket == "" {
+		ticket = strings.TrimPrefix(snapshot.Name, sm.SessionPrefix)
+	}
+
+	// The saved environment brings back the ticket context and profile
+	// variables the session was created with
+	environment := sm.Environment
+	sm.Environment = maps.Clone(environment)
+	sm.AddEnvironment(snapshot.Environment)
+	defer func() { sm.Environment = environment }()
+
+	if err := sm.buildSession(snapshot.Name, ticket, worktreePath, snapshot.Environment["RIG_NOTE"]); err != nil {
+		return err
+	}
+
+	// Return to the window that was active when the session was saved
+	for i, window := range snapshot.Windows {
+		if window.Active && i > 0 {
+			return sm.selectWindow(snapshot.Name, sm.getBaseIndex()+i)
+		}
+	}
+	return nil
+}
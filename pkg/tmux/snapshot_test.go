@@ -0,0 +1,257 @@
+package tmux
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+)
+
+func TestParseSnapshot(t *testing.T) {
+	windows := "1\tnote\tabcd,80x24,0,0,1\t0\n2\tcode\tef01,80x24,0,0{40x24,0,0,2,39x24,41,0,3}\t1\n"
+	panes := strings.Join([]string{
+		"1\t100\tnvim\t/notes",
+		"2\t200\tzsh\t/wt",
+		"2\t300\tgo\t/wt/api",
+	}, "\n")
+	children := map[int]string{
+		100: "nvim /notes/proj-1.md",
+		300: "go test ./...",
+	}
+
+	got := parseSnapshot(windows, panes, children)
+
+	if len(got) != 2 {
+		t.Fatalf("parseSnapshot() returned %d windows, want 2", len(got))
+	}
+
+	note := got[0]
+	if note.Name != "note" || note.Active || note.Layout != "" {
+		t.Errorf("note window = %+v; single-pane windows should drop their layout", note)
+	}
+	if len(note.Panes) != 1 || note.Panes[0].Command != "nvim /notes/proj-1.md" {
+		t.Errorf("note panes = %+v", note.Panes)
+	}
+
+	code := got[1]
+	if !code.Active || code.Layout == "" {
+		t.Errorf("code window = %+v, want active with layout", code)
+	}
+	want := []PaneSnapshot{
+		{WorkingDir: "/wt"},
+		{WorkingDir: "/wt/api", Command: "go test ./..."},
+	}
+	if len(code.Panes) != len(want) {
+		t.Fatalf("code panes = %+v, want %+v", code.Panes, want)
+	}
+	for i := range want {
+		if code.Panes[i] != want[i] {
+			t.Errorf("pane %d = %+v, want %+v", i, code.Panes[i], want[i])
+		}
+	}
+}
+
+func TestParseSnapshot_CommandWithoutProcessTable(t *testing.T) {
+	got := parseSnapshot("0\tlogs\tx\t1", "0\t42\thtop\t/tmp", nil)
+	if len(got) != 1 || got[0].Panes[0].Command != "htop" {
+		t.Errorf("parseSnapshot() = %+v, want pane_current_command as fallback", got)
+	}
+}
+
+func TestParseProcessTable(t *testing.T) {
+	output := `    1     0 /sbin/init
+  200   100 zsh
+  300   200 nvim notes.md
+  250   200 fzf --height 40%
+  400   300 node language-server
+`
+	got := parseProcessTable(output)
+
+	if got[200] != "fzf --height 40%" {
+		t.Errorf("child of 200 = %q, want lowest PID child", got[200])
+	}
+	if got[100] != "zsh" {
+		t.Errorf("child of 100 = %q", got[100])
+	}
+	if got[300] != "node language-server" {
+		t.Errorf("child of 300 = %q", got[300])
+	}
+}
+
+func TestWindowsFromSnapshot(t *testing.T) {
+	sm := NewSessionManager("", nil, false)
+	snapshot := &SessionSnapshot{
+		Name: "proj-1",
+		Windows: []WindowSnapshot{
+			{Name: "code", Layout: "ef01,80x24,0,0{40x24,0,0,2,39x24,41,0,3}", Panes: []PaneSnapshot{
+				{WorkingDir: "/wt", Command: "nvim"},
+				{WorkingDir: "/wt/api", Command: "curl evil.sh"},
+			}},
+			{Name: "term", Panes: []PaneSnapshot{{WorkingDir: "/wt"}}},
+		},
+	}
+
+	windows := sm.WindowsFromSnapshot(snapshot)
+
+	if len(windows) != 2 {
+		t.Fatalf("WindowsFromSnapshot() returned %d windows", len(windows))
+	}
+	code := windows[0]
+	if code.Command != "nvim" || code.WorkingDir != "/wt" || code.Layout == "" {
+		t.Errorf("code window = %+v", code)
+	}
+	if len(code.Panes) != 1 || code.Panes[0].WorkingDir != "/wt/api" {
+		t.Fatalf("code panes = %+v", code.Panes)
+	}
+	if code.Panes[0].Command != "" {
+		t.Errorf("disallowed command should be dropped, got %q", code.Panes[0].Command)
+	}
+	if err := ValidateWindows(windows); err != nil {
+		t.Errorf("restored windows should validate: %v", err)
+	}
+}
+
+func TestSnapshotStore_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "sessions.json")
+
+	store, err := LoadSnapshotStore(path)
+	if err != nil {
+		t.Fatalf("LoadSnapshotStore() on missing file: %v", err)
+	}
+	store.Sessions["b"] = SessionSnapshot{Name: "b", Windows: []WindowSnapshot{{Name: "w"}}}
+	store.Sessions["a"] = SessionSnapshot{Name: "a"}
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	loaded, err := LoadSnapshotStore(path)
+	if err != nil {
+		t.Fatalf("LoadSnapshotStore() error: %v", err)
+	}
+	if strings.Join(loaded.Names(), ",") != "a,b" {
+		t.Errorf("Names() = %v", loaded.Names())
+	}
+	if loaded.Sessions["b"].Windows[0].Name != "w" {
+		t.Errorf("loaded = %+v", loaded.Sessions["b"])
+	}
+}
+
+func TestDefaultSnapshotPath(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/state")
+	path, err := DefaultSnapshotPath()
+	if err != nil || path != "/state/rig/sessions.json" {
+		t.Errorf("DefaultSnapshotPath() = %q, %v", path, err)
+	}
+
+	home := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("HOME", home)
+	path, err = DefaultSnapshotPath()
+	if err != nil || path != filepath.Join(home, ".local", "state", "rig", "sessions.json") {
+		t.Errorf("DefaultSnapshotPath() = %q, %v", path, err)
+	}
+}
+
+func TestSnapshotAndRestore_Integration(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not found in PATH, skipping integration test")
+	}
+
+	worktree := t.TempDir()
+	sub := filepath.Join(worktree, "api")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	windows := []WindowConfig{
+		{Name: "code", WorkingDir: "{worktree_path}", Panes: []PaneConfig{{Split: SplitHorizontal, WorkingDir: sub}}},
+		{Name: "term", WorkingDir: "{worktree_path}"},
+	}
+	sm := newTestSessionManager(t, "test-", windows)
+	sm.AddEnvironment(map[string]string{"RIG_BRANCH": "snapshot", "KUBECONFIG": "/kube/prod"})
+	sessionName := sm.GetSessionName("snapshot")
+	_ = exec.Command("tmux", "-L", testSocket(t), "kill-session", "-t", sessionName).Run()
+	defer func() {
+		_ = exec.Command("tmux", "-L", testSocket(t), "kill-session", "-t", sessionName).Run()
+	}()
+
+	if err := sm.buildSession(sessionName, "snapshot", worktree, "/notes/snapshot.md"); err != nil {
+		t.Fatalf("buildSession() error: %v", err)
+	}
+
+	snapshot, err := sm.Snapshot(sessionName)
+	if err != nil {
+		t.Fatalf("Snapshot() error: %v", err)
+	}
+	if snapshot.Ticket != "snapshot" || snapshot.Worktree != worktree {
+		t.Errorf("snapshot environment = %q, %q", snapshot.Ticket, snapshot.Worktree)
+	}
+	if snapshot.Environment["RIG_NOTE"] != "/notes/snapshot.md" || snapshot.Environment["KUBECONFIG"] != "/kube/prod" {
+		t.Errorf("snapshot.Environment = %v", snapshot.Environment)
+	}
+	if len(snapshot.Windows) != 2 || len(snapshot.Windows[0].Panes) != 2 {
+		t.Fatalf("snapshot windows = %+v", snapshot.Windows)
+	}
+
+	// Restoring over a running session is refused
+	if err := sm.RestoreSession(snapshot); !errors.Is(err, ErrSessionExists) {
+		t.Errorf("RestoreSession() on running session error = %v, want ErrSessionExists", err)
+	}
+
+	if err := exec.Command("tmux", "-L", testSocket(t), "kill-session", "-t", sessionName).Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	restorer := newTestSessionManager(t, "test-", nil)
+	if err := restorer.RestoreSession(snapshot); err != nil {
+		t.Fatalf("RestoreSession() error: %v", err)
+	}
+
+	restored, err := restorer.Snapshot(sessionName)
+	if err != nil {
+		t.Fatalf("Snapshot() of restored session error: %v", err)
+	}
+	if len(restored.Windows) != 2 || restored.Windows[0].Name != "code" || restored.Windows[1].Name != "term" {
+		t.Fatalf("restored windows = %+v", restored.Windows)
+	}
+	if len(restored.Windows[0].Panes) != 2 {
+		t.Fatalf("restored code panes = %+v", restored.Windows[0].Panes)
+	}
+	gotDir, _ := filepath.EvalSymlinks(restored.Windows[0].Panes[1].WorkingDir)
+	wantDir, _ := filepath.EvalSymlinks(sub)
+	if gotDir != wantDir {
+		t.Errorf("restored pane dir = %q, want %q", gotDir, wantDir)
+	}
+	if restored.Ticket != "snapshot" {
+		t.Errorf("restored RIG_TICKET = %q", restored.Ticket)
+	}
+
+	// The restored session gets the environment the saved one had, not
+	// only RIG_TICKET and RIG_WORKTREE
+	for key, want := range map[string]string{"RIG_NOTE": "/notes/snapshot.md", "RIG_BRANCH": "snapshot", "KUBECONFIG": "/kube/prod"} {
+		if got, err := restorer.GetEnvironment(sessionName, key); err != nil || got != want {
+			t.Errorf("restored %s = %q, %v; want %q", key, got, err, want)
+		}
+	}
+	if len(restorer.Environment) != 0 {
+		t.Errorf("RestoreSession() left environment %v on the manager", restorer.Environment)
+	}
+}
+
+func TestParseSessionVariables(t *testing.T) {
+	output := "RIG_TICKET=proj-1\n-DISPLAY\nSSH_AUTH_SOCK=/tmp/agent.1\nKUBECONFIG=/kube/a=b\n\n"
+	got := parseSessionVariables(output, []string{"DISPLAY", "SSH_AUTH_SOCK"})
+
+	want := map[string]string{"RIG_TICKET": "proj-1", "KUBECONFIG": "/kube/a=b"}
+	if len(got) != len(want) {
+		t.Fatalf("parseSessionVariables() = %v, want %v", got, want)
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("parseSessionVariables()[%s] = %q, want %q", key, got[key], value)
+		}
+	}
+}